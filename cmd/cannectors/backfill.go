@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cannectors/runtime/internal/factory"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/internal/runtime"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// Backfill command flags
+var (
+	backfillFrom     string
+	backfillTo       string
+	backfillStep     string
+	backfillParallel int
+	backfillRestart  bool
+)
+
+// backfillProgressSuffix is appended to the pipeline ID to store the progress
+// of its backfill, apart from its incremental state.
+const backfillProgressSuffix = ".backfill"
+
+// backfillProgressKey is the state key holding the backfill progress.
+const backfillProgressKey = "backfill"
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill <config-file>",
+	Short: "Run a pipeline over an explicit time range, one window at a time",
+	Long: `Run a pipeline once per time window between --from and --to, to re-sync
+a date range without editing state files or queries.
+
+Each execution reads the records of one window: the window bounds are sent
+unchanged in the timestamp parameters of the input module (for httpPolling,
+statePersistence.timestamp.queryParam/bodyField and
+untilQueryParam/untilBodyField; for database, {{lastRunTimestamp}} or
+:timestampParam and {{windowEnd}}). Consecutive windows share a bound, so
+the query or API decides which window a record on a bound belongs to: with
+"updated_at > {{lastRunTimestamp}} AND updated_at <= {{windowEnd}}", as in
+incremental runs, each window covers (start, end]. The incremental state of
+the pipeline is neither read nor updated, and the input schedule is ignored.
+
+Completed windows are saved in the state store, so that an interrupted or
+failed backfill resumes with the remaining windows when run again with the
+same range and step (--restart starts over).
+
+--from and --to accept dates (UTC) or RFC 3339 timestamps. --step accepts
+days (1d), weeks (1w) or Go durations (6h, 30m).
+
+Examples:
+  cannectors backfill orders.yaml --from 2026-01-01 --to 2026-02-01
+  cannectors backfill orders.yaml --from 2026-01-01 --to 2026-02-01 --step 1w --parallel 4
+  cannectors backfill orders.yaml --from 2026-01-26T00:00:00Z --to 2026-01-27T00:00:00Z --step 1h --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run:  runBackfill,
+}
+
+func init() {
+	backfillCmd.Flags().StringVar(&backfillFrom, "from", "", "Start of the time range (required)")
+	backfillCmd.Flags().StringVar(&backfillTo, "to", "", "End of the time range (required)")
+	backfillCmd.Flags().StringVar(&backfillStep, "step", "1d", "Window size (e.g. 1d, 1w, 6h)")
+	backfillCmd.Flags().IntVar(&backfillParallel, "parallel", 1, "Number of windows executed concurrently")
+	backfillCmd.Flags().BoolVar(&backfillRestart, "restart", false, "Ignore saved progress and run all windows")
+	backfillCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and prepare without executing output module")
+	_ = backfillCmd.MarkFlagRequired("from")
+	_ = backfillCmd.MarkFlagRequired("to")
+}
+
+// backfillProgress is the progress of a backfill, saved after each window.
+type backfillProgress struct {
+	From      time.Time   `json:"from"`
+	To        time.Time   `json:"to"`
+	Step      string      `json:"step"`
+	Completed []time.Time `json:"completed"` // start of the completed windows
+}
+
+// matches reports whether the progress was saved by a backfill of the same
+// range and step.
+func (p *backfillProgress) matches(from, to time.Time, step string) bool {
+	return p.From.Equal(from) && p.To.Equal(to) && p.Step == step
+}
+
+// isCompleted reports whether the window starting at start was completed.
+func (p *backfillProgress) isCompleted(start time.Time) bool {
+	for _, completed := range p.Completed {
+		if completed.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBackfillStep parses a window size: days (1d), weeks (1w) or a Go duration.
+func parseBackfillStep(s string) (time.Duration, error) {
+	var step time.Duration
+	switch {
+	case strings.HasSuffix(s, "d") || strings.HasSuffix(s, "w"):
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid step %q", s)
+		}
+		step = time.Duration(n) * 24 * time.Hour
+		if strings.HasSuffix(s, "w") {
+			step *= 7
+		}
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid step %q", s)
+		}
+		step = d
+	}
+	if step <= 0 {
+		return 0, fmt.Errorf("invalid step %q: must be positive", s)
+	}
+	return step, nil
+}
+
+// backfillWindows splits the range from --from to --to into consecutive windows
+// of step sharing their bounds, the last one ending at to.
+func backfillWindows(from, to time.Time, step time.Duration) []persistence.Window {
+	var windows []persistence.Window
+	for start := from; start.Before(to); start = start.Add(step) {
+		end := start.Add(step)
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, persistence.Window{From: start, To: end})
+	}
+	return windows
+}
+
+// backfillTracker saves the backfill progress in the state store after each
+// completed window. A nil tracker (dry run) saves nothing.
+type backfillTracker struct {
+	store      persistence.StateStore
+	progressID string
+
+	mu       sync.Mutex
+	progress *backfillProgress
+}
+
+// loadBackfillTracker loads the progress of the backfill of pipelineID. Saved
+// progress of another range or step, or any progress with restart, is discarded.
+func loadBackfillTracker(store persistence.StateStore, pipelineID string, from, to time.Time, step string, restart bool) (*backfillTracker, error) {
+	t := &backfillTracker{
+		store:      store,
+		progressID: pipelineID + backfillProgressSuffix,
+		progress:   &backfillProgress{From: from, To: to, Step: step},
+	}
+	if restart {
+		return t, nil
+	}
+
+	state, err := store.Load(t.progressID)
+	if err != nil {
+		return nil, fmt.Errorf("loading backfill progress: %w", err)
+	}
+	if state == nil || state.Keys[backfillProgressKey] == nil {
+		return t, nil
+	}
+	var saved backfillProgress
+	if err := json.Unmarshal(state.Keys[backfillProgressKey], &saved); err != nil {
+		return nil, fmt.Errorf("parsing backfill progress: %w", err)
+	}
+	if saved.matches(from, to, step) {
+		t.progress = &saved
+	}
+	return t, nil
+}
+
+// pending returns the windows not completed yet.
+func (t *backfillTracker) pending(windows []persistence.Window) []persistence.Window {
+	if t == nil {
+		return windows
+	}
+	var pending []persistence.Window
+	for _, w := range windows {
+		if !t.progress.isCompleted(w.From) {
+			pending = append(pending, w)
+		}
+	}
+	return pending
+}
+
+// complete records a completed window and saves the progress.
+func (t *backfillTracker) complete(window persistence.Window) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress.Completed = append(t.progress.Completed, window.From)
+	data, err := json.Marshal(t.progress)
+	if err != nil {
+		return fmt.Errorf("marshaling backfill progress: %w", err)
+	}
+	state := &persistence.State{
+		Keys:      map[string]json.RawMessage{backfillProgressKey: data},
+		UpdatedAt: time.Now(),
+	}
+	if err := t.store.Save(t.progressID, state); err != nil {
+		return fmt.Errorf("saving backfill progress: %w", err)
+	}
+	return nil
+}
+
+// clear deletes the progress once all windows are completed.
+func (t *backfillTracker) clear() error {
+	if t == nil {
+		return nil
+	}
+	if err := t.store.Delete(t.progressID); err != nil {
+		return fmt.Errorf("deleting backfill progress: %w", err)
+	}
+	return nil
+}
+
+// windowOutcome is the result of the execution of a window.
+type windowOutcome struct {
+	window persistence.Window
+	result *connector.ExecutionResult
+	err    error
+}
+
+func runBackfill(_ *cobra.Command, args []string) {
+	from, err := persistence.ParseTimestamp(backfillFrom)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Invalid --from: %v\n", err)
+		os.Exit(ExitValidationError)
+	}
+	to, err := persistence.ParseTimestamp(backfillTo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Invalid --to: %v\n", err)
+		os.Exit(ExitValidationError)
+	}
+	if !from.Before(to) {
+		fmt.Fprintln(os.Stderr, "✗ --from must be before --to")
+		os.Exit(ExitValidationError)
+	}
+	step, err := parseBackfillStep(backfillStep)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Invalid --step: %v\n", err)
+		os.Exit(ExitValidationError)
+	}
+	if backfillParallel < 1 {
+		fmt.Fprintln(os.Stderr, "✗ --parallel must be at least 1")
+		os.Exit(ExitValidationError)
+	}
+
+	pipeline := loadPipeline(args[0])
+	os.Exit(backfill(pipeline, from.UTC(), to.UTC(), step))
+}
+
+// backfill executes the pending windows between from and to and returns the
+// exit code.
+func backfill(pipeline *connector.Pipeline, from, to time.Time, step time.Duration) int {
+	windows := backfillWindows(from, to, step)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Progress is neither loaded nor saved by dry runs
+	var tracker *backfillTracker
+	if !dryRun {
+		store := openStateStore()
+		defer closeStateStore(store)
+
+		// Two backfills of the same pipeline would overwrite each other's progress
+		lock, err := persistence.AcquireLock(ctx, store, pipeline.ID+backfillProgressSuffix, persistence.LockOptions{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Backfill already running: %v\n", err)
+			return ExitRuntimeError
+		}
+		defer func() { _ = lock.Release() }()
+
+		tracker, err = loadBackfillTracker(store, pipeline.ID, from, to, backfillStep, backfillRestart)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			return ExitRuntimeError
+		}
+	}
+
+	pending := tracker.pending(windows)
+	if !quiet {
+		mode := ""
+		if dryRun {
+			mode = " (dry-run mode - output will not be sent)"
+		}
+		fmt.Printf("Backfilling %s from %s to %s%s\n", pipeline.ID, from.Format(time.RFC3339), to.Format(time.RFC3339), mode)
+		fmt.Printf("  Windows: %d of %s", len(windows), backfillStep)
+		if completed := len(windows) - len(pending); completed > 0 {
+			fmt.Printf(" (%d already completed)", completed)
+		}
+		fmt.Println()
+	}
+
+	outcomes := executeBackfillWindows(ctx, pipeline, pending, tracker)
+
+	records, failed := 0, 0
+	for _, o := range outcomes {
+		if o.result != nil {
+			records += o.result.RecordsProcessed
+		}
+		if o.err != nil {
+			failed++
+		}
+	}
+	if notRun := len(pending) - len(outcomes); failed > 0 || notRun > 0 {
+		fmt.Fprintf(os.Stderr, "✗ Backfill incomplete: %d window(s) failed, %d not run\n", failed, notRun)
+		if tracker != nil {
+			fmt.Fprintln(os.Stderr, "  Run the same command again to resume with the remaining windows")
+		}
+		return ExitRuntimeError
+	}
+
+	if err := tracker.clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ %v\n", err)
+	}
+	if !quiet {
+		fmt.Printf("✓ Backfill completed: %d window(s), %d records processed\n", len(outcomes), records)
+	}
+	return ExitSuccess
+}
+
+// executeBackfillWindows executes the pipeline for each window, up to
+// --parallel at a time. No window is started after the first failure or once
+// ctx is done. Outcomes are printed as windows complete.
+func executeBackfillWindows(ctx context.Context, pipeline *connector.Pipeline, windows []persistence.Window, tracker *backfillTracker) []windowOutcome {
+	jobs := make(chan persistence.Window)
+	results := make(chan windowOutcome)
+	var failed atomic.Bool
+
+	var wg sync.WaitGroup
+	for i := 0; i < backfillParallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for w := range jobs {
+				// Windows already dispatched when another one failed are not run
+				if failed.Load() || ctx.Err() != nil {
+					continue
+				}
+				result, err := executeBackfillWindow(ctx, pipeline, w)
+				if err == nil {
+					err = tracker.complete(w)
+				}
+				if err != nil {
+					failed.Store(true)
+				}
+				results <- windowOutcome{window: w, result: result, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, w := range windows {
+			if failed.Load() {
+				return
+			}
+			select {
+			case jobs <- w:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var outcomes []windowOutcome
+	for o := range results {
+		printWindowOutcome(o)
+		outcomes = append(outcomes, o)
+	}
+	return outcomes
+}
+
+// executeBackfillWindow executes the pipeline for a window with fresh modules.
+func executeBackfillWindow(ctx context.Context, pipeline *connector.Pipeline, window persistence.Window) (*connector.ExecutionResult, error) {
+	inputModule, err := factory.CreateInputModule(pipeline.Input)
+	if err != nil {
+		return nil, fmt.Errorf("creating input module: %w", err)
+	}
+	filterModules, err := factory.CreateFilterModules(pipeline.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("creating filter modules: %w", err)
+	}
+	outputModule, err := factory.CreateOutputModule(pipeline.Output)
+	if err != nil {
+		return nil, fmt.Errorf("creating output module: %w", err)
+	}
+
+	executor := runtime.NewExecutorWithModules(inputModule, filterModules, outputModule, dryRun)
+	executor.SetWindow(&window)
+	return executor.ExecuteWithContext(ctx, pipeline)
+}
+
+// printWindowOutcome prints one line per executed window.
+func printWindowOutcome(o windowOutcome) {
+	// The query decides whether each bound is included
+	bounds := fmt.Sprintf("%s → %s", o.window.From.Format(time.RFC3339), o.window.To.Format(time.RFC3339))
+	if o.err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s %v\n", bounds, o.err)
+		return
+	}
+	if !quiet {
+		fmt.Printf("✓ %s %d records processed\n", bounds, o.result.RecordsProcessed)
+	}
+}
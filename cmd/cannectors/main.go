@@ -36,8 +36,15 @@ var (
 	quiet   bool
 	logFile string
 
+	// State store flags
+	stateBackend string
+	statePath    string
+
 	// Run command flags
-	dryRun bool
+	dryRun      bool
+	lockMode    string
+	lockTimeout time.Duration
+	lockLease   time.Duration
 
 	// Build information (set via ldflags during build)
 	version   = "dev"
@@ -133,6 +140,10 @@ CRON Schedule (input module level):
 
 Flags:
   --dry-run   Validate and prepare the pipeline without executing output module
+  --lock      Pipeline lock held during execution, across processes sharing the
+              state store: skip (default) skips the run if another run holds it,
+              wait waits for it (bounded by --lock-timeout), off disables it.
+              A crashed holder's lock expires after --lock-lease.
 
 Exit codes:
   0 - Pipeline executed successfully
@@ -160,12 +171,19 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output (human-readable format)")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress non-error output")
 	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Write logs to file (always JSON format, in addition to console)")
+	rootCmd.PersistentFlags().StringVar(&stateBackend, "state-backend", persistence.BackendFile, "State store backend (file, sqlite)")
+	rootCmd.PersistentFlags().StringVar(&statePath, "state-path", "", "State store location (directory for file, database file for sqlite; default under ./cannectors-data)")
 
 	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and prepare without executing output module")
+	runCmd.Flags().StringVar(&lockMode, "lock", lockModeSkip, "Pipeline lock mode (skip, wait, off)")
+	runCmd.Flags().DurationVar(&lockTimeout, "lock-timeout", 0, "Maximum wait for the pipeline lock with --lock wait (0 = no limit)")
+	runCmd.Flags().DurationVar(&lockLease, "lock-lease", persistence.DefaultLockLease, "Lease after which the lock of a crashed run expires")
 
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(stateCmd)
+	rootCmd.AddCommand(backfillCmd)
 }
 
 func runValidate(_ *cobra.Command, args []string) {
@@ -198,8 +216,21 @@ func runValidate(_ *cobra.Command, args []string) {
 }
 
 func runPipeline(_ *cobra.Command, args []string) {
-	configPath := args[0]
+	pipeline := loadPipeline(args[0])
+
+	// Check if pipeline has a schedule in input module config
+	schedule := scheduler.GetScheduleFromInput(pipeline)
+	if schedule != "" {
+		runScheduledPipeline(pipeline, schedule)
+		return
+	}
+
+	runPipelineOnce(pipeline)
+}
 
+// loadPipeline parses, validates and converts a pipeline configuration file,
+// exiting on errors.
+func loadPipeline(configPath string) *connector.Pipeline {
 	if !quiet {
 		fmt.Printf("Loading pipeline configuration: %s\n", configPath)
 	}
@@ -232,18 +263,12 @@ func runPipeline(_ *cobra.Command, args []string) {
 			fmt.Printf("  Description: %s\n", pipeline.Description)
 		}
 	}
-
-	// Check if pipeline has a schedule in input module config
-	schedule := scheduler.GetScheduleFromInput(pipeline)
-	if schedule != "" {
-		runScheduledPipeline(pipeline, schedule)
-		return
-	}
-
-	runPipelineOnce(pipeline)
+	return pipeline
 }
 
 func runPipelineOnce(pipeline *connector.Pipeline) {
+	lockOptions := parseLockOptions()
+
 	inputModule, err := factory.CreateInputModule(pipeline.Input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "✗ Failed to create input module: %v\n", err)
@@ -263,9 +288,10 @@ func runPipelineOnce(pipeline *connector.Pipeline) {
 	executor := runtime.NewExecutorWithModules(inputModule, filterModules, outputModule, dryRun)
 
 	// Configure state persistence if input module supports it
-	// Create stateStore with default path (input module will use its own if it has custom storagePath)
-	stateStore := persistence.NewStateStore("")
+	// (input module will use its own store if it has a custom storagePath)
+	stateStore := openStateStore()
 	executor.SetStateStore(stateStore)
+	executor.SetLockOptions(lockOptions)
 
 	if !quiet {
 		if dryRun {
@@ -276,6 +302,7 @@ func runPipelineOnce(pipeline *connector.Pipeline) {
 	}
 
 	execResult, execErr := executor.Execute(pipeline)
+	closeStateStore(stateStore)
 
 	opts := cli.OutputOptions{Verbose: verbose, Quiet: quiet, DryRun: dryRun}
 	cli.PrintExecutionResult(execResult, execErr, opts)
@@ -303,7 +330,10 @@ func runScheduledPipeline(pipeline *connector.Pipeline, schedule string) {
 		fmt.Printf("  Schedule: %s\n", schedule)
 	}
 
-	executorAdapter := &PipelineExecutorAdapter{dryRun: dryRun}
+	lockOptions := parseLockOptions()
+	stateStore := openStateStore()
+
+	executorAdapter := &PipelineExecutorAdapter{dryRun: dryRun, stateStore: stateStore, lockOptions: lockOptions}
 	sched := scheduler.NewWithExecutor(executorAdapter)
 
 	if err := sched.Register(pipeline); err != nil {
@@ -347,6 +377,7 @@ func runScheduledPipeline(pipeline *connector.Pipeline, schedule string) {
 
 	if err := sched.Stop(stopCtx); err != nil {
 		fmt.Fprintf(os.Stderr, "⚠ Scheduler stop timeout: %v\n", err)
+		closeStateStore(stateStore)
 		os.Exit(ExitRuntimeError)
 	}
 
@@ -354,9 +385,50 @@ func runScheduledPipeline(pipeline *connector.Pipeline, schedule string) {
 		fmt.Println("✓ Scheduler stopped gracefully")
 	}
 
+	closeStateStore(stateStore)
 	os.Exit(ExitSuccess)
 }
 
+// Pipeline lock modes
+const (
+	lockModeSkip = "skip"
+	lockModeWait = "wait"
+	lockModeOff  = "off"
+)
+
+// parseLockOptions returns the lock options of the --lock flags (nil for off).
+func parseLockOptions() *persistence.LockOptions {
+	switch lockMode {
+	case lockModeSkip:
+		return &persistence.LockOptions{Lease: lockLease}
+	case lockModeWait:
+		return &persistence.LockOptions{Wait: true, WaitTimeout: lockTimeout, Lease: lockLease}
+	case lockModeOff:
+		return nil
+	default:
+		fmt.Fprintf(os.Stderr, "✗ Invalid --lock %q (supported: %s, %s, %s)\n", lockMode, lockModeSkip, lockModeWait, lockModeOff)
+		os.Exit(ExitValidationError)
+		return nil
+	}
+}
+
+// openStateStore opens the state store selected by --state-backend and --state-path.
+func openStateStore() persistence.StateStore {
+	store, err := persistence.OpenStateStore(stateBackend, statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Failed to open state store: %v\n", err)
+		os.Exit(ExitRuntimeError)
+	}
+	return store
+}
+
+// closeStateStore closes the state store, reporting failures.
+func closeStateStore(store persistence.StateStore) {
+	if err := store.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Failed to close state store: %v\n", err)
+	}
+}
+
 func runVersion(_ *cobra.Command, _ []string) {
 	fmt.Printf("Version: %s\n", version)
 	fmt.Printf("Commit: %s\n", commit)
@@ -365,7 +437,9 @@ func runVersion(_ *cobra.Command, _ []string) {
 
 // PipelineExecutorAdapter adapts the runtime.Executor for use with the scheduler.
 type PipelineExecutorAdapter struct {
-	dryRun bool
+	dryRun      bool
+	stateStore  persistence.StateStore
+	lockOptions *persistence.LockOptions
 }
 
 // Execute runs a pipeline using the runtime executor.
@@ -413,9 +487,9 @@ func (a *PipelineExecutorAdapter) Execute(pipeline *connector.Pipeline) (*connec
 	executor := runtime.NewExecutorWithModules(inputModule, filterModules, outputModule, a.dryRun)
 
 	// Configure state persistence if input module supports it
-	// Create stateStore with default path (input module will use its own if it has custom storagePath)
-	stateStore := persistence.NewStateStore("")
-	executor.SetStateStore(stateStore)
+	// (input module will use its own store if it has a custom storagePath)
+	executor.SetStateStore(a.stateStore)
+	executor.SetLockOptions(a.lockOptions)
 
 	return executor.Execute(pipeline)
 }
@@ -2,11 +2,18 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/internal/persistence"
 )
 
 // testFixturePath returns the path to test fixtures
@@ -261,6 +268,119 @@ func TestCLI_ValidateMissingArg(t *testing.T) {
 	}
 }
 
+func TestCLI_State(t *testing.T) {
+	for _, backend := range []string{"file", "sqlite"} {
+		t.Run(backend, func(t *testing.T) {
+			storePath := filepath.Join(t.TempDir(), "state")
+			storeFlags := []string{"--state-backend", backend, "--state-path", storePath}
+			state := func(args ...string) (string, string, int) {
+				return runCLI(t, append(append([]string{}, storeFlags...), append([]string{"state"}, args...)...)...)
+			}
+
+			if _, stderr, exitCode := state("set", "orders", "--last-timestamp", "2026-01-26T00:00:00Z", "--last-id", "5"); exitCode != ExitSuccess {
+				t.Fatalf("state set: exit code %d, stderr: %s", exitCode, stderr)
+			}
+			if _, stderr, exitCode := state("set", "orders", "--last-id", "9", "--key", `output.hashes={"1":"abc"}`); exitCode != ExitSuccess {
+				t.Fatalf("state set: exit code %d, stderr: %s", exitCode, stderr)
+			}
+
+			stdout, _, _ := state("show", "orders")
+			if !strings.Contains(stdout, `"lastId": "9"`) || !strings.Contains(stdout, "output.hashes") {
+				t.Errorf("state show = %s, want lastId 9 and the output.hashes key", stdout)
+			}
+
+			stdout, _, _ = state("history", "orders")
+			if !strings.Contains(stdout, "2026-01-26T00:00:00Z") {
+				t.Errorf("state history = %s, want the previous state", stdout)
+			}
+
+			// Undo the last change
+			if _, stderr, exitCode := state("rollback", "orders"); exitCode != ExitSuccess {
+				t.Fatalf("state rollback: exit code %d, stderr: %s", exitCode, stderr)
+			}
+			stdout, _, _ = state("export", "orders")
+			if !strings.Contains(stdout, `"lastId": "5"`) || strings.Contains(stdout, "output.hashes") {
+				t.Errorf("state after rollback = %s, want lastId 5 without keys", stdout)
+			}
+
+			// Reset, then import the exported state
+			exported := filepath.Join(t.TempDir(), "orders.json")
+			if err := os.WriteFile(exported, []byte(stdout), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, stderr, exitCode := state("reset", "orders"); exitCode != ExitSuccess {
+				t.Fatalf("state reset: exit code %d, stderr: %s", exitCode, stderr)
+			}
+			if _, stderr, _ := state("show", "orders"); !strings.Contains(stderr, "No state") {
+				t.Errorf("state show after reset: stderr = %s, want No state", stderr)
+			}
+			if _, stderr, exitCode := state("import", "orders-copy", exported); exitCode != ExitSuccess {
+				t.Fatalf("state import: exit code %d, stderr: %s", exitCode, stderr)
+			}
+			stdout, _, _ = state("show", "orders-copy")
+			if !strings.Contains(stdout, `"pipelineId": "orders-copy"`) || !strings.Contains(stdout, `"lastId": "5"`) {
+				t.Errorf("imported state = %s", stdout)
+			}
+
+			// Setting the state clears the checkpoint of an interrupted run
+			interrupted := filepath.Join(t.TempDir(), "interrupted.json")
+			checkpoint := `{"lastId": "5", "checkpoint": {"startedAt": "2026-01-27T00:00:00Z", "chunks": 2, "page": 3}}`
+			if err := os.WriteFile(interrupted, []byte(checkpoint), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, stderr, exitCode := state("import", "streamed", interrupted); exitCode != ExitSuccess {
+				t.Fatalf("state import: exit code %d, stderr: %s", exitCode, stderr)
+			}
+			_, stderr, exitCode := state("set", "streamed", "--last-timestamp", "2026-01-20T00:00:00Z")
+			if exitCode != ExitSuccess || !strings.Contains(stderr, "Checkpoint") {
+				t.Fatalf("state set: exit code %d, stderr: %s, want the checkpoint cleared", exitCode, stderr)
+			}
+			stdout, _, _ = state("show", "streamed")
+			if strings.Contains(stdout, "checkpoint") || !strings.Contains(stdout, "2026-01-20T00:00:00Z") {
+				t.Errorf("state after set = %s, want the timestamp set without checkpoint", stdout)
+			}
+
+			// Changes are refused while a run holds the pipeline lock
+			store, err := persistence.OpenStateStore(backend, storePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lock, err := persistence.AcquireLock(context.Background(), store, "orders", persistence.LockOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, args := range [][]string{
+				{"set", "orders", "--last-id", "42"},
+				{"rollback", "orders"},
+				{"reset", "orders"},
+				{"import", "orders", exported},
+			} {
+				if _, stderr, exitCode := state(args...); exitCode != ExitRuntimeError || !strings.Contains(stderr, "is running") {
+					t.Errorf("state %s with the lock held: exit code %d, stderr: %s", args[0], exitCode, stderr)
+				}
+			}
+			if err := lock.Release(); err != nil {
+				t.Fatal(err)
+			}
+			_ = store.Close()
+			stdout, _, _ = state("show", "orders")
+			if strings.Contains(stdout, `"lastId": "42"`) {
+				t.Errorf("state = %s, changed while the lock was held", stdout)
+			}
+			if _, stderr, exitCode := state("set", "orders", "--last-id", "42"); exitCode != ExitSuccess {
+				t.Fatalf("state set after the lock was released: exit code %d, stderr: %s", exitCode, stderr)
+			}
+
+			if _, _, exitCode := state("set", "orders", "--last-timestamp", "yesterday"); exitCode != ExitRuntimeError {
+				t.Errorf("state set with invalid timestamp: exit code %d, want %d", exitCode, ExitRuntimeError)
+			}
+			if _, _, exitCode := state("rollback", "orders", "--steps", "50"); exitCode != ExitRuntimeError {
+				t.Errorf("state rollback beyond history: exit code %d, want %d", exitCode, ExitRuntimeError)
+			}
+		})
+	}
+}
+
 // TestMainFunction ensures main doesn't panic
 func TestMainFunction(t *testing.T) {
 	t.Helper()
@@ -275,3 +395,174 @@ func TestMainFunction(t *testing.T) {
 	// Run should not panic
 	// Note: This will print help to stdout
 }
+
+func TestParseBackfillStep(t *testing.T) {
+	tests := []struct {
+		step    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{step: "1d", want: 24 * time.Hour},
+		{step: "2w", want: 14 * 24 * time.Hour},
+		{step: "6h", want: 6 * time.Hour},
+		{step: "90m", want: 90 * time.Minute},
+		{step: "0d", wantErr: true},
+		{step: "-1h", wantErr: true},
+		{step: "xd", wantErr: true},
+		{step: "daily", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.step, func(t *testing.T) {
+			got, err := parseBackfillStep(tt.step)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBackfillStep(%q) error = %v, wantErr %v", tt.step, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseBackfillStep(%q) = %v, want %v", tt.step, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackfillWindows(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	windows := backfillWindows(from, to, 24*time.Hour)
+	if len(windows) != 3 {
+		t.Fatalf("backfillWindows() = %d windows, want 3", len(windows))
+	}
+	for i, w := range windows {
+		if !w.From.Equal(from.AddDate(0, 0, i)) {
+			t.Errorf("window %d starts at %v", i, w.From)
+		}
+	}
+	// The last window is clipped to the end of the range
+	if !windows[1].To.Equal(windows[2].From) || !windows[2].To.Equal(to) {
+		t.Errorf("windows = %+v, want contiguous windows ending at %v", windows, to)
+	}
+}
+
+func TestBackfillTracker(t *testing.T) {
+	store := persistence.NewFileStateStore(t.TempDir())
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+	windows := backfillWindows(from, to, 24*time.Hour)
+
+	tracker, err := loadBackfillTracker(store, "orders", from, to, "1d", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.complete(windows[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same backfill resumes with the remaining windows
+	tracker, err = loadBackfillTracker(store, "orders", from, to, "1d", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := tracker.pending(windows); len(pending) != 2 || pending[0] != windows[0] || pending[1] != windows[2] {
+		t.Errorf("pending = %+v, want windows 0 and 2", pending)
+	}
+
+	// Another step, or --restart, starts over
+	for _, restart := range []bool{false, true} {
+		step := "1d"
+		if !restart {
+			step = "24h"
+		}
+		tracker, err = loadBackfillTracker(store, "orders", from, to, step, restart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pending := tracker.pending(windows); len(pending) != 3 {
+			t.Errorf("pending with step %s, restart %v = %d windows, want 3", step, restart, len(pending))
+		}
+	}
+
+	// The incremental state of the pipeline is kept apart
+	if exists, _ := store.Exists("orders"); exists {
+		t.Error("backfill progress saved as the pipeline state")
+	}
+	if err := tracker.clear(); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := store.Exists("orders" + backfillProgressSuffix); exists {
+		t.Error("backfill progress not cleared")
+	}
+}
+
+func TestCLI_Backfill(t *testing.T) {
+	var mu sync.Mutex
+	var windows []string
+	failWindow := "2026-01-02T00:00:00Z"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		after, before := r.URL.Query().Get("updated_after"), r.URL.Query().Get("updated_before")
+		mu.Lock()
+		windows = append(windows, after+"/"+before)
+		fail := after == failWindow
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1}]`))
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "backfill.yaml")
+	config := `connector:
+  name: backfill-orders
+  version: "1.0.0"
+  input:
+    type: httpPolling
+    endpoint: ` + server.URL + `/orders
+    schedule: "0 0 * * *"
+    statePersistence:
+      timestamp:
+        enabled: true
+        queryParam: updated_after
+        untilQueryParam: updated_before
+  filters: []
+  output:
+    type: httpRequest
+    endpoint: ` + server.URL + `/import
+    method: POST
+`
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(t.TempDir(), "state")
+	backfill := func() (string, string, int) {
+		return runCLI(t, "--state-path", statePath, "backfill", configPath,
+			"--from", "2026-01-01", "--to", "2026-01-04", "--step", "1d")
+	}
+
+	// The second window fails: the first one is saved as completed
+	_, stderr, exitCode := backfill()
+	if exitCode != ExitRuntimeError || !strings.Contains(stderr, "1 window(s) failed, 1 not run") {
+		t.Fatalf("first backfill: exit code %d, stderr: %s", exitCode, stderr)
+	}
+
+	// The backfill resumes with the failed window
+	mu.Lock()
+	windows, failWindow = nil, ""
+	mu.Unlock()
+	stdout, stderr, exitCode := backfill()
+	if exitCode != ExitSuccess {
+		t.Fatalf("resumed backfill: exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "1 already completed") || !strings.Contains(stdout, "2 window(s)") {
+		t.Errorf("resumed backfill output = %s", stdout)
+	}
+	want := []string{"2026-01-02T00:00:00Z/2026-01-03T00:00:00Z", "2026-01-03T00:00:00Z/2026-01-04T00:00:00Z"}
+	if strings.Join(windows, ",") != strings.Join(want, ",") {
+		t.Errorf("windows requested = %v, want %v", windows, want)
+	}
+}
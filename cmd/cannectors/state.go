@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cannectors/runtime/internal/persistence"
+)
+
+// State command flags
+var (
+	stateSetTimestamp  string
+	stateSetID         string
+	stateSetKeys       []string
+	stateDeleteKeys    []string
+	stateExportOutput  string
+	stateRollbackSteps int
+	stateRollbackTo    string
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and edit persisted pipeline state",
+	Long: `Inspect and edit the state persisted between pipeline executions
+(last timestamp, last ID, cursor, conditional headers and named keys).
+
+The state store is selected with --state-backend and --state-path, as for
+the run command. Every change keeps the replaced state in the pipeline's
+history (last 20 states), so that it can be rolled back. Changes hold the
+pipeline lock, and fail while a run of the pipeline holds it.
+
+Examples:
+  cannectors state show orders-sync
+  cannectors state set orders-sync --last-timestamp 2026-01-26T00:00:00Z
+  cannectors state history orders-sync
+  cannectors state rollback orders-sync --before 2026-01-26T22:00:00Z
+  cannectors state export orders-sync -o orders-sync.json
+  cannectors state import orders-sync orders-sync.json
+  cannectors state reset orders-sync`,
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show <pipeline>",
+	Short: "Print the current state of a pipeline",
+	Args:  cobra.ExactArgs(1),
+	Run:   runStateShow,
+}
+
+var stateSetCmd = &cobra.Command{
+	Use:   "set <pipeline>",
+	Short: "Set fields of the state of a pipeline",
+	Long: `Set fields of the state of a pipeline. Fields that are not given are kept.
+An empty --last-timestamp or --last-id clears the field.
+
+The checkpoint of an interrupted streaming run, if any, is cleared: the next
+run starts from the state that was set instead of resuming the interrupted
+one.
+
+Examples:
+  cannectors state set orders-sync --last-timestamp 2026-01-26T00:00:00Z
+  cannectors state set orders-sync --last-id 12345
+  cannectors state set orders-sync --key 'output.hashes={}' --delete-key filters.0.seen`,
+	Args: cobra.ExactArgs(1),
+	Run:  runStateSet,
+}
+
+var stateResetCmd = &cobra.Command{
+	Use:   "reset <pipeline>",
+	Short: "Delete the state of a pipeline (next run starts from scratch)",
+	Args:  cobra.ExactArgs(1),
+	Run:   runStateReset,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export <pipeline>",
+	Short: "Export the state of a pipeline as JSON",
+	Args:  cobra.ExactArgs(1),
+	Run:   runStateExport,
+}
+
+var stateImportCmd = &cobra.Command{
+	Use:   "import <pipeline> <file>",
+	Short: "Import the state of a pipeline from JSON (\"-\" reads stdin)",
+	Args:  cobra.ExactArgs(2),
+	Run:   runStateImport,
+}
+
+var stateHistoryCmd = &cobra.Command{
+	Use:   "history <pipeline>",
+	Short: "List the previous states of a pipeline, most recent first",
+	Args:  cobra.ExactArgs(1),
+	Run:   runStateHistory,
+}
+
+var stateRollbackCmd = &cobra.Command{
+	Use:   "rollback <pipeline>",
+	Short: "Restore a previous state of a pipeline",
+	Long: `Restore a previous state of a pipeline from its history.
+
+By default the state before the last change is restored. --steps restores an
+older one (as numbered by the history command). --before restores the last
+state saved before the given time, e.g. the state before last night's run.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runStateRollback,
+}
+
+func init() {
+	stateSetCmd.Flags().StringVar(&stateSetTimestamp, "last-timestamp", "", "Last timestamp (RFC 3339, \"YYYY-MM-DD HH:MM:SS\" or Unix epoch)")
+	stateSetCmd.Flags().StringVar(&stateSetID, "last-id", "", "Last processed record ID")
+	stateSetCmd.Flags().StringArrayVar(&stateSetKeys, "key", nil, "Named key as name=<JSON value> (repeatable)")
+	stateSetCmd.Flags().StringArrayVar(&stateDeleteKeys, "delete-key", nil, "Named key to delete (repeatable)")
+
+	stateExportCmd.Flags().StringVarP(&stateExportOutput, "output", "o", "", "Write to file instead of stdout")
+
+	stateRollbackCmd.Flags().IntVar(&stateRollbackSteps, "steps", 1, "Number of changes to undo")
+	stateRollbackCmd.Flags().StringVar(&stateRollbackTo, "before", "", "Restore the last state saved before this time")
+
+	stateCmd.AddCommand(stateShowCmd, stateSetCmd, stateResetCmd, stateExportCmd, stateImportCmd, stateHistoryCmd, stateRollbackCmd)
+}
+
+// runStateCommand opens the state store, runs fn and exits.
+func runStateCommand(fn func(store persistence.StateStore) error) {
+	store := openStateStore()
+	err := fn(store)
+	closeStateStore(store)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		os.Exit(ExitRuntimeError)
+	}
+	os.Exit(ExitSuccess)
+}
+
+// runLockedStateCommand runs a command changing the state of a pipeline while
+// holding the pipeline lock, so that a running execution does not overwrite
+// the change with the state it saves when it completes. Fails if the lock is
+// held.
+func runLockedStateCommand(pipelineID string, fn func(store persistence.StateStore) error) {
+	runStateCommand(func(store persistence.StateStore) error {
+		lock, err := persistence.AcquireLock(context.Background(), store, pipelineID, persistence.LockOptions{})
+		if errors.Is(err, persistence.ErrLockHeld) {
+			return fmt.Errorf("pipeline %s is running, retry once it completes: %w", pipelineID, err)
+		}
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := lock.Release(); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠ Failed to release pipeline lock: %v\n", err)
+			}
+		}()
+		return fn(store)
+	})
+}
+
+// writeStateJSON writes v as indented JSON.
+func writeStateJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling state: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func runStateShow(cmd *cobra.Command, args []string) {
+	runStateCommand(func(store persistence.StateStore) error {
+		state, err := store.Load(args[0])
+		if err != nil {
+			return fmt.Errorf("loading state: %w", err)
+		}
+		if state == nil {
+			if !quiet {
+				fmt.Fprintf(cmd.ErrOrStderr(), "No state for pipeline %s\n", args[0])
+			}
+			return nil
+		}
+		return writeStateJSON(cmd.OutOrStdout(), state)
+	})
+}
+
+func runStateSet(cmd *cobra.Command, args []string) {
+	flags := cmd.Flags()
+	if !flags.Changed("last-timestamp") && !flags.Changed("last-id") && len(stateSetKeys) == 0 && len(stateDeleteKeys) == 0 {
+		fmt.Fprintln(os.Stderr, "✗ Nothing to set: use --last-timestamp, --last-id, --key or --delete-key")
+		os.Exit(ExitValidationError)
+	}
+
+	runLockedStateCommand(args[0], func(store persistence.StateStore) error {
+		state, err := store.Load(args[0])
+		if err != nil {
+			return fmt.Errorf("loading state: %w", err)
+		}
+		if state == nil {
+			state = &persistence.State{}
+		}
+
+		if flags.Changed("last-timestamp") {
+			state.LastTimestamp = nil
+			if stateSetTimestamp != "" {
+				ts, err := persistence.ParseTimestamp(stateSetTimestamp)
+				if err != nil {
+					return fmt.Errorf("invalid --last-timestamp: %w", err)
+				}
+				state.LastTimestamp = &ts
+			}
+		}
+		if flags.Changed("last-id") {
+			state.LastID = nil
+			if stateSetID != "" {
+				id := stateSetID
+				state.LastID = &id
+			}
+		}
+		for _, kv := range stateSetKeys {
+			name, value, ok := strings.Cut(kv, "=")
+			if !ok || name == "" || !json.Valid([]byte(value)) {
+				return fmt.Errorf("invalid --key %q: want name=<JSON value>", kv)
+			}
+			if state.Keys == nil {
+				state.Keys = make(map[string]json.RawMessage)
+			}
+			state.Keys[name] = json.RawMessage(value)
+		}
+		for _, name := range stateDeleteKeys {
+			delete(state.Keys, name)
+		}
+
+		// A pending checkpoint would make the next run resume from its own
+		// position and overwrite the state that was set
+		if state.Checkpoint != nil {
+			state.Checkpoint = nil
+			if !quiet {
+				fmt.Fprintf(cmd.ErrOrStderr(), "⚠ Checkpoint of an interrupted run of pipeline %s cleared\n", args[0])
+			}
+		}
+
+		state.UpdatedAt = time.Now()
+		if err := store.Save(args[0], state); err != nil {
+			return fmt.Errorf("saving state: %w", err)
+		}
+		if !quiet {
+			fmt.Fprintf(cmd.ErrOrStderr(), "✓ State of pipeline %s updated\n", args[0])
+		}
+		return nil
+	})
+}
+
+func runStateReset(cmd *cobra.Command, args []string) {
+	runLockedStateCommand(args[0], func(store persistence.StateStore) error {
+		if err := store.Delete(args[0]); err != nil {
+			return fmt.Errorf("deleting state: %w", err)
+		}
+		if !quiet {
+			fmt.Fprintf(cmd.ErrOrStderr(), "✓ State of pipeline %s reset (previous state kept in history)\n", args[0])
+		}
+		return nil
+	})
+}
+
+func runStateExport(cmd *cobra.Command, args []string) {
+	runStateCommand(func(store persistence.StateStore) error {
+		state, err := store.Load(args[0])
+		if err != nil {
+			return fmt.Errorf("loading state: %w", err)
+		}
+		if state == nil {
+			return fmt.Errorf("no state for pipeline %s", args[0])
+		}
+
+		if stateExportOutput == "" {
+			return writeStateJSON(cmd.OutOrStdout(), state)
+		}
+		f, err := os.OpenFile(stateExportOutput, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("creating export file: %w", err)
+		}
+		if err := writeStateJSON(f, state); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
+
+func runStateImport(cmd *cobra.Command, args []string) {
+	runLockedStateCommand(args[0], func(store persistence.StateStore) error {
+		var data []byte
+		var err error
+		if args[1] == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			return fmt.Errorf("reading state: %w", err)
+		}
+
+		var state persistence.State
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("parsing state: %w", err)
+		}
+		// The state is imported under the given pipeline, whatever its pipelineId
+		state.UpdatedAt = time.Now()
+		if err := store.Save(args[0], &state); err != nil {
+			return fmt.Errorf("saving state: %w", err)
+		}
+		if !quiet {
+			fmt.Fprintf(cmd.ErrOrStderr(), "✓ State of pipeline %s imported\n", args[0])
+		}
+		return nil
+	})
+}
+
+func runStateHistory(cmd *cobra.Command, args []string) {
+	runStateCommand(func(store persistence.StateStore) error {
+		history, err := store.History(args[0])
+		if err != nil {
+			return fmt.Errorf("loading state history: %w", err)
+		}
+		if len(history) == 0 {
+			if !quiet {
+				fmt.Fprintf(cmd.ErrOrStderr(), "No state history for pipeline %s\n", args[0])
+			}
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "STEPS\tSAVED AT\tLAST TIMESTAMP\tLAST ID\tKEYS")
+		for i, state := range history {
+			lastID := ""
+			if state.LastID != nil {
+				lastID = *state.LastID
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n", i+1, state.UpdatedAt.Format(time.RFC3339), state.FormatTimestamp(), lastID, len(state.Keys))
+		}
+		return w.Flush()
+	})
+}
+
+func runStateRollback(cmd *cobra.Command, args []string) {
+	if stateRollbackSteps < 1 {
+		fmt.Fprintln(os.Stderr, "✗ --steps must be at least 1")
+		os.Exit(ExitValidationError)
+	}
+	var before time.Time
+	if stateRollbackTo != "" {
+		var err error
+		if before, err = persistence.ParseTimestamp(stateRollbackTo); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Invalid --before: %v\n", err)
+			os.Exit(ExitValidationError)
+		}
+	}
+
+	runLockedStateCommand(args[0], func(store persistence.StateStore) error {
+		history, err := store.History(args[0])
+		if err != nil {
+			return fmt.Errorf("loading state history: %w", err)
+		}
+
+		var target *persistence.State
+		if stateRollbackTo != "" {
+			for _, state := range history {
+				if state.UpdatedAt.Before(before) {
+					target = state
+					break
+				}
+			}
+			if target == nil {
+				return fmt.Errorf("no state saved before %s in the history of pipeline %s", before.Format(time.RFC3339), args[0])
+			}
+		} else {
+			if stateRollbackSteps > len(history) {
+				return fmt.Errorf("not enough state history: %d state(s) kept for pipeline %s", len(history), args[0])
+			}
+			target = history[stateRollbackSteps-1]
+		}
+
+		// The rolled back state is itself kept in the history
+		savedAt := target.UpdatedAt
+		target.UpdatedAt = time.Now()
+		if err := store.Save(args[0], target); err != nil {
+			return fmt.Errorf("saving state: %w", err)
+		}
+		if !quiet {
+			fmt.Fprintf(cmd.ErrOrStderr(), "✓ State of pipeline %s rolled back to the state saved at %s\n", args[0], savedAt.Format(time.RFC3339))
+		}
+		return nil
+	})
+}
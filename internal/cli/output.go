@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/cannectors/runtime/pkg/connector"
 )
@@ -33,13 +34,37 @@ func PrintExecutionResult(result *connector.ExecutionResult, err error, opts Out
 		return
 	}
 
+	if result.Status == "skipped" {
+		if !opts.Quiet {
+			fmt.Println("⏭ Pipeline execution skipped")
+			if result.Error != nil {
+				fmt.Printf("  Reason: %s\n", result.Error.Message)
+			}
+		}
+		return
+	}
+
 	if !opts.Quiet {
-		fmt.Println("✓ Pipeline executed successfully")
+		if result.Status == "partial" {
+			fmt.Println("⚠ Pipeline executed with failed records")
+		} else {
+			fmt.Println("✓ Pipeline executed successfully")
+		}
 		fmt.Printf("  Status: %s\n", result.Status)
 		fmt.Printf("  Records processed: %d\n", result.RecordsProcessed)
 		if result.RecordsFailed > 0 {
 			fmt.Printf("  Records failed: %d\n", result.RecordsFailed)
 		}
+		if opts.Verbose {
+			printRecordFailures(result.FailedRecords)
+		}
+		if len(result.ReturnedRecords) > 0 {
+			fmt.Printf("  Records returned: %d\n", len(result.ReturnedRecords))
+		}
+		if r := result.ResumedFrom; r != nil {
+			fmt.Printf("  Resumed from checkpoint: %d records sent before interruption (run started %s)\n",
+				r.RecordsProcessed, r.RunStartedAt.Format(time.RFC3339))
+		}
 		if opts.Verbose {
 			fmt.Printf("  Duration: %v\n", result.CompletedAt.Sub(result.StartedAt))
 		}
@@ -50,6 +75,20 @@ func PrintExecutionResult(result *connector.ExecutionResult, err error, opts Out
 	}
 }
 
+// maxPrintedFailures bounds the record failures listed in verbose output.
+const maxPrintedFailures = 10
+
+// printRecordFailures lists the errors of the first failed records.
+func printRecordFailures(failures []connector.RecordFailure) {
+	for i, failure := range failures {
+		if i == maxPrintedFailures {
+			fmt.Printf("    ... and %d more\n", len(failures)-maxPrintedFailures)
+			break
+		}
+		fmt.Printf("    - %s\n", failure.Error)
+	}
+}
+
 // PrintDryRunPreview displays the request preview for dry-run mode.
 func PrintDryRunPreview(previews []connector.RequestPreview, verbose bool) {
 	fmt.Println()
@@ -57,19 +96,26 @@ func PrintDryRunPreview(previews []connector.RequestPreview, verbose bool) {
 	fmt.Println()
 
 	for i, preview := range previews {
-		if len(previews) > 1 {
-			fmt.Printf("─── Request %d of %d ───\n", i+1, len(previews))
-		}
+		if preview.SQL != nil {
+			if len(previews) > 1 {
+				fmt.Printf("─── Statement %d of %d ───\n", i+1, len(previews))
+			}
+			printSQLPreview(preview, verbose)
+		} else {
+			if len(previews) > 1 {
+				fmt.Printf("─── Request %d of %d ───\n", i+1, len(previews))
+			}
 
-		fmt.Printf("  Endpoint: %s %s\n", preview.Method, preview.Endpoint)
-		fmt.Printf("  Records: %d\n", preview.RecordCount)
+			fmt.Printf("  Endpoint: %s %s\n", preview.Method, preview.Endpoint)
+			fmt.Printf("  Records: %d\n", preview.RecordCount)
 
-		if len(preview.Headers) > 0 {
-			printHeaders(preview.Headers)
-		}
+			if len(preview.Headers) > 0 {
+				printHeaders(preview.Headers)
+			}
 
-		if preview.BodyPreview != "" {
-			printBodyPreview(preview.BodyPreview, verbose)
+			if preview.BodyPreview != "" {
+				printBodyPreview(preview.BodyPreview, verbose)
+			}
 		}
 
 		if i < len(previews)-1 {
@@ -81,6 +127,54 @@ func PrintDryRunPreview(previews []connector.RequestPreview, verbose bool) {
 	fmt.Println("ℹ️  No data was sent to the target system (dry-run mode)")
 }
 
+// printSQLPreview displays a SQL statement preview: operation, statement,
+// bound parameters and, if it was executed and rolled back, its outcome.
+func printSQLPreview(preview connector.RequestPreview, verbose bool) {
+	const maxParamsCompact = 10
+	sqlPreview := preview.SQL
+
+	if sqlPreview.Table != "" {
+		fmt.Printf("  Operation: %s %s\n", strings.ToUpper(sqlPreview.Operation), sqlPreview.Table)
+	} else {
+		fmt.Printf("  Operation: %s\n", strings.ToUpper(sqlPreview.Operation))
+	}
+	if preview.RecordCount > 0 {
+		fmt.Printf("  Records: %d\n", preview.RecordCount)
+	}
+	printBodyPreviewLabeled("Statement", sqlPreview.Statement, verbose)
+
+	if len(sqlPreview.Parameters) > 0 {
+		fmt.Println("  Parameters:")
+		for i, param := range sqlPreview.Parameters {
+			if !verbose && i == maxParamsCompact {
+				fmt.Printf("    ... (%d more, use --verbose for all)\n", len(sqlPreview.Parameters)-maxParamsCompact)
+				break
+			}
+			fmt.Printf("    %d: %s\n", i+1, formatSQLParameter(param))
+		}
+	}
+
+	if sqlPreview.Executed {
+		if sqlPreview.Error != "" {
+			fmt.Printf("  Validation: ✗ %s\n", sqlPreview.Error)
+		} else {
+			fmt.Println("  Validation: ✓ executed and rolled back")
+		}
+	}
+}
+
+// formatSQLParameter formats a bound parameter value for display.
+func formatSQLParameter(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
 // printHeaders prints sorted headers.
 func printHeaders(headers map[string]string) {
 	fmt.Println("  Headers:")
@@ -96,16 +190,22 @@ func printHeaders(headers map[string]string) {
 
 // printBodyPreview displays the formatted JSON body preview.
 func printBodyPreview(bodyPreview string, verbose bool) {
+	printBodyPreviewLabeled("Body", bodyPreview, verbose)
+}
+
+// printBodyPreviewLabeled displays a multi-line preview under label,
+// truncated unless verbose.
+func printBodyPreviewLabeled(label, bodyPreview string, verbose bool) {
 	const maxLinesCompact = 10
 	lineCount := countLines(bodyPreview)
 
 	if verbose || lineCount <= maxLinesCompact {
-		fmt.Println("  Body:")
+		fmt.Printf("  %s:\n", label)
 		printIndentedBody(bodyPreview, "    ")
 		return
 	}
 
-	fmt.Println("  Body (truncated, use --verbose for full):")
+	fmt.Printf("  %s (truncated, use --verbose for full):\n", label)
 	printTruncatedBody(bodyPreview, "    ", maxLinesCompact)
 }
 
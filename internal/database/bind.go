@@ -0,0 +1,461 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned when binding parameters
+var (
+	ErrUnmatchedTemplate = errors.New("unmatched template placeholder in query: missing closing }}")
+	ErrUnresolvedParam   = errors.New("unresolved query parameter")
+)
+
+// ParamKind is the syntax of a parameter reference in a query.
+type ParamKind int
+
+const (
+	// ParamColon is a :name reference
+	ParamColon ParamKind = iota
+	// ParamAt is an @name reference
+	ParamAt
+	// ParamTemplate is a {{path}} reference
+	ParamTemplate
+)
+
+// Param is a parameter reference in a query. Name is the name after : or @,
+// or the trimmed content of a {{ }} template (e.g. "record.id").
+type Param struct {
+	Kind ParamKind
+	Name string
+}
+
+// ParamResolver returns the value bound to a parameter reference. References
+// it does not resolve (ok false) are left unchanged in the query; an error
+// stops the binding.
+type ParamResolver func(p Param) (value interface{}, ok bool, err error)
+
+// sqlSegment is a part of a query: SQL code, or text that is not code
+// (string literal, quoted identifier, comment, dollar-quoted string).
+type sqlSegment struct {
+	text string
+	code bool
+}
+
+// Bind replaces the parameter references of a query (:name, @name and
+// {{path}}) resolved by resolve with the driver's placeholders, and returns
+// the query and its arguments. References inside string literals, quoted
+// identifiers, comments and dollar-quoted strings are left unchanged, as are
+// PostgreSQL casts (::type) and MySQL system variables (@@name).
+//
+// args are the arguments of placeholders already in the query. Numbered
+// placeholders ($n) continue after them, and a parameter referenced several
+// times is bound once. Positional placeholders (?) get one argument per
+// occurrence, inserted after the arguments of the placeholders preceding it.
+func Bind(query, driver string, args []interface{}, resolve ParamResolver) (string, []interface{}, error) {
+	numbered := GetPlaceholderStyle(driver) == PlaceholderDollar
+	bound := make(map[Param]string)
+	result := append([]interface{}(nil), args...)
+	positional := 0 // ? placeholders before the current position
+
+	var b strings.Builder
+	for _, seg := range splitSQL(query, driver) {
+		if !seg.code {
+			b.WriteString(seg.text)
+			continue
+		}
+
+		text := seg.text
+		for i := 0; i < len(text); {
+			p, end, err := parseParam(text, i)
+			if err != nil {
+				return "", nil, err
+			}
+			if end == i {
+				if text[i] == '?' {
+					positional++
+				}
+				b.WriteByte(text[i])
+				i++
+				continue
+			}
+
+			value, ok, err := resolve(p)
+			if err != nil {
+				return "", nil, err
+			}
+			switch {
+			case !ok:
+				b.WriteString(text[i:end])
+			case numbered:
+				placeholder, seen := bound[p]
+				if !seen {
+					result = append(result, value)
+					placeholder = FormatPlaceholder(driver, len(result))
+					bound[p] = placeholder
+				}
+				b.WriteString(placeholder)
+			default:
+				pos := min(positional, len(result))
+				result = append(result[:pos], append([]interface{}{value}, result[pos:]...)...)
+				positional++
+				b.WriteString(FormatPlaceholder(driver, len(result)))
+			}
+			i = end
+		}
+	}
+	return b.String(), result, nil
+}
+
+// parseParam parses the parameter reference starting at text[i], if any.
+// Returns the reference and its end; end == i if there is none. Casts (::)
+// and system variables (@@) are returned as a single character so that they
+// are copied unchanged.
+func parseParam(text string, i int) (Param, int, error) {
+	switch text[i] {
+	case '{':
+		if !strings.HasPrefix(text[i:], "{{") {
+			return Param{}, i, nil
+		}
+		end := strings.Index(text[i:], "}}")
+		if end < 0 {
+			return Param{}, i, ErrUnmatchedTemplate
+		}
+		return Param{Kind: ParamTemplate, Name: strings.TrimSpace(text[i+2 : i+end])}, i + end + 2, nil
+	case ':', '@':
+		// :: cast or @@ system variable
+		if (i+1 < len(text) && text[i+1] == text[i]) || (i > 0 && text[i-1] == text[i]) {
+			return Param{}, i, nil
+		}
+		end := paramNameEnd(text, i+1)
+		if end == i+1 {
+			return Param{}, i, nil
+		}
+		kind := ParamColon
+		if text[i] == '@' {
+			kind = ParamAt
+		}
+		return Param{Kind: kind, Name: text[i+1 : end]}, end, nil
+	}
+	return Param{}, i, nil
+}
+
+// paramNameEnd returns the end of the parameter name starting at text[i]:
+// identifiers separated by dots (e.g. customer.id).
+func paramNameEnd(text string, i int) int {
+	end := i
+	for {
+		if end >= len(text) || !isIdentifierStart(text[end]) {
+			return end
+		}
+		for end < len(text) && isIdentifierChar(text[end]) {
+			end++
+		}
+		if end+1 < len(text) && text[end] == '.' && isIdentifierStart(text[end+1]) {
+			end++
+			continue
+		}
+		return end
+	}
+}
+
+// RecordResolver resolves parameter references to record fields, in dot
+// notation: {{record.customer.id}} (or {{customer.id}}), where missing fields
+// are bound as NULL. With named, :customer.id and @customer.id are resolved
+// too, and a field missing from the record is an ErrUnresolvedParam; without
+// it they are left unchanged, as they may be SQL (MySQL user variables,
+// PostgreSQL operators such as <@, array slices).
+func RecordResolver(record map[string]interface{}, named bool) ParamResolver {
+	return func(p Param) (interface{}, bool, error) {
+		if p.Kind == ParamTemplate {
+			value, _ := recordField(record, strings.TrimPrefix(p.Name, "record."))
+			return value, true, nil
+		}
+		if !named {
+			return nil, false, nil
+		}
+		value, ok := recordField(record, p.Name)
+		if !ok {
+			prefix := ":"
+			if p.Kind == ParamAt {
+				prefix = "@"
+			}
+			return nil, false, fmt.Errorf("%w: %s%s is not a field of the record", ErrUnresolvedParam, prefix, p.Name)
+		}
+		return value, true, nil
+	}
+}
+
+// recordField returns the record field at path, in dot notation, and whether
+// it exists.
+func recordField(record map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = record
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// HasParam reports whether the query references the parameter name, as
+// :name, @name or {{name}}, outside string literals, quoted identifiers and
+// comments.
+func HasParam(query, driver, name string) bool {
+	for _, seg := range splitSQL(query, driver) {
+		if !seg.code {
+			continue
+		}
+		for i := 0; i < len(seg.text); {
+			p, end, err := parseParam(seg.text, i)
+			if err != nil {
+				break
+			}
+			if end == i {
+				i++
+				continue
+			}
+			if p.Name == name {
+				return true
+			}
+			i = end
+		}
+	}
+	return false
+}
+
+// HasKeyword reports whether the query contains the SQL keyword (e.g.
+// RETURNING), case-insensitively, outside string literals, quoted
+// identifiers, comments and parameter references.
+func HasKeyword(query, driver, keyword string) bool {
+	for _, seg := range splitSQL(query, driver) {
+		if !seg.code {
+			continue
+		}
+		text := seg.text
+		for i := 0; i < len(text); {
+			_, end, err := parseParam(text, i)
+			if err != nil {
+				break
+			}
+			if end > i {
+				i = end
+				continue
+			}
+			if !isIdentifierStart(text[i]) || (i > 0 && (isIdentifierChar(text[i-1]) || text[i-1] == '.')) {
+				i++
+				continue
+			}
+			end = i
+			for end < len(text) && isIdentifierChar(text[end]) {
+				end++
+			}
+			if strings.EqualFold(text[i:end], keyword) {
+				return true
+			}
+			i = end
+		}
+	}
+	return false
+}
+
+// CountPlaceholders returns the number of positional placeholders (?) in the
+// query, outside string literals, quoted identifiers and comments.
+func CountPlaceholders(query, driver string) int {
+	count := 0
+	for _, seg := range splitSQL(query, driver) {
+		if seg.code {
+			count += strings.Count(seg.text, "?")
+		}
+	}
+	return count
+}
+
+// TrimStatement returns the query without its trailing semicolons, comments
+// and whitespace, so that clauses can be appended to it or the query nested
+// in another one.
+func TrimStatement(query, driver string) string {
+	segments := splitSQL(query, driver)
+	end := len(query)
+	for i := len(segments) - 1; i >= 0; i-- {
+		seg := segments[i]
+		if !seg.code {
+			if !isComment(seg.text) {
+				break
+			}
+			end -= len(seg.text)
+			continue
+		}
+		trimmed := strings.TrimRight(seg.text, " \t\r\n;")
+		end -= len(seg.text) - len(trimmed)
+		if trimmed != "" {
+			break
+		}
+	}
+	return query[:end]
+}
+
+// isComment reports whether a non-code segment is a comment.
+func isComment(text string) bool {
+	return strings.HasPrefix(text, "--") || strings.HasPrefix(text, "/*") || strings.HasPrefix(text, "#")
+}
+
+// splitSQL splits a query into SQL code and non-code segments: string
+// literals ('...', E'...' and "..." on MySQL, with backslash escapes on
+// MySQL), quoted identifiers ("...", `...` on MySQL and SQLite, [...] on
+// SQLite), comments (--, /* */ nested on PostgreSQL, # on MySQL) and
+// PostgreSQL dollar-quoted strings ($$...$$, $tag$...$tag$). An unterminated
+// segment extends to the end of the query.
+func splitSQL(query, driver string) []sqlSegment {
+	var segments []sqlSegment
+	codeStart := 0
+	flush := func(end int) {
+		if end > codeStart {
+			segments = append(segments, sqlSegment{text: query[codeStart:end], code: true})
+		}
+	}
+
+	for i := 0; i < len(query); {
+		end := skipNonCode(query, i, driver)
+		if end == i {
+			i++
+			continue
+		}
+		flush(i)
+		segments = append(segments, sqlSegment{text: query[i:end]})
+		codeStart = end
+		i = end
+	}
+	flush(len(query))
+	return segments
+}
+
+// skipNonCode returns the end of the non-code segment starting at query[i],
+// or i if query[i] starts SQL code.
+func skipNonCode(query string, i int, driver string) int {
+	c := query[i]
+	switch {
+	case c == '\'':
+		backslash := driver == DriverMySQL || (i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i < 2 || !isIdentifierChar(query[i-2])))
+		return skipQuoted(query, i, '\'', backslash)
+	case c == '"':
+		return skipQuoted(query, i, '"', driver == DriverMySQL)
+	case c == '`' && driver != DriverPostgres:
+		return skipQuoted(query, i, '`', false)
+	case c == '[' && driver == DriverSQLite:
+		if end := strings.IndexByte(query[i+1:], ']'); end >= 0 {
+			return i + end + 2
+		}
+		return len(query)
+	case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#' && driver == DriverMySQL:
+		if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(query)
+	case c == '/' && strings.HasPrefix(query[i:], "/*"):
+		return skipBlockComment(query, i, driver == DriverPostgres)
+	case c == '$' && driver == DriverPostgres && (i == 0 || !isIdentifierChar(query[i-1])):
+		return skipDollarQuoted(query, i)
+	}
+	return i
+}
+
+// skipQuoted returns the end of the quoted text starting at query[i]. A
+// doubled quote is an escaped quote, as is a backslash-escaped quote with
+// backslash.
+func skipQuoted(query string, i int, quote byte, backslash bool) int {
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if backslash {
+				j++
+			}
+		case quote:
+			if j+1 < len(query) && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+// skipBlockComment returns the end of the /* */ comment starting at query[i].
+func skipBlockComment(query string, i int, nested bool) int {
+	depth := 0
+	for j := i; j+1 < len(query); j++ {
+		switch {
+		case query[j] == '/' && query[j+1] == '*' && (nested || depth == 0):
+			depth++
+			j++
+		case query[j] == '*' && query[j+1] == '/':
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(query)
+}
+
+// skipDollarQuoted returns the end of the dollar-quoted string starting at
+// query[i], or i if query[i] does not start one ($1 is a placeholder).
+func skipDollarQuoted(query string, i int) int {
+	j := i + 1
+	if j < len(query) && isIdentifierStart(query[j]) {
+		for j < len(query) && isIdentifierChar(query[j]) {
+			j++
+		}
+	}
+	if j >= len(query) || query[j] != '$' {
+		return i
+	}
+	tag := query[i : j+1]
+	if end := strings.Index(query[j+1:], tag); end >= 0 {
+		return j + 1 + end + len(tag)
+	}
+	return len(query)
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentifierChar(c byte) bool {
+	return isIdentifierStart(c) || (c >= '0' && c <= '9')
+}
+
+// convertQuestionMarks converts the positional placeholders of SQL code to
+// numbered placeholders, starting at *index. ?| and ?& are PostgreSQL JSONB
+// operators; ?? is an escaped ? (the JSONB key-exists operator).
+func convertQuestionMarks(code string, index *int) string {
+	var b strings.Builder
+	for i := 0; i < len(code); i++ {
+		if code[i] != '?' {
+			b.WriteByte(code[i])
+			continue
+		}
+		if i+1 < len(code) {
+			switch code[i+1] {
+			case '?':
+				b.WriteByte('?')
+				i++
+				continue
+			case '|', '&':
+				b.WriteString(code[i : i+2])
+				i++
+				continue
+			}
+		}
+		*index++
+		fmt.Fprintf(&b, "$%d", *index)
+	}
+	return b.String()
+}
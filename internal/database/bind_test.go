@@ -0,0 +1,299 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	t.Parallel()
+
+	params := map[string]interface{}{"id": 1, "name": "a", "since": "2026-01-01"}
+	resolve := func(p Param) (interface{}, bool, error) {
+		v, ok := params[p.Name]
+		return v, ok, nil
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		driver    string
+		args      []interface{}
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "colon, at and template references",
+			query:     "SELECT * FROM t WHERE id = :id AND name = @name AND since > {{ since }}",
+			driver:    DriverPostgres,
+			wantQuery: "SELECT * FROM t WHERE id = $1 AND name = $2 AND since > $3",
+			wantArgs:  []interface{}{1, "a", "2026-01-01"},
+		},
+		{
+			name:      "numbered placeholders are reused",
+			query:     "SELECT * FROM t WHERE a = :id OR b = :id",
+			driver:    DriverPostgres,
+			wantQuery: "SELECT * FROM t WHERE a = $1 OR b = $1",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "positional placeholders per occurrence",
+			query:     "SELECT * FROM t WHERE a = :id OR b = :name OR c = :id",
+			driver:    DriverMySQL,
+			wantQuery: "SELECT * FROM t WHERE a = ? OR b = ? OR c = ?",
+			wantArgs:  []interface{}{1, "a", 1},
+		},
+		{
+			name:      "numbered placeholders continue after args",
+			query:     "SELECT * FROM t WHERE a = $1 AND b = :id",
+			driver:    DriverPostgres,
+			args:      []interface{}{"x"},
+			wantQuery: "SELECT * FROM t WHERE a = $1 AND b = $2",
+			wantArgs:  []interface{}{"x", 1},
+		},
+		{
+			name:      "positional arguments inserted in query order",
+			query:     "SELECT * FROM t WHERE a = :id AND b = ?",
+			driver:    DriverSQLite,
+			args:      []interface{}{"x"},
+			wantQuery: "SELECT * FROM t WHERE a = ? AND b = ?",
+			wantArgs:  []interface{}{1, "x"},
+		},
+		{
+			name:      "string literals and comments",
+			query:     "SELECT ':id', 'it''s @name' -- :id\nFROM t /* {{since}} */ WHERE id = :id",
+			driver:    DriverPostgres,
+			wantQuery: "SELECT ':id', 'it''s @name' -- :id\nFROM t /* {{since}} */ WHERE id = $1",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "quoted identifiers",
+			query:     `SELECT ":id" FROM t WHERE id = :id`,
+			driver:    DriverPostgres,
+			wantQuery: `SELECT ":id" FROM t WHERE id = $1`,
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "dollar-quoted strings",
+			query:     "SELECT $$ :id $$, $fn$ @name $fn$ WHERE id = :id",
+			driver:    DriverPostgres,
+			wantQuery: "SELECT $$ :id $$, $fn$ @name $fn$ WHERE id = $1",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "nested block comments on postgres",
+			query:     "SELECT /* a /* :id */ :name */ :id",
+			driver:    DriverPostgres,
+			wantQuery: "SELECT /* a /* :id */ :name */ $1",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "escape string",
+			query:     `SELECT E'\' :id' WHERE id = :id`,
+			driver:    DriverPostgres,
+			wantQuery: `SELECT E'\' :id' WHERE id = $1`,
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "mysql backslash escapes, backticks and hash comments",
+			query:     "SELECT 'a\\' :id', `:name` # :since\nFROM t WHERE id = :id",
+			driver:    DriverMySQL,
+			wantQuery: "SELECT 'a\\' :id', `:name` # :since\nFROM t WHERE id = ?",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "casts, system variables and operators",
+			query:     "SELECT :id::text, @@name, data ? 'k', data @> '{}' FROM t",
+			driver:    DriverPostgres,
+			wantQuery: "SELECT $1::text, @@name, data ? 'k', data @> '{}' FROM t",
+			wantArgs:  []interface{}{1},
+		},
+		{
+			name:      "unresolved references are kept",
+			query:     "SELECT * FROM t WHERE a = :other AND {{cursorPredicate}} AND id = :id",
+			driver:    DriverPostgres,
+			wantQuery: "SELECT * FROM t WHERE a = :other AND {{cursorPredicate}} AND id = $1",
+			wantArgs:  []interface{}{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := Bind(tt.query, tt.driver, tt.args, resolve)
+			if err != nil {
+				t.Fatalf("Bind() error = %v", err)
+			}
+			if query != tt.wantQuery {
+				t.Errorf("Bind() query = %q, want %q", query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Bind() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBindUnmatchedTemplate(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := Bind("SELECT {{record.id FROM t", DriverPostgres, nil, RecordResolver(nil, false))
+	if !errors.Is(err, ErrUnmatchedTemplate) {
+		t.Errorf("Bind() error = %v, want ErrUnmatchedTemplate", err)
+	}
+}
+
+func TestRecordResolver(t *testing.T) {
+	t.Parallel()
+
+	record := map[string]interface{}{
+		"id":       123,
+		"customer": map[string]interface{}{"id": "c1"},
+		"note":     nil,
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		driver    string
+		named     bool
+		wantQuery string
+		wantArgs  []interface{}
+		wantErr   bool
+	}{
+		{
+			name:      "templates, missing fields bound as NULL",
+			query:     "INSERT INTO t VALUES ({{record.id}}, {{customer.id}}, {{record.missing}})",
+			driver:    DriverSQLite,
+			wantQuery: "INSERT INTO t VALUES (?, ?, ?)",
+			wantArgs:  []interface{}{123, "c1", nil},
+		},
+		{
+			name:      "colon and at references kept without named",
+			query:     "SELECT @rownum := @rownum + 1, {{record.id}} FROM t WHERE tags <@ :tags",
+			driver:    DriverMySQL,
+			wantQuery: "SELECT @rownum := @rownum + 1, ? FROM t WHERE tags <@ :tags",
+			wantArgs:  []interface{}{123},
+		},
+		{
+			name:      "named references",
+			query:     "INSERT INTO t VALUES (:customer.id, @id, :note)",
+			driver:    DriverSQLite,
+			named:     true,
+			wantQuery: "INSERT INTO t VALUES (?, ?, ?)",
+			wantArgs:  []interface{}{"c1", 123, nil},
+		},
+		{
+			name:    "named reference to a missing field",
+			query:   "INSERT INTO t VALUES (:id, @missing)",
+			driver:  DriverSQLite,
+			named:   true,
+			wantErr: true,
+		},
+		{
+			name:    "named reference below a scalar",
+			query:   "INSERT INTO t VALUES (:id.x)",
+			driver:  DriverSQLite,
+			named:   true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := Bind(tt.query, tt.driver, nil, RecordResolver(record, tt.named))
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnresolvedParam) {
+					t.Errorf("Bind() error = %v, want ErrUnresolvedParam", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Bind() error = %v", err)
+			}
+			if query != tt.wantQuery {
+				t.Errorf("Bind() query = %q, want %q", query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Bind() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestHasParam(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query  string
+		driver string
+		want   bool
+	}{
+		{query: "SELECT * FROM t OFFSET :offset", driver: DriverPostgres, want: true},
+		{query: "SELECT * FROM t OFFSET @offset", driver: DriverMySQL, want: true},
+		{query: "SELECT * FROM t OFFSET {{ offset }}", driver: DriverSQLite, want: true},
+		{query: "SELECT ':offset' FROM t -- :offset", driver: DriverPostgres},
+		{query: "SELECT * FROM t /* {{offset}} */ WHERE a = :offsets", driver: DriverSQLite},
+		{query: "SELECT :offset::int, {{offset", driver: DriverPostgres, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := HasParam(tt.query, tt.driver, "offset"); got != tt.want {
+			t.Errorf("HasParam(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHasKeyword(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "INSERT INTO t (id) VALUES ($1) RETURNING id", want: true},
+		{query: "insert into t (id) values ($1)\nreturning\n  id;", want: true},
+		{query: "INSERT INTO t (returning_id) VALUES ({{record.returning}})"},
+		{query: "INSERT INTO t (id) VALUES ('RETURNING') -- RETURNING id"},
+		{query: `INSERT INTO t ("returning") VALUES (:returning)`},
+		{query: "INSERT INTO t (id) SELECT s.returning FROM s"},
+	}
+
+	for _, tt := range tests {
+		if got := HasKeyword(tt.query, DriverPostgres, "RETURNING"); got != tt.want {
+			t.Errorf("HasKeyword(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCountPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	if got := CountPlaceholders("SELECT '?', \"?\" -- ?\n FROM t WHERE a = ? AND b = ?", DriverSQLite); got != 2 {
+		t.Errorf("CountPlaceholders() = %d, want 2", got)
+	}
+}
+
+func TestTrimStatement(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query  string
+		driver string
+		want   string
+	}{
+		{query: "SELECT 1", driver: DriverSQLite, want: "SELECT 1"},
+		{query: "SELECT 1;\n", driver: DriverSQLite, want: "SELECT 1"},
+		{query: "SELECT 1 -- last\n; -- end", driver: DriverPostgres, want: "SELECT 1"},
+		{query: "SELECT 1 /* a */ ; /* b */", driver: DriverSQLite, want: "SELECT 1"},
+		{query: "SELECT 1 # note", driver: DriverMySQL, want: "SELECT 1"},
+		{query: "SELECT ';' AS s; ", driver: DriverSQLite, want: "SELECT ';' AS s"},
+		{query: `SELECT 1 AS "--"`, driver: DriverPostgres, want: `SELECT 1 AS "--"`},
+	}
+
+	for _, tt := range tests {
+		if got := TrimStatement(tt.query, tt.driver); got != tt.want {
+			t.Errorf("TrimStatement(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -91,6 +91,11 @@ func Open(cfg Config) (*sql.DB, string, error) {
 		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedDriver, driver)
 	}
 
+	// Drivers may be excluded from the build (nopostgres, nomysql build tags)
+	if !IsDriverLinked(driver) {
+		return nil, "", fmt.Errorf("%w: %s driver is not included in this build", ErrUnsupportedDriver, driver)
+	}
+
 	// Get the actual driver name for sql.Open
 	driverName := GetDriverName(driver)
 
@@ -286,17 +291,22 @@ func FormatPlaceholder(driver string, index int) string {
 
 // ConvertPlaceholders converts a query with ? placeholders to the driver's style.
 // This allows writing queries with ? placeholders and converting them as needed.
+// Question marks in string literals, quoted identifiers and comments are kept,
+// as are the PostgreSQL JSONB operators ?| and ?&; ?? is converted to ? (the
+// JSONB key-exists operator).
 func ConvertPlaceholders(query string, driver string) string {
 	if GetPlaceholderStyle(driver) == PlaceholderQuestion {
 		return query
 	}
 
-	// Convert ? to $1, $2, etc.
-	result := query
-	paramIndex := 1
-	for strings.Contains(result, "?") {
-		result = strings.Replace(result, "?", fmt.Sprintf("$%d", paramIndex), 1)
-		paramIndex++
+	var b strings.Builder
+	index := 0
+	for _, seg := range splitSQL(query, driver) {
+		if seg.code {
+			b.WriteString(convertQuestionMarks(seg.text, &index))
+		} else {
+			b.WriteString(seg.text)
+		}
 	}
-	return result
+	return b.String()
 }
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -276,6 +278,18 @@ func TestConvertPlaceholders(t *testing.T) {
 			driver: DriverPostgres,
 			want:   "INSERT INTO t (a, b, c) VALUES ($1, $2, $3)",
 		},
+		{
+			name:   "postgres literals and comments kept",
+			query:  "SELECT 'why?', \"a?\" FROM t -- really?\nWHERE id = ?",
+			driver: DriverPostgres,
+			want:   "SELECT 'why?', \"a?\" FROM t -- really?\nWHERE id = $1",
+		},
+		{
+			name:   "postgres jsonb operators",
+			query:  "SELECT * FROM t WHERE data ?? 'k' AND data ?| array['a'] AND id = ?",
+			driver: DriverPostgres,
+			want:   "SELECT * FROM t WHERE data ? 'k' AND data ?| array['a'] AND id = $1",
+		},
 	}
 
 	for _, tt := range tests {
@@ -342,3 +356,19 @@ func TestGetDriverName(t *testing.T) {
 		})
 	}
 }
+
+func TestTxOptions(t *testing.T) {
+	t.Parallel()
+
+	opts, err := TxOptions("", false)
+	if err != nil || opts != nil {
+		t.Errorf("TxOptions(default) = %v, %v, want nil options", opts, err)
+	}
+	opts, err = TxOptions(IsolationRepeatableRead, true)
+	if err != nil || opts == nil || opts.Isolation != sql.LevelRepeatableRead || !opts.ReadOnly {
+		t.Errorf("TxOptions(repeatable_read, read-only) = %+v, %v", opts, err)
+	}
+	if _, err := TxOptions("snapshot", false); !errors.Is(err, ErrUnsupportedIsolationLevel) {
+		t.Errorf("TxOptions(snapshot) error = %v, want ErrUnsupportedIsolationLevel", err)
+	}
+}
@@ -0,0 +1,53 @@
+//go:build !nomysql
+
+package database
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql" // registers the "mysql" database/sql driver
+)
+
+func init() {
+	registerDriver(DriverMySQL, mysqlErrorCode, classifyMySQLCode)
+	// A lost connection is reported by the client without an error number
+	registerConnectionErrors(DriverMySQL, mysql.ErrInvalidConn)
+}
+
+// mysqlErrorCode returns the error number of a MySQL server error.
+func mysqlErrorCode(err error) (string, bool) {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return strconv.Itoa(int(myErr.Number)), true
+	}
+	return "", false
+}
+
+// classifyMySQLCode classifies a MySQL server error number
+// (https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html).
+func classifyMySQLCode(code string) (errorClass, bool) {
+	switch code {
+	case "1048":
+		return errorClass{CategoryConstraint, "not-null constraint violation: required field is null", false}, true
+	case "1062", "1586":
+		return errorClass{CategoryConstraint, "unique constraint violation: duplicate value exists", false}, true
+	case "1216", "1217", "1451", "1452":
+		return errorClass{CategoryConstraint, "foreign key constraint violation: referenced record not found or still referenced", false}, true
+	case "3819":
+		return errorClass{CategoryConstraint, "check constraint violation: value does not meet requirements", false}, true
+	case "1213":
+		return errorClass{CategoryQuery, "deadlock detected", true}, true
+	case "1205":
+		return errorClass{CategoryQuery, "lock wait timeout exceeded", true}, true
+	case "3024":
+		return errorClass{CategoryTimeout, "maximum statement execution time exceeded", true}, true
+	case "1040", "1053":
+		return errorClass{CategoryConnection, "server unavailable or connection lost", true}, true
+	case "1045":
+		return errorClass{CategoryConnection, "authentication failed", false}, true
+	case "1064":
+		return errorClass{CategoryQuery, "SQL syntax error", false}, true
+	}
+	return errorClass{}, false
+}
@@ -0,0 +1,58 @@
+//go:build !nomysql
+
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestClassifyMySQLError(t *testing.T) {
+	tests := []struct {
+		number    uint16
+		category  string
+		retryable bool
+	}{
+		{1062, CategoryConstraint, false},
+		{1452, CategoryConstraint, false},
+		{1048, CategoryConstraint, false},
+		{1213, CategoryQuery, true},
+		{1205, CategoryQuery, true},
+		{3024, CategoryTimeout, true},
+		{1053, CategoryConnection, true},
+		{1045, CategoryConnection, false},
+		{1064, CategoryQuery, false},
+		{1146, CategoryQuery, false}, // table doesn't exist: unclassified number
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.number), func(t *testing.T) {
+			err := fmt.Errorf("executing: %w", &mysql.MySQLError{Number: tt.number, Message: "some error"})
+			dbErr := ClassifyDatabaseError(err, DriverMySQL, "exec", "INSERT INTO t VALUES (?)", 1)
+			if dbErr.Category != tt.category || dbErr.Retryable != tt.retryable {
+				t.Errorf("got category %s retryable %v, want %s %v", dbErr.Category, dbErr.Retryable, tt.category, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestClassifyMySQLConnectionLost(t *testing.T) {
+	for _, lost := range []error{mysql.ErrInvalidConn, driver.ErrBadConn} {
+		t.Run(lost.Error(), func(t *testing.T) {
+			err := fmt.Errorf("querying: %w", lost)
+			dbErr := ClassifyDatabaseError(err, DriverMySQL, "query", "SELECT 1", 0)
+			if dbErr.Category != CategoryConnection || !dbErr.Retryable {
+				t.Errorf("got category %s retryable %v, want retryable connection error", dbErr.Category, dbErr.Retryable)
+			}
+		})
+	}
+}
+
+func TestMySQLDriverLinked(t *testing.T) {
+	if !IsDriverLinked(DriverMySQL) {
+		t.Fatal("mysql driver is not linked")
+	}
+}
@@ -0,0 +1,63 @@
+//go:build !nopostgres
+
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
+)
+
+func init() {
+	registerDriver(DriverPostgres, postgresErrorCode, classifyPostgresCode)
+}
+
+// postgresErrorCode returns the SQLSTATE of a PostgreSQL error.
+func postgresErrorCode(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code, true
+	}
+	return "", false
+}
+
+// classifyPostgresCode classifies a SQLSTATE
+// (https://www.postgresql.org/docs/current/errcodes-appendix.html).
+func classifyPostgresCode(code string) (errorClass, bool) {
+	switch code {
+	case "23502":
+		return errorClass{CategoryConstraint, "not-null constraint violation: required field is null", false}, true
+	case "23503":
+		return errorClass{CategoryConstraint, "foreign key constraint violation: referenced record not found or still referenced", false}, true
+	case "23505":
+		return errorClass{CategoryConstraint, "unique constraint violation: duplicate value exists", false}, true
+	case "23514":
+		return errorClass{CategoryConstraint, "check constraint violation: value does not meet requirements", false}, true
+	case "40001":
+		return errorClass{CategoryQuery, "serialization failure", true}, true
+	case "40P01":
+		return errorClass{CategoryQuery, "deadlock detected", true}, true
+	case "55P03":
+		return errorClass{CategoryQuery, "lock not available", true}, true
+	case "57014":
+		return errorClass{CategoryTimeout, "query canceled (statement timeout)", true}, true
+	case "53300", "57P01", "57P02", "57P03":
+		return errorClass{CategoryConnection, "server unavailable or shutting down", true}, true
+	case "28000", "28P01":
+		return errorClass{CategoryConnection, "authentication failed", false}, true
+	case "42601":
+		return errorClass{CategoryQuery, "SQL syntax error", false}, true
+	}
+
+	switch {
+	case strings.HasPrefix(code, "23"):
+		return errorClass{CategoryConstraint, "constraint violation", false}, true
+	case strings.HasPrefix(code, "08"):
+		return errorClass{CategoryConnection, "connection failed or lost", true}, true
+	case strings.HasPrefix(code, "25"), strings.HasPrefix(code, "2D"):
+		return errorClass{CategoryTransaction, "invalid transaction state", false}, true
+	}
+	return errorClass{}, false
+}
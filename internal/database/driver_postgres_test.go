@@ -0,0 +1,140 @@
+//go:build !nopostgres
+
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+func TestClassifyPostgresError(t *testing.T) {
+	tests := []struct {
+		code      string
+		category  string
+		retryable bool
+	}{
+		{"23505", CategoryConstraint, false},
+		{"23503", CategoryConstraint, false},
+		{"23502", CategoryConstraint, false},
+		{"23P01", CategoryConstraint, false},
+		{"40001", CategoryQuery, true},
+		{"40P01", CategoryQuery, true},
+		{"57014", CategoryTimeout, true},
+		{"08006", CategoryConnection, true},
+		{"28P01", CategoryConnection, false},
+		{"25P02", CategoryTransaction, false},
+		{"42601", CategoryQuery, false},
+		{"42P01", CategoryQuery, false}, // undefined table: unclassified code
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			// Codes are read from the error type, wrapped or not, not from the message
+			err := fmt.Errorf("executing: %w", &pgconn.PgError{Code: tt.code, Message: "some error"})
+			dbErr := ClassifyDatabaseError(err, DriverPostgres, "exec", "INSERT INTO t VALUES ($1)", 1)
+			if dbErr.Category != tt.category || dbErr.Retryable != tt.retryable {
+				t.Errorf("got category %s retryable %v, want %s %v", dbErr.Category, dbErr.Retryable, tt.category, tt.retryable)
+			}
+			if !errors.Is(dbErr, err) {
+				t.Error("classified error does not wrap the original error")
+			}
+		})
+	}
+}
+
+func TestClassifyPostgresErrorIgnoresCodesInMessages(t *testing.T) {
+	// A value in the message must not be mistaken for an error code
+	err := errors.New("invalid input value 23505 for column id")
+	if dbErr := ClassifyDatabaseError(err, DriverPostgres, "exec", "", 0); dbErr.Category == CategoryConstraint {
+		t.Errorf("got category %s, want not constraint", dbErr.Category)
+	}
+}
+
+// servePostgresStandIn serves a minimal PostgreSQL server on ln: it accepts
+// any connection, answers simple queries with an empty result, and rejects
+// every extended-protocol statement with SQLSTATE code.
+func servePostgresStandIn(ln net.Listener, code string) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			backend := pgproto3.NewBackend(conn, conn)
+			if _, err := backend.ReceiveStartupMessage(); err != nil {
+				return
+			}
+			backend.Send(&pgproto3.AuthenticationOk{})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+
+			failed := false
+			for {
+				msg, err := backend.Receive()
+				if err != nil {
+					return
+				}
+				switch msg.(type) {
+				case *pgproto3.Query:
+					backend.Send(&pgproto3.EmptyQueryResponse{})
+					backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+				case *pgproto3.Parse:
+					if !failed {
+						backend.Send(&pgproto3.ErrorResponse{Severity: "ERROR", Code: code, Message: "duplicate key value violates unique constraint \"t_pkey\""})
+						failed = true
+					}
+				case *pgproto3.Sync:
+					backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+					failed = false
+				case *pgproto3.Terminate:
+					return
+				default:
+					continue
+				}
+				if err := backend.Flush(); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func TestPostgresDriver(t *testing.T) {
+	if !IsDriverLinked(DriverPostgres) {
+		t.Fatal("postgres driver is not linked")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	go servePostgresStandIn(ln, "23505")
+
+	db, driver, err := Open(Config{ConnectionString: "postgres://user:pass@" + ln.Addr().String() + "/db?sslmode=disable"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	if driver != DriverPostgres {
+		t.Errorf("driver = %s, want %s", driver, DriverPostgres)
+	}
+
+	_, err = db.ExecContext(context.Background(), "INSERT INTO t (id) VALUES ($1)", 1)
+	if err == nil {
+		t.Fatal("ExecContext() succeeded, want unique violation")
+	}
+	dbErr := ClassifyDatabaseError(err, driver, "exec", "INSERT INTO t (id) VALUES ($1)", 1)
+	if dbErr.Category != CategoryConstraint || dbErr.Message != "unique constraint violation: duplicate value exists" {
+		t.Errorf("got %s: %s, want unique constraint violation", dbErr.Category, dbErr.Message)
+	}
+}
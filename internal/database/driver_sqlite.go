@@ -0,0 +1,53 @@
+package database
+
+import (
+	"errors"
+	"strconv"
+
+	"modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+func init() {
+	registerDriver(DriverSQLite, sqliteErrorCode, classifySQLiteCode)
+}
+
+// sqliteErrorCode returns the (extended) result code of a SQLite error.
+func sqliteErrorCode(err error) (string, bool) {
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) {
+		return strconv.Itoa(liteErr.Code()), true
+	}
+	return "", false
+}
+
+// classifySQLiteCode classifies a SQLite result code
+// (https://www.sqlite.org/rescode.html).
+func classifySQLiteCode(code string) (errorClass, bool) {
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return errorClass{}, false
+	}
+	switch n {
+	case 1299: // SQLITE_CONSTRAINT_NOTNULL
+		return errorClass{CategoryConstraint, "not-null constraint violation: required field is null", false}, true
+	case 1555, 2067: // SQLITE_CONSTRAINT_PRIMARYKEY, SQLITE_CONSTRAINT_UNIQUE
+		return errorClass{CategoryConstraint, "unique constraint violation: duplicate value exists", false}, true
+	case 787: // SQLITE_CONSTRAINT_FOREIGNKEY
+		return errorClass{CategoryConstraint, "foreign key constraint violation: referenced record not found or still referenced", false}, true
+	case 275: // SQLITE_CONSTRAINT_CHECK
+		return errorClass{CategoryConstraint, "check constraint violation: value does not meet requirements", false}, true
+	}
+
+	// Extended result codes carry the primary result code in the low byte
+	switch n & 0xff {
+	case 19: // SQLITE_CONSTRAINT
+		return errorClass{CategoryConstraint, "constraint violation", false}, true
+	case 5, 6: // SQLITE_BUSY, SQLITE_LOCKED
+		return errorClass{CategoryQuery, "database is locked", true}, true
+	case 9: // SQLITE_INTERRUPT
+		return errorClass{CategoryTimeout, "operation interrupted", true}, true
+	case 14: // SQLITE_CANTOPEN
+		return errorClass{CategoryConnection, "unable to open database file", false}, true
+	}
+	return errorClass{}, false
+}
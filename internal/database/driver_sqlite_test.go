@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifySQLiteError(t *testing.T) {
+	db, driver, err := Open(Config{ConnectionString: "file:" + filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := db.Exec("CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO t (id, name) VALUES (1, 'a')"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"primary key", "INSERT INTO t (id, name) VALUES (1, 'b')", "unique constraint violation: duplicate value exists"},
+		{"unique", "INSERT INTO t (id, name) VALUES (2, 'a')", "unique constraint violation: duplicate value exists"},
+		{"not null", "INSERT INTO t (id, name) VALUES (3, NULL)", "not-null constraint violation: required field is null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := db.ExecContext(context.Background(), tt.query)
+			if err == nil {
+				t.Fatal("ExecContext() succeeded, want constraint violation")
+			}
+			dbErr := ClassifyDatabaseError(err, driver, "exec", tt.query, 0)
+			if dbErr.Category != CategoryConstraint || dbErr.Message != tt.message {
+				t.Errorf("got %s: %s, want constraint: %s", dbErr.Category, dbErr.Message, tt.message)
+			}
+		})
+	}
+}
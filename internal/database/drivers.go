@@ -0,0 +1,93 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+)
+
+// errorClass is the classification of a driver error code.
+type errorClass struct {
+	category  string
+	message   string
+	retryable bool
+}
+
+// errorCoder extracts the code of a driver error: SQLSTATE for PostgreSQL,
+// error number for MySQL, result code for SQLite.
+type errorCoder func(err error) (code string, ok bool)
+
+// Per-driver error code extraction and classification, registered by the
+// driver files linked into the build (see driver_*.go and their build tags).
+var (
+	errorCoders      = map[string]errorCoder{}
+	errorClassifier  = map[string]func(code string) (errorClass, bool){}
+	connectionErrors = map[string][]error{}
+)
+
+// registerDriver registers the error code extraction and classification of a
+// linked driver.
+func registerDriver(driver string, coder errorCoder, classify func(code string) (errorClass, bool)) {
+	errorCoders[driver] = coder
+	errorClassifier[driver] = classify
+}
+
+// registerConnectionErrors registers the sentinel errors returned by a linked
+// driver when its connection is lost, which carry no error code.
+func registerConnectionErrors(driver string, errs ...error) {
+	connectionErrors[driver] = append(connectionErrors[driver], errs...)
+}
+
+// IsDriverLinked reports whether the database/sql driver of a supported
+// driver type is linked into the binary. PostgreSQL and MySQL drivers are
+// excluded by the nopostgres and nomysql build tags.
+func IsDriverLinked(driver string) bool {
+	return slices.Contains(sql.Drivers(), GetDriverName(driver))
+}
+
+// ErrorCode returns the driver error code of err (SQLSTATE for PostgreSQL,
+// error number for MySQL, result code for SQLite), if err is a driver error.
+func ErrorCode(err error, driver string) (string, bool) {
+	coder, ok := errorCoders[driver]
+	if !ok || err == nil {
+		return "", false
+	}
+	return coder(err)
+}
+
+// isConnectionLost reports whether err is database/sql's bad connection
+// error or a connection error registered by the driver.
+func isConnectionLost(err error, driverType string) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	for _, connErr := range connectionErrors[driverType] {
+		if errors.Is(err, connErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyByCode classifies a driver error from its error code. Returns nil
+// if err carries no code or the code is not classified.
+func classifyByCode(err error, driver, operation, query string, paramCount int) *DatabaseError {
+	code, ok := ErrorCode(err, driver)
+	if !ok {
+		return nil
+	}
+	class, ok := errorClassifier[driver](code)
+	if !ok {
+		return nil
+	}
+
+	switch class.category {
+	case CategoryQuery:
+		return NewQueryError(operation, class.message, query, paramCount, err, class.retryable)
+	case CategoryConnection:
+		return NewDatabaseError(CategoryConnection, "connect", class.message, err, class.retryable)
+	default:
+		return NewDatabaseError(class.category, operation, class.message, err, class.retryable)
+	}
+}
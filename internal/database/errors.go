@@ -97,12 +97,21 @@ func NewTimeoutError(operation, message string, originalErr error) *DatabaseErro
 }
 
 // ClassifyDatabaseError classifies a raw database error into a DatabaseError.
-// It analyzes the error message and type to determine the category and retryability.
+// Driver errors are classified by their error code (SQLSTATE, MySQL error
+// number, SQLite result code); other errors, such as network and context
+// errors, by their message.
 func ClassifyDatabaseError(err error, driver, operation, query string, paramCount int) *DatabaseError {
 	if err == nil {
 		return nil
 	}
 
+	if dbErr := classifyByCode(err, driver, operation, query, paramCount); dbErr != nil {
+		return dbErr
+	}
+	if isConnectionLost(err, driver) {
+		return NewConnectionError("connection lost", err)
+	}
+
 	errMsg := err.Error()
 	errMsgLower := strings.ToLower(errMsg)
 
@@ -117,17 +126,17 @@ func ClassifyDatabaseError(err error, driver, operation, query string, paramCoun
 	}
 
 	// Check for constraint violations
-	if isConstraintError(errMsgLower, driver) {
+	if isConstraintError(errMsgLower) {
 		return NewConstraintError(operation, extractConstraintMessage(errMsgLower), err)
 	}
 
 	// Check for deadlock (retryable)
-	if isDeadlockError(errMsgLower, driver) {
+	if isDeadlockError(errMsgLower) {
 		return NewQueryError(operation, "deadlock detected", query, paramCount, err, true)
 	}
 
 	// Check for syntax errors (not retryable)
-	if isSyntaxError(errMsgLower, driver) {
+	if isSyntaxError(errMsgLower) {
 		return NewQueryError(operation, "SQL syntax error", query, paramCount, err, false)
 	}
 
@@ -181,8 +190,9 @@ func isConnectionError(errMsg string) bool {
 	return false
 }
 
-// isConstraintError checks if the error is a constraint violation.
-func isConstraintError(errMsg string, driver string) bool {
+// isConstraintError checks if the error message reports a constraint violation,
+// for errors without a driver error code.
+func isConstraintError(errMsg string) bool {
 	// Common constraint indicators
 	commonIndicators := []string{
 		"unique constraint",
@@ -204,45 +214,11 @@ func isConstraintError(errMsg string, driver string) bool {
 		}
 	}
 
-	// Driver-specific error codes
-	switch driver {
-	case DriverPostgres:
-		// PostgreSQL error codes for constraints
-		pgCodes := []string{
-			"23000", // integrity_constraint_violation
-			"23001", // restrict_violation
-			"23502", // not_null_violation
-			"23503", // foreign_key_violation
-			"23505", // unique_violation
-			"23514", // check_violation
-			"23p01", // exclusion_violation
-		}
-		for _, code := range pgCodes {
-			if strings.Contains(errMsg, code) {
-				return true
-			}
-		}
-	case DriverMySQL:
-		// MySQL error codes for constraints
-		mysqlCodes := []string{
-			"1062", // duplicate entry
-			"1216", // foreign key constraint
-			"1217", // foreign key constraint
-			"1451", // foreign key constraint (delete)
-			"1452", // foreign key constraint (insert/update)
-		}
-		for _, code := range mysqlCodes {
-			if strings.Contains(errMsg, code) {
-				return true
-			}
-		}
-	}
-
 	return false
 }
 
 // isDeadlockError checks if the error is a deadlock error.
-func isDeadlockError(errMsg string, driver string) bool {
+func isDeadlockError(errMsg string) bool {
 	commonIndicators := []string{
 		"deadlock",
 		"lock wait timeout",
@@ -256,23 +232,11 @@ func isDeadlockError(errMsg string, driver string) bool {
 		}
 	}
 
-	// Driver-specific error codes
-	switch driver {
-	case DriverPostgres:
-		if strings.Contains(errMsg, "40001") || strings.Contains(errMsg, "40p01") {
-			return true
-		}
-	case DriverMySQL:
-		if strings.Contains(errMsg, "1213") {
-			return true
-		}
-	}
-
 	return false
 }
 
 // isSyntaxError checks if the error is a SQL syntax error.
-func isSyntaxError(errMsg string, driver string) bool {
+func isSyntaxError(errMsg string) bool {
 	commonIndicators := []string{
 		"syntax error",
 		"parse error",
@@ -286,18 +250,6 @@ func isSyntaxError(errMsg string, driver string) bool {
 		}
 	}
 
-	// Driver-specific error codes
-	switch driver {
-	case DriverPostgres:
-		if strings.Contains(errMsg, "42601") {
-			return true
-		}
-	case DriverMySQL:
-		if strings.Contains(errMsg, "1064") {
-			return true
-		}
-	}
-
 	return false
 }
 
@@ -361,5 +313,5 @@ func IsRetryableError(err error) bool {
 
 	// Check for known transient error patterns in raw errors
 	errMsg := strings.ToLower(err.Error())
-	return isTimeoutError(errMsg) || isConnectionError(errMsg) || isDeadlockError(errMsg, "")
+	return isTimeoutError(errMsg) || isConnectionError(errMsg) || isDeadlockError(errMsg)
 }
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Isolation level names accepted in configuration
+const (
+	IsolationDefault         = "default"
+	IsolationReadUncommitted = "read_uncommitted"
+	IsolationReadCommitted   = "read_committed"
+	IsolationRepeatableRead  = "repeatable_read"
+	IsolationSerializable    = "serializable"
+)
+
+// ErrUnsupportedIsolationLevel is returned for an unknown isolation level name.
+var ErrUnsupportedIsolationLevel = errors.New("unsupported isolation level")
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	"":                       sql.LevelDefault,
+	IsolationDefault:         sql.LevelDefault,
+	IsolationReadUncommitted: sql.LevelReadUncommitted,
+	IsolationReadCommitted:   sql.LevelReadCommitted,
+	IsolationRepeatableRead:  sql.LevelRepeatableRead,
+	IsolationSerializable:    sql.LevelSerializable,
+}
+
+// TxOptions returns the transaction options of an isolation level name and
+// read-only mode, or nil for the driver defaults. SQLite transactions are
+// always serializable; the options are accepted and have no effect there.
+func TxOptions(isolation string, readOnly bool) (*sql.TxOptions, error) {
+	level, ok := isolationLevels[isolation]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (supported: %s, %s, %s, %s, %s)", ErrUnsupportedIsolationLevel, isolation,
+			IsolationDefault, IsolationReadUncommitted, IsolationReadCommitted, IsolationRepeatableRead, IsolationSerializable)
+	}
+	if level == sql.LevelDefault && !readOnly {
+		return nil, nil
+	}
+	return &sql.TxOptions{Isolation: level, ReadOnly: readOnly}, nil
+}
@@ -2,8 +2,10 @@
 package httpconfig
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/cannectors/runtime/internal/ratelimit"
 	"github.com/cannectors/runtime/pkg/connector"
 )
 
@@ -129,6 +131,21 @@ func ExtractDataExtractionConfig(config map[string]interface{}) DataExtractionCo
 	return dec
 }
 
+// ExtractRateLimitConfig extracts the rateLimit configuration from a config map.
+// Returns nil if rate limiting is not configured.
+func ExtractRateLimitConfig(config map[string]interface{}) (*ratelimit.Config, error) {
+	raw, ok := config["rateLimit"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	rl, err := ratelimit.ParseConfig(raw)
+	if err != nil {
+		return nil, fmt.Errorf("rateLimit: %w", err)
+	}
+	return &rl, nil
+}
+
 // ExtractStringMap extracts a map[string]string from a config map at the given key.
 func ExtractStringMap(config map[string]interface{}, key string) map[string]string {
 	result := make(map[string]string)
@@ -5,14 +5,20 @@ package modules
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cannectors/runtime/internal/modules/filter"
 	"github.com/cannectors/runtime/internal/modules/input"
 	"github.com/cannectors/runtime/internal/modules/output"
+	"github.com/cannectors/runtime/internal/persistence"
 	"github.com/cannectors/runtime/pkg/connector"
 
 	_ "modernc.org/sqlite"
@@ -154,6 +160,344 @@ func TestDatabaseInputBasicQuery(t *testing.T) {
 	}
 }
 
+// TestDatabaseInputStreaming tests row streaming in chunks
+func TestDatabaseInputStreaming(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT);
+		WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 2500)
+		INSERT INTO events (id, name) SELECT n, 'event-' || n FROM seq;
+	`)
+	if err != nil {
+		t.Fatalf("Failed to setup test data: %v", err)
+	}
+	db.Close()
+
+	cfg := &connector.ModuleConfig{
+		Type: "database",
+		Config: map[string]interface{}{
+			"connectionString": "file:" + tmpFile,
+			"driver":           "sqlite",
+			"query":            "SELECT id, name FROM events ORDER BY id",
+			"streaming": map[string]interface{}{
+				"chunkSize": float64(1000),
+			},
+		},
+	}
+
+	inputModule, err := input.NewDatabaseInputFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create input module: %v", err)
+	}
+	defer inputModule.Close()
+
+	var chunkSizes []int
+	lastID := int64(0)
+	err = inputModule.Stream(context.Background(), func(_ context.Context, records []map[string]interface{}) error {
+		chunkSizes = append(chunkSizes, len(records))
+		for _, record := range records {
+			id := record["id"].(int64)
+			if id != lastID+1 {
+				t.Fatalf("record id = %d, want %d", id, lastID+1)
+			}
+			lastID = id
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if len(chunkSizes) != 3 || chunkSizes[0] != 1000 || chunkSizes[1] != 1000 || chunkSizes[2] != 500 {
+		t.Errorf("chunk sizes = %v, want [1000 1000 500]", chunkSizes)
+	}
+
+	// Fetch collects the streamed chunks
+	records, err := inputModule.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(records) != 2500 {
+		t.Errorf("Expected 2500 records, got %d", len(records))
+	}
+}
+
+// TestDatabaseInputStreamingResume tests resuming an interrupted stream from
+// its checkpoint, by keyset cursor and by offset.
+func TestDatabaseInputStreamingResume(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT);
+		WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 25)
+		INSERT INTO events (id, name) SELECT n, 'event-' || n FROM seq;
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to setup test data: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+	}{
+		{
+			name: "keyset cursor",
+			config: map[string]interface{}{
+				"query":       "SELECT id, name FROM events WHERE {{cursorPredicate}} ORDER BY id",
+				"incremental": map[string]interface{}{"enabled": true, "cursorFields": []interface{}{"id"}},
+			},
+		},
+		{
+			name:   "offset",
+			config: map[string]interface{}{"query": "SELECT id, name FROM events ORDER BY id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newInput := func() *input.DatabaseInput {
+				t.Helper()
+				config := map[string]interface{}{
+					"connectionString": "file:" + tmpFile,
+					"driver":           "sqlite",
+					"streaming":        map[string]interface{}{"chunkSize": float64(10)},
+				}
+				for k, v := range tt.config {
+					config[k] = v
+				}
+				inputModule, err := input.NewDatabaseInputFromConfig(&connector.ModuleConfig{Type: "database", Config: config})
+				if err != nil {
+					t.Fatalf("Failed to create input module: %v", err)
+				}
+				t.Cleanup(func() { _ = inputModule.Close() })
+				return inputModule
+			}
+
+			// The first run fails on the second chunk
+			first := newInput()
+			var checkpoint *persistence.Checkpoint
+			chunks := 0
+			err := first.Stream(context.Background(), func(_ context.Context, _ []map[string]interface{}) error {
+				chunks++
+				if chunks == 2 {
+					return errors.New("destination unavailable")
+				}
+				checkpoint = first.Checkpoint()
+				return nil
+			})
+			if err == nil || checkpoint == nil {
+				t.Fatalf("Stream = %v, checkpoint %+v, want failure after a checkpoint", err, checkpoint)
+			}
+
+			// Round trip through the state store encoding
+			data, err := json.Marshal(checkpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var persisted persistence.Checkpoint
+			if err := json.Unmarshal(data, &persisted); err != nil {
+				t.Fatal(err)
+			}
+
+			second := newInput()
+			if err := second.ResumeFrom(&persisted); err != nil {
+				t.Fatalf("ResumeFrom failed: %v", err)
+			}
+			var ids []int64
+			err = second.Stream(context.Background(), func(_ context.Context, records []map[string]interface{}) error {
+				for _, record := range records {
+					ids = append(ids, record["id"].(int64))
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Stream failed: %v", err)
+			}
+			if len(ids) != 15 || ids[0] != 11 || ids[14] != 25 {
+				t.Errorf("resumed ids = %v, want 11..25", ids)
+			}
+		})
+	}
+}
+
+// TestDatabaseInputKeysetPagination tests composite keyset pagination over
+// rows sharing a timestamp, and resuming from the persisted cursor, for
+// timestamps stored as text in several formats.
+func TestDatabaseInputKeysetPagination(t *testing.T) {
+	t.Parallel()
+
+	formats := []struct {
+		name   string
+		layout string
+	}{
+		{name: "sqlite", layout: "2006-01-02 15:04:05"},
+		{name: "rfc3339", layout: time.RFC3339},
+		{name: "fractional with offset", layout: "2006-01-02 15:04:05.999999999-07:00"},
+	}
+
+	for _, format := range formats {
+		t.Run(format.name, func(t *testing.T) {
+			t.Parallel()
+			testDatabaseInputKeysetPagination(t, format.layout)
+		})
+	}
+}
+
+func testDatabaseInputKeysetPagination(t *testing.T, layout string) {
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+
+	at := func(hour int) string {
+		return time.Date(2026, 1, 26, hour, 0, 0, 500000000, time.UTC).Format(layout)
+	}
+
+	// Five rows share the same timestamp, spanning page boundaries
+	if _, err := db.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT, updated_at DATETIME NOT NULL)`); err != nil {
+		t.Fatalf("Failed to setup test data: %v", err)
+	}
+	for id, hour := range []int{9, 10, 10, 10, 10, 10, 11} {
+		if _, err := db.Exec(`INSERT INTO events (id, name, updated_at) VALUES (?, ?, ?)`, id+1, string(rune('a'+id)), at(hour)); err != nil {
+			t.Fatalf("Failed to setup test data: %v", err)
+		}
+	}
+
+	stateStore := persistence.NewFileStateStore(t.TempDir())
+	cfg := &connector.ModuleConfig{
+		Type: "database",
+		Config: map[string]interface{}{
+			"connectionString": "file:" + tmpFile,
+			"driver":           "sqlite",
+			"query":            "SELECT id, name, updated_at FROM events WHERE name <> :skip AND {{cursorPredicate}} ORDER BY updated_at, id -- keyset",
+			"parameters":       map[string]interface{}{"skip": "none"},
+			"pagination": map[string]interface{}{
+				"type":         "cursor",
+				"limit":        float64(2),
+				"cursorFields": []interface{}{"updated_at", "id"},
+			},
+			"incremental": map[string]interface{}{
+				"enabled":      true,
+				"cursorFields": []interface{}{"updated_at", "id"},
+			},
+		},
+	}
+
+	fetch := func() ([]int64, map[string]interface{}) {
+		t.Helper()
+		inputModule, err := input.NewDatabaseInputFromConfig(cfg)
+		if err != nil {
+			t.Fatalf("Failed to create input module: %v", err)
+		}
+		defer inputModule.Close()
+		inputModule.SetPipelineID("keyset-test")
+		inputModule.SetStateStore(stateStore)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		records, err := inputModule.Fetch(ctx)
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+		ids := make([]int64, len(records))
+		for i, record := range records {
+			ids[i] = record["id"].(int64)
+			if _, ok := record["_cannectors_cursor_0"]; ok {
+				t.Fatalf("record %v holds the cursor text column", record)
+			}
+		}
+		cursor := inputModule.LastCursor()
+		if cursor != nil {
+			if err := stateStore.Save("keyset-test", &persistence.State{PipelineID: "keyset-test", Cursor: cursor}); err != nil {
+				t.Fatalf("Failed to save state: %v", err)
+			}
+		}
+		return ids, cursor
+	}
+
+	ids, cursor := fetch()
+	if len(ids) != 7 {
+		t.Fatalf("first run ids = %v, want 1..7 without duplicates", ids)
+	}
+	for i, id := range ids {
+		if id != int64(i+1) {
+			t.Fatalf("first run ids = %v, want 1..7 in order", ids)
+		}
+	}
+	if cursor["updated_at"] != at(11) || cursor["id"] != int64(7) {
+		t.Errorf("cursor = %v, want updated_at %s and id 7", cursor, at(11))
+	}
+
+	// A late row sharing the last timestamp is read on the next run, and only it
+	if _, err := db.Exec(`INSERT INTO events (id, name, updated_at) VALUES (8, 'h', ?)`, at(11)); err != nil {
+		t.Fatalf("Failed to insert row: %v", err)
+	}
+	ids, _ = fetch()
+	if len(ids) != 1 || ids[0] != 8 {
+		t.Errorf("second run ids = %v, want [8]", ids)
+	}
+}
+
+// TestDatabaseInputKeysetCursorNotAdvancing tests that keyset pagination
+// fails instead of looping when a page ends at the cursor it started after.
+func TestDatabaseInputKeysetCursorNotAdvancing(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`
+		CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO events (id, name) VALUES (1, 'a'), (2, 'b'), (3, 'c');
+	`); err != nil {
+		t.Fatalf("Failed to setup test data: %v", err)
+	}
+
+	// The predicate does not restrict the rows: every page is the first one
+	inputModule, err := input.NewDatabaseInputFromConfig(&connector.ModuleConfig{
+		Type: "database",
+		Config: map[string]interface{}{
+			"connectionString": "file:" + tmpFile,
+			"driver":           "sqlite",
+			"query":            "SELECT id, name FROM events WHERE {{cursorPredicate}} OR 1=1 ORDER BY id",
+			"pagination": map[string]interface{}{
+				"type":         "cursor",
+				"limit":        float64(2),
+				"cursorFields": []interface{}{"id"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create input module: %v", err)
+	}
+	defer inputModule.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := inputModule.Fetch(ctx); !errors.Is(err, input.ErrDatabaseInvalidCursor) {
+		t.Errorf("Fetch() error = %v, want ErrDatabaseInvalidCursor", err)
+	}
+}
+
 // TestDatabaseInputWithQueryFile tests loading query from file
 func TestDatabaseInputWithQueryFile(t *testing.T) {
 	t.Parallel()
@@ -740,3 +1084,537 @@ func TestDatabaseOutputNestedFields(t *testing.T) {
 		t.Errorf("Got (%q, %q), want (login, alice)", eventType, userName)
 	}
 }
+
+// TestDatabaseOutputDeclarative tests the statements generated from table,
+// columns and keys, and the validation of columns against the table
+func TestDatabaseOutputDeclarative(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE products (sku TEXT PRIMARY KEY, name TEXT, price REAL);
+		INSERT INTO products VALUES ('SKU001', 'Old Name', 9.99), ('SKU003', 'Discontinued', 1.00);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to setup: %v", err)
+	}
+
+	newOutput := func(mode string, columns map[string]interface{}) (*output.DatabaseOutput, error) {
+		return output.NewDatabaseOutputFromConfig(&connector.ModuleConfig{
+			Type: "database",
+			Config: map[string]interface{}{
+				"connectionString": "file:" + tmpFile,
+				"driver":           "sqlite",
+				"table":            "products",
+				"columns":          columns,
+				"keys":             []interface{}{"sku"},
+				"mode":             mode,
+			},
+		})
+	}
+	send := func(mode string, columns map[string]interface{}, records []map[string]interface{}) {
+		t.Helper()
+		outputModule, err := newOutput(mode, columns)
+		if err != nil {
+			t.Fatalf("Failed to create %s output module: %v", mode, err)
+		}
+		defer outputModule.Close()
+		if _, err := outputModule.Send(context.Background(), records); err != nil {
+			t.Fatalf("%s Send failed: %v", mode, err)
+		}
+	}
+
+	columns := map[string]interface{}{"sku": "sku", "product_name": "name", "price": "price"}
+	send("upsert", columns, []map[string]interface{}{
+		{"sku": "SKU001", "product_name": "Updated Name", "price": 19.99}, // Update existing
+		{"sku": "SKU002", "product_name": "New Product", "price": 29.99},  // Insert new
+	})
+	send("update", map[string]interface{}{"sku": "sku", "price": "price"}, []map[string]interface{}{
+		{"sku": "SKU002", "price": 24.99},
+	})
+	send("delete", map[string]interface{}{"sku": "sku"}, []map[string]interface{}{
+		{"sku": "SKU003"},
+	})
+
+	rows, err := db.Query("SELECT sku, name, price FROM products ORDER BY sku")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var sku, name string
+		var price float64
+		if err := rows.Scan(&sku, &name, &price); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, sku+"|"+name+"|"+strconv.FormatFloat(price, 'f', 2, 64))
+	}
+	want := []string{"SKU001|Updated Name|19.99", "SKU002|New Product|24.99"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("products = %v, want %v", got, want)
+	}
+
+	// Columns are validated against the table
+	_, err = newOutput("insert", map[string]interface{}{"sku": "sku", "stock": "stock_quantity"})
+	if !errors.Is(err, output.ErrDatabaseOutputInvalidTable) || !strings.Contains(err.Error(), "stock_quantity") {
+		t.Errorf("unknown column error = %v, want ErrDatabaseOutputInvalidTable naming stock_quantity", err)
+	}
+}
+
+// TestDatabaseOutputBatches tests multi-row and prepared batches, and the
+// per-record fallback isolating failing records of a batch
+func TestDatabaseOutputBatches(t *testing.T) {
+	t.Parallel()
+
+	records := []map[string]interface{}{
+		{"sku": "SKU001", "name": "One"},
+		{"sku": "SKU002", "name": "Two"},
+		{"sku": "SKU003", "name": nil}, // violates NOT NULL
+		{"sku": "SKU004", "name": "Four"},
+		{"sku": "SKU005", "name": "Five"},
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]interface{}
+		transaction bool
+	}{
+		{
+			name:   "multi-row insert",
+			config: map[string]interface{}{"table": "products", "columns": map[string]interface{}{"sku": "sku", "name": "name"}},
+		},
+		{
+			name:        "multi-row insert in transaction",
+			config:      map[string]interface{}{"table": "products", "columns": map[string]interface{}{"sku": "sku", "name": "name"}},
+			transaction: true,
+		},
+		{
+			name:   "prepared template query",
+			config: map[string]interface{}{"query": "INSERT INTO products (sku, name) VALUES ({{record.sku}}, {{record.name}})"},
+		},
+		{
+			name:        "prepared template query in transaction",
+			config:      map[string]interface{}{"query": "INSERT INTO products (sku, name) VALUES ({{record.sku}}, {{record.name}})"},
+			transaction: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "test.db")
+			db, err := sql.Open("sqlite", tmpFile)
+			if err != nil {
+				t.Fatalf("Failed to create test db: %v", err)
+			}
+			defer db.Close()
+			if _, err := db.Exec(`CREATE TABLE products (sku TEXT PRIMARY KEY, name TEXT NOT NULL)`); err != nil {
+				t.Fatalf("Failed to setup: %v", err)
+			}
+
+			cfg := map[string]interface{}{
+				"connectionString": "file:" + tmpFile,
+				"driver":           "sqlite",
+				"batchSize":        float64(2),
+				"transaction":      tt.transaction,
+				"onError":          "skip",
+			}
+			for k, v := range tt.config {
+				cfg[k] = v
+			}
+			outputModule, err := output.NewDatabaseOutputFromConfig(&connector.ModuleConfig{Type: "database", Config: cfg})
+			if err != nil {
+				t.Fatalf("Failed to create output module: %v", err)
+			}
+			defer outputModule.Close()
+
+			sent, err := outputModule.Send(context.Background(), records)
+			if err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			if sent != 4 {
+				t.Errorf("Send() = %d, want 4 records written", sent)
+			}
+
+			var skus []string
+			rows, err := db.Query("SELECT sku FROM products ORDER BY sku")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var sku string
+				if err := rows.Scan(&sku); err != nil {
+					t.Fatal(err)
+				}
+				skus = append(skus, sku)
+			}
+			if strings.Join(skus, ",") != "SKU001,SKU002,SKU004,SKU005" {
+				t.Errorf("products = %v, want all but SKU003", skus)
+			}
+		})
+	}
+}
+
+// TestDatabaseOutputDryRunRollback tests that dry-run previews executed in a
+// rolled back transaction report database errors and write nothing
+func TestDatabaseOutputDryRunRollback(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE products (sku TEXT PRIMARY KEY, name TEXT NOT NULL)`); err != nil {
+		t.Fatalf("Failed to setup: %v", err)
+	}
+
+	outputModule, err := output.NewDatabaseOutputFromConfig(&connector.ModuleConfig{
+		Type: "database",
+		Config: map[string]interface{}{
+			"connectionString": "file:" + tmpFile,
+			"driver":           "sqlite",
+			"table":            "products",
+			"columns":          map[string]interface{}{"sku": "sku", "name": "name"},
+			"dryRunRollback":   true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create output module: %v", err)
+	}
+	defer outputModule.Close()
+
+	previews, err := outputModule.PreviewRequest([]map[string]interface{}{
+		{"sku": "SKU001", "name": "One"},
+		{"sku": "SKU001", "name": "Duplicate"},
+		{"sku": "SKU002", "name": nil},
+		{"sku": "SKU003", "name": "Three"},
+	}, output.PreviewOptions{})
+	if err != nil {
+		t.Fatalf("PreviewRequest failed: %v", err)
+	}
+	if len(previews) != 4 {
+		t.Fatalf("PreviewRequest() returned %d previews, want 4", len(previews))
+	}
+
+	wantErrors := []string{"", "unique constraint", "not-null constraint", ""}
+	for i, p := range previews {
+		if !p.SQL.Executed {
+			t.Errorf("preview %d: statement not executed", i)
+		}
+		if wantErrors[i] == "" && p.SQL.Error != "" {
+			t.Errorf("preview %d: unexpected error %s", i, p.SQL.Error)
+		}
+		if wantErrors[i] != "" && !strings.Contains(p.SQL.Error, wantErrors[i]) {
+			t.Errorf("preview %d: error = %q, want %s", i, p.SQL.Error, wantErrors[i])
+		}
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM products").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("products has %d rows after dry-run, want 0", count)
+	}
+}
+
+// TestDatabaseOutputAutoSchema tests that schema auto creates the table,
+// adds columns for new fields and reports type conflicts
+func TestDatabaseOutputAutoSchema(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+
+	outputModule, err := output.NewDatabaseOutputFromConfig(&connector.ModuleConfig{
+		Type: "database",
+		Config: map[string]interface{}{
+			"connectionString": "file:" + tmpFile,
+			"driver":           "sqlite",
+			"table":            "events",
+			"keys":             []interface{}{"id"},
+			"mode":             "upsert",
+			"schema":           "auto",
+			"columnTypes":      map[string]interface{}{"received_at": "TIMESTAMP"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create output module: %v", err)
+	}
+	defer outputModule.Close()
+
+	send := func(records []map[string]interface{}) error {
+		_, err := outputModule.Send(context.Background(), records)
+		return err
+	}
+	first := []map[string]interface{}{
+		{"id": float64(1), "kind": "click", "payload": map[string]interface{}{"x": float64(10)}},
+	}
+
+	// Dry-run previews the DDL without executing it
+	previews, err := outputModule.PreviewRequest(first, output.PreviewOptions{})
+	if err != nil {
+		t.Fatalf("PreviewRequest failed: %v", err)
+	}
+	if len(previews) != 2 || previews[0].SQL.Operation != "create table" || previews[1].SQL.Operation != "upsert" {
+		t.Fatalf("PreviewRequest() = %+v, want create table then upsert", previews)
+	}
+	if _, err := db.Exec("SELECT 1 FROM events"); err == nil {
+		t.Fatal("events table created by PreviewRequest")
+	}
+
+	if err := send(first); err != nil {
+		t.Fatalf("First Send failed: %v", err)
+	}
+	if err := send([]map[string]interface{}{
+		{"id": float64(1), "kind": "view", "duration": 1.5},
+		{"id": float64(2), "kind": "click"},
+	}); err != nil {
+		t.Fatalf("Send with new field failed: %v", err)
+	}
+
+	columns := map[string]string{}
+	rows, err := db.Query("SELECT name, type FROM pragma_table_info('events')")
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			t.Fatal(err)
+		}
+		columns[name] = typ
+	}
+	rows.Close()
+	want := map[string]string{"id": "INTEGER", "kind": "TEXT", "payload": "TEXT", "received_at": "TIMESTAMP", "duration": "REAL"}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("events columns = %v, want %v", columns, want)
+	}
+
+	var kind, payload string
+	if err := db.QueryRow("SELECT kind, payload FROM events WHERE id = 1").Scan(&kind, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if kind != "view" || payload != `{"x":10}` {
+		t.Errorf("event 1 = %s %s, want view {\"x\":10}", kind, payload)
+	}
+
+	// Existing columns are not widened
+	err = send([]map[string]interface{}{{"id": "evt-3", "kind": "click"}})
+	if !errors.Is(err, output.ErrDatabaseOutputSchemaConflict) || !strings.Contains(err.Error(), "column id is INTEGER but records have text values") {
+		t.Errorf("conflict error = %v, want ErrDatabaseOutputSchemaConflict naming column id", err)
+	}
+}
+
+// TestDatabaseOutputTransactionControl tests that failing records are rolled
+// back to their savepoint and skipped, and that commitEvery keeps the records
+// committed before a failure
+func TestDatabaseOutputTransactionControl(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`); err != nil {
+		t.Fatalf("Failed to setup: %v", err)
+	}
+
+	send := func(config map[string]interface{}, records []map[string]interface{}) (int, error) {
+		t.Helper()
+		config["connectionString"] = "file:" + tmpFile
+		config["driver"] = "sqlite"
+		config["table"] = "items"
+		config["columns"] = map[string]interface{}{"id": "id", "name": "name"}
+		config["transaction"] = true
+		outputModule, err := output.NewDatabaseOutputFromConfig(&connector.ModuleConfig{Type: "database", Config: config})
+		if err != nil {
+			t.Fatalf("Failed to create output module: %v", err)
+		}
+		defer outputModule.Close()
+		return outputModule.Send(context.Background(), records)
+	}
+	count := func() int {
+		t.Helper()
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM items").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// onError skip: the failing records are rolled back alone
+	for _, batchSize := range []float64{1, 3} {
+		if _, err := db.Exec("DELETE FROM items"); err != nil {
+			t.Fatal(err)
+		}
+		sent, err := send(map[string]interface{}{"onError": "skip", "isolationLevel": "serializable", "batchSize": batchSize}, []map[string]interface{}{
+			{"id": 1, "name": "One"},
+			{"id": 2, "name": nil},
+			{"id": 1, "name": "Duplicate"},
+			{"id": 3, "name": "Three"},
+		})
+		if err != nil {
+			t.Fatalf("batchSize %v: Send failed: %v", batchSize, err)
+		}
+		if sent != 2 || count() != 2 {
+			t.Errorf("batchSize %v: sent %d, %d rows, want 2", batchSize, sent, count())
+		}
+	}
+
+	// commitEvery: the records committed before the failure are kept
+	if _, err := db.Exec("DELETE FROM items"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = send(map[string]interface{}{"commitEvery": float64(2)}, []map[string]interface{}{
+		{"id": 1, "name": "One"},
+		{"id": 2, "name": "Two"},
+		{"id": 3, "name": "Three"},
+		{"id": 4, "name": nil},
+	})
+	if err == nil {
+		t.Fatal("Send succeeded, want not-null violation")
+	}
+	if n := count(); n != 2 {
+		t.Errorf("items has %d rows after failure, want the 2 committed", n)
+	}
+}
+
+// TestDatabaseInputReadTransaction tests that the pages of a fetch are read
+// in a transaction with the configured isolation level
+func TestDatabaseInputReadTransaction(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		INSERT INTO users (id, name) VALUES (1, 'Alice'), (2, 'Bob'), (3, 'Carol');
+	`); err != nil {
+		t.Fatalf("Failed to setup test data: %v", err)
+	}
+
+	inputModule, err := input.NewDatabaseInputFromConfig(&connector.ModuleConfig{
+		Type: "database",
+		Config: map[string]interface{}{
+			"connectionString": "file:" + tmpFile,
+			"driver":           "sqlite",
+			"query":            "SELECT id, name FROM users ORDER BY id",
+			"pagination":       map[string]interface{}{"type": "limit-offset", "limit": float64(2)},
+			"isolationLevel":   "repeatable_read",
+			"readOnly":         true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create input module: %v", err)
+	}
+	defer inputModule.Close()
+
+	records, err := inputModule.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("Fetch returned %d records, want 3", len(records))
+	}
+}
+
+// TestDatabaseOutputReturning tests that generated ids are stored in the
+// written records, and dropped when their writes are rolled back
+func TestDatabaseOutputReturning(t *testing.T) {
+	t.Parallel()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create test db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE orders (id INTEGER PRIMARY KEY AUTOINCREMENT, ref TEXT NOT NULL UNIQUE)`); err != nil {
+		t.Fatalf("Failed to setup: %v", err)
+	}
+
+	send := func(config map[string]interface{}, records []map[string]interface{}) ([]map[string]interface{}, error) {
+		t.Helper()
+		config["connectionString"] = "file:" + tmpFile
+		config["driver"] = "sqlite"
+		config["returning"] = []interface{}{"id"}
+		config["returningField"] = "_metadata.db"
+		outputModule, err := output.NewDatabaseOutputFromConfig(&connector.ModuleConfig{Type: "database", Config: config})
+		if err != nil {
+			t.Fatalf("Failed to create output module: %v", err)
+		}
+		defer outputModule.Close()
+		_, err = outputModule.Send(context.Background(), records)
+		return outputModule.ReturnedRecords(), err
+	}
+	ids := func(records []map[string]interface{}) []interface{} {
+		var ids []interface{}
+		for _, record := range records {
+			ids = append(ids, record["_metadata"].(map[string]interface{})["db"].(map[string]interface{})["id"])
+		}
+		return ids
+	}
+
+	// Declarative writes, one at a time or in prepared batches; the failing
+	// record is skipped and not returned
+	for i, batchSize := range []float64{1, 3} {
+		prefix := "b" + strconv.Itoa(i) + "-"
+		returned, err := send(map[string]interface{}{
+			"table":     "orders",
+			"columns":   map[string]interface{}{"ref": "ref"},
+			"batchSize": batchSize,
+			"onError":   "skip",
+		}, []map[string]interface{}{{"ref": prefix + "1"}, {"ref": nil}, {"ref": prefix + "2"}})
+		if err != nil {
+			t.Fatalf("batchSize %v: Send failed: %v", batchSize, err)
+		}
+		want := []interface{}{int64(2*i + 1), int64(2*i + 2)}
+		if got := ids(returned); !reflect.DeepEqual(got, want) {
+			t.Errorf("batchSize %v: returned ids = %v, want %v", batchSize, got, want)
+		}
+	}
+
+	// Query template
+	returned, err := send(map[string]interface{}{
+		"query": "INSERT INTO orders (ref) VALUES ({{record.ref}});",
+	}, []map[string]interface{}{{"ref": "q-1"}})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got := ids(returned); !reflect.DeepEqual(got, []interface{}{int64(5)}) {
+		t.Errorf("query returned ids = %v, want [5]", got)
+	}
+
+	// A rolled back transaction returns no records
+	returned, err = send(map[string]interface{}{
+		"table":       "orders",
+		"columns":     map[string]interface{}{"ref": "ref"},
+		"transaction": true,
+	}, []map[string]interface{}{{"ref": "t-1"}, {"ref": "q-1"}})
+	if err == nil {
+		t.Fatal("Send succeeded, want unique violation")
+	}
+	if len(returned) != 0 {
+		t.Errorf("rolled back Send returned %d records, want 0", len(returned))
+	}
+}
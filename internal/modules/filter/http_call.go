@@ -20,6 +20,7 @@ import (
 	"github.com/cannectors/runtime/internal/errhandling"
 	"github.com/cannectors/runtime/internal/httpconfig"
 	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/ratelimit"
 	"github.com/cannectors/runtime/internal/template"
 	"github.com/cannectors/runtime/pkg/connector"
 )
@@ -100,6 +101,9 @@ type HTTPCallConfig struct {
 	// Data extraction configuration (from httpconfig.DataExtractionConfig)
 	DataField string `json:"dataField,omitempty"`
 
+	// RateLimit paces requests with the shared limiter of the target host (optional)
+	RateLimit *ratelimit.Config `json:"rateLimit,omitempty"`
+
 	// Key defines how to extract and use the key value (required for GET, optional for POST/PUT with template)
 	Key KeyConfig `json:"key"`
 	// Cache defines cache behavior (optional, uses defaults if not specified)
@@ -198,6 +202,7 @@ func newHTTPCallError(code, message string, recordIdx int, endpoint string, stat
 //   - timeoutMs: Request timeout in milliseconds
 //   - headers: Custom HTTP headers (supports {{record.field}} templates)
 //   - bodyTemplateFile: Path to external template file for POST/PUT requests
+//   - rateLimit: Client-side rate limit (requestsPerSecond, burst), shared per host
 func NewHTTPCallFromConfig(config HTTPCallConfig) (*HTTPCallModule, error) {
 	if config.Endpoint == "" {
 		return nil, newHTTPCallError(ErrCodeHTTPCallEndpointMissing, "http_call endpoint is required", -1, "", 0, "")
@@ -220,6 +225,9 @@ func NewHTTPCallFromConfig(config HTTPCallConfig) (*HTTPCallModule, error) {
 	timeout := httpconfig.GetTimeoutDuration(config.TimeoutMs, defaultHTTPCallTimeout)
 
 	httpClient := &http.Client{Timeout: timeout}
+	if config.RateLimit != nil {
+		ratelimit.WrapClient(httpClient, *config.RateLimit)
+	}
 	authHandler, err := buildHTTPCallAuth(config.Auth, httpClient)
 	if err != nil {
 		return nil, err
@@ -426,6 +434,13 @@ func ParseHTTPCallConfig(cfg map[string]interface{}, auth *connector.AuthConfig)
 	// Parse body template file (optional, for POST/PUT)
 	config.BodyTemplateFile = parseStringField(cfg, "bodyTemplateFile")
 
+	// Parse rate limit configuration (optional)
+	rateLimit, err := httpconfig.ExtractRateLimitConfig(cfg)
+	if err != nil {
+		return HTTPCallConfig{}, err
+	}
+	config.RateLimit = rateLimit
+
 	return config, nil
 }
 
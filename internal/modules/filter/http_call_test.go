@@ -211,6 +211,26 @@ func TestParseHTTPCallConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("parses rate limit config", func(t *testing.T) {
+		raw := map[string]interface{}{
+			"endpoint":  "https://api.example.com/customers/{id}",
+			"rateLimit": map[string]interface{}{"requestsPerSecond": float64(5), "burst": float64(2)},
+		}
+
+		config, err := ParseHTTPCallConfig(raw, nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config.RateLimit == nil || config.RateLimit.RequestsPerSecond != 5 || config.RateLimit.Burst != 2 {
+			t.Errorf("unexpected rateLimit: %+v", config.RateLimit)
+		}
+
+		raw["rateLimit"] = map[string]interface{}{"burst": float64(2)}
+		if _, err := ParseHTTPCallConfig(raw, nil); err == nil {
+			t.Error("expected error for rateLimit without requestsPerSecond")
+		}
+	})
+
 	t.Run("parses auth config from separate parameter", func(t *testing.T) {
 		raw := map[string]interface{}{
 			"endpoint": "https://api.example.com/customers",
@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/cannectors/runtime/internal/cache"
@@ -56,6 +55,10 @@ type SQLCallConfig struct {
 	QueryFile string   `json:"queryFile"` // Path to SQL file with {{record.field}} templates
 	Queries   []string `json:"queries"`   // Multiple queries (executed sequentially)
 
+	// NamedParameters also binds :field and @field references to record
+	// fields; a field missing from the record is then an error
+	NamedParameters bool `json:"namedParameters"`
+
 	// Result handling
 	MergeStrategy string `json:"mergeStrategy"` // "merge", "replace", "append"
 	DataField     string `json:"dataField"`     // Field to extract from result
@@ -91,6 +94,7 @@ type SQLCallModule struct {
 	driver            string
 	query             string
 	queries           []string
+	namedParameters   bool
 	mergeStrategy     string
 	dataField         string
 	resultKey         string
@@ -130,6 +134,7 @@ func NewSQLCallFromConfig(config SQLCallConfig) (*SQLCallModule, error) {
 		driver:            driver,
 		query:             config.Query,
 		queries:           queries,
+		namedParameters:   config.NamedParameters,
 		mergeStrategy:     mergeStrategy,
 		dataField:         config.DataField,
 		resultKey:         config.ResultKey,
@@ -307,6 +312,9 @@ func parseQuerySettings(config *SQLCallConfig, cfg map[string]interface{}) {
 			}
 		}
 	}
+	if v, ok := cfg["namedParameters"].(bool); ok {
+		config.NamedParameters = v
+	}
 }
 
 // parseResultHandlingSettings extracts result handling settings from config.
@@ -552,66 +560,11 @@ func (m *SQLCallModule) executeQuery(ctx context.Context, queryTemplate string,
 }
 
 // buildParameterizedQuery builds a parameterized query from a template.
-// Replaces {{record.field}} with positional parameters and returns args.
-// Validates that all template placeholders are replaced to prevent SQL injection.
+// Replaces {{record.field}} references (and :field and @field ones with
+// namedParameters) outside string literals and comments with the driver's
+// placeholders and returns args.
 func (m *SQLCallModule) buildParameterizedQuery(queryTemplate string, record map[string]interface{}) (string, []interface{}, error) {
-	query := queryTemplate
-	var args []interface{}
-
-	// Find and replace all {{record.field}} patterns
-	paramIndex := 1
-	for {
-		start := strings.Index(query, "{{")
-		if start == -1 {
-			break
-		}
-		end := strings.Index(query[start:], "}}")
-		if end == -1 {
-			// Unmatched opening brace - potential SQL injection risk
-			return "", nil, fmt.Errorf("unmatched template placeholder in query: missing closing }}")
-		}
-		end += start + 2
-
-		// Extract field path
-		placeholder := query[start:end]
-		fieldPath := strings.TrimSpace(placeholder[2 : len(placeholder)-2])
-
-		// Remove "record." prefix if present
-		fieldPath = strings.TrimPrefix(fieldPath, RecordFieldPrefix)
-
-		// Get value from record
-		value := getSQLNestedValue(record, fieldPath)
-
-		// Replace with positional parameter
-		paramPlaceholder := database.FormatPlaceholder(m.driver, paramIndex)
-		query = query[:start] + paramPlaceholder + query[end:]
-		args = append(args, value)
-		paramIndex++
-	}
-
-	// Validate no unmatched braces remain (security check)
-	if strings.Contains(query, "{{") || strings.Contains(query, "}}") {
-		return "", nil, fmt.Errorf("unmatched template placeholders remain in query after processing")
-	}
-
-	return query, args, nil
-}
-
-// getSQLNestedValue extracts a value from a nested map using dot notation.
-// Uses a different name to avoid conflict with the filter package's getNestedValue.
-func getSQLNestedValue(record map[string]interface{}, path string) interface{} {
-	parts := strings.Split(path, ".")
-	current := interface{}(record)
-
-	for _, part := range parts {
-		if m, ok := current.(map[string]interface{}); ok {
-			current = m[part]
-		} else {
-			return nil
-		}
-	}
-
-	return current
+	return database.Bind(queryTemplate, m.driver, nil, database.RecordResolver(record, m.namedParameters))
 }
 
 // rowsToRecords converts sql.Rows to a slice of maps.
@@ -1,7 +1,10 @@
 package filter
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/cannectors/runtime/internal/database"
 )
 
 func TestParseSQLCallConfig(t *testing.T) {
@@ -259,7 +262,7 @@ func TestNewSQLCallFromConfig_Validation(t *testing.T) {
 	}
 }
 
-func TestGetSQLNestedValue(t *testing.T) {
+func TestSQLCallRecordParameters(t *testing.T) {
 	t.Parallel()
 
 	record := map[string]interface{}{
@@ -312,9 +315,31 @@ func TestGetSQLNestedValue(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getSQLNestedValue(record, tt.path)
-			if got != tt.want {
-				t.Errorf("getSQLNestedValue(%q) = %v, want %v", tt.path, got, tt.want)
+			m := &SQLCallModule{driver: "postgres"}
+			query, args, err := m.buildParameterizedQuery("SELECT {{record."+tt.path+"}}, :"+tt.path, record)
+			if err != nil {
+				t.Fatalf("buildParameterizedQuery() error = %v", err)
+			}
+			if query != "SELECT $1, :"+tt.path || len(args) != 1 {
+				t.Fatalf("buildParameterizedQuery() = %q %v, want the template bound only", query, args)
+			}
+			if args[0] != tt.want {
+				t.Errorf("parameters of %q = %v, want %v", tt.path, args, tt.want)
+			}
+
+			m.namedParameters = true
+			query, args, err = m.buildParameterizedQuery("SELECT @"+tt.path+", :"+tt.path, record)
+			if tt.want == nil {
+				if !errors.Is(err, database.ErrUnresolvedParam) {
+					t.Errorf("buildParameterizedQuery() error = %v, want ErrUnresolvedParam", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildParameterizedQuery() error = %v", err)
+			}
+			if query != "SELECT $1, $2" || len(args) != 2 || args[0] != tt.want || args[1] != tt.want {
+				t.Errorf("buildParameterizedQuery() = %q %v with namedParameters, want %v", query, args, tt.want)
 			}
 		})
 	}
@@ -8,7 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strings"
+	"reflect"
 	"time"
 
 	"github.com/cannectors/runtime/internal/database"
@@ -27,7 +27,13 @@ const (
 // Template placeholder constants
 const (
 	// LastRunTimestampPlaceholder is the template placeholder for the last execution timestamp
-	LastRunTimestampPlaceholder = "{{lastRunTimestamp}}"
+	LastRunTimestampPlaceholder = "{{" + lastRunTimestampParam + "}}"
+	// WindowEndPlaceholder is the template placeholder for the end of the
+	// time window: the window end in backfill executions, the current time otherwise
+	WindowEndPlaceholder = "{{" + windowEndParam + "}}"
+
+	lastRunTimestampParam = "lastRunTimestamp"
+	windowEndParam        = "windowEnd"
 )
 
 // Error types for database input module
@@ -35,6 +41,7 @@ var (
 	ErrDatabaseNilConfig      = errors.New("database input configuration is nil")
 	ErrDatabaseMissingQuery   = errors.New("query is required for database input")
 	ErrDatabaseMissingConnStr = errors.New("connection string is required for database input")
+	ErrDatabaseWindowParams   = errors.New("time window requires timestamp placeholders for both bounds")
 )
 
 // DatabaseInputConfig holds configuration for the database input module.
@@ -55,6 +62,14 @@ type DatabaseInputConfig struct {
 	// Incremental query configuration
 	Incremental *IncrementalConfig `json:"incremental"`
 
+	// Streaming configuration
+	Streaming *DatabaseStreamingConfig `json:"streaming"`
+
+	// Transaction configuration: the queries of an execution run in one
+	// transaction with this isolation level and read-only mode, if set
+	IsolationLevel string `json:"isolationLevel"`
+	ReadOnly       bool   `json:"readOnly"`
+
 	// Pool configuration
 	MaxOpenConns    int `json:"maxOpenConns"`
 	MaxIdleConns    int `json:"maxIdleConns"`
@@ -77,6 +92,9 @@ type DatabasePaginationConfig struct {
 	CursorField string `json:"cursorField"`
 	// CursorParam: parameter name for cursor value
 	CursorParam string `json:"cursorParam"`
+	// CursorFields: columns of a composite keyset cursor, in ORDER BY order
+	// (e.g. ["updated_at", "id"]). The query must contain {{cursorPredicate}}.
+	CursorFields []string `json:"cursorFields"`
 }
 
 // IncrementalConfig defines incremental query configuration.
@@ -87,10 +105,18 @@ type IncrementalConfig struct {
 	TimestampField string `json:"timestampField"`
 	// TimestampParam: parameter name for timestamp in query
 	TimestampParam string `json:"timestampParam"`
+	// Watermark: persist the maximum TimestampField value of the rows read
+	// instead of the execution start time
+	Watermark bool `json:"watermark"`
+	// LookbackSeconds: overlap subtracted from the persisted timestamp on the next run
+	LookbackSeconds int `json:"lookbackSeconds"`
 	// IDField: field name for ID-based incremental queries
 	IDField string `json:"idField"`
 	// IDParam: parameter name for ID in query
 	IDParam string `json:"idParam"`
+	// CursorFields: columns of a composite keyset cursor persisted between
+	// executions. The query must contain {{cursorPredicate}}.
+	CursorFields []string `json:"cursorFields"`
 }
 
 // DatabaseInput implements a database input module.
@@ -101,8 +127,18 @@ type DatabaseInput struct {
 	driver     string
 	timeout    time.Duration
 	pipelineID string
-	stateStore *persistence.StateStore
+	stateStore persistence.StateStore
 	lastState  *persistence.State
+	keyset     *keyset
+	lastCursor []interface{}
+	txOptions  *sql.TxOptions // options of the read transaction, nil to read without one
+
+	// Backfill: time window read instead of resuming from the persisted state
+	window *persistence.Window
+
+	// Streaming checkpoints
+	resume         *persistence.Checkpoint // checkpoint the next stream resumes from
+	streamPosition int                     // rows delivered from the start of the result set
 }
 
 // NewDatabaseInputFromConfig creates a new database input module from configuration.
@@ -132,6 +168,17 @@ func NewDatabaseInputFromConfig(cfg *connector.ModuleConfig) (*DatabaseInput, er
 	if config.ConnectionString == "" && config.ConnectionStringRef == "" {
 		return nil, ErrDatabaseMissingConnStr
 	}
+	if config.Streaming != nil && config.Streaming.Enabled && config.Pagination != nil {
+		return nil, ErrDatabaseStreamingPagination
+	}
+	keyset, err := newDatabaseKeyset(config)
+	if err != nil {
+		return nil, err
+	}
+	txOptions, err := database.TxOptions(config.IsolationLevel, config.ReadOnly)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set timeout
 	timeout := defaultDatabaseTimeout
@@ -157,16 +204,22 @@ func NewDatabaseInputFromConfig(cfg *connector.ModuleConfig) (*DatabaseInput, er
 		return nil, fmt.Errorf("creating database connection: %w", err)
 	}
 
+	if keyset != nil {
+		keyset.driver = driver
+	}
+
 	module := &DatabaseInput{
-		config:  config,
-		db:      db,
-		driver:  driver,
-		timeout: timeout,
+		config:    config,
+		db:        db,
+		driver:    driver,
+		timeout:   timeout,
+		keyset:    keyset,
+		txOptions: txOptions,
 	}
 
 	// Initialize state store if incremental is enabled
 	if config.Incremental != nil && config.Incremental.Enabled {
-		module.stateStore = persistence.NewStateStore(persistence.DefaultStatePath)
+		module.stateStore = persistence.NewFileStateStore(persistence.DefaultStatePath)
 	}
 
 	logger.Debug("database input module created",
@@ -174,6 +227,9 @@ func NewDatabaseInputFromConfig(cfg *connector.ModuleConfig) (*DatabaseInput, er
 		"timeout", timeout.String(),
 		"has_pagination", config.Pagination != nil,
 		"has_incremental", config.Incremental != nil && config.Incremental.Enabled,
+		"streaming", module.Streaming(),
+		"isolation_level", config.IsolationLevel,
+		"read_only", config.ReadOnly,
 	)
 
 	return module, nil
@@ -205,6 +261,14 @@ func parseDatabaseInputConfig(cfg map[string]interface{}) DatabaseInputConfig {
 		config.Parameters = v
 	}
 
+	// Transaction settings
+	if v, ok := cfg["isolationLevel"].(string); ok {
+		config.IsolationLevel = v
+	}
+	if v, ok := cfg["readOnly"].(bool); ok {
+		config.ReadOnly = v
+	}
+
 	// Pool settings
 	if v, ok := cfg["maxOpenConns"].(float64); ok {
 		config.MaxOpenConns = int(v)
@@ -232,6 +296,11 @@ func parseDatabaseInputConfig(cfg map[string]interface{}) DatabaseInputConfig {
 		config.Incremental = parseIncrementalConfig(incrementalRaw)
 	}
 
+	// Parse streaming
+	if streamingRaw, ok := cfg["streaming"].(map[string]interface{}); ok {
+		config.Streaming = parseDatabaseStreamingConfig(streamingRaw)
+	}
+
 	return config
 }
 
@@ -257,6 +326,7 @@ func parseDatabasePaginationConfig(cfg map[string]interface{}) *DatabasePaginati
 	if v, ok := cfg["cursorParam"].(string); ok {
 		config.CursorParam = v
 	}
+	config.CursorFields = parseCursorFields(cfg["cursorFields"])
 
 	return config
 }
@@ -274,33 +344,42 @@ func parseIncrementalConfig(cfg map[string]interface{}) *IncrementalConfig {
 	if v, ok := cfg["timestampParam"].(string); ok {
 		config.TimestampParam = v
 	}
+	if v, ok := cfg["watermark"].(bool); ok {
+		config.Watermark = v
+	}
+	if v, ok := cfg["lookbackSeconds"].(float64); ok && v > 0 {
+		config.LookbackSeconds = int(v)
+	}
 	if v, ok := cfg["idField"].(string); ok {
 		config.IDField = v
 	}
 	if v, ok := cfg["idParam"].(string); ok {
 		config.IDParam = v
 	}
+	config.CursorFields = parseCursorFields(cfg["cursorFields"])
 
 	return config
 }
 
 // Fetch retrieves data from the database.
+// With streaming enabled, the streamed chunks are collected into a single slice.
 func (d *DatabaseInput) Fetch(ctx context.Context) ([]map[string]interface{}, error) {
-	startTime := time.Now()
-
-	// Load state if state store is initialized (for incremental queries)
-	if d.stateStore != nil && d.pipelineID != "" {
-		state, err := d.LoadState()
+	if d.Streaming() {
+		var records []map[string]interface{}
+		err := d.Stream(ctx, func(_ context.Context, chunk []map[string]interface{}) error {
+			records = append(records, chunk...)
+			return nil
+		})
 		if err != nil {
-			logger.Warn("failed to load state for database input, continuing without incremental support",
-				"pipeline_id", d.pipelineID,
-				"error", err.Error(),
-			)
-		} else if state != nil {
-			d.lastState = state
+			return nil, err
 		}
+		return records, nil
 	}
 
+	startTime := time.Now()
+	d.loadIncrementalState()
+	d.lastCursor = nil
+
 	logger.Info("database input fetch started",
 		"module_type", "database",
 		"driver", d.driver,
@@ -309,16 +388,25 @@ func (d *DatabaseInput) Fetch(ctx context.Context) ([]map[string]interface{}, er
 	)
 
 	// Build query with parameters
-	query, args := d.buildQuery()
+	query, args, err := d.buildQuery()
+	if err != nil {
+		return nil, fmt.Errorf("building query: %w", err)
+	}
+	if d.keyset != nil && !d.keysetPaginated() {
+		query, args = d.keyset.apply(query, args, d.initialCursor())
+	}
 
 	// Execute query based on pagination configuration
 	var records []map[string]interface{}
-	var err error
 
-	if d.config.Pagination != nil && d.config.Pagination.Type != "" {
-		records, err = d.fetchWithPagination(ctx, query, args)
-	} else {
-		records, err = d.fetchSingle(ctx, query, args)
+	q, end, err := d.beginRead(ctx)
+	if err == nil {
+		if d.config.Pagination != nil && d.config.Pagination.Type != "" {
+			records, err = d.fetchWithPagination(ctx, q, query, args)
+		} else {
+			records, err = d.fetchSingle(ctx, q, query, args)
+		}
+		err = end(err)
 	}
 
 	duration := time.Since(startTime)
@@ -341,159 +429,109 @@ func (d *DatabaseInput) Fetch(ctx context.Context) ([]map[string]interface{}, er
 	return records, nil
 }
 
-// buildQuery builds the SQL query with parameters.
-func (d *DatabaseInput) buildQuery() (string, []interface{}) {
-	query := d.config.Query
-	var args []interface{}
-
-	// Replace {{lastRunTimestamp}} placeholder
-	query, args = d.replaceLastRunTimestamp(query, args)
-
-	// Add incremental parameters (legacy support)
-	query, args = d.replaceIncrementalParameters(query, args)
-
-	// Add static parameters
-	query, args = d.replaceStaticParameters(query, args)
-
-	return query, args
-}
-
-// replaceLastRunTimestamp replaces {{lastRunTimestamp}} placeholder with parameterized value.
-func (d *DatabaseInput) replaceLastRunTimestamp(query string, args []interface{}) (string, []interface{}) {
-	if !strings.Contains(query, LastRunTimestampPlaceholder) {
-		return query, args
+// loadIncrementalState loads the persisted state if the state store is
+// initialized (for incremental queries). Failures are logged and ignored.
+func (d *DatabaseInput) loadIncrementalState() {
+	if d.stateStore == nil || d.pipelineID == "" {
+		return
 	}
-
-	var timestamp time.Time
-	if d.lastState != nil && d.lastState.LastTimestamp != nil {
-		timestamp = *d.lastState.LastTimestamp
-	} else {
-		// First run: use epoch time (1970-01-01) to get all records
-		timestamp = time.Unix(0, 0)
+	state, err := d.LoadState()
+	if err != nil {
+		logger.Warn("failed to load state for database input, continuing without incremental support",
+			"pipeline_id", d.pipelineID,
+			"error", err.Error(),
+		)
+	} else if state != nil {
+		d.lastState = state
 	}
-
-	placeholder := database.FormatPlaceholder(d.driver, len(args)+1)
-	query = strings.ReplaceAll(query, LastRunTimestampPlaceholder, placeholder)
-	args = append(args, timestamp)
-
-	return query, args
 }
 
-// replaceIncrementalParameters replaces incremental parameter placeholders (legacy support).
-func (d *DatabaseInput) replaceIncrementalParameters(query string, args []interface{}) (string, []interface{}) {
-	if d.config.Incremental == nil || !d.config.Incremental.Enabled || d.lastState == nil {
-		return query, args
-	}
-
-	// Replace timestamp parameter if configured
-	if d.config.Incremental.TimestampParam != "" && d.lastState.LastTimestamp != nil {
-		placeholder := ":" + d.config.Incremental.TimestampParam
-		paramPlaceholder := database.FormatPlaceholder(d.driver, len(args)+1)
-		query = strings.ReplaceAll(query, placeholder, paramPlaceholder)
-		args = append(args, *d.lastState.LastTimestamp)
-	}
-
-	// Replace ID parameter if configured
-	if d.config.Incremental.IDParam != "" && d.lastState.LastID != nil {
-		placeholder := ":" + d.config.Incremental.IDParam
-		paramPlaceholder := database.FormatPlaceholder(d.driver, len(args)+1)
-		query = strings.ReplaceAll(query, placeholder, paramPlaceholder)
-		args = append(args, *d.lastState.LastID)
-	}
-
-	return query, args
+// buildQuery builds the SQL query with parameters, bound by resolveParameter.
+func (d *DatabaseInput) buildQuery() (string, []interface{}, error) {
+	return database.Bind(d.config.Query, d.driver, nil, d.resolveParameter)
 }
 
-// replaceStaticParameters replaces static parameter placeholders from config.Parameters.
-// For Postgres ($n): reuses same placeholder for all occurrences (append arg once).
-// For MySQL/SQLite (?): replaces each occurrence sequentially (append arg each time).
-func (d *DatabaseInput) replaceStaticParameters(query string, args []interface{}) (string, []interface{}) {
-	paramOrder := extractParameterOrder(query)
-	for _, paramName := range paramOrder {
-		value, exists := d.config.Parameters[paramName]
-		if !exists {
-			continue
+// resolveParameter resolves the parameter references of the query, each one
+// as :name, @name or {{name}}:
+//   - lastRunTimestamp and windowEnd
+//   - the incremental timestampParam and idParam (legacy support), once there is state
+//   - the static parameters from config.Parameters
+//
+// Other references, such as pagination parameters and {{cursorPredicate}},
+// are left for the later stages.
+func (d *DatabaseInput) resolveParameter(p database.Param) (interface{}, bool, error) {
+	switch p.Name {
+	case lastRunTimestampParam:
+		return d.lastRunTimestamp(), true, nil
+	case windowEndParam:
+		return d.windowEnd(), true, nil
+	}
+	if inc := d.config.Incremental; inc != nil && inc.Enabled {
+		if since := d.timestampSince(); inc.TimestampParam != "" && p.Name == inc.TimestampParam && since != nil {
+			return *since, true, nil
+		}
+		if inc.IDParam != "" && p.Name == inc.IDParam && d.lastState != nil && d.lastState.LastID != nil {
+			return *d.lastState.LastID, true, nil
 		}
-
-		namedPlaceholder := ":" + paramName
-		query, args = d.replaceNamedParameter(query, args, namedPlaceholder, value)
 	}
 
-	return query, args
+	value, ok := d.config.Parameters[p.Name]
+	return value, ok, nil
 }
 
-// replaceNamedParameter replaces a named parameter placeholder with a positional placeholder.
-// Handles driver-specific behavior:
-//   - Postgres: replaces all occurrences with same $n placeholder (reuses $1)
-//   - MySQL/SQLite: replaces each occurrence sequentially with separate ? placeholders
-func (d *DatabaseInput) replaceNamedParameter(query string, args []interface{}, namedPlaceholder string, value interface{}) (string, []interface{}) {
-	if !strings.Contains(query, namedPlaceholder) {
-		return query, args
+// lastRunTimestamp returns the value of {{lastRunTimestamp}}: the lower
+// timestamp bound, or the epoch on the first run to get all records.
+func (d *DatabaseInput) lastRunTimestamp() time.Time {
+	if since := d.timestampSince(); since != nil {
+		return *since
 	}
-
-	if d.driver == "postgres" {
-		// Postgres: replace all occurrences with same placeholder, append arg once
-		paramPlaceholder := database.FormatPlaceholder(d.driver, len(args)+1)
-		query = strings.ReplaceAll(query, namedPlaceholder, paramPlaceholder)
-		args = append(args, value)
-	} else {
-		// MySQL/SQLite: replace each occurrence sequentially, append arg each time
-		for strings.Contains(query, namedPlaceholder) {
-			paramPlaceholder := database.FormatPlaceholder(d.driver, len(args)+1)
-			query = strings.Replace(query, namedPlaceholder, paramPlaceholder, 1)
-			args = append(args, value)
-		}
-	}
-
-	return query, args
+	return time.Unix(0, 0)
 }
 
-// extractParameterOrder extracts parameter names from query in left-to-right order.
-// This ensures args slice matches placeholder order for MySQL/SQLite compatibility.
-func extractParameterOrder(query string) []string {
-	var order []string
-	seen := make(map[string]bool)
+// bindNamedParameter binds the :name, @name and {{name}} references of a
+// parameter resolved after buildQuery, such as pagination parameters.
+func (d *DatabaseInput) bindNamedParameter(query string, args []interface{}, name string, value interface{}) (string, []interface{}, error) {
+	return database.Bind(query, d.driver, args, func(p database.Param) (interface{}, bool, error) {
+		return value, p.Name == name, nil
+	})
+}
 
-	// Find all :paramName patterns in order
-	start := 0
-	for {
-		idx := strings.Index(query[start:], ":")
-		if idx == -1 {
-			break
-		}
-		idx += start
+// sqlQueryer runs queries on a database or within a transaction.
+type sqlQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
 
-		// Find end of parameter name (alphanumeric + underscore)
-		end := idx + 1
-		for end < len(query) && (isAlphanumeric(query[end]) || query[end] == '_') {
-			end++
+// beginRead returns where the queries of an execution run: a transaction
+// with the configured isolation level and read-only mode, so that under
+// repeatable_read or serializable all pages read the same snapshot, or the
+// database without one. end commits the transaction, or rolls it back if
+// err is set, and returns err or the commit error.
+func (d *DatabaseInput) beginRead(ctx context.Context) (q sqlQueryer, end func(err error) error, err error) {
+	if d.txOptions == nil {
+		return d.db, func(err error) error { return err }, nil
+	}
+	tx, err := d.db.BeginTx(ctx, d.txOptions)
+	if err != nil {
+		return nil, nil, database.ClassifyDatabaseError(err, d.driver, "begin", "", 0)
+	}
+	return tx, func(err error) error {
+		if err != nil {
+			_ = tx.Rollback()
+			return err
 		}
-
-		if end > idx+1 {
-			paramName := query[idx+1 : end]
-			if !seen[paramName] {
-				order = append(order, paramName)
-				seen[paramName] = true
-			}
+		if err := tx.Commit(); err != nil {
+			return database.ClassifyDatabaseError(err, d.driver, "commit", "", 0)
 		}
-
-		start = end
-	}
-
-	return order
-}
-
-// isAlphanumeric checks if a byte is alphanumeric.
-func isAlphanumeric(b byte) bool {
-	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+		return nil
+	}, nil
 }
 
 // fetchSingle executes a single query without pagination.
-func (d *DatabaseInput) fetchSingle(ctx context.Context, query string, args []interface{}) ([]map[string]interface{}, error) {
+func (d *DatabaseInput) fetchSingle(ctx context.Context, q sqlQueryer, query string, args []interface{}) ([]map[string]interface{}, error) {
 	ctx, cancel := context.WithTimeout(ctx, d.timeout)
 	defer cancel()
 
-	rows, err := d.db.QueryContext(ctx, query, args...)
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		dbErr := database.ClassifyDatabaseError(err, d.driver, "select", query, len(args))
 		return nil, dbErr
@@ -506,19 +544,22 @@ func (d *DatabaseInput) fetchSingle(ctx context.Context, query string, args []in
 }
 
 // fetchWithPagination executes queries with pagination.
-func (d *DatabaseInput) fetchWithPagination(ctx context.Context, query string, args []interface{}) ([]map[string]interface{}, error) {
+func (d *DatabaseInput) fetchWithPagination(ctx context.Context, q sqlQueryer, query string, args []interface{}) ([]map[string]interface{}, error) {
 	switch d.config.Pagination.Type {
 	case "limit-offset":
-		return d.fetchLimitOffset(ctx, query, args)
+		return d.fetchLimitOffset(ctx, q, query, args)
 	case "cursor":
-		return d.fetchCursor(ctx, query, args)
+		if d.keysetPaginated() {
+			return d.fetchKeyset(ctx, q, query, args)
+		}
+		return d.fetchCursor(ctx, q, query, args)
 	default:
-		return d.fetchSingle(ctx, query, args)
+		return d.fetchSingle(ctx, q, query, args)
 	}
 }
 
 // fetchLimitOffset implements LIMIT/OFFSET pagination.
-func (d *DatabaseInput) fetchLimitOffset(ctx context.Context, query string, args []interface{}) ([]map[string]interface{}, error) {
+func (d *DatabaseInput) fetchLimitOffset(ctx context.Context, q sqlQueryer, query string, args []interface{}) ([]map[string]interface{}, error) {
 	var allRecords []map[string]interface{}
 	offset := 0
 	limit := d.config.Pagination.Limit
@@ -528,17 +569,19 @@ func (d *DatabaseInput) fetchLimitOffset(ctx context.Context, query string, args
 
 	// Check if query uses offsetParam placeholder
 	offsetParam := d.config.Pagination.OffsetParam
-	usesOffsetParam := offsetParam != "" && strings.Contains(query, ":"+offsetParam)
+	usesOffsetParam := offsetParam != "" && database.HasParam(query, d.driver, offsetParam)
 
 	for {
 		var paginatedQuery string
 		var paginatedArgs []interface{}
 
 		if usesOffsetParam {
-			// Replace :offsetParam placeholder with parameterized value
-			paginatedQuery = strings.ReplaceAll(query, ":"+offsetParam,
-				database.FormatPlaceholder(d.driver, len(args)+1))
-			paginatedArgs = append(args, offset)
+			// Bind :offsetParam to the offset
+			var err error
+			paginatedQuery, paginatedArgs, err = d.bindNamedParameter(query, args, offsetParam, offset)
+			if err != nil {
+				return nil, err
+			}
 			paginatedQuery = fmt.Sprintf("%s LIMIT %d", paginatedQuery, limit)
 		} else {
 			// Use literal LIMIT/OFFSET syntax
@@ -547,7 +590,7 @@ func (d *DatabaseInput) fetchLimitOffset(ctx context.Context, query string, args
 		}
 
 		queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
-		rows, err := d.db.QueryContext(queryCtx, paginatedQuery, paginatedArgs...)
+		rows, err := q.QueryContext(queryCtx, paginatedQuery, paginatedArgs...)
 		if err != nil {
 			cancel()
 			dbErr := database.ClassifyDatabaseError(err, d.driver, "select", paginatedQuery, len(paginatedArgs))
@@ -575,7 +618,7 @@ func (d *DatabaseInput) fetchLimitOffset(ctx context.Context, query string, args
 }
 
 // fetchCursor implements cursor-based pagination.
-func (d *DatabaseInput) fetchCursor(ctx context.Context, query string, args []interface{}) ([]map[string]interface{}, error) {
+func (d *DatabaseInput) fetchCursor(ctx context.Context, q sqlQueryer, query string, args []interface{}) ([]map[string]interface{}, error) {
 	var allRecords []map[string]interface{}
 	var cursor interface{}
 	limit := d.config.Pagination.Limit
@@ -587,24 +630,19 @@ func (d *DatabaseInput) fetchCursor(ctx context.Context, query string, args []in
 	cursorParam := d.config.Pagination.CursorParam
 
 	for {
-		cursorQuery := query
-		cursorArgs := make([]interface{}, len(args))
-		copy(cursorArgs, args)
-
+		cursorQuery, cursorArgs := query, args
 		if cursorParam != "" {
-			placeholder := ":" + cursorParam
-			if strings.Contains(cursorQuery, placeholder) {
-				cursorQuery = strings.ReplaceAll(cursorQuery, placeholder,
-					database.FormatPlaceholder(d.driver, len(cursorArgs)+1))
-				// Always append the current cursor value, which may be nil on the first page.
-				cursorArgs = append(cursorArgs, cursor)
+			// Always bind the current cursor value, which may be nil on the first page.
+			var err error
+			if cursorQuery, cursorArgs, err = d.bindNamedParameter(query, args, cursorParam, cursor); err != nil {
+				return nil, err
 			}
 		}
 
 		cursorQuery = fmt.Sprintf("%s LIMIT %d", cursorQuery, limit)
 
 		queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
-		rows, err := d.db.QueryContext(queryCtx, cursorQuery, cursorArgs...)
+		rows, err := q.QueryContext(queryCtx, cursorQuery, cursorArgs...)
 		if err != nil {
 			cancel()
 			dbErr := database.ClassifyDatabaseError(err, d.driver, "select", cursorQuery, len(cursorArgs))
@@ -636,32 +674,70 @@ func (d *DatabaseInput) fetchCursor(ctx context.Context, query string, args []in
 	return allRecords, nil
 }
 
+// fetchKeyset implements keyset pagination over pagination.cursorFields.
+// Each page selects the rows after the cursor of the last row of the
+// previous page; the first page starts after the persisted cursor, if any.
+func (d *DatabaseInput) fetchKeyset(ctx context.Context, q sqlQueryer, query string, args []interface{}) ([]map[string]interface{}, error) {
+	var allRecords []map[string]interface{}
+	cursor := d.initialCursor()
+	limit := d.config.Pagination.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+
+	for {
+		pageQuery, pageArgs := d.keyset.apply(query, args, cursor)
+		pageQuery = fmt.Sprintf("%s LIMIT %d", database.TrimStatement(pageQuery, d.driver), limit)
+
+		queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		rows, err := q.QueryContext(queryCtx, pageQuery, pageArgs...)
+		if err != nil {
+			cancel()
+			return nil, database.ClassifyDatabaseError(err, d.driver, "select", pageQuery, len(pageArgs))
+		}
+
+		records, err := d.rowsToRecords(rows)
+		_ = rows.Close()
+		cancel()
+
+		if err != nil {
+			return nil, err
+		}
+
+		allRecords = append(allRecords, records...)
+
+		if len(records) < limit {
+			break
+		}
+		// A page ending at the cursor it started after would be read again
+		// and again: the predicate does not select the rows after it
+		if cursor != nil && reflect.DeepEqual(d.lastCursor, cursor) {
+			return nil, fmt.Errorf("%w: cursor %v did not advance, check that ORDER BY matches cursorFields", ErrDatabaseInvalidCursor, d.keyset.toMap(cursor))
+		}
+		cursor = d.lastCursor
+	}
+
+	return allRecords, nil
+}
+
 // rowsToRecords converts sql.Rows to a slice of map records.
 func (d *DatabaseInput) rowsToRecords(rows *sql.Rows) ([]map[string]interface{}, error) {
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, fmt.Errorf("getting column names: %w", err)
 	}
+	positions, err := d.cursorPositions(columns)
+	if err != nil {
+		return nil, err
+	}
 
 	var records []map[string]interface{}
 
 	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, fmt.Errorf("scanning row: %w", err)
-		}
-
-		record := make(map[string]interface{}, len(columns))
-		for i, col := range columns {
-			val := values[i]
-			record[col] = convertDatabaseValue(val)
+		record, err := d.scanRow(rows, columns, positions)
+		if err != nil {
+			return nil, err
 		}
-
 		records = append(records, record)
 	}
 
@@ -672,6 +748,54 @@ func (d *DatabaseInput) rowsToRecords(rows *sql.Rows) ([]map[string]interface{},
 	return records, nil
 }
 
+// cursorPositions returns the position of the cursor fields in the result
+// columns, or nil if no cursor fields are configured.
+func (d *DatabaseInput) cursorPositions(columns []string) (*cursorColumns, error) {
+	if d.keyset == nil {
+		return nil, nil
+	}
+	return d.keyset.positions(columns)
+}
+
+// scanRow scans the current row into a map record keyed by column name and,
+// when cursor fields are configured, keeps its cursor as the last cursor.
+func (d *DatabaseInput) scanRow(rows *sql.Rows, columns []string, positions *cursorColumns) (map[string]interface{}, error) {
+	values, err := scanValues(rows, len(columns))
+	if err != nil {
+		return nil, err
+	}
+	if positions != nil {
+		cursor, err := d.keyset.cursorOf(values, positions)
+		if err != nil {
+			return nil, err
+		}
+		d.lastCursor = cursor
+	}
+
+	record := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		if positions != nil && positions.added(i) {
+			continue
+		}
+		record[col] = convertDatabaseValue(values[i])
+	}
+	return record, nil
+}
+
+// scanValues scans the raw values of the current row.
+func scanValues(rows *sql.Rows, n int) ([]interface{}, error) {
+	values := make([]interface{}, n)
+	valuePtrs := make([]interface{}, n)
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, fmt.Errorf("scanning row: %w", err)
+	}
+	return values, nil
+}
+
 // convertDatabaseValue converts database values to appropriate Go types.
 func convertDatabaseValue(val interface{}) interface{} {
 	if val == nil {
@@ -721,21 +845,37 @@ func (d *DatabaseInput) LoadState() (*persistence.State, error) {
 	return state, nil
 }
 
+// SetStateStore sets the state store to use for persistence.
+func (d *DatabaseInput) SetStateStore(store persistence.StateStore) {
+	d.stateStore = store
+}
+
 // GetPersistenceConfig returns the state persistence configuration.
 func (d *DatabaseInput) GetPersistenceConfig() *persistence.StatePersistenceConfig {
 	if d.config.Incremental == nil || !d.config.Incremental.Enabled {
 		return nil
 	}
 
-	return &persistence.StatePersistenceConfig{
+	config := &persistence.StatePersistenceConfig{
 		Timestamp: &persistence.TimestampConfig{
-			Enabled: d.config.Incremental.TimestampField != "",
+			Enabled:         d.config.Incremental.TimestampField != "",
+			LookbackSeconds: d.config.Incremental.LookbackSeconds,
 		},
 		ID: &persistence.IDConfig{
 			Enabled: d.config.Incremental.IDField != "",
 			Field:   d.config.Incremental.IDField,
 		},
 	}
+	if d.config.Incremental.Watermark {
+		config.Timestamp.Field = d.config.Incremental.TimestampField
+	}
+	if len(d.config.Incremental.CursorFields) > 0 && d.keyset != nil {
+		config.Cursor = &persistence.CursorConfig{
+			Enabled: true,
+			Fields:  d.keyset.fields(),
+		}
+	}
+	return config
 }
 
 // GetLastState returns the last loaded state.
@@ -0,0 +1,407 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cannectors/runtime/internal/database"
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// CursorPredicatePlaceholder is the template placeholder replaced by the
+// keyset predicate selecting the rows after the current cursor. It is
+// replaced by (1=1) when there is no cursor yet.
+const CursorPredicatePlaceholder = "{{cursorPredicate}}"
+
+// cursorTextColumn prefixes the result columns holding the stored text of
+// the cursor columns, added to SQLite queries (see keyset.textQuery).
+const cursorTextColumn = "_cannectors_cursor_"
+
+// ErrDatabaseInvalidCursor is returned for invalid keyset cursor configuration or values.
+var ErrDatabaseInvalidCursor = errors.New("invalid database cursor")
+
+// cursorColumnPattern matches cursor column expressions: an optionally
+// qualified and quoted column name.
+var cursorColumnPattern = regexp.MustCompile("^[A-Za-z_\"`][A-Za-z0-9_.\"`]*$")
+
+// keysetColumn is one column of a composite keyset cursor.
+type keysetColumn struct {
+	// Column is the SQL column expression, as written in ORDER BY
+	Column string
+	// Field is the result column (record field) holding the column value
+	Field string
+	// Desc is true when the column is sorted in descending order
+	Desc bool
+}
+
+// keyset generates the predicates for keyset pagination over one or more
+// columns. Rows are expected to be ordered by the columns, in order, with
+// the configured directions. Cursor columns must not be NULL.
+type keyset struct {
+	driver  string
+	columns []keysetColumn
+}
+
+// parseCursorFields parses a cursorFields list from raw configuration.
+func parseCursorFields(raw interface{}) []string {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+	fields := make([]string, 0, len(items))
+	for _, item := range items {
+		if field, ok := item.(string); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// parseKeysetColumns parses cursor field specifications such as
+// "updated_at", "o.id" or "created_at DESC".
+func parseKeysetColumns(specs []string) ([]keysetColumn, error) {
+	columns := make([]keysetColumn, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.Fields(spec)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("%w: cursor field %q must be a column name optionally followed by ASC or DESC", ErrDatabaseInvalidCursor, spec)
+		}
+		column := keysetColumn{Column: parts[0]}
+		if len(parts) == 2 {
+			switch strings.ToUpper(parts[1]) {
+			case "ASC":
+			case "DESC":
+				column.Desc = true
+			default:
+				return nil, fmt.Errorf("%w: cursor field %q has invalid direction %q", ErrDatabaseInvalidCursor, spec, parts[1])
+			}
+		}
+		if !cursorColumnPattern.MatchString(column.Column) {
+			return nil, fmt.Errorf("%w: cursor field %q is not a column name", ErrDatabaseInvalidCursor, spec)
+		}
+		name := column.Column[strings.LastIndex(column.Column, ".")+1:]
+		column.Field = strings.Trim(name, "\"`")
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// newDatabaseKeyset builds the keyset from pagination.cursorFields and/or
+// incremental.cursorFields. Returns nil if neither is configured. When both
+// are set, they must be identical, since the persisted cursor resumes the
+// paginated read. The driver is set once the connection is opened.
+func newDatabaseKeyset(config DatabaseInputConfig) (*keyset, error) {
+	var specs []string
+	if config.Pagination != nil && len(config.Pagination.CursorFields) > 0 {
+		specs = config.Pagination.CursorFields
+	}
+	if config.Incremental != nil && config.Incremental.Enabled && len(config.Incremental.CursorFields) > 0 {
+		if specs != nil && strings.Join(specs, ",") != strings.Join(config.Incremental.CursorFields, ",") {
+			return nil, fmt.Errorf("%w: pagination.cursorFields and incremental.cursorFields must be identical", ErrDatabaseInvalidCursor)
+		}
+		specs = config.Incremental.CursorFields
+	}
+	if specs == nil {
+		return nil, nil
+	}
+
+	if !strings.Contains(config.Query, CursorPredicatePlaceholder) {
+		return nil, fmt.Errorf("%w: query must contain %s when cursorFields are configured", ErrDatabaseInvalidCursor, CursorPredicatePlaceholder)
+	}
+	columns, err := parseKeysetColumns(specs)
+	if err != nil {
+		return nil, err
+	}
+	return &keyset{columns: columns}, nil
+}
+
+// fields returns the record fields making up the cursor, in order.
+func (k *keyset) fields() []string {
+	fields := make([]string, len(k.columns))
+	for i, c := range k.columns {
+		fields[i] = c.Field
+	}
+	return fields
+}
+
+// useRowValues reports whether the predicate can be written as a row-value
+// comparison, (a, b) > (?, ?). This requires all columns to share the same
+// direction. PostgreSQL and SQLite (3.15+) use row values with an index
+// range scan; MySQL evaluates them correctly but cannot use an index range
+// for them, so the expanded form is generated instead.
+func (k *keyset) useRowValues() bool {
+	if len(k.columns) < 2 || k.driver == database.DriverMySQL {
+		return false
+	}
+	for _, c := range k.columns[1:] {
+		if c.Desc != k.columns[0].Desc {
+			return false
+		}
+	}
+	return true
+}
+
+// predicate returns the SQL predicate selecting the rows strictly after the
+// cursor, and its arguments. argIndex is the number of arguments already
+// bound, used for numbered placeholders ($n).
+func (k *keyset) predicate(cursor []interface{}, argIndex int) (string, []interface{}) {
+	if cursor == nil {
+		return "(1=1)", nil
+	}
+
+	numbered := database.GetPlaceholderStyle(k.driver) == database.PlaceholderDollar
+	var args []interface{}
+	// bind returns the placeholder of cursor value i. Numbered placeholders
+	// are reused; positional placeholders need one argument per occurrence.
+	bind := func(i int) string {
+		if numbered {
+			return database.FormatPlaceholder(k.driver, argIndex+i+1)
+		}
+		args = append(args, cursor[i])
+		return database.FormatPlaceholder(k.driver, 0)
+	}
+	if numbered {
+		args = append(args, cursor...)
+	}
+
+	if k.useRowValues() {
+		columns := make([]string, len(k.columns))
+		placeholders := make([]string, len(k.columns))
+		for i, c := range k.columns {
+			columns[i] = c.Column
+			placeholders[i] = bind(i)
+		}
+		return fmt.Sprintf("((%s) %s (%s))", strings.Join(columns, ", "), keysetOperator(k.columns[0].Desc), strings.Join(placeholders, ", ")), args
+	}
+
+	// Expanded form: a > ? OR (a = ? AND b > ?) OR ..., preceded by an
+	// inclusive bound on the leading column so that an index on it is used.
+	// Placeholders are bound in the order they appear in the text.
+	var bound string
+	if len(k.columns) > 1 {
+		lead := k.columns[0]
+		bound = fmt.Sprintf("%s %s= %s", lead.Column, keysetOperator(lead.Desc), bind(0))
+	}
+	var terms []string
+	for i, c := range k.columns {
+		conds := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, fmt.Sprintf("%s = %s", k.columns[j].Column, bind(j)))
+		}
+		conds = append(conds, fmt.Sprintf("%s %s %s", c.Column, keysetOperator(c.Desc), bind(i)))
+		if len(conds) == 1 {
+			terms = append(terms, conds[0])
+		} else {
+			terms = append(terms, "("+strings.Join(conds, " AND ")+")")
+		}
+	}
+	if len(k.columns) == 1 {
+		return "(" + terms[0] + ")", args
+	}
+	return fmt.Sprintf("(%s AND (%s))", bound, strings.Join(terms, " OR ")), args
+}
+
+// keysetOperator returns the comparison operator selecting the rows after
+// the cursor for the given direction.
+func keysetOperator(desc bool) string {
+	if desc {
+		return "<"
+	}
+	return ">"
+}
+
+// apply replaces every occurrence of {{cursorPredicate}} in the query with
+// the predicate for cursor. With positional placeholders (?), the predicate
+// arguments are inserted at the position of the placeholder in the query,
+// after the arguments of the placeholders preceding it.
+func (k *keyset) apply(query string, args []interface{}, cursor []interface{}) (string, []interface{}) {
+	result := make([]interface{}, len(args))
+	copy(result, args)
+
+	if database.GetPlaceholderStyle(k.driver) == database.PlaceholderDollar {
+		predicate, predicateArgs := k.predicate(cursor, len(result))
+		return strings.ReplaceAll(query, CursorPredicatePlaceholder, predicate), append(result, predicateArgs...)
+	}
+
+	for {
+		idx := strings.Index(query, CursorPredicatePlaceholder)
+		if idx < 0 {
+			return k.textQuery(query), result
+		}
+		predicate, predicateArgs := k.predicate(cursor, 0)
+		pos := database.CountPlaceholders(query[:idx], k.driver)
+		if pos > len(result) {
+			pos = len(result)
+		}
+		result = append(result[:pos], append(predicateArgs, result[pos:]...)...)
+		query = query[:idx] + predicate + query[idx+len(CursorPredicatePlaceholder):]
+	}
+}
+
+// textQuery nests a SQLite query so that the stored text of each cursor
+// column is also returned. SQLite compares timestamps as text, but the
+// driver returns the columns declared DATE, DATETIME or TIMESTAMP as
+// time.Time: their stored text, whatever its format, is the only cursor
+// value that compares correctly. Other drivers compare timestamps by value.
+func (k *keyset) textQuery(query string) string {
+	if k.driver != database.DriverSQLite {
+		return query
+	}
+	columns := make([]string, len(k.columns))
+	for i, c := range k.columns {
+		columns[i] = fmt.Sprintf(`CAST("%s" AS TEXT) AS "%s%d"`, c.Field, cursorTextColumn, i)
+	}
+	return fmt.Sprintf("SELECT *, %s FROM (%s)", strings.Join(columns, ", "), database.TrimStatement(query, k.driver))
+}
+
+// cursorColumns locates the cursor fields in the result columns of a query.
+type cursorColumns struct {
+	values []int // index of each cursor field
+	texts  []int // index of the stored text of each cursor field (SQLite), or nil
+}
+
+// added reports whether result column i was added by textQuery, and is not
+// part of the records.
+func (c *cursorColumns) added(i int) bool {
+	for _, pos := range c.texts {
+		if pos == i {
+			return true
+		}
+	}
+	return false
+}
+
+// positions returns the index of each cursor field in the result columns.
+func (k *keyset) positions(columns []string) (*cursorColumns, error) {
+	index := func(name string) int {
+		for j, column := range columns {
+			if column == name {
+				return j
+			}
+		}
+		return -1
+	}
+
+	positions := &cursorColumns{values: make([]int, len(k.columns))}
+	for i, c := range k.columns {
+		positions.values[i] = index(c.Field)
+		if positions.values[i] < 0 {
+			return nil, fmt.Errorf("%w: cursor field %q is not a column of the query result", ErrDatabaseInvalidCursor, c.Field)
+		}
+		if text := index(fmt.Sprintf("%s%d", cursorTextColumn, i)); text >= 0 {
+			positions.texts = append(positions.texts, text)
+		}
+	}
+	if len(positions.texts) != len(k.columns) {
+		positions.texts = nil
+	}
+	return positions, nil
+}
+
+// cursorOf extracts the cursor of a scanned row from its raw values.
+// Cursor columns must not be NULL: a NULL never compares greater than the
+// cursor, so rows would be silently skipped.
+func (k *keyset) cursorOf(values []interface{}, positions *cursorColumns) ([]interface{}, error) {
+	cursor := make([]interface{}, len(positions.values))
+	for i, pos := range positions.values {
+		v := values[pos]
+		if v == nil {
+			return nil, fmt.Errorf("%w: cursor field %q is NULL", ErrDatabaseInvalidCursor, k.columns[i].Field)
+		}
+		if _, ok := v.(time.Time); ok && positions.texts != nil {
+			v = values[positions.texts[i]]
+		}
+		cursor[i] = k.cursorValue(v)
+	}
+	return cursor, nil
+}
+
+// cursorValue converts a raw column value into a cursor value that binds
+// back to the same value and survives JSON persistence. Timestamps keep
+// their fractional seconds, in the text format each database compares
+// against: RFC 3339 for PostgreSQL, "YYYY-MM-DD HH:MM:SS[.fraction]" in UTC
+// for MySQL. SQLite timestamps are read as their stored text (textQuery).
+func (k *keyset) cursorValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case time.Time:
+		if k.driver == database.DriverPostgres {
+			return val.Format(time.RFC3339Nano)
+		}
+		return val.UTC().Format("2006-01-02 15:04:05.999999999")
+	default:
+		return v
+	}
+}
+
+// toMap returns the cursor keyed by record field, as persisted in the state.
+func (k *keyset) toMap(cursor []interface{}) map[string]interface{} {
+	if cursor == nil {
+		return nil
+	}
+	m := make(map[string]interface{}, len(cursor))
+	for i, c := range k.columns {
+		m[c.Field] = cursor[i]
+	}
+	return m
+}
+
+// fromMap returns the cursor values of a persisted cursor, in column order.
+// Returns nil if a field is missing (e.g. the cursor fields were changed).
+// Integral numbers decoded from JSON as float64 are converted back to int64.
+func (k *keyset) fromMap(m map[string]interface{}) []interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	cursor := make([]interface{}, len(k.columns))
+	for i, c := range k.columns {
+		v, ok := m[c.Field]
+		if !ok || v == nil {
+			return nil
+		}
+		if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			v = int64(f)
+		}
+		cursor[i] = v
+	}
+	return cursor
+}
+
+// initialCursor returns the persisted cursor to resume from, when
+// incremental cursor persistence is configured.
+func (d *DatabaseInput) initialCursor() []interface{} {
+	if d.keyset == nil || d.config.Incremental == nil || !d.config.Incremental.Enabled ||
+		len(d.config.Incremental.CursorFields) == 0 || d.lastState == nil || d.lastState.Cursor == nil {
+		return nil
+	}
+	cursor := d.keyset.fromMap(d.lastState.Cursor)
+	if cursor == nil {
+		logger.Warn("persisted cursor does not match cursorFields, reading from the start",
+			"module_type", "database",
+			"pipeline_id", d.pipelineID,
+			"cursor_fields", strings.Join(d.keyset.fields(), ","),
+		)
+	}
+	return cursor
+}
+
+// keysetPaginated reports whether pages are read with keyset pagination.
+func (d *DatabaseInput) keysetPaginated() bool {
+	return d.keyset != nil && d.config.Pagination != nil &&
+		d.config.Pagination.Type == "cursor" && len(d.config.Pagination.CursorFields) > 0
+}
+
+// LastCursor returns the composite cursor of the last row read, keyed by
+// record field, or nil if no row was read or no cursor fields are configured.
+func (d *DatabaseInput) LastCursor() map[string]interface{} {
+	if d.keyset == nil {
+		return nil
+	}
+	return d.keyset.toMap(d.lastCursor)
+}
@@ -0,0 +1,235 @@
+package input
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+func TestParseKeysetColumns(t *testing.T) {
+	t.Parallel()
+
+	columns, err := parseKeysetColumns([]string{"o.updated_at DESC", "`id`", "\"o\".\"seq\" asc"})
+	if err != nil {
+		t.Fatalf("parseKeysetColumns() error = %v", err)
+	}
+	want := []keysetColumn{
+		{Column: "o.updated_at", Field: "updated_at", Desc: true},
+		{Column: "`id`", Field: "id"},
+		{Column: "\"o\".\"seq\"", Field: "seq"},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns = %+v, want %+v", columns, want)
+	}
+
+	for _, spec := range []string{"", "id sideways", "id DESC NULLS LAST", "lower(name)", "id; DROP TABLE x"} {
+		if _, err := parseKeysetColumns([]string{spec}); !errors.Is(err, ErrDatabaseInvalidCursor) {
+			t.Errorf("parseKeysetColumns(%q) error = %v, want ErrDatabaseInvalidCursor", spec, err)
+		}
+	}
+}
+
+func TestKeysetPredicate(t *testing.T) {
+	t.Parallel()
+
+	cursor := []interface{}{"2026-01-26 10:00:00", int64(42)}
+
+	tests := []struct {
+		name     string
+		driver   string
+		specs    []string
+		cursor   []interface{}
+		argIndex int
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:   "first page",
+			driver: "postgres",
+			specs:  []string{"updated_at", "id"},
+			want:   "(1=1)",
+		},
+		{
+			name:     "postgres row values",
+			driver:   "postgres",
+			specs:    []string{"updated_at", "id"},
+			cursor:   cursor,
+			argIndex: 2,
+			want:     "((updated_at, id) > ($3, $4))",
+			wantArgs: cursor,
+		},
+		{
+			name:     "sqlite row values descending",
+			driver:   "sqlite",
+			specs:    []string{"updated_at DESC", "id DESC"},
+			cursor:   cursor,
+			want:     "((updated_at, id) < (?, ?))",
+			wantArgs: cursor,
+		},
+		{
+			name:     "mysql expanded form",
+			driver:   "mysql",
+			specs:    []string{"updated_at", "id"},
+			cursor:   cursor,
+			want:     "(updated_at >= ? AND (updated_at > ? OR (updated_at = ? AND id > ?)))",
+			wantArgs: []interface{}{cursor[0], cursor[0], cursor[0], cursor[1]},
+		},
+		{
+			name:     "postgres mixed directions",
+			driver:   "postgres",
+			specs:    []string{"updated_at DESC", "id"},
+			cursor:   cursor,
+			want:     "(updated_at <= $1 AND (updated_at < $1 OR (updated_at = $1 AND id > $2)))",
+			wantArgs: cursor,
+		},
+		{
+			name:     "single column",
+			driver:   "sqlite",
+			specs:    []string{"id"},
+			cursor:   []interface{}{int64(7)},
+			want:     "(id > ?)",
+			wantArgs: []interface{}{int64(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			columns, err := parseKeysetColumns(tt.specs)
+			if err != nil {
+				t.Fatalf("parseKeysetColumns() error = %v", err)
+			}
+			k := &keyset{driver: tt.driver, columns: columns}
+			got, args := k.predicate(tt.cursor, tt.argIndex)
+			if got != tt.want {
+				t.Errorf("predicate = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestKeysetApply_PositionalArguments(t *testing.T) {
+	t.Parallel()
+
+	columns, _ := parseKeysetColumns([]string{"updated_at", "id"})
+	k := &keyset{driver: "mysql", columns: columns}
+
+	query := "SELECT * FROM t WHERE tenant = ? AND {{cursorPredicate}} AND status = ? ORDER BY updated_at, id"
+	got, args := k.apply(query, []interface{}{"acme", "active"}, []interface{}{"ts", int64(1)})
+
+	want := "SELECT * FROM t WHERE tenant = ? AND (updated_at >= ? AND (updated_at > ? OR (updated_at = ? AND id > ?))) AND status = ? ORDER BY updated_at, id"
+	if got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+	wantArgs := []interface{}{"acme", "ts", "ts", "ts", int64(1), "active"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestKeysetApply_SQLiteCursorText(t *testing.T) {
+	t.Parallel()
+
+	columns, _ := parseKeysetColumns([]string{"e.updated_at", "id"})
+	k := &keyset{driver: "sqlite", columns: columns}
+
+	got, _ := k.apply("SELECT * FROM events e WHERE {{cursorPredicate}} ORDER BY e.updated_at, id; -- page", nil, nil)
+	want := `SELECT *, CAST("updated_at" AS TEXT) AS "_cannectors_cursor_0", CAST("id" AS TEXT) AS "_cannectors_cursor_1" ` +
+		"FROM (SELECT * FROM events e WHERE (1=1) ORDER BY e.updated_at, id)"
+	if got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+}
+
+func TestKeysetCursorValues(t *testing.T) {
+	t.Parallel()
+
+	columns, _ := parseKeysetColumns([]string{"updated_at", "id"})
+	ts := time.Date(2026, 1, 26, 10, 0, 0, 123456000, time.UTC)
+
+	pg := &keyset{driver: "postgres", columns: columns}
+	cursor, err := pg.cursorOf([]interface{}{"x", ts, int64(42)}, &cursorColumns{values: []int{1, 2}})
+	if err != nil {
+		t.Fatalf("cursorOf() error = %v", err)
+	}
+	if cursor[0] != "2026-01-26T10:00:00.123456Z" {
+		t.Errorf("postgres timestamp = %v, want RFC 3339 with fractional seconds", cursor[0])
+	}
+
+	my := &keyset{driver: "mysql", columns: columns}
+	cursor, _ = my.cursorOf([]interface{}{ts, int64(42)}, &cursorColumns{values: []int{0, 1}})
+	if cursor[0] != "2026-01-26 10:00:00.123456" {
+		t.Errorf("mysql timestamp = %v, want 2026-01-26 10:00:00.123456", cursor[0])
+	}
+
+	// SQLite timestamps are their stored text, in whatever format
+	lite := &keyset{driver: "sqlite", columns: columns}
+	positions, err := lite.positions([]string{"updated_at", "id", "_cannectors_cursor_0", "_cannectors_cursor_1"})
+	if err != nil {
+		t.Fatalf("positions() error = %v", err)
+	}
+	if !positions.added(2) || positions.added(1) {
+		t.Errorf("positions() = %+v, want the text columns only added", positions)
+	}
+	cursor, _ = lite.cursorOf([]interface{}{ts, int64(42), "2026-01-26T10:00:00.123456Z", "42"}, positions)
+	if !reflect.DeepEqual(cursor, []interface{}{"2026-01-26T10:00:00.123456Z", int64(42)}) {
+		t.Errorf("sqlite cursor = %v, want the stored timestamp text and the raw id", cursor)
+	}
+
+	if _, err := lite.cursorOf([]interface{}{nil, int64(42), nil, "42"}, positions); !errors.Is(err, ErrDatabaseInvalidCursor) {
+		t.Errorf("cursorOf() with NULL error = %v, want ErrDatabaseInvalidCursor", err)
+	}
+
+	// Persisted cursors are decoded from JSON
+	restored := lite.fromMap(map[string]interface{}{"updated_at": "2026-01-26 10:00:00", "id": float64(42)})
+	if !reflect.DeepEqual(restored, []interface{}{"2026-01-26 10:00:00", int64(42)}) {
+		t.Errorf("fromMap() = %v", restored)
+	}
+	if restored := lite.fromMap(map[string]interface{}{"id": float64(42)}); restored != nil {
+		t.Errorf("fromMap() with missing field = %v, want nil", restored)
+	}
+}
+
+func TestNewDatabaseInputFromConfig_CursorValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+	}{
+		{
+			name: "missing cursorPredicate placeholder",
+			config: map[string]interface{}{
+				"query":      "SELECT * FROM users ORDER BY updated_at, id",
+				"pagination": map[string]interface{}{"type": "cursor", "cursorFields": []interface{}{"updated_at", "id"}},
+			},
+		},
+		{
+			name: "different pagination and incremental cursor fields",
+			config: map[string]interface{}{
+				"query":      "SELECT * FROM users WHERE {{cursorPredicate}} ORDER BY updated_at, id",
+				"pagination": map[string]interface{}{"type": "cursor", "cursorFields": []interface{}{"updated_at", "id"}},
+				"incremental": map[string]interface{}{
+					"enabled":      true,
+					"cursorFields": []interface{}{"id"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["connectionString"] = "postgres://localhost/db"
+			_, err := NewDatabaseInputFromConfig(&connector.ModuleConfig{Type: "database", Config: tt.config})
+			if !errors.Is(err, ErrDatabaseInvalidCursor) {
+				t.Errorf("error = %v, want ErrDatabaseInvalidCursor", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cannectors/runtime/internal/database"
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/persistence"
+)
+
+// Default configuration values for database input streaming
+const (
+	defaultStreamChunkSize = 1000
+	defaultStreamFetchSize = 1000
+
+	// streamCursorName is the name of the server-side cursor used on PostgreSQL.
+	streamCursorName = "cannectors_stream"
+)
+
+var (
+	// ErrDatabaseStreamingPagination is returned when streaming and pagination are both configured.
+	ErrDatabaseStreamingPagination = errors.New("database input streaming cannot be combined with pagination")
+
+	// ErrDatabaseInvalidCheckpoint is returned when a checkpoint cannot be resumed from.
+	ErrDatabaseInvalidCheckpoint = errors.New("invalid database input checkpoint")
+)
+
+// DatabaseStreamingConfig defines row streaming for database queries.
+// The query is executed once and rows are handed downstream in chunks
+// instead of being materialised in memory.
+type DatabaseStreamingConfig struct {
+	// Enabled: whether to stream rows
+	Enabled bool `json:"enabled"`
+	// ChunkSize: number of records handed downstream at a time
+	ChunkSize int `json:"chunkSize"`
+	// FetchSize: number of rows fetched per round trip from the server-side
+	// cursor (PostgreSQL). Other drivers read rows from the connection as
+	// they are iterated.
+	FetchSize int `json:"fetchSize"`
+}
+
+// parseDatabaseStreamingConfig parses streaming configuration.
+func parseDatabaseStreamingConfig(cfg map[string]interface{}) *DatabaseStreamingConfig {
+	config := &DatabaseStreamingConfig{
+		Enabled:   true,
+		ChunkSize: defaultStreamChunkSize,
+		FetchSize: defaultStreamFetchSize,
+	}
+
+	if v, ok := cfg["enabled"].(bool); ok {
+		config.Enabled = v
+	}
+	if v, ok := cfg["chunkSize"].(float64); ok && v > 0 {
+		config.ChunkSize = int(v)
+	}
+	if v, ok := cfg["fetchSize"].(float64); ok && v > 0 {
+		config.FetchSize = int(v)
+	}
+
+	return config
+}
+
+// Streaming reports whether rows are streamed in chunks.
+func (d *DatabaseInput) Streaming() bool {
+	return d.config.Streaming != nil && d.config.Streaming.Enabled
+}
+
+// Stream executes the query once and calls handle for each chunk of
+// streaming.chunkSize records. On PostgreSQL, rows are read through a
+// server-side cursor, streaming.fetchSize rows at a time, and timeoutMs
+// applies to each fetch. Other drivers iterate the result set as it is read
+// from the connection; the stream is then bounded by ctx only.
+//
+// After ResumeFrom, the stream starts after the checkpointed position: the
+// keyset cursor of the last delivered row when cursor fields are configured,
+// otherwise the number of rows already delivered, which are read and skipped
+// (the query must then have a deterministic ORDER BY).
+func (d *DatabaseInput) Stream(ctx context.Context, handle ChunkHandler) error {
+	startTime := time.Now()
+	d.loadIncrementalState()
+	d.lastCursor = nil
+	resume := d.resume
+	d.resume = nil
+
+	logger.Info("database input stream started",
+		"module_type", "database",
+		"driver", d.driver,
+		"chunk_size", d.config.Streaming.ChunkSize,
+		"fetch_size", d.config.Streaming.FetchSize,
+		"resumed", resume != nil,
+	)
+
+	query, args, err := d.buildQuery()
+	if err != nil {
+		return fmt.Errorf("building query: %w", err)
+	}
+	chunker := &recordChunker{input: d, size: d.config.Streaming.ChunkSize, handle: handle}
+	if d.keyset != nil {
+		cursor := d.initialCursor()
+		if resume != nil {
+			// Rows up to the checkpoint were delivered: the cursor persisted
+			// on completion is at least the checkpointed one
+			cursor = d.keyset.fromMap(resume.Cursor)
+			d.lastCursor = cursor
+		}
+		query, args = d.keyset.apply(query, args, cursor)
+	} else if resume != nil {
+		chunker.skip = resume.Offset
+	}
+	d.streamPosition = chunker.skip
+
+	if d.driver == database.DriverPostgres {
+		err = d.streamCursor(ctx, query, args, chunker)
+	} else {
+		err = d.streamRows(ctx, query, args, chunker)
+	}
+	if err == nil {
+		err = chunker.flush(ctx)
+	}
+
+	duration := time.Since(startTime)
+	if err != nil {
+		logger.Error("database input stream failed",
+			"module_type", "database",
+			"record_count", chunker.total,
+			"duration", duration,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	logger.Info("database input stream completed",
+		"module_type", "database",
+		"record_count", chunker.total,
+		"chunk_count", chunker.chunks,
+		"duration", duration,
+	)
+	return nil
+}
+
+// streamRows iterates the result set of a single query.
+func (d *DatabaseInput) streamRows(ctx context.Context, query string, args []interface{}, chunker *recordChunker) error {
+	q, end, err := d.beginRead(ctx)
+	if err != nil {
+		return err
+	}
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return end(database.ClassifyDatabaseError(err, d.driver, "select", query, len(args)))
+	}
+
+	_, err = chunker.consume(ctx, rows)
+	_ = rows.Close()
+	return end(err)
+}
+
+// streamCursor reads the result set through a PostgreSQL server-side cursor,
+// so the server sends fetchSize rows at a time instead of the full result.
+func (d *DatabaseInput) streamCursor(ctx context.Context, query string, args []interface{}, chunker *recordChunker) error {
+	opts := &sql.TxOptions{ReadOnly: true}
+	if d.txOptions != nil {
+		opts.Isolation = d.txOptions.Isolation
+	}
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return database.ClassifyDatabaseError(err, d.driver, "begin", "", 0)
+	}
+	// Read-only transaction: rolling back releases the cursor
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", streamCursorName, strings.TrimRight(strings.TrimSpace(query), ";"))
+	if _, err := tx.ExecContext(ctx, declare, args...); err != nil {
+		return database.ClassifyDatabaseError(err, d.driver, "select", declare, len(args))
+	}
+
+	fetchSize := d.config.Streaming.FetchSize
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", fetchSize, streamCursorName)
+	for {
+		fetchCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		rows, err := tx.QueryContext(fetchCtx, fetch)
+		if err != nil {
+			cancel()
+			return database.ClassifyDatabaseError(err, d.driver, "select", fetch, 0)
+		}
+		n, err := chunker.consume(ctx, rows)
+		_ = rows.Close()
+		cancel()
+		if err != nil {
+			return err
+		}
+		if n < fetchSize {
+			return nil
+		}
+	}
+}
+
+// ResumeFrom makes the next Stream start after the checkpointed position.
+func (d *DatabaseInput) ResumeFrom(checkpoint *persistence.Checkpoint) error {
+	if !d.Streaming() {
+		return fmt.Errorf("%w: streaming is not enabled", ErrDatabaseInvalidCheckpoint)
+	}
+	if d.keyset != nil {
+		if checkpoint.Cursor == nil || d.keyset.fromMap(checkpoint.Cursor) == nil {
+			return fmt.Errorf("%w: cursor does not match cursorFields %s", ErrDatabaseInvalidCheckpoint, strings.Join(d.keyset.fields(), ","))
+		}
+	} else if checkpoint.Offset < 0 {
+		return fmt.Errorf("%w: negative offset %d", ErrDatabaseInvalidCheckpoint, checkpoint.Offset)
+	}
+	d.resume = checkpoint
+	return nil
+}
+
+// Checkpoint returns the position after the last chunk handed downstream:
+// the keyset cursor of its last row, or the number of rows delivered.
+func (d *DatabaseInput) Checkpoint() *persistence.Checkpoint {
+	if d.keyset != nil {
+		cursor := d.LastCursor()
+		if cursor == nil {
+			return nil
+		}
+		return &persistence.Checkpoint{Cursor: cursor}
+	}
+	return &persistence.Checkpoint{Offset: d.streamPosition}
+}
+
+// recordChunker accumulates scanned rows and hands them to the handler in
+// chunks of size records. The first skip rows are read and dropped.
+type recordChunker struct {
+	input   *DatabaseInput
+	size    int
+	skip    int
+	handle  ChunkHandler
+	pending []map[string]interface{}
+	total   int
+	chunks  int
+}
+
+// consume scans all rows, handing full chunks to the handler.
+// Returns the number of rows read.
+func (c *recordChunker) consume(ctx context.Context, rows *sql.Rows) (int, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("getting column names: %w", err)
+	}
+	positions, err := c.input.cursorPositions(columns)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for rows.Next() {
+		record, err := c.input.scanRow(rows, columns, positions)
+		if err != nil {
+			return n, err
+		}
+		n++
+		if c.skip > 0 {
+			c.skip--
+			continue
+		}
+		c.pending = append(c.pending, record)
+		if len(c.pending) >= c.size {
+			if err := c.flush(ctx); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("iterating rows: %w", err)
+	}
+	return n, nil
+}
+
+// flush hands the pending records to the handler, if any.
+func (c *recordChunker) flush(ctx context.Context) error {
+	if len(c.pending) == 0 {
+		return nil
+	}
+	chunk := c.pending
+	c.pending = make([]map[string]interface{}, 0, c.size)
+	c.total += len(chunk)
+	c.chunks++
+	c.input.streamPosition += len(chunk)
+	return c.handle(ctx, chunk)
+}
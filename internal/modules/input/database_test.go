@@ -1,8 +1,10 @@
 package input
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/cannectors/runtime/internal/database"
 	"github.com/cannectors/runtime/pkg/connector"
 )
 
@@ -187,6 +189,18 @@ func TestParseDatabasePaginationConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "composite cursor pagination",
+			cfg: map[string]interface{}{
+				"type":         "cursor",
+				"cursorFields": []interface{}{"updated_at", "id"},
+			},
+			check: func(t *testing.T, config *DatabasePaginationConfig) {
+				if len(config.CursorFields) != 2 || config.CursorFields[0] != "updated_at" || config.CursorFields[1] != "id" {
+					t.Errorf("CursorFields = %v, want [updated_at id]", config.CursorFields)
+				}
+			},
+		},
 		{
 			name: "default limit",
 			cfg: map[string]interface{}{
@@ -210,6 +224,31 @@ func TestParseDatabasePaginationConfig(t *testing.T) {
 	}
 }
 
+func TestParseDatabaseStreamingConfig(t *testing.T) {
+	t.Parallel()
+
+	config := parseDatabaseStreamingConfig(map[string]interface{}{})
+	if !config.Enabled {
+		t.Error("Enabled = false, want true when streaming is configured")
+	}
+	if config.ChunkSize != defaultStreamChunkSize || config.FetchSize != defaultStreamFetchSize {
+		t.Errorf("ChunkSize/FetchSize = %d/%d, want defaults %d/%d",
+			config.ChunkSize, config.FetchSize, defaultStreamChunkSize, defaultStreamFetchSize)
+	}
+
+	config = parseDatabaseStreamingConfig(map[string]interface{}{
+		"enabled":   false,
+		"chunkSize": float64(200),
+		"fetchSize": float64(5000),
+	})
+	if config.Enabled {
+		t.Error("Enabled = true, want false")
+	}
+	if config.ChunkSize != 200 || config.FetchSize != 5000 {
+		t.Errorf("ChunkSize/FetchSize = %d/%d, want 200/5000", config.ChunkSize, config.FetchSize)
+	}
+}
+
 func TestParseIncrementalConfig(t *testing.T) {
 	t.Parallel()
 
@@ -256,6 +295,40 @@ func TestParseIncrementalConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "watermark incremental",
+			cfg: map[string]interface{}{
+				"enabled":         true,
+				"timestampField":  "updated_at",
+				"watermark":       true,
+				"lookbackSeconds": float64(120),
+			},
+			check: func(t *testing.T, config *IncrementalConfig) {
+				if !config.Watermark {
+					t.Error("Watermark should be true")
+				}
+				if config.LookbackSeconds != 120 {
+					t.Errorf("LookbackSeconds = %d, want 120", config.LookbackSeconds)
+				}
+				d := &DatabaseInput{config: DatabaseInputConfig{Incremental: config}}
+				pc := d.GetPersistenceConfig()
+				if !pc.WatermarkEnabled() || pc.Timestamp.Field != "updated_at" || pc.Timestamp.LookbackSeconds != 120 {
+					t.Errorf("GetPersistenceConfig().Timestamp = %+v, want watermark on updated_at with 120s lookback", pc.Timestamp)
+				}
+			},
+		},
+		{
+			name: "composite cursor incremental",
+			cfg: map[string]interface{}{
+				"enabled":      true,
+				"cursorFields": []interface{}{"updated_at", "id"},
+			},
+			check: func(t *testing.T, config *IncrementalConfig) {
+				if len(config.CursorFields) != 2 || config.CursorFields[0] != "updated_at" || config.CursorFields[1] != "id" {
+					t.Errorf("CursorFields = %v, want [updated_at id]", config.CursorFields)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -301,6 +374,31 @@ func TestNewDatabaseInputFromConfig_Validation(t *testing.T) {
 			},
 			wantErr: ErrDatabaseMissingConnStr,
 		},
+		{
+			name: "streaming with pagination",
+			cfg: &connector.ModuleConfig{
+				Type: "database",
+				Config: map[string]interface{}{
+					"connectionString": "postgres://localhost/db",
+					"query":            "SELECT * FROM users",
+					"streaming":        map[string]interface{}{"chunkSize": float64(500)},
+					"pagination":       map[string]interface{}{"type": "limit-offset"},
+				},
+			},
+			wantErr: ErrDatabaseStreamingPagination,
+		},
+		{
+			name: "unsupported isolation level",
+			cfg: &connector.ModuleConfig{
+				Type: "database",
+				Config: map[string]interface{}{
+					"connectionString": "postgres://localhost/db",
+					"query":            "SELECT * FROM users",
+					"isolationLevel":   "snapshot",
+				},
+			},
+			wantErr: database.ErrUnsupportedIsolationLevel,
+		},
 	}
 
 	for _, tt := range tests {
@@ -310,9 +408,63 @@ func TestNewDatabaseInputFromConfig_Validation(t *testing.T) {
 				t.Error("expected error, got nil")
 				return
 			}
-			if err != tt.wantErr {
+			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("error = %v, want %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestDatabaseInputBuildQuery(t *testing.T) {
+	t.Parallel()
+
+	d := &DatabaseInput{
+		driver: "mysql",
+		config: DatabaseInputConfig{
+			Query: "SELECT * FROM orders WHERE status = :status AND note <> ':status' AND region = @region " +
+				"AND created_at > {{lastRunTimestamp}} AND tier = {{tier}} LIMIT :limit",
+			Parameters: map[string]interface{}{"status": "open", "region": "eu", "tier": 2},
+		},
+	}
+
+	query, args, err := d.buildQuery()
+	if err != nil {
+		t.Fatalf("buildQuery() error = %v", err)
+	}
+	want := "SELECT * FROM orders WHERE status = ? AND note <> ':status' AND region = ? " +
+		"AND created_at > ? AND tier = ? LIMIT :limit"
+	if query != want {
+		t.Errorf("buildQuery() query = %q, want %q", query, want)
+	}
+	if len(args) != 4 || args[0] != "open" || args[1] != "eu" || args[3] != 2 {
+		t.Errorf("buildQuery() args = %v, want parameters in query order", args)
+	}
+
+	// Parameters resolved later are bound at their position in the query
+	query, args, err = d.bindNamedParameter(query, args, "limit", 10)
+	if err != nil {
+		t.Fatalf("bindNamedParameter() error = %v", err)
+	}
+	if len(args) != 5 || args[4] != 10 {
+		t.Errorf("bindNamedParameter() = %q %v, want limit bound last", query, args)
+	}
+}
+
+func TestDatabaseInputBindNamedParameter(t *testing.T) {
+	t.Parallel()
+
+	d := &DatabaseInput{driver: "postgres"}
+	for _, query := range []string{
+		"SELECT * FROM orders OFFSET :offset",
+		"SELECT * FROM orders OFFSET @offset",
+		"SELECT * FROM orders OFFSET {{offset}}",
+	} {
+		got, args, err := d.bindNamedParameter(query, nil, "offset", 20)
+		if err != nil {
+			t.Fatalf("bindNamedParameter(%q) error = %v", query, err)
+		}
+		if got != "SELECT * FROM orders OFFSET $1" || len(args) != 1 || args[0] != 20 {
+			t.Errorf("bindNamedParameter(%q) = %q %v, want the offset bound", query, got, args)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cannectors/runtime/internal/database"
+	"github.com/cannectors/runtime/internal/persistence"
+)
+
+// SetWindow makes the next fetch read the rows of a time window (backfill):
+// {{lastRunTimestamp}} and incremental.timestampParam are bound to the window
+// start, without lookback, and {{windowEnd}} to its end.
+func (d *DatabaseInput) SetWindow(window persistence.Window) error {
+	query := d.config.Query
+	hasStart := database.HasParam(query, d.driver, lastRunTimestampParam)
+	if inc := d.config.Incremental; inc != nil && inc.Enabled && inc.TimestampParam != "" {
+		hasStart = hasStart || database.HasParam(query, d.driver, inc.TimestampParam)
+	}
+	if !hasStart {
+		return fmt.Errorf("%w: query must contain %s or incremental.timestampParam", ErrDatabaseWindowParams, LastRunTimestampPlaceholder)
+	}
+	if !database.HasParam(query, d.driver, windowEndParam) {
+		return fmt.Errorf("%w: query must contain %s", ErrDatabaseWindowParams, WindowEndPlaceholder)
+	}
+	d.window = &window
+	return nil
+}
+
+// timestampSince returns the lower timestamp bound of the query: the window
+// start in backfill executions, the persisted timestamp minus the lookback
+// otherwise. Returns nil if there is none (first execution).
+func (d *DatabaseInput) timestampSince() *time.Time {
+	if d.window != nil {
+		from := d.window.From
+		return &from
+	}
+	return d.GetPersistenceConfig().TimestampSince(d.lastState)
+}
+
+// windowEnd returns the value of {{windowEnd}}: the window end in backfill
+// executions, the current time otherwise.
+func (d *DatabaseInput) windowEnd() time.Time {
+	if d.window != nil {
+		return d.window.To
+	}
+	return time.Now()
+}
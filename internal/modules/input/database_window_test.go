@@ -0,0 +1,97 @@
+package input
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/internal/persistence"
+)
+
+func TestDatabaseInput_SetWindow(t *testing.T) {
+	lastTimestamp := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	window := persistence.Window{
+		From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	d := &DatabaseInput{
+		driver: "postgres",
+		config: DatabaseInputConfig{
+			Query: "SELECT * FROM users WHERE updated_at > {{lastRunTimestamp}} AND updated_at <= {{windowEnd}}",
+			Incremental: &IncrementalConfig{
+				Enabled:         true,
+				TimestampField:  "updated_at",
+				LookbackSeconds: 300,
+			},
+		},
+		lastState: &persistence.State{LastTimestamp: &lastTimestamp},
+	}
+	if err := d.SetWindow(window); err != nil {
+		t.Fatalf("SetWindow() error = %v", err)
+	}
+
+	query, args, err := d.buildQuery()
+	if err != nil {
+		t.Fatalf("buildQuery() error = %v", err)
+	}
+	if want := "SELECT * FROM users WHERE updated_at > $1 AND updated_at <= $2"; query != want {
+		t.Errorf("buildQuery() query = %q, want %q", query, want)
+	}
+	if len(args) != 2 || !args[0].(time.Time).Equal(window.From) || !args[1].(time.Time).Equal(window.To) {
+		t.Errorf("buildQuery() args = %v, want window bounds without lookback", args)
+	}
+}
+
+func TestDatabaseInput_SetWindow_TimestampParam(t *testing.T) {
+	window := persistence.Window{
+		From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	d := &DatabaseInput{
+		driver: "mysql",
+		config: DatabaseInputConfig{
+			Query: "SELECT * FROM users WHERE updated_at > :since AND updated_at <= {{windowEnd}}",
+			Incremental: &IncrementalConfig{
+				Enabled:        true,
+				TimestampField: "updated_at",
+				TimestampParam: "since",
+			},
+		},
+	}
+	if err := d.SetWindow(window); err != nil {
+		t.Fatalf("SetWindow() error = %v", err)
+	}
+
+	query, args, err := d.buildQuery()
+	if err != nil {
+		t.Fatalf("buildQuery() error = %v", err)
+	}
+	if want := "SELECT * FROM users WHERE updated_at > ? AND updated_at <= ?"; query != want {
+		t.Errorf("buildQuery() query = %q, want %q", query, want)
+	}
+	// Positional placeholders are bound in the order they appear in the query
+	if len(args) != 2 || !args[0].(time.Time).Equal(window.From) || !args[1].(time.Time).Equal(window.To) {
+		t.Errorf("buildQuery() args = %v, want window start then end", args)
+	}
+}
+
+func TestDatabaseInput_SetWindow_RequiresBothBounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "no start placeholder", query: "SELECT * FROM users WHERE updated_at <= {{windowEnd}}"},
+		{name: "no end placeholder", query: "SELECT * FROM users WHERE updated_at > {{lastRunTimestamp}}"},
+		{name: "end placeholder in a comment", query: "SELECT * FROM users WHERE updated_at > {{lastRunTimestamp}} -- until {{windowEnd}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DatabaseInput{config: DatabaseInputConfig{Query: tt.query}}
+			if err := d.SetWindow(persistence.Window{}); !errors.Is(err, ErrDatabaseWindowParams) {
+				t.Errorf("SetWindow() error = %v, want ErrDatabaseWindowParams", err)
+			}
+		})
+	}
+}
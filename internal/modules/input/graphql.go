@@ -0,0 +1,615 @@
+// Package input provides implementations for input modules.
+// GraphQL module fetches data from GraphQL APIs using Relay cursor pagination.
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cannectors/runtime/internal/auth"
+	"github.com/cannectors/runtime/internal/errhandling"
+	"github.com/cannectors/runtime/internal/httpconfig"
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/pathutil"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/internal/template"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// Default configuration values for GraphQL input
+const (
+	defaultGraphQLCursorVariable = "after"
+)
+
+// Error types for GraphQL input module
+var (
+	ErrGraphQLMissingQuery      = errors.New("query or queryFile is required for graphql input")
+	ErrGraphQLResponse          = errors.New("graphql response contains errors")
+	ErrGraphQLConnectionMissing = errors.New("connection not found in graphql response")
+)
+
+// graphQLRequest is the JSON payload sent to the GraphQL endpoint.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// graphQLResponse is the JSON envelope returned by a GraphQL endpoint.
+type graphQLResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []graphQLError         `json:"errors"`
+}
+
+// graphQLError is a single entry of the GraphQL "errors" array.
+type graphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// GraphQLInput implements polling of GraphQL APIs.
+// It sends the configured query as a POST request, follows Relay-style
+// pagination (pageInfo { hasNextPage endCursor }) by injecting the cursor
+// variable, and returns the nodes of the connection as records.
+// State persistence can be configured to inject the last timestamp and/or
+// last ID as query variables.
+type GraphQLInput struct {
+	endpoint       string
+	headers        map[string]string
+	timeout        time.Duration
+	query          string
+	variables      map[string]interface{}
+	connectionPath string
+	cursorVariable string
+	authHandler    auth.Handler
+	client         *http.Client
+	retryConfig    errhandling.RetryConfig
+	lastRetryInfo  *connector.RetryInfo
+
+	// State persistence
+	persistenceConfig *persistence.StatePersistenceConfig
+	stateStore        persistence.StateStore
+	pipelineID        string
+	lastState         *persistence.State
+}
+
+// NewGraphQLInputFromConfig creates a new GraphQL input module from configuration.
+//
+// Required config fields:
+//   - endpoint: The GraphQL endpoint URL
+//   - query or queryFile: The GraphQL query (inline or path to a .graphql file)
+//
+// Optional config fields:
+//   - variables: Static query variables (map)
+//   - connectionPath: Dot path to the Relay connection below "data" (e.g. "repository.issues").
+//     May be omitted when "data" has a single top-level field.
+//   - cursorVariable: Variable receiving the pagination cursor (default "after")
+//   - headers: Custom HTTP headers (map[string]string)
+//   - timeoutMs: Request timeout in milliseconds (default 30000)
+//   - retry: Retry configuration
+//   - rateLimit: Client-side rate limit (requestsPerSecond, burst), shared per host
+//   - statePersistence: State persistence configuration (timestamp.variable / id.variable)
+func NewGraphQLInputFromConfig(config *connector.ModuleConfig) (*GraphQLInput, error) {
+	if config == nil {
+		return nil, ErrNilConfig
+	}
+
+	endpoint, err := extractEndpoint(config)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := extractGraphQLQuery(config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := extractTimeout(config)
+	retryConfig := extractRetryConfig(config)
+
+	rateLimit, err := httpconfig.ExtractRateLimitConfig(config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	client := createHTTPClient(timeout, rateLimit)
+	authHandler, err := createAuthHandler(config, client)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, _ := config.Config["variables"].(map[string]interface{})
+	connectionPath, _ := config.Config["connectionPath"].(string)
+	cursorVariable, _ := config.Config["cursorVariable"].(string)
+	if cursorVariable == "" {
+		cursorVariable = defaultGraphQLCursorVariable
+	}
+
+	persistenceConfig := persistence.ParseStatePersistenceConfig(config.Config)
+
+	g := &GraphQLInput{
+		endpoint:          endpoint,
+		headers:           extractHeaders(config),
+		timeout:           timeout,
+		query:             query,
+		variables:         variables,
+		connectionPath:    connectionPath,
+		cursorVariable:    cursorVariable,
+		authHandler:       authHandler,
+		client:            client,
+		retryConfig:       retryConfig,
+		persistenceConfig: persistenceConfig,
+	}
+
+	// Initialize state store if persistence is enabled
+	if persistenceConfig != nil && persistenceConfig.IsEnabled() {
+		storagePath := persistenceConfig.StoragePath
+		if storagePath == "" {
+			storagePath = persistence.DefaultStatePath
+		}
+		g.stateStore = persistence.NewFileStateStore(storagePath)
+	}
+
+	logger.Debug("graphql input module created",
+		"endpoint", endpoint,
+		"timeout", timeout.String(),
+		"has_auth", authHandler != nil,
+		"connection_path", connectionPath,
+		"cursor_variable", cursorVariable,
+		"retry_max_attempts", retryConfig.MaxAttempts,
+	)
+
+	return g, nil
+}
+
+// extractGraphQLQuery extracts the query from "query" or loads it from "queryFile".
+func extractGraphQLQuery(cfg map[string]interface{}) (string, error) {
+	if query, ok := cfg["query"].(string); ok && strings.TrimSpace(query) != "" {
+		return query, nil
+	}
+
+	queryFile, ok := cfg["queryFile"].(string)
+	if !ok || queryFile == "" {
+		return "", ErrGraphQLMissingQuery
+	}
+	if err := pathutil.ValidateFilePath(queryFile); err != nil {
+		return "", fmt.Errorf("query file path: %w", err)
+	}
+	queryBytes, err := os.ReadFile(queryFile)
+	if err != nil {
+		return "", fmt.Errorf("reading query file %s: %w", queryFile, err)
+	}
+	if strings.TrimSpace(string(queryBytes)) == "" {
+		return "", ErrGraphQLMissingQuery
+	}
+	return string(queryBytes), nil
+}
+
+// Fetch executes the GraphQL query and follows Relay pagination until
+// pageInfo.hasNextPage is false. A cursor returned twice is an
+// ErrPaginationLoop, and more than maxPaginationPages pages an
+// ErrPaginationLimit: no records are returned, so that no state is persisted.
+//
+// Returns:
+//   - []map[string]interface{}: The nodes of the connection across all pages
+//   - error: Any error encountered during fetching
+func (g *GraphQLInput) Fetch(ctx context.Context) ([]map[string]interface{}, error) {
+	startTime := time.Now()
+	variables := g.buildVariablesWithState()
+
+	logger.Info("input fetch started",
+		"module_type", "graphql",
+		"endpoint", g.endpoint,
+		"timeout", g.timeout.String(),
+		"has_auth", g.authHandler != nil,
+		"has_state_persistence", g.persistenceConfig != nil && g.persistenceConfig.IsEnabled(),
+	)
+
+	var allRecords []map[string]interface{}
+	pages := 0
+	cursors := make(map[string]struct{})
+
+	for {
+		if pages >= maxPaginationPages {
+			// Returning the pages fetched so far would persist state past
+			// the records that were not fetched
+			return nil, g.fetchFailed(startTime, pages, fmt.Errorf("%w: %d pages fetched and pageInfo.hasNextPage is still true", ErrPaginationLimit, maxPaginationPages))
+		}
+		pages++
+
+		records, hasNextPage, endCursor, err := g.fetchPage(ctx, variables)
+		if err != nil {
+			return nil, g.fetchFailed(startTime, pages, err)
+		}
+
+		logger.Debug(logMsgPaginationPageFetched,
+			"module_type", "graphql",
+			"pagination_type", "relay",
+			"current_page", pages,
+			"has_next_page", hasNextPage,
+			"records_in_page", len(records),
+			"total_records_so_far", len(allRecords)+len(records),
+		)
+
+		allRecords = append(allRecords, records...)
+
+		if !hasNextPage {
+			break
+		}
+		if endCursor == "" {
+			logger.Warn("graphql pageInfo.hasNextPage is true but no cursor was returned; stopping pagination",
+				"module_type", "graphql",
+				"endpoint", g.endpoint,
+				"page", pages,
+			)
+			break
+		}
+		if _, repeated := cursors[endCursor]; repeated {
+			return nil, g.fetchFailed(startTime, pages, fmt.Errorf("%w: endCursor %q returned again on page %d", ErrPaginationLoop, endCursor, pages))
+		}
+		cursors[endCursor] = struct{}{}
+
+		variables[g.cursorVariable] = endCursor
+	}
+
+	logger.Info("input fetch completed",
+		"module_type", "graphql",
+		"endpoint", g.endpoint,
+		"record_count", len(allRecords),
+		"pages_fetched", pages,
+		"duration", time.Since(startTime),
+	)
+
+	return allRecords, nil
+}
+
+// fetchFailed logs a failed fetch and returns err.
+func (g *GraphQLInput) fetchFailed(startTime time.Time, page int, err error) error {
+	logger.Error("input fetch failed",
+		"module_type", "graphql",
+		"endpoint", g.endpoint,
+		"page", page,
+		"duration", time.Since(startTime),
+		"error", err.Error(),
+	)
+	return err
+}
+
+// buildVariablesWithState copies the configured variables and injects
+// state-based values (last timestamp / last ID) when state exists.
+func (g *GraphQLInput) buildVariablesWithState() map[string]interface{} {
+	variables := make(map[string]interface{}, len(g.variables)+3)
+	for k, v := range g.variables {
+		variables[k] = v
+	}
+
+	if g.persistenceConfig == nil || g.lastState == nil {
+		return variables
+	}
+
+	if g.persistenceConfig.TimestampEnabled() && g.persistenceConfig.Timestamp.Variable != "" && g.lastState.LastTimestamp != nil {
+		variables[g.persistenceConfig.Timestamp.Variable] = g.persistenceConfig.FormatTimestampSince(g.lastState)
+		logger.Debug("added timestamp variable for state persistence",
+			"pipeline_id", g.pipelineID,
+			"variable", g.persistenceConfig.Timestamp.Variable,
+			"value", g.persistenceConfig.FormatTimestampSince(g.lastState),
+		)
+	}
+
+	if g.persistenceConfig.IDEnabled() && g.persistenceConfig.ID.Variable != "" && g.lastState.LastID != nil {
+		variables[g.persistenceConfig.ID.Variable] = *g.lastState.LastID
+		logger.Debug("added ID variable for state persistence",
+			"pipeline_id", g.pipelineID,
+			"variable", g.persistenceConfig.ID.Variable,
+			"value", *g.lastState.LastID,
+		)
+	}
+
+	return variables
+}
+
+// fetchPage executes one GraphQL request and extracts the connection nodes and page info.
+func (g *GraphQLInput) fetchPage(ctx context.Context, variables map[string]interface{}) ([]map[string]interface{}, bool, string, error) {
+	payload, err := json.Marshal(graphQLRequest{Query: g.query, Variables: variables})
+	if err != nil {
+		return nil, false, "", fmt.Errorf("encoding graphql request: %w", err)
+	}
+
+	body, err := g.doRequestWithRetry(ctx, payload)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	var resp graphQLResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, false, "", fmt.Errorf("%w: %w", ErrJSONParse, err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, false, "", fmt.Errorf("%w: %s", ErrGraphQLResponse, joinGraphQLErrors(resp.Errors))
+	}
+
+	connection, err := g.extractConnection(resp.Data)
+	if err != nil {
+		return nil, false, "", err
+	}
+
+	records := extractConnectionNodes(connection)
+	hasNextPage, endCursor := extractPageInfo(connection)
+	return records, hasNextPage, endCursor, nil
+}
+
+// joinGraphQLErrors formats GraphQL errors into a single message.
+func joinGraphQLErrors(errs []graphQLError) string {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// extractConnection navigates from the "data" object to the Relay connection.
+// When no connectionPath is configured, "data" must have exactly one field.
+func (g *GraphQLInput) extractConnection(data map[string]interface{}) (map[string]interface{}, error) {
+	if data == nil {
+		return nil, fmt.Errorf("%w: response has no data", ErrGraphQLConnectionMissing)
+	}
+
+	if g.connectionPath == "" {
+		if len(data) != 1 {
+			return nil, fmt.Errorf("%w: connectionPath is required when data has %d fields", ErrGraphQLConnectionMissing, len(data))
+		}
+		for _, v := range data {
+			if conn, ok := v.(map[string]interface{}); ok {
+				return conn, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: data field is not an object", ErrGraphQLConnectionMissing)
+	}
+
+	value, ok := template.GetNestedValue(data, g.connectionPath)
+	if !ok {
+		return nil, fmt.Errorf("%w: path '%s'", ErrGraphQLConnectionMissing, g.connectionPath)
+	}
+	conn, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: path '%s' is not an object", ErrGraphQLConnectionMissing, g.connectionPath)
+	}
+	return conn, nil
+}
+
+// extractConnectionNodes returns edges[].node, falling back to the "nodes" shortcut.
+func extractConnectionNodes(connection map[string]interface{}) []map[string]interface{} {
+	records := []map[string]interface{}{}
+
+	if edges, ok := connection["edges"].([]interface{}); ok {
+		for _, edge := range edges {
+			edgeObj, ok := edge.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if node, ok := edgeObj["node"].(map[string]interface{}); ok {
+				records = append(records, node)
+			}
+		}
+		return records
+	}
+
+	if nodes, ok := connection["nodes"].([]interface{}); ok {
+		for _, node := range nodes {
+			if nodeObj, ok := node.(map[string]interface{}); ok {
+				records = append(records, nodeObj)
+			}
+		}
+	}
+
+	return records
+}
+
+// extractPageInfo returns pageInfo.hasNextPage and the cursor for the next page.
+// If pageInfo.endCursor is absent, the cursor of the last edge is used.
+func extractPageInfo(connection map[string]interface{}) (bool, string) {
+	pageInfo, ok := connection["pageInfo"].(map[string]interface{})
+	if !ok {
+		return false, ""
+	}
+
+	hasNextPage, _ := pageInfo["hasNextPage"].(bool)
+	endCursor, _ := pageInfo["endCursor"].(string)
+
+	if endCursor == "" {
+		if edges, ok := connection["edges"].([]interface{}); ok && len(edges) > 0 {
+			if last, ok := edges[len(edges)-1].(map[string]interface{}); ok {
+				endCursor, _ = last["cursor"].(string)
+			}
+		}
+	}
+
+	return hasNextPage, endCursor
+}
+
+// doRequestWithRetry executes a GraphQL POST request with retry logic.
+func (g *GraphQLInput) doRequestWithRetry(ctx context.Context, payload []byte) ([]byte, error) {
+	executor := errhandling.NewRetryExecutor(g.retryConfig)
+
+	result, err := executor.ExecuteWithCallback(ctx,
+		func(ctx context.Context) (interface{}, error) {
+			return g.doRequest(ctx, payload)
+		},
+		func(attempt int, err error, nextDelay time.Duration) {
+			if err != nil && nextDelay > 0 {
+				logger.Info("retrying http request",
+					"module_type", "graphql",
+					"endpoint", g.endpoint,
+					"attempt", attempt+1,
+					"max_attempts", g.retryConfig.MaxAttempts+1,
+					"next_delay", nextDelay.String(),
+					"error", err.Error(),
+					"error_category", errhandling.GetErrorCategory(err),
+					"retryable", errhandling.IsRetryable(err),
+				)
+			}
+		},
+	)
+
+	g.lastRetryInfo = retryInfoFromErrhandling(executor.GetRetryInfo())
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, ok := result.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type from retry executor")
+	}
+	return body, nil
+}
+
+// doRequest executes a single GraphQL POST request and returns the raw response body.
+func (g *GraphQLInput) doRequest(ctx context.Context, payload []byte) ([]byte, error) {
+	requestStart := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("creating http request: %w", err)
+	}
+
+	setRequestHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for key, value := range g.headers {
+		req.Header.Set(key, value)
+	}
+
+	if g.authHandler != nil {
+		if err := g.authHandler.ApplyAuth(ctx, req); err != nil {
+			return nil, fmt.Errorf("applying authentication: %w", err)
+		}
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		logger.Error("http request failed",
+			"module_type", "graphql",
+			"endpoint", g.endpoint,
+			"method", http.MethodPost,
+			"duration", time.Since(requestStart),
+			"error", err.Error(),
+		)
+		return nil, errhandling.ClassifyNetworkError(err)
+	}
+	defer closeResponseBody(resp, g.endpoint)
+
+	body, err := readResponseBody(resp, g.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, g.handleHTTPError(resp, body, requestStart)
+	}
+
+	logger.Debug("http request completed",
+		"module_type", "graphql",
+		"endpoint", g.endpoint,
+		"method", http.MethodPost,
+		"status_code", resp.StatusCode,
+		"duration", time.Since(requestStart),
+		"response_size", len(body),
+	)
+	return body, nil
+}
+
+// handleHTTPError classifies an HTTP error response (status >= 400).
+func (g *GraphQLInput) handleHTTPError(resp *http.Response, body []byte, startTime time.Time) error {
+	bodySnippet := truncateBodyForLogging(body)
+
+	logger.Error("http error response",
+		"module_type", "graphql",
+		"endpoint", g.endpoint,
+		"method", http.MethodPost,
+		"status_code", resp.StatusCode,
+		"status", resp.Status,
+		"duration", time.Since(startTime),
+		"response_body", bodySnippet,
+	)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		if invalidator, ok := g.authHandler.(interface{ InvalidateToken() }); ok {
+			invalidator.InvalidateToken()
+		}
+	}
+
+	classifiedErr := errhandling.ClassifyHTTPStatus(resp.StatusCode, bodySnippet)
+	classifiedErr.OriginalErr = &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Endpoint:   g.endpoint,
+		Message:    string(body),
+	}
+	return classifiedErr
+}
+
+// GetRetryInfo returns retry information from the last request (RetryInfoProvider).
+func (g *GraphQLInput) GetRetryInfo() *connector.RetryInfo {
+	return g.lastRetryInfo
+}
+
+// Close releases idle connections held by the HTTP client.
+func (g *GraphQLInput) Close() error {
+	if g.client != nil {
+		rt := g.client.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		if transport, ok := rt.(interface{ CloseIdleConnections() }); ok {
+			transport.CloseIdleConnections()
+		}
+	}
+	return nil
+}
+
+// SetPipelineID sets the pipeline ID for state persistence.
+func (g *GraphQLInput) SetPipelineID(pipelineID string) {
+	g.pipelineID = pipelineID
+}
+
+// SetStateStore sets the state store to use for persistence.
+func (g *GraphQLInput) SetStateStore(store persistence.StateStore) {
+	g.stateStore = store
+}
+
+// LoadState loads the last persisted state for this pipeline.
+// Returns nil, nil if no state exists or if persistence is disabled.
+func (g *GraphQLInput) LoadState() (*persistence.State, error) {
+	if g.stateStore == nil || g.pipelineID == "" {
+		return nil, nil
+	}
+
+	state, err := g.stateStore.Load(g.pipelineID)
+	if err != nil {
+		logger.Warn("failed to load state",
+			"pipeline_id", g.pipelineID,
+			"error", err.Error(),
+		)
+		return nil, err
+	}
+
+	g.lastState = state
+	return state, nil
+}
+
+// GetPersistenceConfig returns the state persistence configuration.
+func (g *GraphQLInput) GetPersistenceConfig() *persistence.StatePersistenceConfig {
+	return g.persistenceConfig
+}
+
+// GetLastState returns the last loaded state.
+func (g *GraphQLInput) GetLastState() *persistence.State {
+	return g.lastState
+}
@@ -0,0 +1,339 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// graphQLTestPage builds a Relay connection response for tests.
+func graphQLTestPage(ids []string, hasNextPage bool, endCursor string) map[string]interface{} {
+	edges := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		edges = append(edges, map[string]interface{}{
+			"cursor": "c-" + id,
+			"node":   map[string]interface{}{"id": id},
+		})
+	}
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"orders": map[string]interface{}{
+				"edges": edges,
+				"pageInfo": map[string]interface{}{
+					"hasNextPage": hasNextPage,
+					"endCursor":   endCursor,
+				},
+			},
+		},
+	}
+}
+
+// decodeGraphQLRequest decodes the request payload sent by the module.
+func decodeGraphQLRequest(t *testing.T, r *http.Request) graphQLRequest {
+	t.Helper()
+	var req graphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		t.Fatalf("failed to decode graphql request: %v", err)
+	}
+	return req
+}
+
+func TestGraphQLInput_Fetch_RelayPagination(t *testing.T) {
+	var afterValues []interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST method, got %s", r.Method)
+		}
+		req := decodeGraphQLRequest(t, r)
+		if req.Variables["first"] != float64(2) {
+			t.Errorf("expected static variable first=2, got %v", req.Variables["first"])
+		}
+		afterValues = append(afterValues, req.Variables["after"])
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Variables["after"] == nil {
+			_ = json.NewEncoder(w).Encode(graphQLTestPage([]string{"1", "2"}, true, "cursor-2"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(graphQLTestPage([]string{"3"}, false, "cursor-3"))
+	}))
+	defer server.Close()
+
+	module, err := NewGraphQLInputFromConfig(&connector.ModuleConfig{
+		Type: "graphql",
+		Config: map[string]interface{}{
+			"endpoint":       server.URL,
+			"query":          "query($first: Int, $after: String) { orders(first: $first, after: $after) { edges { node { id } } pageInfo { hasNextPage endCursor } } }",
+			"variables":      map[string]interface{}{"first": float64(2)},
+			"connectionPath": "orders",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewGraphQLInputFromConfig failed: %v", err)
+	}
+
+	records, err := module.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if records[2]["id"] != "3" {
+		t.Errorf("expected last record id '3', got %v", records[2]["id"])
+	}
+	if len(afterValues) != 2 || afterValues[0] != nil || afterValues[1] != "cursor-2" {
+		t.Errorf("unexpected after variables: %v", afterValues)
+	}
+}
+
+func TestGraphQLInput_Fetch_NodesShortcutAndCustomCursorVariable(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		req := decodeGraphQLRequest(t, r)
+		hasNext := req.Variables["cursor"] == nil
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"repository": map[string]interface{}{
+					"issues": map[string]interface{}{
+						"nodes":    []interface{}{map[string]interface{}{"number": float64(requests)}},
+						"pageInfo": map[string]interface{}{"hasNextPage": hasNext, "endCursor": "next"},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	module, err := NewGraphQLInputFromConfig(&connector.ModuleConfig{
+		Type: "graphql",
+		Config: map[string]interface{}{
+			"endpoint":       server.URL,
+			"query":          "query($cursor: String) { repository { issues(after: $cursor) { nodes { number } pageInfo { hasNextPage endCursor } } } }",
+			"connectionPath": "repository.issues",
+			"cursorVariable": "cursor",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewGraphQLInputFromConfig failed: %v", err)
+	}
+
+	records, err := module.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 2 || requests != 2 {
+		t.Errorf("expected 2 records over 2 requests, got %d records over %d requests", len(records), requests)
+	}
+}
+
+func TestGraphQLInput_Fetch_EndlessPagination(t *testing.T) {
+	tests := []struct {
+		name    string
+		cursor  func(request int) string
+		wantErr error
+	}{
+		{name: "repeated cursor", cursor: func(int) string { return "same" }, wantErr: ErrPaginationLoop},
+		{name: "page cap", cursor: func(request int) string { return fmt.Sprint("cursor-", request) }, wantErr: ErrPaginationLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				_ = json.NewEncoder(w).Encode(graphQLTestPage([]string{fmt.Sprint(requests)}, true, tt.cursor(requests)))
+			}))
+			defer server.Close()
+
+			module, err := NewGraphQLInputFromConfig(&connector.ModuleConfig{
+				Type: "graphql",
+				Config: map[string]interface{}{
+					"endpoint":       server.URL,
+					"query":          "query($after: String) { orders(after: $after) { edges { node { id } } pageInfo { hasNextPage endCursor } } }",
+					"connectionPath": "orders",
+				},
+			})
+			if err != nil {
+				t.Fatalf("NewGraphQLInputFromConfig failed: %v", err)
+			}
+
+			// No records are returned, so that no state is persisted past them
+			records, err := module.Fetch(context.Background())
+			if !errors.Is(err, tt.wantErr) || records != nil {
+				t.Fatalf("Fetch() = %d records, %v, want no records and %v", len(records), err, tt.wantErr)
+			}
+			if tt.wantErr == ErrPaginationLoop && requests != 2 {
+				t.Errorf("requests = %d, want pagination stopped on the second page", requests)
+			}
+		})
+	}
+}
+
+func TestGraphQLInput_Fetch_GraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":   nil,
+			"errors": []interface{}{map[string]interface{}{"message": "Field 'orders' doesn't exist"}},
+		})
+	}))
+	defer server.Close()
+
+	module, err := NewGraphQLInputFromConfig(&connector.ModuleConfig{
+		Type: "graphql",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"query":    "{ orders { edges { node { id } } } }",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewGraphQLInputFromConfig failed: %v", err)
+	}
+
+	_, err = module.Fetch(context.Background())
+	if !errors.Is(err, ErrGraphQLResponse) {
+		t.Fatalf("expected ErrGraphQLResponse, got %v", err)
+	}
+}
+
+func TestGraphQLInput_Fetch_AmbiguousConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"orders":    map[string]interface{}{"edges": []interface{}{}},
+				"customers": map[string]interface{}{"edges": []interface{}{}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	module, err := NewGraphQLInputFromConfig(&connector.ModuleConfig{
+		Type: "graphql",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"query":    "{ orders { edges { node { id } } } customers { edges { node { id } } } }",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewGraphQLInputFromConfig failed: %v", err)
+	}
+
+	_, err = module.Fetch(context.Background())
+	if !errors.Is(err, ErrGraphQLConnectionMissing) {
+		t.Fatalf("expected ErrGraphQLConnectionMissing, got %v", err)
+	}
+}
+
+func TestGraphQLInput_Fetch_StateVariablesAndAuth(t *testing.T) {
+	var receivedAuth string
+	var received graphQLRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAuth = r.Header.Get("Authorization")
+		received = decodeGraphQLRequest(t, r)
+		_ = json.NewEncoder(w).Encode(graphQLTestPage([]string{"10"}, false, ""))
+	}))
+	defer server.Close()
+
+	storeDir := t.TempDir()
+	store := persistence.NewFileStateStore(storeDir)
+	lastTimestamp := time.Date(2026, 1, 26, 10, 30, 0, 0, time.UTC)
+	lastID := "9"
+	if err := store.Save("graphql-pipeline", &persistence.State{
+		PipelineID:    "graphql-pipeline",
+		LastTimestamp: &lastTimestamp,
+		LastID:        &lastID,
+	}); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	module, err := NewGraphQLInputFromConfig(&connector.ModuleConfig{
+		Type: "graphql",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"query":    "query($updatedAfter: DateTime) { orders(updatedAfter: $updatedAfter) { edges { node { id } } } }",
+			"statePersistence": map[string]interface{}{
+				"timestamp":   map[string]interface{}{"enabled": true, "variable": "updatedAfter"},
+				"id":          map[string]interface{}{"enabled": true, "field": "id", "variable": "afterId"},
+				"storagePath": storeDir,
+			},
+		},
+		Authentication: &connector.AuthConfig{
+			Type:        "bearer",
+			Credentials: map[string]string{"token": "gql-token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewGraphQLInputFromConfig failed: %v", err)
+	}
+
+	module.SetPipelineID("graphql-pipeline")
+	if _, err := module.LoadState(); err != nil {
+		t.Fatalf("LoadState() returned error: %v", err)
+	}
+
+	records, err := module.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("expected 1 record, got %d", len(records))
+	}
+	if receivedAuth != "Bearer gql-token" {
+		t.Errorf("expected bearer auth header, got %q", receivedAuth)
+	}
+	if received.Variables["updatedAfter"] != "2026-01-26T10:30:00Z" {
+		t.Errorf("expected updatedAfter from state, got %v", received.Variables["updatedAfter"])
+	}
+	if received.Variables["afterId"] != "9" {
+		t.Errorf("expected afterId from state, got %v", received.Variables["afterId"])
+	}
+}
+
+func TestNewGraphQLInputFromConfig_QueryFile(t *testing.T) {
+	queryFile := filepath.Join(t.TempDir(), "orders.graphql")
+	if err := os.WriteFile(queryFile, []byte("{ orders { edges { node { id } } } }"), 0o600); err != nil {
+		t.Fatalf("failed to write query file: %v", err)
+	}
+
+	module, err := NewGraphQLInputFromConfig(&connector.ModuleConfig{
+		Type: "graphql",
+		Config: map[string]interface{}{
+			"endpoint":  "https://api.example.com/graphql",
+			"queryFile": queryFile,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewGraphQLInputFromConfig failed: %v", err)
+	}
+	if module.query != "{ orders { edges { node { id } } } }" {
+		t.Errorf("unexpected query loaded from file: %q", module.query)
+	}
+	if module.cursorVariable != defaultGraphQLCursorVariable {
+		t.Errorf("expected default cursor variable %q, got %q", defaultGraphQLCursorVariable, module.cursorVariable)
+	}
+}
+
+func TestNewGraphQLInputFromConfig_MissingQuery(t *testing.T) {
+	_, err := NewGraphQLInputFromConfig(&connector.ModuleConfig{
+		Type:   "graphql",
+		Config: map[string]interface{}{"endpoint": "https://api.example.com/graphql"},
+	})
+	if !errors.Is(err, ErrGraphQLMissingQuery) {
+		t.Fatalf("expected ErrGraphQLMissingQuery, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@
 package input
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,14 +11,21 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"strconv"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/expr-lang/expr/vm"
+
 	"github.com/cannectors/runtime/internal/auth"
 	"github.com/cannectors/runtime/internal/errhandling"
 	"github.com/cannectors/runtime/internal/httpconfig"
 	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/pathutil"
 	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/internal/ratelimit"
+	"github.com/cannectors/runtime/internal/template"
 	"github.com/cannectors/runtime/pkg/connector"
 )
 
@@ -28,6 +36,18 @@ const (
 	maxPaginationPages = 1000 // Prevent infinite loops
 )
 
+// Pagination parameter locations
+const (
+	paginationLocationQuery = "query"
+	paginationLocationBody  = "body"
+)
+
+// Defaults for searchAfter pagination
+const (
+	defaultSearchAfterParam = "search_after"
+	defaultSortField        = "sort"
+)
+
 // Error messages
 const (
 	errMsgParsingEndpointURL = "parsing endpoint URL: %w"
@@ -47,6 +67,7 @@ var (
 	ErrHTTPRequest      = errors.New("http request failed")
 	ErrJSONParse        = errors.New("failed to parse JSON response")
 	ErrInvalidDataField = errors.New("dataField does not contain an array")
+	ErrInvalidBody      = errors.New("request body template must be a JSON object")
 )
 
 // HTTPError represents an HTTP error with status code and context
@@ -63,7 +84,8 @@ func (e *HTTPError) Error() string {
 
 // PaginationConfig holds pagination configuration
 type PaginationConfig struct {
-	Type            string // "page", "offset", "cursor"
+	Type            string // "page", "offset", "cursor", "searchAfter"
+	Location        string // "query" (default) or "body"
 	PageParam       string
 	TotalPagesField string
 	OffsetParam     string
@@ -72,14 +94,56 @@ type PaginationConfig struct {
 	TotalField      string
 	CursorParam     string
 	NextCursorField string
+
+	// searchAfter pagination: the sort values of the last record of a page
+	// are sent in SearchAfterParam to fetch the next page.
+	SearchAfterParam string
+	SortField        string
+
+	// Limits: pagination stops after MaxPages pages or MaxRecords records,
+	// or when the StopWhen expression is true for a page. Hitting a limit or
+	// detecting a loop (repeated cursor, identical page) stops cleanly when
+	// OnLimit is "stop" (default) and fails the fetch when it is "error".
+	MaxPages        int
+	MaxRecords      int
+	StopWhen        string
+	OnLimit         string
+	stopWhenProgram *vm.Program
+
+	// Concurrency bounds the number of pages fetched in parallel once the
+	// total is known from the first page (page and offset pagination).
+	// Defaults to 1 (sequential).
+	Concurrency int
+}
+
+// fetchTarget is the endpoint, body template and state used for one fetch.
+// Without forEach, a single target is built from the module configuration.
+type fetchTarget struct {
+	endpoint     string
+	bodyTemplate string
+	state        *persistence.State
+	conditional  *conditionalExchange // nil when conditional requests are disabled
+}
+
+// pageRequest describes a single HTTP request issued by the polling module.
+// body is nil for requests without a body (GET). method defaults to the
+// module's configured method when empty.
+type pageRequest struct {
+	endpoint    string
+	method      string
+	body        map[string]interface{}
+	conditional *conditionalExchange
 }
 
 // HTTPPolling implements polling-based HTTP data fetching.
-// It supports HTTP GET requests with authentication, pagination, and retry logic.
+// It supports HTTP GET requests, and POST requests with a JSON body template,
+// with authentication, pagination, and retry logic.
 // State persistence can be configured to track last timestamp and/or last ID
 // for reliable resumption after restarts.
 type HTTPPolling struct {
 	endpoint      string
+	method        string
+	bodyTemplate  string
 	headers       map[string]string
 	timeout       time.Duration
 	dataField     string
@@ -88,12 +152,29 @@ type HTTPPolling struct {
 	client        *http.Client
 	retryConfig   errhandling.RetryConfig
 	lastRetryInfo *connector.RetryInfo
+	forEach       *ForEachConfig
+	mu            sync.Mutex // guards lastRetryInfo and pendingStates during forEach fan-out
+
+	// Streaming: pages are handed to pageSink as they are fetched
+	streaming bool
+	pageSink  func(records []map[string]interface{}) error
+	resume    *persistence.Checkpoint // checkpoint the next stream resumes from
+	start     *persistence.Checkpoint // position the current stream started at
+	position  *persistence.Checkpoint // position after the last page delivered
+
+	// Backfill: time window read instead of resuming from the persisted state
+	window *persistence.Window
 
 	// State persistence
 	persistenceConfig *persistence.StatePersistenceConfig
-	stateStore        *persistence.StateStore
+	stateStore        persistence.StateStore
 	pipelineID        string
 	lastState         *persistence.State
+	forEachStates     map[string]*persistence.State // per-value state restored from the state keys (forEach)
+	pendingStates     map[string]*persistence.State // per-value state awaiting StateKeys (forEach)
+	forEachStartedAt  time.Time                     // start of the last forEach fetch
+	etag              string                        // ETag of the last fetch (conditional requests)
+	lastModified      string                        // Last-Modified of the last fetch (conditional requests)
 }
 
 // NewHTTPPollingFromConfig creates a new HTTP polling input module from configuration.
@@ -107,6 +188,17 @@ type HTTPPolling struct {
 //   - timeoutMs: Request timeout in milliseconds (default 30000). Also accepts timeout in seconds (float64) for backward compatibility.
 //   - dataField: JSON field containing the array of records (for object responses)
 //   - pagination: Pagination configuration (map with type, params, etc.)
+//   - method: GET (default) or POST
+//   - bodyTemplateFile: JSON request body sent with POST. Pagination values
+//     (pagination.location: body) and state values (statePersistence bodyField)
+//     are injected into its fields.
+//   - forEach: Fan-out over a list of values (static, file or preliminary request).
+//     {{forEach.value}} is evaluated in the endpoint and body template per value.
+//   - rateLimit: Client-side rate limit (requestsPerSecond, burst), shared per host
+//   - streaming: Hand each page downstream as it is fetched (requires pagination,
+//     not combined with forEach). Streamed runs are checkpointed per page.
+//   - statePersistence: Timestamp / ID state and conditional requests
+//     (ETag / Last-Modified, GET only; 304 Not Modified yields zero records).
 func NewHTTPPollingFromConfig(config *connector.ModuleConfig) (*HTTPPolling, error) {
 	if config == nil {
 		return nil, ErrNilConfig
@@ -120,10 +212,41 @@ func NewHTTPPollingFromConfig(config *connector.ModuleConfig) (*HTTPPolling, err
 	timeout := extractTimeout(config)
 	headers := extractHeaders(config)
 	dataField := extractDataField(config)
-	pagination := extractPagination(config)
+	pagination, err := extractPagination(config)
+	if err != nil {
+		return nil, err
+	}
 	retryConfig := extractRetryConfig(config)
 
-	client := createHTTPClient(timeout)
+	bodyTemplate, err := extractBodyTemplate(config)
+	if err != nil {
+		return nil, err
+	}
+	bodyPagination := pagination != nil && pagination.Location == paginationLocationBody
+	method, err := extractPollingMethod(config, bodyTemplate != "" || bodyPagination)
+	if err != nil {
+		return nil, err
+	}
+	if bodyPagination && method != http.MethodPost {
+		return nil, fmt.Errorf("pagination.location %q requires method POST, got %s", paginationLocationBody, method)
+	}
+
+	forEach, err := extractForEach(config)
+	if err != nil {
+		return nil, err
+	}
+
+	streaming, err := extractStreaming(config, pagination, forEach)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit, err := httpconfig.ExtractRateLimitConfig(config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	client := createHTTPClient(timeout, rateLimit)
 	authHandler, err := createAuthHandler(config, client)
 	if err != nil {
 		return nil, err
@@ -134,6 +257,8 @@ func NewHTTPPollingFromConfig(config *connector.ModuleConfig) (*HTTPPolling, err
 
 	h := &HTTPPolling{
 		endpoint:          endpoint,
+		method:            method,
+		bodyTemplate:      bodyTemplate,
 		headers:           headers,
 		timeout:           timeout,
 		dataField:         dataField,
@@ -141,6 +266,8 @@ func NewHTTPPollingFromConfig(config *connector.ModuleConfig) (*HTTPPolling, err
 		authHandler:       authHandler,
 		client:            client,
 		retryConfig:       retryConfig,
+		forEach:           forEach,
+		streaming:         streaming,
 		persistenceConfig: persistenceConfig,
 	}
 
@@ -150,7 +277,7 @@ func NewHTTPPollingFromConfig(config *connector.ModuleConfig) (*HTTPPolling, err
 		if storagePath == "" {
 			storagePath = persistence.DefaultStatePath
 		}
-		h.stateStore = persistence.NewStateStore(storagePath)
+		h.stateStore = persistence.NewFileStateStore(storagePath)
 
 		logger.Debug("state persistence enabled for HTTP polling module",
 			"endpoint", endpoint,
@@ -192,11 +319,61 @@ func extractDataField(config *connector.ModuleConfig) string {
 }
 
 // extractPagination extracts pagination config from config.
-func extractPagination(config *connector.ModuleConfig) *PaginationConfig {
+func extractPagination(config *connector.ModuleConfig) (*PaginationConfig, error) {
 	if paginationVal, ok := config.Config["pagination"].(map[string]interface{}); ok {
 		return parsePaginationConfig(paginationVal)
 	}
-	return nil
+	return nil, nil
+}
+
+// extractBodyTemplate loads the request body template configured via bodyTemplateFile
+// (at module level or under "request"). The template must be a JSON object.
+func extractBodyTemplate(config *connector.ModuleConfig) (string, error) {
+	btc := httpconfig.ExtractBodyTemplateConfig(config.Config)
+	if btc.BodyTemplateFile == "" {
+		btc = httpconfig.ExtractBodyTemplateConfigFromRequest(config.Config)
+	}
+	if btc.BodyTemplateFile == "" {
+		return "", nil
+	}
+
+	if err := pathutil.ValidateFilePath(btc.BodyTemplateFile); err != nil {
+		return "", fmt.Errorf("body template file path: %w", err)
+	}
+	content, err := os.ReadFile(btc.BodyTemplateFile)
+	if err != nil {
+		return "", fmt.Errorf("loading body template file %q: %w", btc.BodyTemplateFile, err)
+	}
+
+	// Templates with forEach placeholders are only valid JSON once evaluated
+	if template.HasVariables(string(content)) {
+		if err := template.ValidateSyntax(string(content)); err != nil {
+			return "", fmt.Errorf("invalid template syntax in %q: %w", btc.BodyTemplateFile, err)
+		}
+		return string(content), nil
+	}
+
+	var probe map[string]interface{}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidBody, btc.BodyTemplateFile, err)
+	}
+	return string(content), nil
+}
+
+// extractPollingMethod returns the HTTP method for polling requests.
+// Defaults to POST when a request body is used, GET otherwise.
+func extractPollingMethod(config *connector.ModuleConfig, hasBody bool) (string, error) {
+	method := strings.ToUpper(httpconfig.ExtractBaseConfig(config).Method)
+	if method == "" {
+		if hasBody {
+			return http.MethodPost, nil
+		}
+		return http.MethodGet, nil
+	}
+	if err := httpconfig.ValidateMethod(method, []string{http.MethodGet, http.MethodPost}); err != nil {
+		return "", err
+	}
+	return method, nil
 }
 
 // extractRetryConfig extracts retry configuration from config.
@@ -208,10 +385,15 @@ func extractRetryConfig(config *connector.ModuleConfig) errhandling.RetryConfig
 }
 
 // createHTTPClient creates an HTTP client with the configured timeout.
-func createHTTPClient(timeout time.Duration) *http.Client {
-	return &http.Client{
+// If rateLimit is set, requests are paced by the shared limiter of their host.
+func createHTTPClient(timeout time.Duration, rateLimit *ratelimit.Config) *http.Client {
+	client := &http.Client{
 		Timeout: timeout,
 	}
+	if rateLimit != nil {
+		ratelimit.WrapClient(client, *rateLimit)
+	}
+	return client
 }
 
 // createAuthHandler creates an authentication handler if configured.
@@ -238,12 +420,20 @@ func logModuleCreation(endpoint string, timeout time.Duration, authHandler auth.
 }
 
 // parsePaginationConfig extracts pagination configuration from map
-func parsePaginationConfig(config map[string]interface{}) *PaginationConfig {
+func parsePaginationConfig(config map[string]interface{}) (*PaginationConfig, error) {
 	p := &PaginationConfig{}
 
 	if t, ok := config["type"].(string); ok {
 		p.Type = t
 	}
+	// searchAfter values are arrays and can only be sent in a JSON body
+	p.Location = paginationLocationQuery
+	if p.Type == "searchAfter" {
+		p.Location = paginationLocationBody
+	}
+	if location, ok := config["location"].(string); ok && location != "" {
+		p.Location = location
+	}
 
 	// Page-based pagination
 	if param, ok := config["pageParam"].(string); ok {
@@ -275,7 +465,23 @@ func parsePaginationConfig(config map[string]interface{}) *PaginationConfig {
 		p.NextCursorField = field
 	}
 
-	return p
+	// searchAfter pagination
+	p.SearchAfterParam = defaultSearchAfterParam
+	if param, ok := config["searchAfterParam"].(string); ok && param != "" {
+		p.SearchAfterParam = param
+	}
+	p.SortField = defaultSortField
+	if field, ok := config["sortField"].(string); ok && field != "" {
+		p.SortField = field
+	}
+
+	if err := parsePaginationLimits(p, config); err != nil {
+		return nil, err
+	}
+	if err := parsePaginationConcurrency(p, config); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 // Fetch retrieves data via HTTP polling.
@@ -291,38 +497,49 @@ func parsePaginationConfig(config map[string]interface{}) *PaginationConfig {
 //   - []map[string]interface{}: The fetched records
 //   - error: Any error encountered during fetching
 func (h *HTTPPolling) Fetch(ctx context.Context) ([]map[string]interface{}, error) {
-	startTime := time.Now()
-
-	// Build endpoint with state-based query params if applicable
-	endpoint, err := h.buildEndpointWithState(h.endpoint)
-	if err != nil {
-		logger.Error("failed to build endpoint with state params",
-			"module_type", "httpPolling",
-			"endpoint", h.endpoint,
-			"error", err.Error(),
-		)
-		return nil, fmt.Errorf("building endpoint with state: %w", err)
+	if h.streaming {
+		records := []map[string]interface{}{}
+		err := h.Stream(ctx, func(_ context.Context, page []map[string]interface{}) error {
+			records = append(records, page...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return records, nil
 	}
 
+	startTime := time.Now()
+
 	// Log fetch start with configuration summary
 	logger.Info("input fetch started",
 		"module_type", "httpPolling",
-		"endpoint", endpoint,
-		"original_endpoint", h.endpoint,
+		"endpoint", h.endpoint,
+		"method", h.method,
 		"timeout", h.timeout.String(),
 		"has_pagination", h.pagination != nil,
+		"has_for_each", h.forEach != nil,
 		"has_auth", h.authHandler != nil,
 		"has_state_persistence", h.persistenceConfig != nil && h.persistenceConfig.IsEnabled(),
 	)
 
 	var records []map[string]interface{}
+	var err error
 
-	// Handle pagination if configured
-	if h.pagination != nil {
-		records, err = h.fetchWithPagination(ctx)
+	if h.forEach != nil {
+		// One fetch per forEach value, results merged
+		records, err = h.fetchForEach(ctx)
 	} else {
-		// Single request without pagination
-		records, err = h.fetchSingle(ctx, endpoint)
+		target := fetchTarget{
+			endpoint:     h.endpoint,
+			bodyTemplate: h.bodyTemplate,
+			state:        h.lastState,
+			conditional:  h.newConditionalExchange(h.lastState),
+		}
+		records, err = h.fetchTarget(ctx, target)
+		if err == nil && target.conditional != nil {
+			h.etag, h.lastModified = target.conditional.validators()
+		}
 	}
 
 	duration := time.Since(startTime)
@@ -349,12 +566,56 @@ func (h *HTTPPolling) Fetch(ctx context.Context) ([]map[string]interface{}, erro
 	return records, nil
 }
 
-// doRequest executes an HTTP GET request and returns the raw response body
-func (h *HTTPPolling) doRequest(ctx context.Context, endpoint string) ([]byte, error) {
+// fetchTarget fetches all records of one target, following pagination if configured.
+func (h *HTTPPolling) fetchTarget(ctx context.Context, t fetchTarget) ([]map[string]interface{}, error) {
+	// Build endpoint (and body) with state-based params if applicable
+	base, err := h.buildBaseRequest(t)
+	if err != nil {
+		logger.Error("failed to build endpoint with state params",
+			"module_type", "httpPolling",
+			"endpoint", t.endpoint,
+			"error", err.Error(),
+		)
+		return nil, fmt.Errorf("building endpoint with state: %w", err)
+	}
+
+	if base.endpoint != t.endpoint {
+		logger.Debug("endpoint built with state params",
+			"module_type", "httpPolling",
+			"endpoint", base.endpoint,
+			"original_endpoint", t.endpoint,
+		)
+	}
+
+	base.conditional = t.conditional
+
+	var records []map[string]interface{}
+	if h.pagination != nil {
+		// Handle pagination if configured
+		records, err = h.fetchWithPagination(ctx, base, newPaginationGuard(h.pagination, t.state))
+	} else {
+		// Single request without pagination
+		records, err = h.fetchSingle(ctx, base)
+	}
+
+	if errors.Is(err, errNotModified) {
+		logger.Info("resource not modified since last execution",
+			"module_type", "httpPolling",
+			"endpoint", base.endpoint,
+		)
+		return []map[string]interface{}{}, nil
+	}
+	return records, err
+}
+
+// doRequest executes an HTTP request and returns the raw response body
+func (h *HTTPPolling) doRequest(ctx context.Context, pr pageRequest) ([]byte, error) {
 	requestStart := time.Now()
-	logRequestStart(endpoint)
+	endpoint := pr.endpoint
+	method := h.requestMethod(pr)
+	logRequestStart(endpoint, method)
 
-	req, err := h.buildRequest(ctx, endpoint)
+	req, err := h.buildRequest(ctx, pr)
 	if err != nil {
 		return nil, err
 	}
@@ -374,13 +635,38 @@ func (h *HTTPPolling) doRequest(ctx context.Context, endpoint string) ([]byte, e
 		return nil, err
 	}
 
-	logRequestSuccess(endpoint, resp.StatusCode, requestStart, len(body))
+	if pr.conditional.capture(resp) {
+		logRequestSuccess(endpoint, method, resp.StatusCode, requestStart, 0)
+		return nil, errNotModified
+	}
+
+	logRequestSuccess(endpoint, method, resp.StatusCode, requestStart, len(body))
 	return body, nil
 }
 
+// requestMethod returns the HTTP method for a request.
+func (h *HTTPPolling) requestMethod(pr pageRequest) string {
+	if pr.method != "" {
+		return pr.method
+	}
+	return h.method
+}
+
 // buildRequest creates and configures the HTTP request.
-func (h *HTTPPolling) buildRequest(ctx context.Context, endpoint string) (*http.Request, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+// The JSON body, if any, is encoded from pr.body.
+func (h *HTTPPolling) buildRequest(ctx context.Context, pr pageRequest) (*http.Request, error) {
+	endpoint := pr.endpoint
+
+	var body io.Reader
+	if pr.body != nil {
+		payload, err := json.Marshal(pr.body)
+		if err != nil {
+			return nil, fmt.Errorf("encoding request body: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, h.requestMethod(pr), endpoint, body)
 	if err != nil {
 		logger.Error("http request creation failed",
 			"module_type", "httpPolling",
@@ -391,9 +677,13 @@ func (h *HTTPPolling) buildRequest(ctx context.Context, endpoint string) (*http.
 	}
 
 	setRequestHeaders(req)
+	if pr.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	for key, value := range h.headers {
 		req.Header.Set(key, value)
 	}
+	pr.conditional.apply(req)
 
 	if err := h.applyAuthentication(ctx, req); err != nil {
 		logger.Error("authentication failed",
@@ -421,7 +711,7 @@ func (h *HTTPPolling) executeRequest(req *http.Request, endpoint string, startTi
 		logger.Error("http request failed",
 			"module_type", "httpPolling",
 			"endpoint", endpoint,
-			"method", http.MethodGet,
+			"method", req.Method,
 			"duration", requestDuration,
 			"error", err.Error(),
 		)
@@ -468,7 +758,7 @@ func (h *HTTPPolling) handleHTTPError(resp *http.Response, body []byte, endpoint
 	logger.Error("http error response",
 		"module_type", "httpPolling",
 		"endpoint", endpoint,
-		"method", http.MethodGet,
+		"method", resp.Request.Method,
 		"status_code", resp.StatusCode,
 		"status", resp.Status,
 		"duration", requestDuration,
@@ -514,20 +804,20 @@ func (h *HTTPPolling) handleOAuth2Unauthorized(resp *http.Response, endpoint str
 }
 
 // logRequestStart logs the start of an HTTP request.
-func logRequestStart(endpoint string) {
+func logRequestStart(endpoint, method string) {
 	logger.Debug("http request started",
 		"module_type", "httpPolling",
 		"endpoint", endpoint,
-		"method", http.MethodGet,
+		"method", method,
 	)
 }
 
 // logRequestSuccess logs a successful HTTP request.
-func logRequestSuccess(endpoint string, statusCode int, startTime time.Time, bodySize int) {
+func logRequestSuccess(endpoint, method string, statusCode int, startTime time.Time, bodySize int) {
 	logger.Debug("http request completed",
 		"module_type", "httpPolling",
 		"endpoint", endpoint,
-		"method", http.MethodGet,
+		"method", method,
 		"status_code", statusCode,
 		"duration", time.Since(startTime),
 		"response_size", bodySize,
@@ -536,12 +826,20 @@ func logRequestSuccess(endpoint string, statusCode int, startTime time.Time, bod
 
 // doRequestWithRetry executes an HTTP request with retry logic.
 // It uses the RetryExecutor to retry transient errors.
-func (h *HTTPPolling) doRequestWithRetry(ctx context.Context, endpoint string) ([]byte, error) {
+func (h *HTTPPolling) doRequestWithRetry(ctx context.Context, pr pageRequest) ([]byte, error) {
 	executor := errhandling.NewRetryExecutor(h.retryConfig)
+	endpoint := pr.endpoint
 
+	notModified := false
 	result, err := executor.ExecuteWithCallback(ctx,
 		func(ctx context.Context) (interface{}, error) {
-			return h.doRequest(ctx, endpoint)
+			body, err := h.doRequest(ctx, pr)
+			if errors.Is(err, errNotModified) {
+				// Not a failure: stop without retrying
+				notModified = true
+				return []byte(nil), nil
+			}
+			return body, err
 		},
 		func(attempt int, err error, nextDelay time.Duration) {
 			if err != nil && nextDelay > 0 {
@@ -560,7 +858,7 @@ func (h *HTTPPolling) doRequestWithRetry(ctx context.Context, endpoint string) (
 	)
 
 	info := executor.GetRetryInfo()
-	h.lastRetryInfo = retryInfoFromErrhandling(info)
+	h.setLastRetryInfo(retryInfoFromErrhandling(info))
 
 	if err != nil {
 		if info.RetryCount > 0 {
@@ -575,6 +873,10 @@ func (h *HTTPPolling) doRequestWithRetry(ctx context.Context, endpoint string) (
 		return nil, err
 	}
 
+	if notModified {
+		return nil, errNotModified
+	}
+
 	body, ok := result.([]byte)
 	if !ok {
 		return nil, fmt.Errorf("unexpected result type from retry executor")
@@ -595,9 +897,18 @@ func (h *HTTPPolling) doRequestWithRetry(ctx context.Context, endpoint string) (
 
 // GetRetryInfo returns retry information from the last Fetch request (RetryInfoProvider).
 func (h *HTTPPolling) GetRetryInfo() *connector.RetryInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	return h.lastRetryInfo
 }
 
+// setLastRetryInfo records retry information; safe for concurrent forEach requests.
+func (h *HTTPPolling) setLastRetryInfo(info *connector.RetryInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastRetryInfo = info
+}
+
 func retryInfoFromErrhandling(info errhandling.RetryInfo) *connector.RetryInfo {
 	out := &connector.RetryInfo{
 		TotalAttempts: info.TotalAttempts,
@@ -609,9 +920,9 @@ func retryInfoFromErrhandling(info errhandling.RetryInfo) *connector.RetryInfo {
 	return out
 }
 
-// fetchSingle executes a single HTTP GET request and returns the records
-func (h *HTTPPolling) fetchSingle(ctx context.Context, endpoint string) ([]map[string]interface{}, error) {
-	body, err := h.doRequestWithRetry(ctx, endpoint)
+// fetchSingle executes a single HTTP request and returns the records
+func (h *HTTPPolling) fetchSingle(ctx context.Context, pr pageRequest) ([]map[string]interface{}, error) {
+	body, err := h.doRequestWithRetry(ctx, pr)
 	if err != nil {
 		return nil, err
 	}
@@ -650,9 +961,10 @@ func (h *HTTPPolling) parseResponse(body []byte) ([]map[string]interface{}, erro
 	return []map[string]interface{}{objectResult}, nil
 }
 
-// extractDataFromField extracts array data from a specific field in the response object
+// extractDataFromField extracts array data from a specific field in the response object.
+// The field supports dot notation for nested arrays (e.g. "hits.hits").
 func (h *HTTPPolling) extractDataFromField(obj map[string]interface{}, field string) ([]map[string]interface{}, error) {
-	data, ok := obj[field]
+	data, ok := template.GetNestedValue(obj, field)
 	if !ok {
 		return nil, fmt.Errorf("%w: field '%s' not found", ErrInvalidDataField, field)
 	}
@@ -686,30 +998,31 @@ func (h *HTTPPolling) applyAuthentication(ctx context.Context, req *http.Request
 	return h.authHandler.ApplyAuth(ctx, req)
 }
 
-// fetchWithPagination handles paginated requests
-func (h *HTTPPolling) fetchWithPagination(ctx context.Context) ([]map[string]interface{}, error) {
-	// Get base endpoint with state params
-	baseEndpoint, err := h.buildEndpointWithState(h.endpoint)
-	if err != nil {
-		return nil, fmt.Errorf("building endpoint with state: %w", err)
-	}
-
+// fetchWithPagination handles paginated requests.
+// base is the first request, already carrying state-based params.
+// guard enforces the page and record limits and detects pagination loops.
+func (h *HTTPPolling) fetchWithPagination(ctx context.Context, base pageRequest, guard *paginationGuard) ([]map[string]interface{}, error) {
 	switch h.pagination.Type {
 	case "page":
-		return h.fetchPageBased(ctx, baseEndpoint)
+		return h.fetchPageBased(ctx, base, guard)
 	case "offset":
-		return h.fetchOffsetBased(ctx, baseEndpoint)
+		return h.fetchOffsetBased(ctx, base, guard)
 	case "cursor":
-		return h.fetchCursorBased(ctx, baseEndpoint)
+		return h.fetchCursorBased(ctx, base, guard)
+	case "searchAfter":
+		return h.fetchSearchAfterBased(ctx, base, guard)
 	default:
-		return h.fetchSingle(ctx, baseEndpoint)
+		return h.fetchSingle(ctx, base)
 	}
 }
 
 // fetchPageBased handles page-based pagination
-func (h *HTTPPolling) fetchPageBased(ctx context.Context, baseEndpoint string) ([]map[string]interface{}, error) {
+func (h *HTTPPolling) fetchPageBased(ctx context.Context, base pageRequest, guard *paginationGuard) ([]map[string]interface{}, error) {
 	var allRecords []map[string]interface{}
 	page := 1
+	if h.start != nil {
+		page = h.start.Page
+	}
 
 	logger.Debug(logMsgPaginationStarted,
 		"module_type", "httpPolling",
@@ -717,15 +1030,23 @@ func (h *HTTPPolling) fetchPageBased(ctx context.Context, baseEndpoint string) (
 		"page_param", h.pagination.PageParam,
 	)
 
-	for page <= maxPaginationPages {
-		// Build URL with page parameter
-		pageURL, err := h.buildPaginatedURLFrom(baseEndpoint, h.pagination.PageParam, strconv.Itoa(page))
+	for {
+		if stop, err := guard.beforePage(nil); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+
+		// Build request with page parameter
+		pageReq, err := h.withPageParams(base, map[string]interface{}{
+			h.pagination.PageParam: page,
+		})
 		if err != nil {
 			return nil, err
 		}
 
 		// Fetch page
-		records, totalPages, err := h.fetchPageWithMeta(ctx, pageURL, h.pagination.TotalPagesField)
+		records, totalPages, err := h.fetchPageWithMeta(ctx, pageReq, h.pagination.TotalPagesField)
 		if err != nil {
 			return nil, err
 		}
@@ -739,23 +1060,43 @@ func (h *HTTPPolling) fetchPageBased(ctx context.Context, baseEndpoint string) (
 			"total_records_so_far", len(allRecords)+len(records),
 		)
 
-		allRecords = append(allRecords, records...)
+		kept, stop, err := guard.afterPage(records)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.deliverPage(&allRecords, kept, &persistence.Checkpoint{Page: page + 1}); err != nil {
+			return nil, err
+		}
 
 		// Check if we've reached the last page
-		if totalPages > 0 && page >= totalPages {
+		if stop || (totalPages > 0 && page >= totalPages) {
 			break
 		}
 		if len(records) == 0 {
 			break
 		}
 
+		// Total known from the first page: fetch the remaining pages in parallel
+		if page == 1 && totalPages > 1 && h.parallelPages() {
+			rest, err := h.fetchRemainingPages(ctx, guard, totalPages-1, len(records), func(i int) (pageRequest, error) {
+				return h.withPageParams(base, map[string]interface{}{
+					h.pagination.PageParam: i + 2,
+				})
+			})
+			if err != nil {
+				return nil, err
+			}
+			allRecords = append(allRecords, rest...)
+			break
+		}
+
 		page++
 	}
 
 	logger.Info(logMsgPaginationCompleted,
 		"module_type", "httpPolling",
 		"pagination_type", "page",
-		"pages_fetched", page,
+		"pages_fetched", guard.pages,
 		"total_records", len(allRecords),
 	)
 
@@ -764,8 +1105,8 @@ func (h *HTTPPolling) fetchPageBased(ctx context.Context, baseEndpoint string) (
 
 // fetchAndParseObject fetches an endpoint and parses response as JSON object.
 // Returns the parsed JSON object or an error if parsing fails.
-func (h *HTTPPolling) fetchAndParseObject(ctx context.Context, endpoint string) (map[string]interface{}, error) {
-	body, err := h.doRequest(ctx, endpoint)
+func (h *HTTPPolling) fetchAndParseObject(ctx context.Context, pr pageRequest) (map[string]interface{}, error) {
+	body, err := h.doRequest(ctx, pr)
 	if err != nil {
 		return nil, err
 	}
@@ -823,8 +1164,8 @@ func (h *HTTPPolling) extractRecordsFromObject(obj map[string]interface{}) ([]ma
 }
 
 // fetchPageWithMeta fetches a page and extracts metadata
-func (h *HTTPPolling) fetchPageWithMeta(ctx context.Context, endpoint, totalPagesField string) ([]map[string]interface{}, int, error) {
-	obj, err := h.fetchAndParseObject(ctx, endpoint)
+func (h *HTTPPolling) fetchPageWithMeta(ctx context.Context, pr pageRequest, totalPagesField string) ([]map[string]interface{}, int, error) {
+	obj, err := h.fetchAndParseObject(ctx, pr)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -838,14 +1179,16 @@ func (h *HTTPPolling) fetchPageWithMeta(ctx context.Context, endpoint, totalPage
 }
 
 // fetchOffsetBased handles offset-based pagination
-func (h *HTTPPolling) fetchOffsetBased(ctx context.Context, baseEndpoint string) ([]map[string]interface{}, error) {
+func (h *HTTPPolling) fetchOffsetBased(ctx context.Context, base pageRequest, guard *paginationGuard) ([]map[string]interface{}, error) {
 	var allRecords []map[string]interface{}
 	offset := 0
+	if h.start != nil {
+		offset = h.start.Offset
+	}
 	limit := h.pagination.Limit
 	if limit == 0 {
 		limit = 100 // Default limit
 	}
-	pageNum := 0
 
 	logger.Debug(logMsgPaginationStarted,
 		"module_type", "httpPolling",
@@ -855,19 +1198,23 @@ func (h *HTTPPolling) fetchOffsetBased(ctx context.Context, baseEndpoint string)
 		"limit", limit,
 	)
 
-	for offset < maxPaginationPages*limit {
-		pageNum++
-		// Build URL with offset and limit parameters
-		offsetURL, err := h.buildPaginatedURLMultiFrom(baseEndpoint, map[string]string{
-			h.pagination.OffsetParam: strconv.Itoa(offset),
-			h.pagination.LimitParam:  strconv.Itoa(limit),
+	for {
+		if stop, err := guard.beforePage(nil); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+		// Build request with offset and limit parameters
+		offsetReq, err := h.withPageParams(base, map[string]interface{}{
+			h.pagination.OffsetParam: offset,
+			h.pagination.LimitParam:  limit,
 		})
 		if err != nil {
 			return nil, err
 		}
 
 		// Fetch page
-		records, total, err := h.fetchOffsetWithMeta(ctx, offsetURL, h.pagination.TotalField)
+		records, total, err := h.fetchOffsetWithMeta(ctx, offsetReq, h.pagination.TotalField)
 		if err != nil {
 			return nil, err
 		}
@@ -882,23 +1229,44 @@ func (h *HTTPPolling) fetchOffsetBased(ctx context.Context, baseEndpoint string)
 			"total_records_so_far", len(allRecords)+len(records),
 		)
 
-		allRecords = append(allRecords, records...)
+		kept, stop, err := guard.afterPage(records)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.deliverPage(&allRecords, kept, &persistence.Checkpoint{Offset: offset + limit}); err != nil {
+			return nil, err
+		}
 
 		// Check if we've fetched all records
-		if total > 0 && len(allRecords) >= total {
+		if stop || (total > 0 && offset+len(records) >= total) {
 			break
 		}
 		if len(records) == 0 || len(records) < limit {
 			break
 		}
 
+		// Total known from the first page: fetch the remaining pages in parallel
+		if offset == 0 && total > limit && h.parallelPages() {
+			rest, err := h.fetchRemainingPages(ctx, guard, (total-1)/limit, limit, func(i int) (pageRequest, error) {
+				return h.withPageParams(base, map[string]interface{}{
+					h.pagination.OffsetParam: (i + 1) * limit,
+					h.pagination.LimitParam:  limit,
+				})
+			})
+			if err != nil {
+				return nil, err
+			}
+			allRecords = append(allRecords, rest...)
+			break
+		}
+
 		offset += limit
 	}
 
 	logger.Info(logMsgPaginationCompleted,
 		"module_type", "httpPolling",
 		"pagination_type", "offset",
-		"pages_fetched", pageNum,
+		"pages_fetched", guard.pages,
 		"total_records", len(allRecords),
 	)
 
@@ -906,8 +1274,8 @@ func (h *HTTPPolling) fetchOffsetBased(ctx context.Context, baseEndpoint string)
 }
 
 // fetchOffsetWithMeta fetches with offset pagination and extracts metadata
-func (h *HTTPPolling) fetchOffsetWithMeta(ctx context.Context, endpoint, totalField string) ([]map[string]interface{}, int, error) {
-	obj, err := h.fetchAndParseObject(ctx, endpoint)
+func (h *HTTPPolling) fetchOffsetWithMeta(ctx context.Context, pr pageRequest, totalField string) ([]map[string]interface{}, int, error) {
+	obj, err := h.fetchAndParseObject(ctx, pr)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -921,9 +1289,12 @@ func (h *HTTPPolling) fetchOffsetWithMeta(ctx context.Context, endpoint, totalFi
 }
 
 // fetchCursorBased handles cursor-based pagination
-func (h *HTTPPolling) fetchCursorBased(ctx context.Context, baseEndpoint string) ([]map[string]interface{}, error) {
+func (h *HTTPPolling) fetchCursorBased(ctx context.Context, base pageRequest, guard *paginationGuard) ([]map[string]interface{}, error) {
 	var allRecords []map[string]interface{}
 	cursor := ""
+	if h.start != nil {
+		cursor = h.start.PageCursor
+	}
 	iterations := 0
 
 	logger.Debug(logMsgPaginationStarted,
@@ -932,21 +1303,27 @@ func (h *HTTPPolling) fetchCursorBased(ctx context.Context, baseEndpoint string)
 		"cursor_param", h.pagination.CursorParam,
 	)
 
-	for iterations < maxPaginationPages {
-		// Build URL with cursor parameter (only if we have a cursor)
-		var fetchURL string
-		var err error
-		if cursor == "" {
-			fetchURL = baseEndpoint
-		} else {
-			fetchURL, err = h.buildPaginatedURLFrom(baseEndpoint, h.pagination.CursorParam, cursor)
+	for {
+		if stop, err := guard.beforePage(cursor); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+
+		// Build request with cursor parameter (only if we have a cursor)
+		fetchReq := base
+		if cursor != "" {
+			var err error
+			fetchReq, err = h.withPageParams(base, map[string]interface{}{
+				h.pagination.CursorParam: cursor,
+			})
 			if err != nil {
 				return nil, err
 			}
 		}
 
 		// Fetch page
-		records, nextCursor, err := h.fetchCursorWithMeta(ctx, fetchURL, h.pagination.NextCursorField)
+		records, nextCursor, err := h.fetchCursorWithMeta(ctx, fetchReq, h.pagination.NextCursorField)
 		if err != nil {
 			return nil, err
 		}
@@ -960,10 +1337,20 @@ func (h *HTTPPolling) fetchCursorBased(ctx context.Context, baseEndpoint string)
 			"total_records_so_far", len(allRecords)+len(records),
 		)
 
-		allRecords = append(allRecords, records...)
+		kept, stop, err := guard.afterPage(records)
+		if err != nil {
+			return nil, err
+		}
+		var next *persistence.Checkpoint
+		if nextCursor != "" {
+			next = &persistence.Checkpoint{PageCursor: nextCursor}
+		}
+		if err := h.deliverPage(&allRecords, kept, next); err != nil {
+			return nil, err
+		}
 
 		// Check if we've reached the end
-		if nextCursor == "" {
+		if stop || nextCursor == "" {
 			break
 		}
 
@@ -982,8 +1369,8 @@ func (h *HTTPPolling) fetchCursorBased(ctx context.Context, baseEndpoint string)
 }
 
 // fetchCursorWithMeta fetches with cursor pagination and extracts next cursor
-func (h *HTTPPolling) fetchCursorWithMeta(ctx context.Context, endpoint, nextCursorField string) ([]map[string]interface{}, string, error) {
-	obj, err := h.fetchAndParseObject(ctx, endpoint)
+func (h *HTTPPolling) fetchCursorWithMeta(ctx context.Context, pr pageRequest, nextCursorField string) ([]map[string]interface{}, string, error) {
+	obj, err := h.fetchAndParseObject(ctx, pr)
 	if err != nil {
 		return nil, "", err
 	}
@@ -996,19 +1383,135 @@ func (h *HTTPPolling) fetchCursorWithMeta(ctx context.Context, endpoint, nextCur
 	return records, extractStringField(obj, nextCursorField), nil
 }
 
-// buildPaginatedURLFrom adds a query parameter to the given base URL.
-// Returns the modified URL or an error if the URL cannot be parsed.
-func (h *HTTPPolling) buildPaginatedURLFrom(baseURL, param, value string) (string, error) {
-	parsedURL, err := url.Parse(baseURL)
-	if err != nil {
-		return "", fmt.Errorf(errMsgParsingEndpointURL, err)
+// fetchSearchAfterBased handles search_after pagination (e.g. Elasticsearch):
+// the sort values of the last record of a page are sent to fetch the next page.
+func (h *HTTPPolling) fetchSearchAfterBased(ctx context.Context, base pageRequest, guard *paginationGuard) ([]map[string]interface{}, error) {
+	var allRecords []map[string]interface{}
+	var searchAfter interface{}
+	if h.start != nil {
+		searchAfter = h.start.SearchAfter
 	}
+	limit := h.pagination.Limit
+	iterations := 0
 
-	q := parsedURL.Query()
-	q.Set(param, value)
-	parsedURL.RawQuery = q.Encode()
+	logger.Debug(logMsgPaginationStarted,
+		"module_type", "httpPolling",
+		"pagination_type", "searchAfter",
+		"search_after_param", h.pagination.SearchAfterParam,
+		"sort_field", h.pagination.SortField,
+	)
 
-	return parsedURL.String(), nil
+	for {
+		if stop, err := guard.beforePage(searchAfter); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+
+		params := map[string]interface{}{}
+		if limit > 0 {
+			params[h.pagination.LimitParam] = limit
+		}
+		if searchAfter != nil {
+			params[h.pagination.SearchAfterParam] = searchAfter
+		}
+
+		pageReq, err := h.withPageParams(base, params)
+		if err != nil {
+			return nil, err
+		}
+
+		obj, err := h.fetchAndParseObject(ctx, pageReq)
+		if err != nil {
+			return nil, err
+		}
+		records, err := h.extractRecordsFromObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		iterations++
+
+		logger.Debug(logMsgPaginationPageFetched,
+			"module_type", "httpPolling",
+			"pagination_type", "searchAfter",
+			"iteration", iterations,
+			"records_in_page", len(records),
+			"total_records_so_far", len(allRecords)+len(records),
+		)
+
+		kept, stop, err := guard.afterPage(records)
+		if err != nil {
+			return nil, err
+		}
+		var next interface{}
+		if len(records) > 0 {
+			next, _ = template.GetNestedValue(records[len(records)-1], h.pagination.SortField)
+		}
+		var position *persistence.Checkpoint
+		if next != nil {
+			position = &persistence.Checkpoint{SearchAfter: next}
+		}
+		if err := h.deliverPage(&allRecords, kept, position); err != nil {
+			return nil, err
+		}
+
+		if stop || len(records) == 0 || (limit > 0 && len(records) < limit) {
+			break
+		}
+
+		if next == nil {
+			logger.Warn("last record has no sort values; stopping searchAfter pagination",
+				"module_type", "httpPolling",
+				"sort_field", h.pagination.SortField,
+			)
+			break
+		}
+		searchAfter = next
+	}
+
+	logger.Info(logMsgPaginationCompleted,
+		"module_type", "httpPolling",
+		"pagination_type", "searchAfter",
+		"iterations", iterations,
+		"total_records", len(allRecords),
+	)
+
+	return allRecords, nil
+}
+
+// withPageParams returns a copy of base with pagination parameters applied,
+// as query parameters or as fields of the JSON body depending on pagination.location.
+// Parameters with an empty name are ignored.
+func (h *HTTPPolling) withPageParams(base pageRequest, params map[string]interface{}) (pageRequest, error) {
+	if h.pagination != nil && h.pagination.Location == paginationLocationBody {
+		body := copyBody(base.body)
+		for path, value := range params {
+			if path == "" {
+				continue
+			}
+			if err := setBodyField(body, path, value); err != nil {
+				return pageRequest{}, err
+			}
+		}
+		pr := base
+		pr.body = body
+		return pr, nil
+	}
+
+	query := make(map[string]string, len(params))
+	for name, value := range params {
+		if name == "" {
+			continue
+		}
+		query[name] = template.ValueToString(value)
+	}
+	endpoint, err := h.buildPaginatedURLMultiFrom(base.endpoint, query)
+	if err != nil {
+		return pageRequest{}, err
+	}
+	pr := base
+	pr.endpoint = endpoint
+	return pr, nil
 }
 
 // buildPaginatedURLMultiFrom adds multiple query parameters to the given base URL.
@@ -1039,7 +1542,7 @@ func (h *HTTPPolling) Close() error {
 			rt = http.DefaultTransport
 		}
 
-		if transport, ok := rt.(*http.Transport); ok {
+		if transport, ok := rt.(interface{ CloseIdleConnections() }); ok {
 			transport.CloseIdleConnections()
 		}
 	}
@@ -1088,14 +1591,17 @@ func (h *HTTPPolling) GetLastState() *persistence.State {
 
 // SetStateStore sets the state store to use for persistence.
 // Overrides the state store created during module initialization.
-func (h *HTTPPolling) SetStateStore(store *persistence.StateStore) {
+func (h *HTTPPolling) SetStateStore(store persistence.StateStore) {
 	h.stateStore = store
 }
 
 // buildEndpointWithState builds the endpoint URL with state-based query parameters.
 // If state persistence is enabled and state exists, adds appropriate query params.
-func (h *HTTPPolling) buildEndpointWithState(endpoint string) (string, error) {
-	if h.persistenceConfig == nil || !h.persistenceConfig.IsEnabled() || h.lastState == nil {
+func (h *HTTPPolling) buildEndpointWithState(endpoint string, state *persistence.State) (string, error) {
+	if h.window != nil {
+		return h.buildEndpointWithWindow(endpoint)
+	}
+	if h.persistenceConfig == nil || !h.persistenceConfig.IsEnabled() || state == nil {
 		return endpoint, nil
 	}
 
@@ -1109,26 +1615,26 @@ func (h *HTTPPolling) buildEndpointWithState(endpoint string) (string, error) {
 
 	// Add timestamp query param if configured
 	if h.persistenceConfig.TimestampEnabled() && h.persistenceConfig.Timestamp.QueryParam != "" {
-		if h.lastState.LastTimestamp != nil {
-			q.Set(h.persistenceConfig.Timestamp.QueryParam, h.lastState.FormatTimestamp())
+		if state.LastTimestamp != nil {
+			q.Set(h.persistenceConfig.Timestamp.QueryParam, h.persistenceConfig.FormatTimestampSince(state))
 			modified = true
 			logger.Debug("added timestamp query param for state persistence",
 				"pipeline_id", h.pipelineID,
 				"param", h.persistenceConfig.Timestamp.QueryParam,
-				"value", h.lastState.FormatTimestamp(),
+				"value", h.persistenceConfig.FormatTimestampSince(state),
 			)
 		}
 	}
 
 	// Add ID query param if configured
 	if h.persistenceConfig.IDEnabled() && h.persistenceConfig.ID.QueryParam != "" {
-		if h.lastState.LastID != nil {
-			q.Set(h.persistenceConfig.ID.QueryParam, *h.lastState.LastID)
+		if state.LastID != nil {
+			q.Set(h.persistenceConfig.ID.QueryParam, *state.LastID)
 			modified = true
 			logger.Debug("added ID query param for state persistence",
 				"pipeline_id", h.pipelineID,
 				"param", h.persistenceConfig.ID.QueryParam,
-				"value", *h.lastState.LastID,
+				"value", *state.LastID,
 			)
 		}
 	}
@@ -1139,3 +1645,115 @@ func (h *HTTPPolling) buildEndpointWithState(endpoint string) (string, error) {
 
 	return parsedURL.String(), nil
 }
+
+// buildBaseRequest builds the first request of a fetch: the endpoint with
+// state-based query parameters and, for POST, the JSON body template with
+// state-based body fields.
+func (h *HTTPPolling) buildBaseRequest(t fetchTarget) (pageRequest, error) {
+	endpoint, err := h.buildEndpointWithState(t.endpoint, t.state)
+	if err != nil {
+		return pageRequest{}, err
+	}
+
+	pr := pageRequest{endpoint: endpoint}
+	if h.method != http.MethodPost {
+		return pr, nil
+	}
+
+	body := map[string]interface{}{}
+	if t.bodyTemplate != "" {
+		if err := json.Unmarshal([]byte(t.bodyTemplate), &body); err != nil {
+			return pageRequest{}, fmt.Errorf("%w: %w", ErrInvalidBody, err)
+		}
+	}
+	if err := h.applyStateToBody(body, t.state); err != nil {
+		return pageRequest{}, err
+	}
+	pr.body = body
+
+	return pr, nil
+}
+
+// applyStateToBody sets state-based values in the request body.
+// If state persistence is enabled and state exists, sets the configured body fields.
+func (h *HTTPPolling) applyStateToBody(body map[string]interface{}, state *persistence.State) error {
+	if h.window != nil {
+		return h.applyWindowToBody(body)
+	}
+	if h.persistenceConfig == nil || !h.persistenceConfig.IsEnabled() || state == nil {
+		return nil
+	}
+
+	if h.persistenceConfig.TimestampEnabled() && h.persistenceConfig.Timestamp.BodyField != "" && state.LastTimestamp != nil {
+		if err := setBodyField(body, h.persistenceConfig.Timestamp.BodyField, h.persistenceConfig.FormatTimestampSince(state)); err != nil {
+			return err
+		}
+		logger.Debug("added timestamp body field for state persistence",
+			"pipeline_id", h.pipelineID,
+			"field", h.persistenceConfig.Timestamp.BodyField,
+			"value", h.persistenceConfig.FormatTimestampSince(state),
+		)
+	}
+
+	if h.persistenceConfig.IDEnabled() && h.persistenceConfig.ID.BodyField != "" && state.LastID != nil {
+		if err := setBodyField(body, h.persistenceConfig.ID.BodyField, *state.LastID); err != nil {
+			return err
+		}
+		logger.Debug("added ID body field for state persistence",
+			"pipeline_id", h.pipelineID,
+			"field", h.persistenceConfig.ID.BodyField,
+			"value", *state.LastID,
+		)
+	}
+
+	return nil
+}
+
+// setBodyField sets a value in a JSON body using dot notation (e.g. "query.range.from"),
+// creating intermediate objects as needed.
+func setBodyField(body map[string]interface{}, path string, value interface{}) error {
+	parts := strings.Split(path, ".")
+	current := body
+	for _, part := range parts[:len(parts)-1] {
+		next, exists := current[part]
+		if !exists || next == nil {
+			child := map[string]interface{}{}
+			current[part] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: cannot set %q, %q is not an object", ErrInvalidBody, path, part)
+		}
+		current = child
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// copyBody returns a deep copy of a JSON body so that per-page changes
+// do not leak into the base request.
+func copyBody(body map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(body))
+	for k, v := range body {
+		out[k] = copyBodyValue(v)
+	}
+	return out
+}
+
+// copyBodyValue deep-copies a decoded JSON value.
+func copyBodyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return copyBody(val)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = copyBodyValue(item)
+		}
+		return out
+	default:
+		return val
+	}
+}
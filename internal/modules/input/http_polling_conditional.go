@@ -0,0 +1,97 @@
+// Package input provides implementations for input modules.
+// This file implements conditional requests (ETag / Last-Modified) for the HTTP polling module.
+package input
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cannectors/runtime/internal/persistence"
+)
+
+// errNotModified is returned internally when the server answers 304 Not Modified
+// to a conditional request. fetchTarget turns it into a zero-record fetch.
+var errNotModified = errors.New("resource not modified")
+
+// conditionalExchange carries the validators of one conditional fetch.
+// It is shared by the page requests of a target: only the first page is sent
+// with If-None-Match / If-Modified-Since, and only its response validators
+// are kept. Retries of the first page resend the validators.
+type conditionalExchange struct {
+	// Validators sent with the first request (from persisted state)
+	etag         string
+	lastModified string
+
+	// Validators received with the first response
+	respETag         string
+	respLastModified string
+
+	done        bool
+	notModified bool
+}
+
+// newConditionalExchange returns the conditional exchange for a fetch with the
+// given state. Returns nil if conditional requests are disabled. Conditional
+// headers are only sent with GET requests, and not by backfill windows.
+func (h *HTTPPolling) newConditionalExchange(state *persistence.State) *conditionalExchange {
+	if !h.persistenceConfig.ConditionalEnabled() || h.method != http.MethodGet || h.window != nil {
+		return nil
+	}
+	c := &conditionalExchange{}
+	if state != nil {
+		c.etag = state.ETag
+		c.lastModified = state.LastModified
+	}
+	return c
+}
+
+// apply sets the conditional headers on the first page request.
+func (c *conditionalExchange) apply(req *http.Request) {
+	if c == nil || c.done {
+		return
+	}
+	if c.etag != "" {
+		req.Header.Set("If-None-Match", c.etag)
+	}
+	if c.lastModified != "" {
+		req.Header.Set("If-Modified-Since", c.lastModified)
+	}
+}
+
+// capture records the validators of the first page response.
+// Returns true if the response is 304 Not Modified.
+func (c *conditionalExchange) capture(resp *http.Response) bool {
+	if c == nil || c.done {
+		return false
+	}
+	c.done = true
+	c.respETag = resp.Header.Get("ETag")
+	c.respLastModified = resp.Header.Get("Last-Modified")
+	c.notModified = resp.StatusCode == http.StatusNotModified
+	return c.notModified
+}
+
+// validators returns the ETag and Last-Modified values to persist after the fetch.
+// A 304 response without validators keeps the previously sent ones.
+func (c *conditionalExchange) validators() (etag, lastModified string) {
+	if c == nil {
+		return "", ""
+	}
+	etag, lastModified = c.respETag, c.respLastModified
+	if c.notModified {
+		if etag == "" {
+			etag = c.etag
+		}
+		if lastModified == "" {
+			lastModified = c.lastModified
+		}
+	}
+	return etag, lastModified
+}
+
+// ResponseValidators returns the ETag and Last-Modified response headers of the
+// last successful fetch. The runtime persists them in the pipeline state when
+// conditional requests are enabled.
+func (h *HTTPPolling) ResponseValidators() (etag, lastModified string) {
+	return h.etag, h.lastModified
+}
@@ -0,0 +1,152 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// conditionalTestConfig returns an httpPolling config with conditional requests enabled.
+func conditionalTestConfig(endpoint string) *connector.ModuleConfig {
+	return &connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": endpoint,
+			"statePersistence": map[string]interface{}{
+				"conditional": map[string]interface{}{"enabled": true},
+			},
+		},
+	}
+}
+
+func TestHTTPPolling_Conditional_NotModified(t *testing.T) {
+	var ifNoneMatch, ifModifiedSince string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		ifModifiedSince = r.Header.Get("If-Modified-Since")
+		if ifNoneMatch == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 26 Jan 2026 10:30:00 GMT")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "1"}})
+	}))
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(conditionalTestConfig(server.URL))
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	// First run: no validators sent, validators captured
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("first Fetch() returned error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if ifNoneMatch != "" || ifModifiedSince != "" {
+		t.Errorf("expected no conditional headers on first run, got %q / %q", ifNoneMatch, ifModifiedSince)
+	}
+	etag, lastModified := h.ResponseValidators()
+	if etag != `"v1"` || lastModified != "Mon, 26 Jan 2026 10:30:00 GMT" {
+		t.Fatalf("unexpected validators %q / %q", etag, lastModified)
+	}
+
+	// Second run with persisted validators: 304 is a zero-record success
+	h.lastState = &persistence.State{ETag: etag, LastModified: lastModified}
+	records, err = h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("second Fetch() returned error: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected 0 records on 304, got %d", len(records))
+	}
+	if ifModifiedSince != lastModified {
+		t.Errorf("expected If-Modified-Since %q, got %q", lastModified, ifModifiedSince)
+	}
+	if etag, _ := h.ResponseValidators(); etag != `"v1"` {
+		t.Errorf("expected validators kept after 304, got %q", etag)
+	}
+	if info := h.GetRetryInfo(); info != nil && info.RetryCount != 0 {
+		t.Errorf("expected no retry on 304, got %d", info.RetryCount)
+	}
+}
+
+func TestHTTPPolling_Conditional_FirstPageOnly(t *testing.T) {
+	conditionalPages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditionalPages++
+		}
+		w.Header().Set("ETag", `"page-`+r.URL.Query().Get("page")+`"`)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":       []map[string]interface{}{{"page": r.URL.Query().Get("page")}},
+			"totalPages": 2,
+		})
+	}))
+	defer server.Close()
+
+	config := conditionalTestConfig(server.URL)
+	config.Config["pagination"] = map[string]interface{}{
+		"type":            "page",
+		"pageParam":       "page",
+		"totalPagesField": "totalPages",
+	}
+	h, err := NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+	h.lastState = &persistence.State{ETag: `"page-1-old"`}
+
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if conditionalPages != 1 {
+		t.Errorf("expected conditional headers on the first page only, got %d pages", conditionalPages)
+	}
+	if etag, _ := h.ResponseValidators(); etag != `"page-1"` {
+		t.Errorf("expected validators of the first page, got %q", etag)
+	}
+}
+
+func TestHTTPPolling_Conditional_Disabled(t *testing.T) {
+	var ifNoneMatch string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "1"}})
+	}))
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type:   "httpPolling",
+		Config: map[string]interface{}{"endpoint": server.URL},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+	h.lastState = &persistence.State{ETag: `"v1"`}
+
+	if _, err := h.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if ifNoneMatch != "" {
+		t.Errorf("expected no If-None-Match without conditional config, got %q", ifNoneMatch)
+	}
+	if etag, _ := h.ResponseValidators(); etag != "" {
+		t.Errorf("expected no validators captured, got %q", etag)
+	}
+}
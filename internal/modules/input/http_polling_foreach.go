@@ -0,0 +1,466 @@
+// Package input provides implementations for input modules.
+// This file implements forEach fan-out for the HTTP polling module.
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/pathutil"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/internal/template"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// Default configuration values for forEach fan-out
+const (
+	defaultForEachConcurrency = 4
+	forEachMetadataField      = "_metadata"
+	forEachMetadataKey        = "forEach"
+	forEachStateKeyPrefix     = "forEach."
+)
+
+// Error types for forEach fan-out
+var (
+	ErrForEachSource  = errors.New("forEach requires exactly one of values, file or request")
+	ErrInvalidForEach = errors.New("invalid forEach configuration")
+)
+
+// ForEachConfig configures fan-out polling over a list of values.
+// The endpoint and body template are evaluated once per value with
+// {{forEach.value}} (or {{forEach.value.field}} for object values).
+type ForEachConfig struct {
+	// Values is a static list of values.
+	Values []interface{}
+	// File is a path to a JSON array or a newline-delimited list of values.
+	File string
+	// Request fetches the values from a preliminary GET request.
+	Request *ForEachRequestConfig
+	// Concurrency bounds the number of values fetched in parallel (default 4).
+	Concurrency int
+}
+
+// ForEachRequestConfig configures the preliminary request returning forEach values.
+type ForEachRequestConfig struct {
+	// Endpoint is the URL returning the list (same headers and authentication as the module).
+	Endpoint string
+	// DataField is the field (dot notation) containing the array, for object responses.
+	DataField string
+	// ValueField is the field (dot notation) of each item used as value. If empty, the whole item is used.
+	ValueField string
+}
+
+// extractForEach parses and validates the forEach configuration.
+// Returns nil if forEach is not configured.
+func extractForEach(config *connector.ModuleConfig) (*ForEachConfig, error) {
+	raw, ok := config.Config["forEach"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	fe := &ForEachConfig{Concurrency: defaultForEachConcurrency}
+	sources := 0
+
+	if values, ok := raw["values"].([]interface{}); ok {
+		fe.Values = values
+		sources++
+	}
+	if file, ok := raw["file"].(string); ok && file != "" {
+		if err := pathutil.ValidateFilePath(file); err != nil {
+			return nil, fmt.Errorf("forEach file path: %w", err)
+		}
+		fe.File = file
+		sources++
+	}
+	if req, ok := raw["request"].(map[string]interface{}); ok {
+		fe.Request = &ForEachRequestConfig{}
+		fe.Request.Endpoint, _ = req["endpoint"].(string)
+		fe.Request.DataField, _ = req["dataField"].(string)
+		fe.Request.ValueField, _ = req["valueField"].(string)
+		if fe.Request.Endpoint == "" {
+			return nil, fmt.Errorf("forEach.request: %w", ErrMissingEndpoint)
+		}
+		sources++
+	}
+	if sources != 1 {
+		return nil, ErrForEachSource
+	}
+
+	if concurrency, ok := raw["concurrency"].(float64); ok {
+		if concurrency < 1 {
+			return nil, fmt.Errorf("%w: concurrency must be at least 1", ErrInvalidForEach)
+		}
+		fe.Concurrency = int(concurrency)
+	}
+
+	endpoint, _ := config.Config["endpoint"].(string)
+	if err := template.ValidateSyntax(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid endpoint template: %w", err)
+	}
+
+	return fe, nil
+}
+
+// fetchForEach resolves the forEach values, fetches each value with bounded
+// concurrency and merges the results in value order.
+func (h *HTTPPolling) fetchForEach(ctx context.Context) ([]map[string]interface{}, error) {
+	values, err := h.resolveForEachValues(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolving forEach values: %w", err)
+	}
+
+	logger.Info("forEach fan-out started",
+		"module_type", "httpPolling",
+		"value_count", len(values),
+		"concurrency", h.forEach.Concurrency,
+	)
+
+	h.mu.Lock()
+	h.pendingStates = make(map[string]*persistence.State, len(values))
+	h.forEachStartedAt = time.Now()
+	h.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]map[string]interface{}, len(values))
+	sem := make(chan struct{}, h.forEach.Concurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for i, value := range values {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, value interface{}) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			records, err := h.fetchForEachValue(ctx, value)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("forEach value %q: %w", forEachValueString(value), err)
+					cancel()
+				})
+				return
+			}
+			results[i] = records
+		}(i, value)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var allRecords []map[string]interface{}
+	for _, records := range results {
+		allRecords = append(allRecords, records...)
+	}
+
+	logger.Info("forEach fan-out completed",
+		"module_type", "httpPolling",
+		"value_count", len(values),
+		"total_records", len(allRecords),
+	)
+
+	return allRecords, nil
+}
+
+// fetchForEachValue fetches all records for a single forEach value.
+// Records are tagged with the value in _metadata.forEach.value.
+func (h *HTTPPolling) fetchForEachValue(ctx context.Context, value interface{}) ([]map[string]interface{}, error) {
+	key := forEachStateKey(value)
+	// Evaluator caches are not thread-safe: one evaluator per value
+	evaluator := template.NewEvaluator()
+	templateCtx := map[string]interface{}{
+		forEachMetadataKey: map[string]interface{}{"value": value},
+	}
+
+	state := h.loadForEachState(key)
+	target := fetchTarget{
+		endpoint:     evaluator.EvaluateForURL(h.endpoint, templateCtx),
+		bodyTemplate: evaluator.Evaluate(h.bodyTemplate, templateCtx),
+		state:        state,
+		conditional:  h.newConditionalExchange(state),
+	}
+
+	logger.Debug("forEach value fetch started",
+		"module_type", "httpPolling",
+		"value", forEachValueString(value),
+		"endpoint", target.endpoint,
+		"has_state", target.state != nil,
+	)
+
+	records, err := h.fetchTarget(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		tagForEachRecord(record, value)
+	}
+	h.recordPendingState(key, target, records)
+
+	return records, nil
+}
+
+// resolveForEachValues returns the values to fan out over.
+func (h *HTTPPolling) resolveForEachValues(ctx context.Context) ([]interface{}, error) {
+	switch {
+	case h.forEach.File != "":
+		return readForEachFile(h.forEach.File)
+	case h.forEach.Request != nil:
+		return h.fetchForEachValues(ctx)
+	default:
+		return h.forEach.Values, nil
+	}
+}
+
+// readForEachFile reads values from a JSON array file or a newline-delimited
+// file (blank lines and lines starting with # are ignored).
+func readForEachFile(path string) ([]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading forEach file %s: %w", path, err)
+	}
+
+	trimmed := strings.TrimSpace(string(content))
+	if strings.HasPrefix(trimmed, "[") {
+		var values []interface{}
+		if err := json.Unmarshal([]byte(trimmed), &values); err != nil {
+			return nil, fmt.Errorf("%w: forEach file %s: %w", ErrJSONParse, path, err)
+		}
+		return values, nil
+	}
+
+	var values []interface{}
+	for _, line := range strings.Split(trimmed, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values = append(values, line)
+	}
+	return values, nil
+}
+
+// fetchForEachValues executes the preliminary GET request and extracts the values.
+func (h *HTTPPolling) fetchForEachValues(ctx context.Context) ([]interface{}, error) {
+	req := h.forEach.Request
+	body, err := h.doRequestWithRetry(ctx, pageRequest{endpoint: req.Endpoint, method: http.MethodGet})
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJSONParse, err)
+	}
+
+	if req.DataField != "" {
+		obj, ok := parsed.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: expected object response for dataField '%s'", ErrInvalidDataField, req.DataField)
+		}
+		data, found := template.GetNestedValue(obj, req.DataField)
+		if !found {
+			return nil, fmt.Errorf("%w: field '%s' not found", ErrInvalidDataField, req.DataField)
+		}
+		parsed = data
+	}
+
+	items, ok := parsed.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: expected array, got %T", ErrInvalidDataField, parsed)
+	}
+
+	if req.ValueField == "" {
+		return items, nil
+	}
+
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, found := template.GetNestedValue(obj, req.ValueField); found && value != nil {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// tagForEachRecord stores the forEach value in the record metadata.
+func tagForEachRecord(record map[string]interface{}, value interface{}) {
+	metadata, ok := record[forEachMetadataField].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		record[forEachMetadataField] = metadata
+	}
+	metadata[forEachMetadataKey] = map[string]interface{}{"value": value}
+}
+
+// forEachValueString returns the string form of a forEach value, used in logs.
+func forEachValueString(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(value)
+		if err == nil {
+			return string(encoded)
+		}
+	}
+	return template.ValueToString(value)
+}
+
+// forEachStateKey returns the state key of a forEach value: its JSON encoding,
+// so that distinct values (such as "1" and 1) never share a state.
+func forEachStateKey(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return forEachValueString(value)
+	}
+	return string(encoded)
+}
+
+// forEachStateEnabled reports whether per-value state is restored and saved.
+// Backfill windows neither read nor save it.
+func (h *HTTPPolling) forEachStateEnabled() bool {
+	return h.forEach != nil && h.persistenceConfig.IsEnabled() && h.window == nil
+}
+
+// loadForEachState returns the restored state of a forEach value, or nil.
+func (h *HTTPPolling) loadForEachState(key string) *persistence.State {
+	if !h.forEachStateEnabled() {
+		return nil
+	}
+	return h.forEachStates[key]
+}
+
+// recordPendingState computes the state of a forEach value after a successful
+// fetch. It is returned by StateKeys, committed once the whole execution succeeded.
+func (h *HTTPPolling) recordPendingState(key string, target fetchTarget, records []map[string]interface{}) {
+	if !h.forEachStateEnabled() {
+		return
+	}
+
+	previous := target.state
+	pending := &persistence.State{}
+	pending.ETag, pending.LastModified = target.conditional.validators()
+	if previous != nil && previous.LastID != nil {
+		lastID := *previous.LastID
+		pending.LastID = &lastID
+	}
+	if h.persistenceConfig.WatermarkEnabled() {
+		if previous != nil {
+			pending.LastTimestamp = previous.LastTimestamp
+		}
+		if len(records) > 0 {
+			watermark, err := persistence.ExtractWatermark(records, h.persistenceConfig.Timestamp.Field)
+			switch {
+			case err == nil:
+				pending.LastTimestamp = persistence.LaterTimestamp(pending.LastTimestamp, watermark)
+			case !errors.Is(err, persistence.ErrNoRecords):
+				logger.Warn("failed to extract watermark for forEach value",
+					"pipeline_id", h.pipelineID,
+					"value", key,
+					"error", err.Error(),
+				)
+			}
+		}
+	}
+	if h.persistenceConfig.IDEnabled() && h.persistenceConfig.ID.Field != "" && len(records) > 0 {
+		if lastID, err := persistence.ExtractLastID(records, h.persistenceConfig.ID.Field); err == nil {
+			pending.LastID = &lastID
+		} else {
+			logger.Warn("failed to extract last ID for forEach value",
+				"pipeline_id", h.pipelineID,
+				"value", key,
+				"error", err.Error(),
+			)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.pendingStates != nil {
+		h.pendingStates[key] = pending
+	}
+}
+
+// RestoreStateKeys restores the per-value states of forEach, persisted as
+// named keys of the pipeline state (runtime.KeyedStateModule). Keys that
+// cannot be decoded are reported, and their values fetched without state.
+func (h *HTTPPolling) RestoreStateKeys(keys map[string]json.RawMessage) error {
+	h.forEachStates = nil
+	if !h.forEachStateEnabled() {
+		return nil
+	}
+
+	h.forEachStates = make(map[string]*persistence.State)
+	var errs []error
+	for name, raw := range keys {
+		key, ok := strings.CutPrefix(name, forEachStateKeyPrefix)
+		if !ok {
+			continue
+		}
+		var state persistence.State
+		if err := json.Unmarshal(raw, &state); err != nil {
+			errs = append(errs, fmt.Errorf("decoding state key %q: %w", name, err))
+			continue
+		}
+		h.forEachStates[key] = &state
+	}
+	return errors.Join(errs...)
+}
+
+// StateKeys returns the per-value states collected by the last forEach fetch,
+// one key per value, keyed by the raw value. The runtime commits them with
+// the pipeline state after a successful execution; values not fetched keep
+// their previous key. Without a watermark, the persisted timestamp is the
+// start of the fetch.
+func (h *HTTPPolling) StateKeys() (map[string]json.RawMessage, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.pendingStates) == 0 || !h.forEachStateEnabled() {
+		return nil, nil
+	}
+
+	keys := make(map[string]json.RawMessage, len(h.pendingStates))
+	for key, state := range h.pendingStates {
+		if h.persistenceConfig.TimestampEnabled() && !h.persistenceConfig.WatermarkEnabled() {
+			timestamp := h.forEachStartedAt
+			state.LastTimestamp = &timestamp
+		}
+		if state.LastTimestamp == nil && state.LastID == nil && state.ETag == "" && state.LastModified == "" {
+			continue
+		}
+		raw, err := json.Marshal(state)
+		if err != nil {
+			return nil, fmt.Errorf("encoding state of forEach value %s: %w", key, err)
+		}
+		keys[forEachStateKeyPrefix+key] = raw
+	}
+
+	logger.Debug("forEach states collected",
+		"pipeline_id", h.pipelineID,
+		"value_count", len(keys),
+	)
+	return keys, nil
+}
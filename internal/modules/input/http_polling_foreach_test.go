@@ -0,0 +1,430 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// forEachTestServer serves /accounts/{id}/orders with one record per account.
+func forEachTestServer(t *testing.T, handler func(account string, r *http.Request)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 3 || parts[0] != "accounts" {
+			http.NotFound(w, r)
+			return
+		}
+		account := parts[1]
+		if handler != nil {
+			handler(account, r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"id": account + "-1", "account": account},
+		})
+	}))
+}
+
+func TestHTTPPolling_ForEach_StaticValues(t *testing.T) {
+	server := forEachTestServer(t, nil)
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "/accounts/{{forEach.value}}/orders",
+			"forEach": map[string]interface{}{
+				"values": []interface{}{"a", "b", "c"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	for i, want := range []string{"a", "b", "c"} {
+		if records[i]["account"] != want {
+			t.Errorf("record %d: expected account %q (value order), got %v", i, want, records[i]["account"])
+		}
+		metadata, ok := records[i]["_metadata"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("record %d: expected _metadata map, got %T", i, records[i]["_metadata"])
+		}
+		forEach, _ := metadata["forEach"].(map[string]interface{})
+		if forEach["value"] != want {
+			t.Errorf("record %d: expected _metadata.forEach.value %q, got %v", i, want, forEach["value"])
+		}
+	}
+}
+
+func TestHTTPPolling_ForEach_ConcurrencyBound(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := forEachTestServer(t, func(_ string, _ *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	})
+	defer server.Close()
+
+	values := make([]interface{}, 0, 8)
+	for _, v := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		values = append(values, v)
+	}
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "/accounts/{{forEach.value}}/orders",
+			"forEach": map[string]interface{}{
+				"values":      values,
+				"concurrency": float64(2),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 8 {
+		t.Errorf("expected 8 records, got %d", len(records))
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", got)
+	}
+}
+
+func TestHTTPPolling_ForEach_ValuesFromFile(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	server := forEachTestServer(t, func(account string, _ *http.Request) {
+		mu.Lock()
+		seen = append(seen, account)
+		mu.Unlock()
+	})
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "accounts.txt")
+	if err := os.WriteFile(file, []byte("# accounts\nx\n\ny\n"), 0o600); err != nil {
+		t.Fatalf("failed to write forEach file: %v", err)
+	}
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "/accounts/{{forEach.value}}/orders",
+			"forEach":  map[string]interface{}{"file": file},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 2 || len(seen) != 2 {
+		t.Errorf("expected 2 records from 2 requests, got %d records, requests %v", len(records), seen)
+	}
+}
+
+func TestHTTPPolling_ForEach_ValuesFromRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/accounts" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"accounts": []interface{}{
+						map[string]interface{}{"slug": "north"},
+						map[string]interface{}{"slug": "south"},
+					},
+				},
+			})
+			return
+		}
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST for search request, got %s", r.Method)
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"region": body["region"]}})
+	}))
+	defer server.Close()
+
+	bodyFile := filepath.Join(t.TempDir(), "search.json")
+	if err := os.WriteFile(bodyFile, []byte(`{"region": "{{forEach.value}}"}`), 0o600); err != nil {
+		t.Fatalf("failed to write body template: %v", err)
+	}
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint":         server.URL + "/search",
+			"bodyTemplateFile": bodyFile,
+			"forEach": map[string]interface{}{
+				"request": map[string]interface{}{
+					"endpoint":   server.URL + "/accounts",
+					"dataField":  "data.accounts",
+					"valueField": "slug",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0]["region"] != "north" || records[1]["region"] != "south" {
+		t.Errorf("expected body evaluated per value, got %v and %v", records[0]["region"], records[1]["region"])
+	}
+}
+
+func TestHTTPPolling_ForEach_PerValueState(t *testing.T) {
+	var mu sync.Mutex
+	afterIDs := make(map[string]string)
+	server := forEachTestServer(t, func(account string, r *http.Request) {
+		mu.Lock()
+		afterIDs[account] = r.URL.Query().Get("after_id")
+		mu.Unlock()
+	})
+	defer server.Close()
+
+	config := &connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "/accounts/{{forEach.value}}/orders",
+			"forEach":  map[string]interface{}{"values": []interface{}{"a", "b"}},
+			"statePersistence": map[string]interface{}{
+				"id": map[string]interface{}{"enabled": true, "field": "id", "queryParam": "after_id"},
+			},
+		},
+	}
+
+	h, err := NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+	h.SetPipelineID("fanout")
+
+	if _, err := h.Fetch(context.Background()); err != nil {
+		t.Fatalf("first Fetch() returned error: %v", err)
+	}
+	if afterIDs["a"] != "" || afterIDs["b"] != "" {
+		t.Errorf("expected no after_id on first run, got %v", afterIDs)
+	}
+	keys, err := h.StateKeys()
+	if err != nil {
+		t.Fatalf("StateKeys() returned error: %v", err)
+	}
+	var state persistence.State
+	if err := json.Unmarshal(keys[`forEach."a"`], &state); err != nil || state.LastID == nil || *state.LastID != "a-1" {
+		t.Fatalf("expected state key for value a with last ID a-1, got %v (err %v)", keys, err)
+	}
+	if err := h.RestoreStateKeys(keys); err != nil {
+		t.Fatalf("RestoreStateKeys() returned error: %v", err)
+	}
+
+	if _, err := h.Fetch(context.Background()); err != nil {
+		t.Fatalf("second Fetch() returned error: %v", err)
+	}
+	if afterIDs["a"] != "a-1" || afterIDs["b"] != "b-1" {
+		t.Errorf("expected per-value after_id on second run, got %v", afterIDs)
+	}
+}
+
+func TestHTTPPolling_ForEach_PerValueWatermark(t *testing.T) {
+	var mu sync.Mutex
+	since := make(map[string]string)
+	updatedAt := map[string]string{"a": "2026-01-26T10:00:00Z", "b": "2026-01-26T12:00:00Z"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account := strings.Split(r.URL.Path, "/")[2]
+		mu.Lock()
+		since[account] = r.URL.Query().Get("since")
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": account + "-1", "updated_at": updatedAt[account]}})
+	}))
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "/accounts/{{forEach.value}}/orders",
+			"forEach":  map[string]interface{}{"values": []interface{}{"a", "b"}},
+			"statePersistence": map[string]interface{}{
+				"timestamp": map[string]interface{}{
+					"enabled":         true,
+					"queryParam":      "since",
+					"field":           "updated_at",
+					"lookbackSeconds": float64(60),
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+	h.SetPipelineID("fanout-watermark")
+
+	for run := 1; run <= 2; run++ {
+		if _, err := h.Fetch(context.Background()); err != nil {
+			t.Fatalf("Fetch() %d returned error: %v", run, err)
+		}
+		keys, err := h.StateKeys()
+		if err != nil {
+			t.Fatalf("StateKeys() returned error: %v", err)
+		}
+		if err := h.RestoreStateKeys(keys); err != nil {
+			t.Fatalf("RestoreStateKeys() returned error: %v", err)
+		}
+	}
+
+	if since["a"] != "2026-01-26T09:59:00Z" || since["b"] != "2026-01-26T11:59:00Z" {
+		t.Errorf("expected per-value watermark minus lookback on second run, got %v", since)
+	}
+}
+
+func TestHTTPPolling_ForEach_StateKeysPerDistinctValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": r.URL.Query().Get("account")}})
+	}))
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "/orders?account={{forEach.value}}",
+			"forEach":  map[string]interface{}{"values": []interface{}{"a/b", "a_b", "1", float64(1)}},
+			"statePersistence": map[string]interface{}{
+				"id": map[string]interface{}{"enabled": true, "field": "id", "queryParam": "after_id"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+	if _, err := h.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+
+	keys, err := h.StateKeys()
+	if err != nil {
+		t.Fatalf("StateKeys() returned error: %v", err)
+	}
+	for _, name := range []string{`forEach."a/b"`, `forEach."a_b"`, `forEach."1"`, `forEach.1`} {
+		if _, ok := keys[name]; !ok {
+			t.Errorf("expected state key %s, got %v", name, keys)
+		}
+	}
+}
+
+func TestHTTPPolling_ForEach_ErrorCancelsFanOut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/bad/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "1"}})
+	}))
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "/accounts/{{forEach.value}}/orders",
+			"forEach":  map[string]interface{}{"values": []interface{}{"good", "bad"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	_, err = h.Fetch(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `forEach value "bad"`) {
+		t.Fatalf("expected error for value bad, got %v", err)
+	}
+}
+
+func TestHTTPPolling_ForEach_InvalidSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		forEach map[string]interface{}
+	}{
+		{name: "no source", forEach: map[string]interface{}{"concurrency": float64(2)}},
+		{name: "multiple sources", forEach: map[string]interface{}{
+			"values": []interface{}{"a"},
+			"file":   "accounts.txt",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+				Type: "httpPolling",
+				Config: map[string]interface{}{
+					"endpoint": "https://api.example.com/accounts/{{forEach.value}}",
+					"forEach":  tt.forEach,
+				},
+			})
+			if !errors.Is(err, ErrForEachSource) {
+				t.Errorf("expected ErrForEachSource, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPPolling_ForEach_InvalidConcurrency(t *testing.T) {
+	for _, concurrency := range []float64{0, -1} {
+		_, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+			Type: "httpPolling",
+			Config: map[string]interface{}{
+				"endpoint": "https://api.example.com/accounts/{{forEach.value}}",
+				"forEach": map[string]interface{}{
+					"values":      []interface{}{"a"},
+					"concurrency": concurrency,
+				},
+			},
+		})
+		if !errors.Is(err, ErrInvalidForEach) {
+			t.Errorf("concurrency %v: expected ErrInvalidForEach, got %v", concurrency, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/persistence"
+)
+
+// Behaviors when a pagination limit is reached or a loop is detected
+const (
+	paginationOnLimitStop  = "stop"
+	paginationOnLimitError = "error"
+)
+
+// Errors for pagination limits
+var (
+	// ErrPaginationLimit is returned when maxPages or maxRecords is reached
+	// and pagination.onLimit is "error".
+	ErrPaginationLimit = errors.New("pagination limit reached")
+
+	// ErrPaginationLoop is returned when a repeated cursor or an identical page is
+	// detected and pagination.onLimit is "error".
+	ErrPaginationLoop = errors.New("pagination loop detected")
+
+	// ErrInvalidPagination is returned when the pagination limits configuration is invalid.
+	ErrInvalidPagination = errors.New("invalid pagination configuration")
+)
+
+// parsePaginationLimits parses maxPages, maxRecords, stopWhen and onLimit into p.
+// The stopWhen expression is compiled once here.
+func parsePaginationLimits(p *PaginationConfig, config map[string]interface{}) error {
+	if maxPages, ok := config["maxPages"].(float64); ok {
+		if maxPages < 1 {
+			return fmt.Errorf("%w: maxPages must be at least 1", ErrInvalidPagination)
+		}
+		p.MaxPages = int(maxPages)
+	}
+	if maxRecords, ok := config["maxRecords"].(float64); ok {
+		if maxRecords < 1 {
+			return fmt.Errorf("%w: maxRecords must be at least 1", ErrInvalidPagination)
+		}
+		p.MaxRecords = int(maxRecords)
+	}
+
+	p.OnLimit = paginationOnLimitStop
+	if onLimit, ok := config["onLimit"].(string); ok && onLimit != "" {
+		if onLimit != paginationOnLimitStop && onLimit != paginationOnLimitError {
+			return fmt.Errorf("%w: onLimit must be %q or %q, got %q",
+				ErrInvalidPagination, paginationOnLimitStop, paginationOnLimitError, onLimit)
+		}
+		p.OnLimit = onLimit
+	}
+
+	if stopWhen, ok := config["stopWhen"].(string); ok && stopWhen != "" {
+		program, err := expr.Compile(stopWhen, expr.AllowUndefinedVariables(), expr.AsBool())
+		if err != nil {
+			return fmt.Errorf("%w: compiling stopWhen %q: %w", ErrInvalidPagination, stopWhen, err)
+		}
+		p.StopWhen = stopWhen
+		p.stopWhenProgram = program
+	}
+
+	return nil
+}
+
+// paginationGuard enforces the limits of one paginated fetch: maxPages,
+// maxRecords, the stopWhen expression and loop detection (repeated cursors
+// and identical consecutive pages).
+type paginationGuard struct {
+	cfg      *PaginationConfig
+	maxPages int
+	state    map[string]interface{}
+
+	pages    int
+	records  int
+	lastHash string
+	cursors  map[string]struct{}
+}
+
+// newPaginationGuard creates the guard of one paginated fetch.
+// state is the persisted state of the target, exposed to stopWhen.
+func newPaginationGuard(cfg *PaginationConfig, state *persistence.State) *paginationGuard {
+	maxPages := maxPaginationPages
+	if cfg.MaxPages > 0 && cfg.MaxPages < maxPages {
+		maxPages = cfg.MaxPages
+	}
+	return &paginationGuard{
+		cfg:      cfg,
+		maxPages: maxPages,
+		state:    stopWhenState(state),
+		cursors:  make(map[string]struct{}),
+	}
+}
+
+// stopWhenState exposes the persisted state to stopWhen as
+// state.lastTimestamp (RFC3339) and state.lastId. Missing values are nil.
+func stopWhenState(state *persistence.State) map[string]interface{} {
+	env := map[string]interface{}{"lastTimestamp": nil, "lastId": nil}
+	if state == nil {
+		return env
+	}
+	if state.LastTimestamp != nil {
+		env["lastTimestamp"] = state.LastTimestamp.UTC().Format(time.RFC3339)
+	}
+	if state.LastID != nil {
+		env["lastId"] = *state.LastID
+	}
+	return env
+}
+
+// beforePage is called before fetching each page. cursor identifies the page
+// to fetch (next cursor or searchAfter values), empty for the first page and
+// for page / offset pagination. It returns true when pagination must stop.
+func (g *paginationGuard) beforePage(cursor interface{}) (bool, error) {
+	if g.pages >= g.maxPages {
+		return g.limitReached(ErrPaginationLimit, fmt.Sprintf("maxPages %d", g.maxPages))
+	}
+	// With onLimit error, the next page is fetched to tell whether the limit
+	// cuts records off: afterPage fails on a non-empty page only, so that a
+	// source holding exactly maxRecords records ends cleanly
+	if g.cfg.MaxRecords > 0 && g.records >= g.cfg.MaxRecords && g.cfg.OnLimit != paginationOnLimitError {
+		return g.limitReached(ErrPaginationLimit, fmt.Sprintf("maxRecords %d", g.cfg.MaxRecords))
+	}
+	if cursor == nil || cursor == "" {
+		return false, nil
+	}
+
+	key, err := json.Marshal(cursor)
+	if err != nil {
+		return false, nil
+	}
+	if _, seen := g.cursors[string(key)]; seen {
+		return g.limitReached(ErrPaginationLoop, fmt.Sprintf("repeated cursor %s", key))
+	}
+	g.cursors[string(key)] = struct{}{}
+	return false, nil
+}
+
+// afterPage is called with the records of each fetched page. It returns the
+// records to keep (truncated to maxRecords, none for a page identical to the
+// previous one) and true when pagination must stop.
+func (g *paginationGuard) afterPage(records []map[string]interface{}) ([]map[string]interface{}, bool, error) {
+	g.pages++
+
+	if len(records) > 0 {
+		hash := hashPage(records)
+		if hash != "" && hash == g.lastHash {
+			stop, err := g.limitReached(ErrPaginationLoop, fmt.Sprintf("page %d identical to the previous page", g.pages))
+			return nil, stop, err
+		}
+		g.lastHash = hash
+	}
+
+	if g.cfg.MaxRecords > 0 && g.records+len(records) > g.cfg.MaxRecords {
+		kept := records[:g.cfg.MaxRecords-g.records]
+		g.records += len(kept)
+		stop, err := g.limitReached(ErrPaginationLimit, fmt.Sprintf("maxRecords %d", g.cfg.MaxRecords))
+		if err != nil {
+			return nil, true, err
+		}
+		return kept, stop, nil
+	}
+	g.records += len(records)
+
+	if g.cfg.stopWhenProgram != nil && len(records) > 0 {
+		stop, err := g.evaluateStopWhen(records)
+		if err != nil {
+			return nil, true, err
+		}
+		if stop {
+			logger.Info("pagination stopped by stopWhen",
+				"module_type", "httpPolling",
+				"stop_when", g.cfg.StopWhen,
+				"page", g.pages,
+				"total_records", g.records,
+			)
+			return records, true, nil
+		}
+	}
+
+	return records, false, nil
+}
+
+// evaluateStopWhen evaluates the stopWhen expression against a page.
+// The expression sees records, firstRecord, lastRecord, page, totalRecords and state.
+func (g *paginationGuard) evaluateStopWhen(records []map[string]interface{}) (bool, error) {
+	env := map[string]interface{}{
+		"records":      records,
+		"firstRecord":  records[0],
+		"lastRecord":   records[len(records)-1],
+		"page":         g.pages,
+		"totalRecords": g.records,
+		"state":        g.state,
+	}
+	output, err := expr.Run(g.cfg.stopWhenProgram, env)
+	if err != nil {
+		return false, fmt.Errorf("evaluating pagination stopWhen %q: %w", g.cfg.StopWhen, err)
+	}
+	stop, _ := output.(bool)
+	return stop, nil
+}
+
+// limitReached applies pagination.onLimit: it returns an error wrapping reason
+// when onLimit is "error", otherwise it logs a warning and stops cleanly.
+func (g *paginationGuard) limitReached(reason error, detail string) (bool, error) {
+	if g.cfg.OnLimit == paginationOnLimitError {
+		return true, fmt.Errorf("%w: %s", reason, detail)
+	}
+	logger.Warn("stopping pagination",
+		"module_type", "httpPolling",
+		"reason", reason.Error(),
+		"detail", detail,
+		"pages_fetched", g.pages,
+		"total_records", g.records,
+	)
+	return true, nil
+}
+
+// hashPage returns a digest of the records of a page, used to detect identical pages.
+func hashPage(records []map[string]interface{}) string {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,307 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// newPagedServer serves 10 pages of 3 records each with page-based pagination.
+func newPagedServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		data := make([]map[string]interface{}, 0, 3)
+		for i := 0; i < 3; i++ {
+			data = append(data, map[string]interface{}{"id": (page-1)*3 + i + 1})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "totalPages": 10})
+	}))
+}
+
+func pageLimitsConfig(endpoint string, limits map[string]interface{}) *connector.ModuleConfig {
+	pagination := map[string]interface{}{
+		"type":            "page",
+		"pageParam":       "page",
+		"totalPagesField": "totalPages",
+	}
+	for k, v := range limits {
+		pagination[k] = v
+	}
+	return &connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint":   endpoint,
+			"pagination": pagination,
+		},
+	}
+}
+
+func TestHTTPPolling_PaginationLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		limits       map[string]interface{}
+		wantRecords  int
+		wantRequests int
+		wantErr      error
+	}{
+		{
+			name:         "maxPages stops cleanly",
+			limits:       map[string]interface{}{"maxPages": float64(2)},
+			wantRecords:  6,
+			wantRequests: 2,
+		},
+		{
+			name:         "maxRecords truncates the last page",
+			limits:       map[string]interface{}{"maxRecords": float64(7)},
+			wantRecords:  7,
+			wantRequests: 3,
+		},
+		{
+			name:         "maxPages with onLimit error",
+			limits:       map[string]interface{}{"maxPages": float64(2), "onLimit": "error"},
+			wantRequests: 2,
+			wantErr:      ErrPaginationLimit,
+		},
+		{
+			name:         "maxRecords with onLimit error",
+			limits:       map[string]interface{}{"maxRecords": float64(4), "onLimit": "error"},
+			wantRequests: 2,
+			wantErr:      ErrPaginationLimit,
+		},
+		{
+			name:         "maxRecords reached on a page boundary with onLimit error",
+			limits:       map[string]interface{}{"maxRecords": float64(6), "onLimit": "error"},
+			wantRequests: 3,
+			wantErr:      ErrPaginationLimit,
+		},
+		{
+			name:         "stopWhen is always a clean stop",
+			limits:       map[string]interface{}{"stopWhen": "lastRecord.id >= 9", "onLimit": "error"},
+			wantRecords:  9,
+			wantRequests: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := newPagedServer(t, &requests)
+			defer server.Close()
+
+			h, err := NewHTTPPollingFromConfig(pageLimitsConfig(server.URL, tt.limits))
+			if err != nil {
+				t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+			}
+
+			records, err := h.Fetch(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Fetch() returned error: %v", err)
+			} else if len(records) != tt.wantRecords {
+				t.Errorf("expected %d records, got %d", tt.wantRecords, len(records))
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("expected %d requests, got %d", tt.wantRequests, requests)
+			}
+		})
+	}
+}
+
+func TestHTTPPolling_PaginationLimits_ExactMaxRecords(t *testing.T) {
+	// 6 records in pages of 3, the end being detected with an empty page
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		data := make([]map[string]interface{}, 0, 3)
+		for i := 0; i < 3 && page <= 2; i++ {
+			data = append(data, map[string]interface{}{"id": (page-1)*3 + i + 1})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	config := pageLimitsConfig(server.URL, map[string]interface{}{"maxRecords": float64(6), "onLimit": "error"})
+	delete(config.Config["pagination"].(map[string]interface{}), "totalPagesField")
+	h, err := NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	// No record was cut off by the limit: the fetch completes
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() error = %v, want all records", err)
+	}
+	if len(records) != 6 || requests != 3 {
+		t.Errorf("Fetch() = %d records over %d requests, want 6 over 3", len(records), requests)
+	}
+}
+
+func TestHTTPPolling_PaginationStopWhen_State(t *testing.T) {
+	// Records are sorted by updated_at descending: stop once a page reaches
+	// records older than the persisted timestamp.
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		pages := map[string][]map[string]interface{}{
+			"":   {{"id": "1", "updated_at": "2026-01-26T12:00:00Z"}, {"id": "2", "updated_at": "2026-01-26T11:00:00Z"}},
+			"c2": {{"id": "3", "updated_at": "2026-01-26T10:00:00Z"}, {"id": "4", "updated_at": "2026-01-26T09:00:00Z"}},
+			"c3": {{"id": "5", "updated_at": "2026-01-26T08:00:00Z"}},
+		}
+		next := map[string]string{"": "c2", "c2": "c3"}
+		cursor := r.URL.Query().Get("cursor")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": pages[cursor], "next": next[cursor]})
+	}))
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"pagination": map[string]interface{}{
+				"type":            "cursor",
+				"cursorParam":     "cursor",
+				"nextCursorField": "next",
+				"stopWhen":        "state.lastTimestamp != nil && date(lastRecord.updated_at) < date(state.lastTimestamp)",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	// Without state, all pages are fetched
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 5 || requests != 3 {
+		t.Fatalf("expected 5 records in 3 requests without state, got %d in %d", len(records), requests)
+	}
+
+	lastTimestamp := time.Date(2026, 1, 26, 9, 30, 0, 0, time.UTC)
+	h.lastState = &persistence.State{LastTimestamp: &lastTimestamp}
+	requests = 0
+	records, err = h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 4 || requests != 2 {
+		t.Errorf("expected 4 records in 2 requests with state, got %d in %d", len(records), requests)
+	}
+}
+
+func TestHTTPPolling_PaginationLoopDetection(t *testing.T) {
+	t.Run("repeated cursor", func(t *testing.T) {
+		for _, onLimit := range []string{"stop", "error"} {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				cursor := r.URL.Query().Get("cursor")
+				next := map[string]string{"": "a", "a": "b", "b": "a"}[cursor]
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"data": []map[string]interface{}{{"cursor": cursor}},
+					"next": next,
+				})
+			}))
+
+			h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+				Type: "httpPolling",
+				Config: map[string]interface{}{
+					"endpoint": server.URL,
+					"pagination": map[string]interface{}{
+						"type":            "cursor",
+						"cursorParam":     "cursor",
+						"nextCursorField": "next",
+						"onLimit":         onLimit,
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+			}
+
+			records, err := h.Fetch(context.Background())
+			server.Close()
+			if onLimit == "error" {
+				if !errors.Is(err, ErrPaginationLoop) {
+					t.Errorf("onLimit error: Fetch() error = %v, want ErrPaginationLoop", err)
+				}
+			} else if err != nil || len(records) != 3 {
+				t.Errorf("onLimit stop: expected 3 records without error, got %d (%v)", len(records), err)
+			}
+			if requests != 3 {
+				t.Errorf("onLimit %s: expected 3 requests, got %d", onLimit, requests)
+			}
+		}
+	})
+
+	t.Run("identical page", func(t *testing.T) {
+		// The server ignores the offset parameter and returns the same full page
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{"id": 1}, {"id": 2}},
+			})
+		}))
+		defer server.Close()
+
+		h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+			Type: "httpPolling",
+			Config: map[string]interface{}{
+				"endpoint": server.URL,
+				"pagination": map[string]interface{}{
+					"type":        "offset",
+					"offsetParam": "offset",
+					"limitParam":  "limit",
+					"limit":       float64(2),
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+		}
+
+		records, err := h.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch() returned error: %v", err)
+		}
+		if len(records) != 2 {
+			t.Errorf("expected the duplicate page to be dropped, got %d records", len(records))
+		}
+		if requests != 2 {
+			t.Errorf("expected 2 requests, got %d", requests)
+		}
+	})
+}
+
+func TestHTTPPolling_PaginationLimits_InvalidConfig(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"maxPages": float64(0)},
+		{"maxRecords": float64(-1)},
+		{"onLimit": "ignore"},
+		{"stopWhen": "lastRecord.id >"},
+	}
+	for _, limits := range invalid {
+		_, err := NewHTTPPollingFromConfig(pageLimitsConfig("http://example.com", limits))
+		if !errors.Is(err, ErrInvalidPagination) {
+			t.Errorf("limits %v: error = %v, want ErrInvalidPagination", limits, err)
+		}
+	}
+}
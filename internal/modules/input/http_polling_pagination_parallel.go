@@ -0,0 +1,156 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// defaultPaginationConcurrency fetches pages sequentially unless configured otherwise.
+const defaultPaginationConcurrency = 1
+
+// parsePaginationConcurrency parses pagination.concurrency into p.
+func parsePaginationConcurrency(p *PaginationConfig, config map[string]interface{}) error {
+	p.Concurrency = defaultPaginationConcurrency
+	if concurrency, ok := config["concurrency"].(float64); ok {
+		if concurrency < 1 {
+			return fmt.Errorf("%w: concurrency must be at least 1", ErrInvalidPagination)
+		}
+		p.Concurrency = int(concurrency)
+	}
+	return nil
+}
+
+// parallelPages reports whether the remaining pages can be fetched in parallel.
+// Streamed pages are fetched sequentially, to be delivered and checkpointed in order.
+func (h *HTTPPolling) parallelPages() bool {
+	return h.pagination.Concurrency > 1 && h.pageSink == nil
+}
+
+// fetchRemainingPages fetches the remaining pages of a paginated fetch whose
+// total is known from the first page, with at most pagination.concurrency
+// requests in flight. build returns the request of the i-th remaining page
+// (0-based); pageSize is the expected number of records per page, used to
+// avoid fetching pages beyond maxRecords.
+//
+// Pages are passed to the guard in page order, so maxRecords truncation,
+// stopWhen and identical page detection behave as with sequential fetching.
+// Pages after an empty page or a stop are discarded.
+func (h *HTTPPolling) fetchRemainingPages(ctx context.Context, guard *paginationGuard, remaining, pageSize int, build func(i int) (pageRequest, error)) ([]map[string]interface{}, error) {
+	var allRecords []map[string]interface{}
+
+	next := 0
+	for next < remaining {
+		if stop, err := guard.beforePage(nil); err != nil {
+			return nil, err
+		} else if stop {
+			break
+		}
+
+		count := min(remaining-next, guard.maxPages-guard.pages)
+		if guard.cfg.MaxRecords > 0 && pageSize > 0 {
+			needed := (guard.cfg.MaxRecords - guard.records + pageSize - 1) / pageSize
+			count = min(count, needed)
+		}
+
+		requests := make([]pageRequest, count)
+		for i := range requests {
+			pr, err := build(next + i)
+			if err != nil {
+				return nil, err
+			}
+			// Conditional headers only apply to the first page
+			pr.conditional = nil
+			requests[i] = pr
+		}
+		next += count
+
+		pages, err := h.fetchPagesParallel(ctx, requests)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, records := range pages {
+			kept, stop, err := guard.afterPage(records)
+			if err != nil {
+				return nil, err
+			}
+			allRecords = append(allRecords, kept...)
+			if stop || len(records) == 0 {
+				return allRecords, nil
+			}
+		}
+	}
+
+	return allRecords, nil
+}
+
+// fetchPagesParallel fetches the given pages with at most pagination.concurrency
+// requests in flight. Retry and rate limiting apply per page. The first error
+// cancels the pages still in flight. Results are returned in request order.
+func (h *HTTPPolling) fetchPagesParallel(ctx context.Context, requests []pageRequest) ([][]map[string]interface{}, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]map[string]interface{}, len(requests))
+	sem := make(chan struct{}, h.pagination.Concurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for i, pr := range requests {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, pr pageRequest) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			records, err := h.fetchParallelPage(ctx, pr)
+			results[i] = records
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			logger.Debug(logMsgPaginationPageFetched,
+				"module_type", "httpPolling",
+				"pagination_type", h.pagination.Type,
+				"endpoint", pr.endpoint,
+				"records_in_page", len(results[i]),
+				"parallel", true,
+			)
+		}(i, pr)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// fetchParallelPage fetches and parses one page fetched in parallel, with retry.
+func (h *HTTPPolling) fetchParallelPage(ctx context.Context, pr pageRequest) ([]map[string]interface{}, error) {
+	body, err := h.doRequestWithRetry(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJSONParse, err)
+	}
+	return h.extractRecordsFromObject(obj)
+}
@@ -0,0 +1,246 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// parallelPageServer serves totalPages pages of 2 records with page-based
+// pagination, tracking the maximum number of requests in flight.
+type parallelPageServer struct {
+	totalPages  int
+	failOnce    int // page failing with 500 on its first request, 0 for none
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	mu          sync.Mutex
+	requests    map[int]int
+}
+
+func (s *parallelPageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	current := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		previous := s.maxInFlight.Load()
+		if current <= previous || s.maxInFlight.CompareAndSwap(previous, current) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	s.mu.Lock()
+	s.requests[page]++
+	attempt := s.requests[page]
+	s.mu.Unlock()
+
+	if page == s.failOnce && attempt == 1 {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": []map[string]interface{}{
+			{"id": page*10 + 1},
+			{"id": page*10 + 2},
+		},
+		"totalPages": s.totalPages,
+	})
+}
+
+func parallelPageConfig(endpoint string, pagination map[string]interface{}) *connector.ModuleConfig {
+	p := map[string]interface{}{
+		"type":            "page",
+		"pageParam":       "page",
+		"totalPagesField": "totalPages",
+		"concurrency":     float64(3),
+	}
+	for k, v := range pagination {
+		p[k] = v
+	}
+	return &connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint":   endpoint,
+			"pagination": p,
+			"retry": map[string]interface{}{
+				"maxAttempts": float64(2),
+				"delayMs":     float64(1),
+			},
+		},
+	}
+}
+
+func TestHTTPPolling_ParallelPages_PageBased(t *testing.T) {
+	srv := &parallelPageServer{totalPages: 8, failOnce: 5, requests: map[int]int{}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(parallelPageConfig(server.URL, nil))
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 16 {
+		t.Fatalf("expected 16 records, got %d", len(records))
+	}
+
+	// Records are reassembled in page order
+	for i, record := range records {
+		page, n := i/2+1, i%2+1
+		if id, _ := record["id"].(float64); int(id) != page*10+n {
+			t.Fatalf("record %d: expected id %d, got %v", i, page*10+n, record["id"])
+		}
+	}
+
+	if got := srv.maxInFlight.Load(); got > 3 {
+		t.Errorf("expected at most 3 requests in flight, got %d", got)
+	}
+	if got := srv.maxInFlight.Load(); got < 2 {
+		t.Errorf("expected pages to be fetched in parallel, got %d in flight", got)
+	}
+	// The failing page is retried on its own
+	if srv.requests[5] != 2 || srv.requests[4] != 1 {
+		t.Errorf("expected page 5 retried once and page 4 fetched once, got %v", srv.requests)
+	}
+}
+
+func TestHTTPPolling_ParallelPages_OffsetBased(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		if current > maxInFlight.Load() {
+			maxInFlight.Store(current)
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		var data []map[string]interface{}
+		for id := offset; id < offset+4 && id < 10; id++ {
+			data = append(data, map[string]interface{}{"id": id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "total": 10})
+	}))
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"pagination": map[string]interface{}{
+				"type":        "offset",
+				"offsetParam": "offset",
+				"limitParam":  "limit",
+				"limit":       float64(4),
+				"totalField":  "total",
+				"concurrency": float64(4),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 10 {
+		t.Fatalf("expected 10 records, got %d", len(records))
+	}
+	for i, record := range records {
+		if id, _ := record["id"].(float64); int(id) != i {
+			t.Fatalf("record %d: expected id %d, got %v", i, i, record["id"])
+		}
+	}
+}
+
+func TestHTTPPolling_ParallelPages_Limits(t *testing.T) {
+	t.Run("maxRecords bounds the pages fetched", func(t *testing.T) {
+		srv := &parallelPageServer{totalPages: 20, requests: map[int]int{}}
+		server := httptest.NewServer(srv)
+		defer server.Close()
+
+		h, err := NewHTTPPollingFromConfig(parallelPageConfig(server.URL, map[string]interface{}{
+			"maxRecords": float64(5),
+		}))
+		if err != nil {
+			t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+		}
+		records, err := h.Fetch(context.Background())
+		if err != nil {
+			t.Fatalf("Fetch() returned error: %v", err)
+		}
+		if len(records) != 5 {
+			t.Errorf("expected 5 records, got %d", len(records))
+		}
+		if len(srv.requests) != 3 {
+			t.Errorf("expected 3 pages requested, got %d", len(srv.requests))
+		}
+	})
+
+	t.Run("maxPages with onLimit error", func(t *testing.T) {
+		srv := &parallelPageServer{totalPages: 20, requests: map[int]int{}}
+		server := httptest.NewServer(srv)
+		defer server.Close()
+
+		h, err := NewHTTPPollingFromConfig(parallelPageConfig(server.URL, map[string]interface{}{
+			"maxPages": float64(4),
+			"onLimit":  "error",
+		}))
+		if err != nil {
+			t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+		}
+		if _, err := h.Fetch(context.Background()); !errors.Is(err, ErrPaginationLimit) {
+			t.Errorf("Fetch() error = %v, want ErrPaginationLimit", err)
+		}
+		if len(srv.requests) != 4 {
+			t.Errorf("expected 4 pages requested, got %d", len(srv.requests))
+		}
+	})
+
+	t.Run("error cancels remaining pages", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data":       []map[string]interface{}{{"id": 1}},
+				"totalPages": 5,
+			})
+		}))
+		defer server.Close()
+
+		h, err := NewHTTPPollingFromConfig(parallelPageConfig(server.URL, nil))
+		if err != nil {
+			t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+		}
+		if _, err := h.Fetch(context.Background()); err == nil {
+			t.Error("expected Fetch() to fail when a page fails")
+		}
+	})
+}
+
+func TestHTTPPolling_ParallelPages_InvalidConcurrency(t *testing.T) {
+	_, err := NewHTTPPollingFromConfig(parallelPageConfig("http://example.com", map[string]interface{}{
+		"concurrency": float64(0),
+	}))
+	if !errors.Is(err, ErrInvalidPagination) {
+		t.Errorf("error = %v, want ErrInvalidPagination", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+var (
+	// ErrInvalidStreaming is returned when streaming is configured without
+	// pagination or with forEach.
+	ErrInvalidStreaming = errors.New("invalid streaming configuration")
+
+	// ErrInvalidCheckpoint is returned when a checkpoint cannot be resumed from.
+	ErrInvalidCheckpoint = errors.New("invalid httpPolling checkpoint")
+)
+
+// extractStreaming parses the streaming configuration: {enabled} (default
+// true when the object is present). Streaming hands each page downstream as
+// it is fetched, so it requires pagination and cannot be combined with forEach.
+func extractStreaming(config *connector.ModuleConfig, pagination *PaginationConfig, forEach *ForEachConfig) (bool, error) {
+	raw, ok := config.Config["streaming"].(map[string]interface{})
+	if !ok {
+		return false, nil
+	}
+	enabled := true
+	if v, ok := raw["enabled"].(bool); ok {
+		enabled = v
+	}
+	if !enabled {
+		return false, nil
+	}
+	if pagination == nil {
+		return false, fmt.Errorf("%w: streaming requires pagination", ErrInvalidStreaming)
+	}
+	if forEach != nil {
+		return false, fmt.Errorf("%w: streaming cannot be combined with forEach", ErrInvalidStreaming)
+	}
+	return true, nil
+}
+
+// Streaming reports whether pages are streamed downstream as they are fetched.
+func (h *HTTPPolling) Streaming() bool {
+	return h.streaming
+}
+
+// Stream follows pagination and calls handle with the records of each page
+// as it is fetched, instead of aggregating all pages. Pages are fetched
+// sequentially (pagination.concurrency does not apply).
+//
+// After ResumeFrom, pagination starts at the checkpointed page, offset,
+// cursor or searchAfter values, and conditional requests are not sent.
+func (h *HTTPPolling) Stream(ctx context.Context, handle ChunkHandler) error {
+	startTime := time.Now()
+	resume := h.resume
+	h.resume = nil
+	h.start = resume
+	h.position = nil
+	h.pageSink = func(records []map[string]interface{}) error {
+		return handle(ctx, records)
+	}
+	defer func() {
+		h.pageSink = nil
+		h.start = nil
+	}()
+
+	logger.Info("input stream started",
+		"module_type", "httpPolling",
+		"endpoint", h.endpoint,
+		"method", h.method,
+		"pagination_type", h.pagination.Type,
+		"resumed", resume != nil,
+	)
+
+	target := fetchTarget{
+		endpoint:     h.endpoint,
+		bodyTemplate: h.bodyTemplate,
+		state:        h.lastState,
+	}
+	if resume == nil {
+		// A resumed run does not start with the first page: validators
+		// would not describe the resource
+		target.conditional = h.newConditionalExchange(h.lastState)
+	}
+	_, err := h.fetchTarget(ctx, target)
+	if err == nil && target.conditional != nil {
+		h.etag, h.lastModified = target.conditional.validators()
+	}
+
+	duration := time.Since(startTime)
+	if err != nil {
+		logger.Error("input stream failed",
+			"module_type", "httpPolling",
+			"endpoint", h.endpoint,
+			"duration", duration,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	logger.Info("input stream completed",
+		"module_type", "httpPolling",
+		"endpoint", h.endpoint,
+		"duration", duration,
+	)
+	return nil
+}
+
+// deliverPage adds the records kept from a page to all, or hands them
+// downstream when streaming. next is the position to resume from after this
+// page, nil if the page cannot be resumed after.
+func (h *HTTPPolling) deliverPage(all *[]map[string]interface{}, records []map[string]interface{}, next *persistence.Checkpoint) error {
+	if h.pageSink == nil {
+		*all = append(*all, records...)
+		return nil
+	}
+	h.position = next
+	if len(records) == 0 {
+		return nil
+	}
+	return h.pageSink(records)
+}
+
+// ResumeFrom makes the next Stream start at the checkpointed position.
+func (h *HTTPPolling) ResumeFrom(checkpoint *persistence.Checkpoint) error {
+	if !h.streaming {
+		return fmt.Errorf("%w: streaming is not enabled", ErrInvalidCheckpoint)
+	}
+	switch h.pagination.Type {
+	case "page":
+		if checkpoint.Page < 1 {
+			return fmt.Errorf("%w: no page for page pagination", ErrInvalidCheckpoint)
+		}
+	case "offset":
+		if checkpoint.Offset < 0 {
+			return fmt.Errorf("%w: negative offset %d", ErrInvalidCheckpoint, checkpoint.Offset)
+		}
+	case "cursor":
+		if checkpoint.PageCursor == "" {
+			return fmt.Errorf("%w: no cursor for cursor pagination", ErrInvalidCheckpoint)
+		}
+	case "searchAfter":
+		if checkpoint.SearchAfter == nil {
+			return fmt.Errorf("%w: no searchAfter values for searchAfter pagination", ErrInvalidCheckpoint)
+		}
+	default:
+		return fmt.Errorf("%w: pagination type %q cannot be resumed", ErrInvalidCheckpoint, h.pagination.Type)
+	}
+	h.resume = checkpoint
+	return nil
+}
+
+// Checkpoint returns the position after the last page handed downstream, or
+// nil if it cannot be resumed after (e.g. last page of cursor pagination).
+func (h *HTTPPolling) Checkpoint() *persistence.Checkpoint {
+	if h.position == nil {
+		return nil
+	}
+	position := *h.position
+	return &position
+}
@@ -0,0 +1,163 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+func TestExtractStreaming(t *testing.T) {
+	pagination := &PaginationConfig{Type: "page"}
+	tests := []struct {
+		name       string
+		config     map[string]interface{}
+		pagination *PaginationConfig
+		forEach    *ForEachConfig
+		want       bool
+		wantErr    bool
+	}{
+		{name: "absent", config: map[string]interface{}{}, pagination: pagination},
+		{name: "enabled", config: map[string]interface{}{"streaming": map[string]interface{}{}}, pagination: pagination, want: true},
+		{name: "disabled", config: map[string]interface{}{"streaming": map[string]interface{}{"enabled": false}}},
+		{name: "without pagination", config: map[string]interface{}{"streaming": map[string]interface{}{}}, wantErr: true},
+		{name: "with forEach", config: map[string]interface{}{"streaming": map[string]interface{}{}}, pagination: pagination, forEach: &ForEachConfig{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractStreaming(&connector.ModuleConfig{Config: tt.config}, tt.pagination, tt.forEach)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidStreaming)) {
+				t.Fatalf("extractStreaming() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("extractStreaming() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPPolling_Stream_PageResume(t *testing.T) {
+	server := &parallelPageServer{totalPages: 4, requests: map[int]int{}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	newStream := func() *HTTPPolling {
+		t.Helper()
+		cfg := parallelPageConfig(ts.URL, nil)
+		cfg.Config["streaming"] = map[string]interface{}{}
+		h, err := NewHTTPPollingFromConfig(cfg)
+		if err != nil {
+			t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+		}
+		return h
+	}
+
+	// The first run fails on the second page
+	first := newStream()
+	var checkpoint *persistence.Checkpoint
+	pages := 0
+	err := first.Stream(context.Background(), func(_ context.Context, records []map[string]interface{}) error {
+		pages++
+		if pages == 2 {
+			return errors.New("destination unavailable")
+		}
+		checkpoint = first.Checkpoint()
+		return nil
+	})
+	if err == nil || checkpoint == nil || checkpoint.Page != 2 {
+		t.Fatalf("Stream() = %v, checkpoint %+v, want failure after a checkpoint at page 2", err, checkpoint)
+	}
+	if got := server.maxInFlight.Load(); got != 1 {
+		t.Errorf("streamed pages fetched with %d requests in flight, want sequential", got)
+	}
+
+	// The second run resumes at the page that failed
+	second := newStream()
+	if err := second.ResumeFrom(checkpoint); err != nil {
+		t.Fatalf("ResumeFrom() error = %v", err)
+	}
+	var ids []int
+	err = second.Stream(context.Background(), func(_ context.Context, records []map[string]interface{}) error {
+		for _, record := range records {
+			ids = append(ids, int(record["id"].(float64)))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if len(ids) != 6 || ids[0] != 21 || ids[5] != 42 {
+		t.Errorf("resumed ids = %v, want pages 2 to 4", ids)
+	}
+	if server.requests[1] != 1 {
+		t.Errorf("page 1 requested %d times, want 1", server.requests[1])
+	}
+}
+
+func TestHTTPPolling_Stream_CursorResume(t *testing.T) {
+	// Three pages of cursor pagination: "" -> c1 -> c2 -> end
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := 0
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			page, _ = strconv.Atoi(cursor[1:])
+		}
+		next := ""
+		if page < 2 {
+			next = "c" + strconv.Itoa(page+1)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":       []map[string]interface{}{{"id": page}},
+			"nextCursor": next,
+		})
+	}))
+	defer ts.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": ts.URL,
+			"pagination": map[string]interface{}{
+				"type":            "cursor",
+				"cursorParam":     "cursor",
+				"nextCursorField": "nextCursor",
+			},
+			"streaming": map[string]interface{}{"enabled": true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+	}
+
+	if err := h.ResumeFrom(&persistence.Checkpoint{}); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Errorf("ResumeFrom(empty) error = %v, want ErrInvalidCheckpoint", err)
+	}
+	if err := h.ResumeFrom(&persistence.Checkpoint{PageCursor: "c1"}); err != nil {
+		t.Fatalf("ResumeFrom() error = %v", err)
+	}
+
+	var ids []int
+	var positions []*persistence.Checkpoint
+	err = h.Stream(context.Background(), func(_ context.Context, records []map[string]interface{}) error {
+		ids = append(ids, int(records[0]["id"].(float64)))
+		positions = append(positions, h.Checkpoint())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("resumed ids = %v, want [1 2]", ids)
+	}
+	// The last page has no next cursor: it cannot be resumed after
+	if len(positions) != 2 || positions[0] == nil || positions[0].PageCursor != "c2" || positions[1] != nil {
+		t.Errorf("checkpoints = %+v, want c2 then none", positions)
+	}
+}
@@ -4,12 +4,17 @@ package input
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/internal/ratelimit"
 	"github.com/cannectors/runtime/pkg/connector"
 )
 
@@ -1333,3 +1338,372 @@ func TestHTTPPolling_Close_ReleasesConnections(t *testing.T) {
 		t.Errorf("Close() returned error on second call: %v", err)
 	}
 }
+
+// =============================================================================
+// Request Body Pagination Tests
+// =============================================================================
+
+// writeBodyTemplate writes a JSON body template file for tests.
+func writeBodyTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "body.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write body template: %v", err)
+	}
+	return path
+}
+
+// TestHTTPPolling_Fetch_OffsetPaginationInBody tests offset/limit injected into nested body fields.
+func TestHTTPPolling_Fetch_OffsetPaginationInBody(t *testing.T) {
+	var bodies []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST method, got %s", r.Method)
+		}
+		if r.URL.RawQuery != "" {
+			t.Errorf("expected no query params, got %q", r.URL.RawQuery)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected Content-Type application/json, got %q", ct)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		bodies = append(bodies, body)
+
+		paging := body["paging"].(map[string]interface{})
+		var items []map[string]interface{}
+		if paging["offset"] == float64(0) {
+			items = []map[string]interface{}{{"id": float64(1)}, {"id": float64(2)}}
+		} else {
+			items = []map[string]interface{}{{"id": float64(3)}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	defer server.Close()
+
+	config := &connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint":         server.URL + "/search",
+			"bodyTemplateFile": writeBodyTemplate(t, `{"filter": {"status": "open"}}`),
+			"dataField":        "items",
+			"pagination": map[string]interface{}{
+				"type":        "offset",
+				"location":    "body",
+				"offsetParam": "paging.offset",
+				"limitParam":  "paging.limit",
+				"limit":       float64(2),
+			},
+		},
+	}
+
+	polling, err := NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := polling.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(bodies))
+	}
+	second := bodies[1]["paging"].(map[string]interface{})
+	if second["offset"] != float64(2) || second["limit"] != float64(2) {
+		t.Errorf("unexpected paging in second body: %v", second)
+	}
+	filter := bodies[1]["filter"].(map[string]interface{})
+	if filter["status"] != "open" {
+		t.Errorf("expected template fields to be preserved, got %v", bodies[1])
+	}
+}
+
+// TestHTTPPolling_Fetch_CursorPaginationInBody tests the cursor injected into the body.
+func TestHTTPPolling_Fetch_CursorPaginationInBody(t *testing.T) {
+	var cursors []interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		cursors = append(cursors, body["pageToken"])
+
+		if body["pageToken"] == nil {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data":          []map[string]interface{}{{"id": "a"}},
+				"nextPageToken": "token-2",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{"id": "b"}},
+		})
+	}))
+	defer server.Close()
+
+	config := &connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"method":   "post",
+			"pagination": map[string]interface{}{
+				"type":            "cursor",
+				"location":        "body",
+				"cursorParam":     "pageToken",
+				"nextCursorField": "nextPageToken",
+			},
+		},
+	}
+
+	polling, err := NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := polling.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 records, got %d", len(records))
+	}
+	if len(cursors) != 2 || cursors[0] != nil || cursors[1] != "token-2" {
+		t.Errorf("unexpected cursors sent: %v", cursors)
+	}
+}
+
+// TestHTTPPolling_Fetch_SearchAfterPagination tests Elasticsearch-style search_after pagination.
+func TestHTTPPolling_Fetch_SearchAfterPagination(t *testing.T) {
+	var searchAfters []interface{}
+
+	hit := func(id string, ts float64) map[string]interface{} {
+		return map[string]interface{}{
+			"_id":     id,
+			"_source": map[string]interface{}{"id": id},
+			"sort":    []interface{}{ts, id},
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["size"] != float64(2) {
+			t.Errorf("expected size 2, got %v", body["size"])
+		}
+		searchAfters = append(searchAfters, body["search_after"])
+
+		var hits []map[string]interface{}
+		if body["search_after"] == nil {
+			hits = []map[string]interface{}{hit("1", 100), hit("2", 200)}
+		} else {
+			hits = []map[string]interface{}{hit("3", 300)}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"hits": map[string]interface{}{"hits": hits},
+		})
+	}))
+	defer server.Close()
+
+	config := &connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint":         server.URL + "/orders/_search",
+			"bodyTemplateFile": writeBodyTemplate(t, `{"query": {"match_all": {}}, "sort": [{"ts": "asc"}, {"_id": "asc"}]}`),
+			"dataField":        "hits.hits",
+			"pagination": map[string]interface{}{
+				"type":       "searchAfter",
+				"limitParam": "size",
+				"limit":      float64(2),
+			},
+		},
+	}
+
+	polling, err := NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := polling.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if len(searchAfters) != 2 || searchAfters[0] != nil {
+		t.Fatalf("unexpected search_after values: %v", searchAfters)
+	}
+	second, ok := searchAfters[1].([]interface{})
+	if !ok || len(second) != 2 || second[0] != float64(200) || second[1] != "2" {
+		t.Errorf("expected search_after [200, \"2\"], got %v", searchAfters[1])
+	}
+}
+
+// TestHTTPPolling_Fetch_StateInBody tests state persistence values injected into body fields.
+func TestHTTPPolling_Fetch_StateInBody(t *testing.T) {
+	var received map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "42"}})
+	}))
+	defer server.Close()
+
+	storeDir := t.TempDir()
+	lastTimestamp := time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)
+	lastID := "41"
+	if err := persistence.NewFileStateStore(storeDir).Save("body-state", &persistence.State{
+		PipelineID:    "body-state",
+		LastTimestamp: &lastTimestamp,
+		LastID:        &lastID,
+	}); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	config := &connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint":         server.URL,
+			"bodyTemplateFile": writeBodyTemplate(t, `{"query": {"type": "orders"}}`),
+			"statePersistence": map[string]interface{}{
+				"timestamp":   map[string]interface{}{"enabled": true, "bodyField": "query.updatedSince"},
+				"id":          map[string]interface{}{"enabled": true, "field": "id", "bodyField": "afterId"},
+				"storagePath": storeDir,
+			},
+		},
+	}
+
+	polling, err := NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+	polling.SetPipelineID("body-state")
+	if _, err := polling.LoadState(); err != nil {
+		t.Fatalf("LoadState() returned error: %v", err)
+	}
+
+	if _, err := polling.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+
+	query := received["query"].(map[string]interface{})
+	if query["updatedSince"] != "2026-02-01T08:00:00Z" {
+		t.Errorf("expected query.updatedSince from state, got %v", query["updatedSince"])
+	}
+	if query["type"] != "orders" {
+		t.Errorf("expected template field query.type to be preserved, got %v", query["type"])
+	}
+	if received["afterId"] != "41" {
+		t.Errorf("expected afterId from state, got %v", received["afterId"])
+	}
+}
+
+// TestNewHTTPPollingFromConfig_BodyConfigErrors tests invalid body-related configuration.
+func TestNewHTTPPollingFromConfig_BodyConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+	}{
+		{
+			name: "body pagination with GET",
+			config: map[string]interface{}{
+				"endpoint":   "https://api.example.com",
+				"method":     "GET",
+				"pagination": map[string]interface{}{"type": "page", "location": "body", "pageParam": "page"},
+			},
+		},
+		{
+			name: "body template is not a JSON object",
+			config: map[string]interface{}{
+				"endpoint":         "https://api.example.com",
+				"bodyTemplateFile": writeBodyTemplate(t, `[1, 2, 3]`),
+			},
+		},
+		{
+			name: "unsupported method",
+			config: map[string]interface{}{
+				"endpoint": "https://api.example.com",
+				"method":   "DELETE",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{Type: "httpPolling", Config: tt.config})
+			if err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Rate Limiting Tests
+// =============================================================================
+
+func TestHTTPPolling_RateLimit_PacesPages(t *testing.T) {
+	var requestTimes []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestTimes = append(requestTimes, time.Now())
+		page := r.URL.Query().Get("page")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data":       []map[string]interface{}{{"page": page}},
+			"totalPages": 3,
+		})
+	}))
+	defer server.Close()
+
+	h, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"pagination": map[string]interface{}{
+				"type":            "page",
+				"pageParam":       "page",
+				"totalPagesField": "totalPages",
+			},
+			"rateLimit": map[string]interface{}{
+				"requestsPerSecond": float64(20),
+				"burst":             float64(1),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig failed: %v", err)
+	}
+
+	records, err := h.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch() returned error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	// 3 requests at 20 req/s with burst 1: at least ~100ms between first and last
+	if elapsed := requestTimes[2].Sub(requestTimes[0]); elapsed < 90*time.Millisecond {
+		t.Errorf("expected paced requests, 3 pages took %v", elapsed)
+	}
+}
+
+func TestNewHTTPPollingFromConfig_InvalidRateLimit(t *testing.T) {
+	_, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint":  "https://api.example.com/data",
+			"rateLimit": map[string]interface{}{"requestsPerSecond": "fast"},
+		},
+	})
+	if !errors.Is(err, ratelimit.ErrInvalidConfig) {
+		t.Fatalf("expected ratelimit.ErrInvalidConfig, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package input provides implementations for input modules.
+package input
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/persistence"
+)
+
+// ErrWindowParams is returned when a time window is set on a module without
+// timestamp parameters for both of its bounds.
+var ErrWindowParams = errors.New("time window requires timestamp parameters for both bounds")
+
+// SetWindow makes the next fetch read the records of a time window (backfill):
+// the window start is sent in statePersistence.timestamp queryParam or
+// bodyField, and its end in untilQueryParam or untilBodyField. The persisted
+// ID and response validators are not sent, and no lookback is applied.
+func (h *HTTPPolling) SetWindow(window persistence.Window) error {
+	if !h.persistenceConfig.TimestampEnabled() {
+		return fmt.Errorf("%w: statePersistence.timestamp is not enabled", ErrWindowParams)
+	}
+	ts := h.persistenceConfig.Timestamp
+	if ts.QueryParam == "" && ts.BodyField == "" {
+		return fmt.Errorf("%w: set statePersistence.timestamp queryParam or bodyField", ErrWindowParams)
+	}
+	if ts.UntilQueryParam == "" && ts.UntilBodyField == "" {
+		return fmt.Errorf("%w: set statePersistence.timestamp untilQueryParam or untilBodyField", ErrWindowParams)
+	}
+	h.window = &window
+	return nil
+}
+
+// buildEndpointWithWindow sets the window bounds in the configured query parameters.
+func (h *HTTPPolling) buildEndpointWithWindow(endpoint string) (string, error) {
+	ts := h.persistenceConfig.Timestamp
+	if ts.QueryParam == "" && ts.UntilQueryParam == "" {
+		return endpoint, nil
+	}
+
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf(errMsgParsingEndpointURL, err)
+	}
+	q := parsedURL.Query()
+	if ts.QueryParam != "" {
+		q.Set(ts.QueryParam, h.window.From.Format(time.RFC3339))
+	}
+	if ts.UntilQueryParam != "" {
+		q.Set(ts.UntilQueryParam, h.window.To.Format(time.RFC3339))
+	}
+	parsedURL.RawQuery = q.Encode()
+
+	logger.Debug("added time window query params",
+		"pipeline_id", h.pipelineID,
+		"from", h.window.From.Format(time.RFC3339),
+		"to", h.window.To.Format(time.RFC3339),
+	)
+	return parsedURL.String(), nil
+}
+
+// applyWindowToBody sets the window bounds in the configured body fields.
+func (h *HTTPPolling) applyWindowToBody(body map[string]interface{}) error {
+	ts := h.persistenceConfig.Timestamp
+	if ts.BodyField != "" {
+		if err := setBodyField(body, ts.BodyField, h.window.From.Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+	if ts.UntilBodyField != "" {
+		if err := setBodyField(body, ts.UntilBodyField, h.window.To.Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package input
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+func TestHTTPPolling_SetWindow(t *testing.T) {
+	var received url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Query()
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": 1}})
+	}))
+	defer server.Close()
+
+	polling, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+		Type: "httpPolling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "?status=open",
+			"statePersistence": map[string]interface{}{
+				"timestamp": map[string]interface{}{
+					"enabled":         true,
+					"queryParam":      "updated_after",
+					"untilQueryParam": "updated_before",
+					"lookbackSeconds": float64(300),
+				},
+				"id": map[string]interface{}{"enabled": true, "field": "id", "queryParam": "after_id"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+	}
+
+	// The persisted state is ignored by windowed fetches
+	lastID := "42"
+	lastTimestamp := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	polling.lastState = &persistence.State{LastTimestamp: &lastTimestamp, LastID: &lastID}
+
+	window := persistence.Window{
+		From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if err := polling.SetWindow(window); err != nil {
+		t.Fatalf("SetWindow() error = %v", err)
+	}
+	if _, err := polling.Fetch(context.Background()); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if got := received.Get("updated_after"); got != "2026-01-01T00:00:00Z" {
+		t.Errorf("updated_after = %q, want window start without lookback", got)
+	}
+	if got := received.Get("updated_before"); got != "2026-01-02T00:00:00Z" {
+		t.Errorf("updated_before = %q, want window end", got)
+	}
+	if received.Has("after_id") || received.Get("status") != "open" {
+		t.Errorf("query = %v, want status kept and no after_id", received)
+	}
+}
+
+func TestHTTPPolling_SetWindow_RequiresBothBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		timestamp map[string]interface{}
+	}{
+		{name: "timestamp disabled", timestamp: map[string]interface{}{"enabled": false, "queryParam": "from", "untilQueryParam": "to"}},
+		{name: "no start parameter", timestamp: map[string]interface{}{"enabled": true, "untilQueryParam": "to"}},
+		{name: "no end parameter", timestamp: map[string]interface{}{"enabled": true, "queryParam": "from"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polling, err := NewHTTPPollingFromConfig(&connector.ModuleConfig{
+				Type: "httpPolling",
+				Config: map[string]interface{}{
+					"endpoint":         "https://api.example.com/orders",
+					"statePersistence": map[string]interface{}{"timestamp": tt.timestamp},
+				},
+			})
+			if err != nil {
+				t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+			}
+			if err := polling.SetWindow(persistence.Window{}); !errors.Is(err, ErrWindowParams) {
+				t.Errorf("SetWindow() error = %v, want ErrWindowParams", err)
+			}
+		})
+	}
+}
@@ -143,3 +143,24 @@ type Module interface {
 	// Implementations must release all resources (connections, file handles, etc.).
 	Close() error
 }
+
+// ChunkHandler processes one chunk of records streamed by a StreamingModule.
+// Returning an error stops the stream.
+type ChunkHandler func(ctx context.Context, records []map[string]interface{}) error
+
+// StreamingModule is an optional interface for input modules that can hand
+// records downstream in chunks instead of materialising the full result set.
+// The runtime runs filters and output once per chunk, so memory usage is
+// bounded by the chunk size rather than by the size of the source.
+type StreamingModule interface {
+	Module
+
+	// Streaming reports whether the module is configured to stream.
+	// When false, the runtime uses Fetch.
+	Streaming() bool
+
+	// Stream fetches records and calls handle once per chunk, in source order.
+	// Chunks are not reused after handle returns.
+	// If handle returns an error, streaming stops and the error is returned.
+	Stream(ctx context.Context, handle ChunkHandler) error
+}
@@ -33,7 +33,8 @@ const (
 var (
 	ErrDatabaseOutputNilConfig      = errors.New("database output configuration is nil")
 	ErrDatabaseOutputMissingConnStr = errors.New("connection string is required for database output")
-	ErrDatabaseOutputMissingQuery   = errors.New("query or queryFile is required for database output")
+	ErrDatabaseOutputMissingQuery   = errors.New("query, queryFile or table is required for database output")
+	ErrDatabaseOutputInvalidTx      = errors.New("invalid database output transaction configuration")
 )
 
 // DatabaseOutputConfig holds configuration for the database output module.
@@ -47,12 +48,43 @@ type DatabaseOutputConfig struct {
 	Query     string `json:"query"`     // Inline SQL query with {{record.field}} templates
 	QueryFile string `json:"queryFile"` // Path to SQL file with {{record.field}} templates
 
+	// NamedParameters also binds :field and @field references to record
+	// fields; a field missing from the record is then an error
+	NamedParameters bool `json:"namedParameters"`
+
+	// Declarative mode - use table instead of query: the statement is generated
+	Table   string        `json:"table"`   // Target table, optionally schema-qualified
+	Columns []writeColumn `json:"columns"` // Record field to column mapping
+	Keys    []string      `json:"keys"`    // Key columns matching existing rows
+	Mode    string        `json:"mode"`    // "insert", "upsert", "update", "delete"
+
+	// Schema management: "manual" (default) or "auto" to create the table
+	// and add missing columns, typed from columnTypes or inferred from the
+	// first schemaSampleSize values of each column
+	Schema           string            `json:"schema"`
+	SchemaSampleSize int               `json:"schemaSampleSize"`
+	ColumnTypes      map[string]string `json:"columnTypes"` // Column to SQL type
+
 	// Transaction configuration
-	Transaction bool `json:"transaction"` // Wrap operations in transaction
+	Transaction    bool   `json:"transaction"`    // Wrap operations in transaction
+	IsolationLevel string `json:"isolationLevel"` // "default", "read_committed", "serializable", ...
+	CommitEvery    int    `json:"commitEvery"`    // Commit the transaction every N records (0 = once)
+
+	// Returning: columns returned by each write (RETURNING, or LastInsertId
+	// on MySQL), stored in the record or at returningField (e.g. _metadata.db)
+	Returning      []string `json:"returning"`
+	ReturningField string   `json:"returningField"`
+
+	// Batching: records written per statement or prepared batch (0 or 1 = one at a time)
+	BatchSize int `json:"batchSize"`
 
 	// Error handling
 	OnError string `json:"onError"` // "fail", "skip", "log"
 
+	// Dry-run: execute the previewed statements in a transaction that is
+	// always rolled back, to report constraint and syntax errors
+	DryRunRollback bool `json:"dryRunRollback"`
+
 	// Pool configuration
 	MaxOpenConns    int `json:"maxOpenConns"`
 	MaxIdleConns    int `json:"maxIdleConns"`
@@ -65,10 +97,16 @@ type DatabaseOutputConfig struct {
 
 // DatabaseOutput implements a database output module.
 type DatabaseOutput struct {
-	db      *sql.DB
-	driver  string
-	config  DatabaseOutputConfig
-	timeout time.Duration
+	db        *sql.DB
+	driver    string
+	config    DatabaseOutputConfig
+	timeout   time.Duration
+	statement *writeStatement // generated statement in declarative mode
+	txOptions *sql.TxOptions  // isolation level of transactions, nil for the default
+
+	tableSchema *tableSchema // table columns with schema auto, read on first send
+
+	returned []map[string]interface{} // records of the last send with returned values
 }
 
 // NewDatabaseOutputFromConfig creates a new database output module from configuration.
@@ -96,8 +134,22 @@ func NewDatabaseOutputFromConfig(cfg *connector.ModuleConfig) (*DatabaseOutput,
 		config.Query = string(queryBytes)
 	}
 
-	// Validate query is present
-	if config.Query == "" {
+	if err := validateSchemaConfig(config); err != nil {
+		return nil, err
+	}
+
+	// Validate query or table is present
+	if config.Table != "" {
+		if config.Query != "" {
+			return nil, fmt.Errorf("%w: table cannot be combined with query or queryFile", ErrDatabaseOutputInvalidTable)
+		}
+		if config.Mode == "" {
+			config.Mode = WriteModeInsert
+		}
+		if err := validateWriteConfig(config); err != nil {
+			return nil, err
+		}
+	} else if config.Query == "" {
 		return nil, ErrDatabaseOutputMissingQuery
 	}
 
@@ -110,6 +162,17 @@ func NewDatabaseOutputFromConfig(cfg *connector.ModuleConfig) (*DatabaseOutput,
 	if config.OnError == "" {
 		config.OnError = "fail"
 	}
+	if config.SchemaSampleSize <= 0 {
+		config.SchemaSampleSize = defaultSchemaSampleSize
+	}
+
+	txOptions, err := database.TxOptions(config.IsolationLevel, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDatabaseOutputInvalidTx, err)
+	}
+	if config.CommitEvery > 0 && !config.Transaction {
+		return nil, fmt.Errorf("%w: commitEvery requires transaction", ErrDatabaseOutputInvalidTx)
+	}
 
 	// Create database config
 	dbConfig := database.Config{
@@ -129,16 +192,40 @@ func NewDatabaseOutputFromConfig(cfg *connector.ModuleConfig) (*DatabaseOutput,
 		return nil, fmt.Errorf("creating database output connection: %w", err)
 	}
 
+	if err := validateReturningConfig(config, driver); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
 	module := &DatabaseOutput{
-		db:      db,
-		driver:  driver,
-		config:  config,
-		timeout: timeout,
+		db:        db,
+		driver:    driver,
+		config:    config,
+		timeout:   timeout,
+		txOptions: txOptions,
+	}
+
+	// With schema auto, the table and statement are prepared on each send
+	if config.Table != "" && config.Schema != SchemaAuto {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := validateTableColumns(ctx, db, driver, config)
+		cancel()
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		module.statement = buildWriteStatement(driver, config)
 	}
 
 	logger.Debug("database output module created",
 		slog.String("driver", driver),
+		slog.String("table", config.Table),
+		slog.String("mode", config.Mode),
+		slog.String("schema", config.Schema),
 		slog.Bool("transaction", config.Transaction),
+		slog.String("isolation_level", config.IsolationLevel),
+		slog.Int("commit_every", config.CommitEvery),
+		slog.Int("batch_size", config.BatchSize),
 		slog.String("on_error", config.OnError),
 	)
 
@@ -167,16 +254,71 @@ func parseDatabaseOutputConfig(cfg map[string]interface{}) DatabaseOutputConfig
 	if v, ok := cfg["queryFile"].(string); ok {
 		config.QueryFile = v
 	}
+	if v, ok := cfg["namedParameters"].(bool); ok {
+		config.NamedParameters = v
+	}
+
+	// Declarative settings
+	if v, ok := cfg["table"].(string); ok {
+		config.Table = v
+	}
+	config.Columns = parseWriteColumns(cfg["columns"])
+	if keys, ok := cfg["keys"].([]interface{}); ok {
+		for _, key := range keys {
+			if k, ok := key.(string); ok {
+				config.Keys = append(config.Keys, k)
+			}
+		}
+	}
+	if v, ok := cfg["mode"].(string); ok {
+		config.Mode = v
+	}
+	if v, ok := cfg["schema"].(string); ok {
+		config.Schema = v
+	}
+	if v, ok := cfg["schemaSampleSize"].(float64); ok {
+		config.SchemaSampleSize = int(v)
+	}
+	if types, ok := cfg["columnTypes"].(map[string]interface{}); ok {
+		config.ColumnTypes = make(map[string]string, len(types))
+		for column, v := range types {
+			if t, ok := v.(string); ok {
+				config.ColumnTypes[column] = t
+			}
+		}
+	}
 
 	// Transaction configuration
 	if v, ok := cfg["transaction"].(bool); ok {
 		config.Transaction = v
 	}
+	if v, ok := cfg["isolationLevel"].(string); ok {
+		config.IsolationLevel = v
+	}
+	if v, ok := cfg["commitEvery"].(float64); ok {
+		config.CommitEvery = int(v)
+	}
+	if returning, ok := cfg["returning"].([]interface{}); ok {
+		for _, column := range returning {
+			if c, ok := column.(string); ok {
+				config.Returning = append(config.Returning, c)
+			}
+		}
+	}
+	if v, ok := cfg["returningField"].(string); ok {
+		config.ReturningField = v
+	}
+	if v, ok := cfg["batchSize"].(float64); ok {
+		config.BatchSize = int(v)
+	}
 
 	// Error handling
 	if v, ok := cfg["onError"].(string); ok {
 		config.OnError = v
 	}
+	if v, ok := cfg["dryRunRollback"].(bool); ok {
+		config.DryRunRollback = v
+	}
 
 	// Pool settings
 	if v, ok := cfg["maxOpenConns"].(float64); ok {
@@ -211,15 +353,22 @@ func (d *DatabaseOutput) Send(ctx context.Context, records []map[string]interfac
 		slog.String("module_type", "database"),
 		slog.Int("record_count", len(records)),
 		slog.Bool("transaction", d.config.Transaction),
+		slog.Int("batch_size", d.config.BatchSize),
 	)
 
-	var err error
 	var sentCount int
-
-	if d.config.Transaction {
-		sentCount, err = d.sendWithTransaction(ctx, records)
-	} else {
-		sentCount, err = d.sendWithoutTransaction(ctx, records)
+	d.returned = nil
+
+	err := d.ensureSchema(ctx, records)
+	if err == nil {
+		switch {
+		case d.config.BatchSize > 1:
+			sentCount, err = d.sendBatches(ctx, records)
+		case d.config.Transaction:
+			sentCount, err = d.sendWithTransaction(ctx, records)
+		default:
+			sentCount, err = d.sendWithoutTransaction(ctx, records)
+		}
 	}
 
 	duration := time.Since(startTime)
@@ -244,25 +393,29 @@ func (d *DatabaseOutput) Send(ctx context.Context, records []map[string]interfac
 	return sentCount, nil
 }
 
-// sendWithTransaction executes queries within a transaction.
+// sendWithTransaction executes queries within a transaction, committed
+// every commitEvery records if set.
 func (d *DatabaseOutput) sendWithTransaction(ctx context.Context, records []map[string]interface{}) (int, error) {
-	tx, err := d.db.BeginTx(ctx, nil)
+	tx, err := d.beginSendTx(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("beginning transaction: %w", err)
+		return 0, err
 	}
 
 	defer func() {
 		if r := recover(); r != nil {
-			_ = tx.Rollback()
+			tx.rollback()
 			panic(r)
 		}
 	}()
 
 	successCount := 0
 	for i, record := range records {
-		processed, err := d.processRecordInTransaction(ctx, tx, record, i)
+		processed, err := d.processRecordInTransaction(ctx, tx.Tx, record, i)
+		if err == nil {
+			err = tx.written(ctx, 1)
+		}
 		if err != nil {
-			_ = tx.Rollback()
+			tx.rollback()
 			return successCount, err
 		}
 		if processed {
@@ -280,7 +433,7 @@ func (d *DatabaseOutput) sendWithTransaction(ctx context.Context, records []map[
 // processRecordInTransaction processes a single record within a transaction.
 // Returns true if the record was successfully processed, false if skipped, and an error if processing should stop.
 func (d *DatabaseOutput) processRecordInTransaction(ctx context.Context, tx *sql.Tx, record map[string]interface{}, recordIndex int) (bool, error) {
-	query, args, err := d.buildParameterizedQuery(d.config.Query, record)
+	query, args, err := d.buildRecordQuery(record)
 	if err != nil {
 		return d.handleQueryBuildError(err, recordIndex)
 	}
@@ -288,9 +441,14 @@ func (d *DatabaseOutput) processRecordInTransaction(ctx context.Context, tx *sql
 	queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
 	defer cancel()
 
-	_, err = tx.ExecContext(queryCtx, query, args...)
+	execErr, err := d.execIsolated(ctx, tx, func() error {
+		return d.execWrite(queryCtx, tx, query, args, record)
+	})
 	if err != nil {
-		return d.handleDatabaseError(err, query, len(args), recordIndex)
+		return false, err
+	}
+	if execErr != nil {
+		return d.handleDatabaseError(execErr, query, len(args), recordIndex)
 	}
 
 	return true, nil
@@ -360,7 +518,7 @@ func (d *DatabaseOutput) sendWithoutTransaction(ctx context.Context, records []m
 // processRecordWithoutTransaction processes a single record without a transaction.
 // Returns true if the record was successfully processed, false if skipped, and an error if processing should stop.
 func (d *DatabaseOutput) processRecordWithoutTransaction(ctx context.Context, record map[string]interface{}, recordIndex int) (bool, error) {
-	query, args, err := d.buildParameterizedQuery(d.config.Query, record)
+	query, args, err := d.buildRecordQuery(record)
 	if err != nil {
 		return d.handleQueryBuildError(err, recordIndex)
 	}
@@ -368,7 +526,7 @@ func (d *DatabaseOutput) processRecordWithoutTransaction(ctx context.Context, re
 	queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
 	defer cancel()
 
-	_, err = d.db.ExecContext(queryCtx, query, args...)
+	err = d.execWrite(queryCtx, d.db, query, args, record)
 	if err != nil {
 		return d.handleDatabaseError(err, query, len(args), recordIndex)
 	}
@@ -376,46 +534,31 @@ func (d *DatabaseOutput) processRecordWithoutTransaction(ctx context.Context, re
 	return true, nil
 }
 
-// buildParameterizedQuery builds a parameterized query from a template.
-// Replaces {{record.field}} placeholders with parameterized values.
-// Validates that all template placeholders are replaced to prevent SQL injection.
-func (d *DatabaseOutput) buildParameterizedQuery(queryTemplate string, record map[string]interface{}) (string, []interface{}, error) {
-	query := queryTemplate
-	var args []interface{}
-
-	paramIndex := 1
-	for {
-		start := strings.Index(query, "{{")
-		if start == -1 {
-			break
-		}
-		end := strings.Index(query[start:], "}}")
-		if end == -1 {
-			// Unmatched opening brace - potential SQL injection risk
-			return "", nil, fmt.Errorf("unmatched template placeholder in query: missing closing }}")
-		}
-		end += start + 2
-
-		placeholder := query[start:end]
-		fieldPath := strings.TrimSpace(placeholder[2 : len(placeholder)-2])
-		fieldPath = strings.TrimPrefix(fieldPath, RecordFieldPrefix)
-
-		value := getDBFieldValue(record, fieldPath)
-
-		paramPlaceholder := database.FormatPlaceholder(d.driver, paramIndex)
-		query = query[:start] + paramPlaceholder + query[end:]
-		args = append(args, value)
-		paramIndex++
+// buildRecordQuery returns the statement and parameters writing a record:
+// the generated statement in declarative mode, the query template otherwise,
+// followed by the RETURNING clause of the returned columns.
+func (d *DatabaseOutput) buildRecordQuery(record map[string]interface{}) (string, []interface{}, error) {
+	if d.statement != nil {
+		return d.statement.query, d.statement.args(record), nil
 	}
-
-	// Validate no unmatched braces remain (security check)
-	if strings.Contains(query, "{{") || strings.Contains(query, "}}") {
-		return "", nil, fmt.Errorf("unmatched template placeholders remain in query after processing")
+	query, args, err := d.buildParameterizedQuery(d.config.Query, record)
+	if err != nil {
+		return "", nil, err
+	}
+	if clause := returningClause(d.driver, d.config.Returning); clause != "" {
+		query = database.TrimStatement(query, d.driver) + clause
 	}
-
 	return query, args, nil
 }
 
+// buildParameterizedQuery builds a parameterized query from a template.
+// Replaces {{record.field}} references (and :field and @field ones with
+// namedParameters) outside string literals and comments with the driver's
+// placeholders and returns args.
+func (d *DatabaseOutput) buildParameterizedQuery(queryTemplate string, record map[string]interface{}) (string, []interface{}, error) {
+	return database.Bind(queryTemplate, d.driver, nil, database.RecordResolver(record, d.config.NamedParameters))
+}
+
 // getDBFieldValue extracts a field value from a record using dot notation.
 func getDBFieldValue(record map[string]interface{}, field string) interface{} {
 	parts := strings.Split(field, ".")
@@ -0,0 +1,242 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// maxBatchParams bounds the parameters of a multi-row statement (SQLite's
+// default limit; PostgreSQL and MySQL accept up to 65535).
+const maxBatchParams = 32766
+
+// sqlExecer executes statements on a database, within a transaction or as a
+// prepared statement.
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// batchRows returns the number of records written per batch of statement:
+// batchSize, bounded so that a multi-row statement stays under maxBatchParams.
+func (d *DatabaseOutput) batchRows(statement *writeStatement) int {
+	rows := d.config.BatchSize
+	if statement != nil && statement.multiRow() {
+		if limit := maxBatchParams / len(statement.fields); rows > limit {
+			rows = limit
+		}
+	}
+	return rows
+}
+
+// sendBatches writes records in batches of batchSize. With transaction, all
+// batches run in a single transaction, committed every commitEvery records
+// if set; otherwise each batch is committed on its own, so a failure keeps
+// the batches already written.
+func (d *DatabaseOutput) sendBatches(ctx context.Context, records []map[string]interface{}) (int, error) {
+	var tx *sendTx
+	if d.config.Transaction {
+		var err error
+		if tx, err = d.beginSendTx(ctx); err != nil {
+			return 0, err
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				tx.rollback()
+				panic(r)
+			}
+		}()
+	}
+
+	size := d.batchRows(d.statement)
+	successCount := 0
+	for start := 0; start < len(records); start += size {
+		end := start + size
+		if end > len(records) {
+			end = len(records)
+		}
+		processed, err := d.sendBatch(ctx, tx.current(), records[start:end], start)
+		successCount += processed
+		if err == nil && tx != nil {
+			err = tx.written(ctx, end-start)
+		}
+		if err != nil {
+			if tx != nil {
+				tx.rollback()
+			}
+			return successCount, err
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return successCount, fmt.Errorf("committing transaction: %w", err)
+		}
+	}
+	return successCount, nil
+}
+
+// sendBatch writes one batch, within tx if set. Declarative inserts and
+// upserts are written with a single multi-row statement, other statements
+// with a prepared statement executed once per record. If the batch fails,
+// its records are written one at a time, so that onError applies to the
+// failing records only.
+func (d *DatabaseOutput) sendBatch(ctx context.Context, tx *sql.Tx, batch []map[string]interface{}, offset int) (int, error) {
+	var err error
+	switch {
+	case d.statement != nil && d.statement.multiRow() && tx != nil:
+		// The savepoint keeps the transaction usable for the per-record retry
+		var savepointErr error
+		err, savepointErr = execInSavepoint(ctx, tx, recordSavepoint, func() error {
+			return d.execMultiRow(ctx, tx, batch)
+		})
+		if savepointErr != nil {
+			return 0, savepointErr
+		}
+	case d.statement != nil && d.statement.multiRow():
+		err = d.execMultiRow(ctx, nil, batch)
+	case tx != nil:
+		// Records written before a failure cannot be rolled back alone:
+		// failures are handled per record, as without batching
+		return d.execPrepared(ctx, tx, batch, offset, true)
+	default:
+		err = d.execPreparedBatch(ctx, batch, offset)
+	}
+	if err == nil {
+		return len(batch), nil
+	}
+
+	logger.Warn("database output batch failed, writing its records one at a time",
+		slog.String("module_type", "database"),
+		slog.Int("batch_offset", offset),
+		slog.Int("batch_size", len(batch)),
+		slog.String("error", err.Error()),
+	)
+	successCount := 0
+	for i, record := range batch {
+		var processed bool
+		var err error
+		if tx != nil {
+			processed, err = d.processRecordInTransaction(ctx, tx, record, offset+i)
+		} else {
+			processed, err = d.processRecordWithoutTransaction(ctx, record, offset+i)
+		}
+		if err != nil {
+			return successCount, err
+		}
+		if processed {
+			successCount++
+		}
+	}
+	return successCount, nil
+}
+
+// execMultiRow writes a batch with a single multi-row VALUES statement. The
+// statement is atomic: on failure, none of the records are written.
+func (d *DatabaseOutput) execMultiRow(ctx context.Context, tx *sql.Tx, batch []map[string]interface{}) error {
+	var exec sqlExecer = d.db
+	if tx != nil {
+		exec = tx
+	}
+
+	query := d.statement.multiRowQuery(len(batch))
+	args := make([]interface{}, 0, len(batch)*len(d.statement.fields))
+	for _, record := range batch {
+		args = append(args, d.statement.args(record)...)
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	_, err := exec.ExecContext(queryCtx, query, args...)
+	return err
+}
+
+// execPreparedBatch writes a batch with a prepared statement in its own
+// transaction, committed at the end of the batch. On failure, none of the
+// records are written.
+func (d *DatabaseOutput) execPreparedBatch(ctx context.Context, batch []map[string]interface{}, offset int) error {
+	tx, err := d.db.BeginTx(ctx, d.txOptions)
+	if err != nil {
+		return fmt.Errorf("beginning batch transaction: %w", err)
+	}
+	returned := len(d.returned)
+	if _, err := d.execPrepared(ctx, tx, batch, offset, false); err != nil {
+		_ = tx.Rollback()
+		d.returned = d.returned[:returned]
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing batch transaction: %w", err)
+	}
+	return nil
+}
+
+// execPrepared executes the statement of each record of a batch in tx, with
+// a statement prepared once. With handleErrors, query and database errors are
+// handled per record according to onError; otherwise the first error is
+// returned. Returns the number of records written.
+func (d *DatabaseOutput) execPrepared(ctx context.Context, tx *sql.Tx, batch []map[string]interface{}, offset int, handleErrors bool) (int, error) {
+	var stmt *sql.Stmt
+	var prepared string
+	defer func() {
+		if stmt != nil {
+			_ = stmt.Close()
+		}
+	}()
+
+	successCount := 0
+	for i, record := range batch {
+		query, args, err := d.buildRecordQuery(record)
+		if err != nil {
+			if !handleErrors {
+				return successCount, err
+			}
+			if _, err := d.handleQueryBuildError(err, offset+i); err != nil {
+				return successCount, err
+			}
+			continue
+		}
+
+		// Template queries render the same statement for every record
+		if stmt == nil || query != prepared {
+			if stmt != nil {
+				_ = stmt.Close()
+			}
+			if stmt, err = tx.PrepareContext(ctx, query); err != nil {
+				stmt = nil
+				return successCount, fmt.Errorf("preparing batch statement: %w", err)
+			}
+			prepared = query
+		}
+
+		queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		exec := func() error {
+			return d.execWrite(queryCtx, preparedExecer{stmt}, query, args, record)
+		}
+		if handleErrors {
+			var savepointErr error
+			if err, savepointErr = d.execIsolated(ctx, tx, exec); savepointErr != nil {
+				cancel()
+				return successCount, savepointErr
+			}
+		} else {
+			err = exec()
+		}
+		cancel()
+		if err != nil {
+			if !handleErrors {
+				return successCount, err
+			}
+			if _, err := d.handleDatabaseError(err, query, len(args), offset+i); err != nil {
+				return successCount, err
+			}
+			continue
+		}
+		successCount++
+	}
+	return successCount, nil
+}
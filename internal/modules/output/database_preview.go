@@ -0,0 +1,219 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/cannectors/runtime/internal/database"
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// sensitiveParamPattern matches the record fields and columns whose values
+// are masked in previews.
+var sensitiveParamPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|credential|ssn|card_?number|cvv)`)
+
+// dryRunSavepoint is the savepoint isolating each statement executed in the
+// dry-run transaction, so that a failing statement does not abort the others.
+const dryRunSavepoint = "cannectors_dry_run"
+
+// previewStatement is a statement Send would execute, with its arguments and
+// their masked form.
+type previewStatement struct {
+	query   string
+	args    []interface{}
+	masked  []interface{}
+	records int
+	ddl     bool
+}
+
+// PreviewRequest returns the statements Send would execute, without
+// executing them: one per record, or one per batch for multi-row statements.
+// With schema auto, they are preceded by the DDL creating the table or
+// adding its missing columns.
+//
+// Values of sensitive fields (passwords, tokens, ...) are masked unless
+// opts.ShowCredentials is set. With dryRunRollback, the statements are
+// executed in a transaction that is always rolled back, and their errors
+// reported in the previews.
+func (d *DatabaseOutput) PreviewRequest(records []map[string]interface{}, opts PreviewOptions) ([]RequestPreview, error) {
+	if len(records) == 0 {
+		return []RequestPreview{}, nil
+	}
+
+	stmt := d.statement
+	var ddl []string
+	if d.config.Schema == SchemaAuto {
+		ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+		existing, _ := d.currentTableSchema(ctx)
+		cancel()
+		plan, err := d.planSchema(existing, records)
+		if err != nil {
+			return nil, err
+		}
+		stmt, ddl = plan.statement, plan.ddl
+	}
+
+	writes, err := d.previewStatements(records, stmt, opts.ShowCredentials)
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]previewStatement, 0, len(ddl)+len(writes))
+	for _, query := range ddl {
+		statements = append(statements, previewStatement{query: query, ddl: true})
+	}
+	statements = append(statements, writes...)
+
+	previews := make([]RequestPreview, len(statements))
+	for i, stmt := range statements {
+		operation, table := d.previewTarget(stmt)
+		previews[i] = RequestPreview{
+			Endpoint:    table,
+			Method:      strings.ToUpper(operation),
+			RecordCount: stmt.records,
+			SQL: &SQLPreview{
+				Statement:  stmt.query,
+				Parameters: stmt.masked,
+				Table:      table,
+				Operation:  operation,
+			},
+		}
+	}
+
+	if d.config.DryRunRollback {
+		if err := d.executeInRollback(statements, previews); err != nil {
+			return nil, err
+		}
+	}
+	return previews, nil
+}
+
+// previewStatements builds the statements Send would execute for records,
+// with the generated statement if any.
+func (d *DatabaseOutput) previewStatements(records []map[string]interface{}, statement *writeStatement, showCredentials bool) ([]previewStatement, error) {
+	var statements []previewStatement
+
+	if d.config.BatchSize > 1 && statement != nil && statement.multiRow() {
+		size := d.batchRows(statement)
+		for start := 0; start < len(records); start += size {
+			batch := records[start:min(start+size, len(records))]
+			stmt := previewStatement{query: statement.multiRowQuery(len(batch)), records: len(batch)}
+			for _, record := range batch {
+				args := statement.args(record)
+				stmt.args = append(stmt.args, args...)
+				stmt.masked = append(stmt.masked, maskStatementArgs(statement, d.config.Columns, args, showCredentials)...)
+			}
+			statements = append(statements, stmt)
+		}
+		return statements, nil
+	}
+
+	for _, record := range records {
+		stmt := previewStatement{records: 1}
+		if statement != nil {
+			stmt.query = statement.query
+			stmt.args = statement.args(record)
+			stmt.masked = maskStatementArgs(statement, d.config.Columns, stmt.args, showCredentials)
+		} else {
+			var err error
+			if stmt.query, stmt.args, err = d.buildRecordQuery(record); err != nil {
+				return nil, err
+			}
+			resolve := database.RecordResolver(record, d.config.NamedParameters)
+			_, stmt.masked, _ = database.Bind(d.config.Query, d.driver, nil, func(p database.Param) (interface{}, bool, error) {
+				value, ok, err := resolve(p)
+				if ok && !showCredentials && sensitiveParamPattern.MatchString(p.Name) {
+					return maskValue("value"), true, nil
+				}
+				return value, ok, err
+			})
+		}
+		statements = append(statements, stmt)
+	}
+	return statements, nil
+}
+
+// maskStatementArgs masks the arguments of a generated statement bound to
+// sensitive fields or mapped columns.
+func maskStatementArgs(statement *writeStatement, mapped []writeColumn, args []interface{}, showCredentials bool) []interface{} {
+	masked := make([]interface{}, len(args))
+	copy(masked, args)
+	if showCredentials {
+		return masked
+	}
+
+	columns := make(map[string]string, len(mapped))
+	for _, c := range mapped {
+		columns[c.Field] = c.Column
+	}
+	for i, field := range statement.fields {
+		if sensitiveParamPattern.MatchString(field) || sensitiveParamPattern.MatchString(columns[field]) {
+			masked[i] = maskValue("value")
+		}
+	}
+	return masked
+}
+
+// previewTarget returns the operation and target table of a statement: the
+// leading keywords of DDL (e.g. "create table"), the configured mode and
+// table in declarative mode, the leading keyword of the query otherwise.
+func (d *DatabaseOutput) previewTarget(stmt previewStatement) (operation, table string) {
+	if stmt.ddl {
+		return strings.ToLower(strings.Join(strings.Fields(stmt.query)[:2], " ")), d.config.Table
+	}
+	if d.config.Table != "" {
+		return d.config.Mode, d.config.Table
+	}
+	if fields := strings.Fields(d.config.Query); len(fields) > 0 {
+		operation = strings.ToLower(fields[0])
+	}
+	return operation, ""
+}
+
+// executeInRollback executes the statements in a transaction that is always
+// rolled back, recording in each preview whether it succeeded. Each statement
+// runs under a savepoint so that a failure does not affect the next ones.
+func (d *DatabaseOutput) executeInRollback(statements []previewStatement, previews []RequestPreview) error {
+	ctx := context.Background()
+	tx, err := d.db.BeginTx(ctx, d.txOptions)
+	if err != nil {
+		return fmt.Errorf("beginning dry-run transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	failed := 0
+	for i, stmt := range statements {
+		if stmt.ddl && d.driver == database.DriverMySQL {
+			continue // DDL commits the transaction implicitly on MySQL
+		}
+
+		queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		execErr, err := execInSavepoint(ctx, tx, dryRunSavepoint, func() error {
+			_, err := tx.ExecContext(queryCtx, stmt.query, stmt.args...)
+			return err
+		})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("dry-run: %w", err)
+		}
+
+		previews[i].SQL.Executed = true
+		if execErr != nil {
+			failed++
+			previews[i].SQL.Error = database.ClassifyDatabaseError(execErr, d.driver, "exec", stmt.query, len(stmt.args)).Error()
+		}
+	}
+
+	logger.Info("database output dry-run statements executed and rolled back",
+		slog.String("module_type", "database"),
+		slog.Int("statements", len(statements)),
+		slog.Int("failed", failed),
+	)
+	return nil
+}
+
+// Verify DatabaseOutput implements PreviewableModule
+var _ PreviewableModule = (*DatabaseOutput)(nil)
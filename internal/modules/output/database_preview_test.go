@@ -0,0 +1,105 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDatabaseOutputPreviewRequest_Query(t *testing.T) {
+	d := &DatabaseOutput{
+		driver: "postgres",
+		config: DatabaseOutputConfig{
+			Query:           "INSERT INTO users (email, password_hash) VALUES ({{record.email}}, :password)",
+			NamedParameters: true,
+		},
+	}
+	records := []map[string]interface{}{
+		{"email": "a@example.com", "password": "s3cret"},
+		{"email": "b@example.com", "password": "hunter2"},
+	}
+
+	previews, err := d.PreviewRequest(records, PreviewOptions{})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if len(previews) != 2 {
+		t.Fatalf("PreviewRequest() returned %d previews, want one per record", len(previews))
+	}
+
+	p := previews[0]
+	if p.Method != "INSERT" || p.RecordCount != 1 || p.SQL == nil {
+		t.Fatalf("preview = %+v, want one INSERT record with SQL", p)
+	}
+	if want := "INSERT INTO users (email, password_hash) VALUES ($1, $2)"; p.SQL.Statement != want {
+		t.Errorf("Statement = %q, want %q", p.SQL.Statement, want)
+	}
+	if want := []interface{}{"a@example.com", "[MASKED-VALUE]"}; !reflect.DeepEqual(p.SQL.Parameters, want) {
+		t.Errorf("Parameters = %v, want %v", p.SQL.Parameters, want)
+	}
+	if p.SQL.Executed {
+		t.Error("statement executed without dryRunRollback")
+	}
+
+	previews, err = d.PreviewRequest(records, PreviewOptions{ShowCredentials: true})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if got := previews[1].SQL.Parameters[1]; got != "hunter2" {
+		t.Errorf("Parameters[1] = %v with ShowCredentials, want the value", got)
+	}
+}
+
+func TestDatabaseOutputPreviewRequest_Table(t *testing.T) {
+	config := DatabaseOutputConfig{
+		Table:     "accounts",
+		Columns:   []writeColumn{{Column: "api_key", Field: "key"}, {Column: "name", Field: "name"}},
+		Keys:      []string{"name"},
+		Mode:      WriteModeUpsert,
+		BatchSize: 2,
+	}
+	d := &DatabaseOutput{driver: "sqlite", config: config, statement: buildWriteStatement("sqlite", config)}
+	records := []map[string]interface{}{
+		{"name": "a", "key": "k1"},
+		{"name": "b", "key": "k2"},
+		{"name": "c", "key": "k3"},
+	}
+
+	previews, err := d.PreviewRequest(records, PreviewOptions{})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if len(previews) != 2 || previews[0].RecordCount != 2 || previews[1].RecordCount != 1 {
+		t.Fatalf("PreviewRequest() = %d previews, want batches of 2 and 1", len(previews))
+	}
+
+	p := previews[0]
+	if p.Endpoint != "accounts" || p.SQL.Table != "accounts" || p.SQL.Operation != WriteModeUpsert {
+		t.Errorf("preview target = %s %s, want upsert accounts", p.SQL.Operation, p.SQL.Table)
+	}
+	if want := d.statement.multiRowQuery(2); p.SQL.Statement != want {
+		t.Errorf("Statement = %q, want %q", p.SQL.Statement, want)
+	}
+	// The api_key column is masked although its field name is not sensitive
+	if want := []interface{}{"[MASKED-VALUE]", "a", "[MASKED-VALUE]", "b"}; !reflect.DeepEqual(p.SQL.Parameters, want) {
+		t.Errorf("Parameters = %v, want %v", p.SQL.Parameters, want)
+	}
+}
+
+func TestDatabaseOutputPreviewRequest_QueryReturning(t *testing.T) {
+	d := &DatabaseOutput{
+		driver: "postgres",
+		config: DatabaseOutputConfig{
+			Query:     "INSERT INTO orders (ref) VALUES ({{record.ref}});",
+			Returning: []string{"id"},
+		},
+	}
+
+	previews, err := d.PreviewRequest([]map[string]interface{}{{"ref": "A1"}}, PreviewOptions{})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	// The preview shows the statement executed, with its RETURNING clause
+	if want := `INSERT INTO orders (ref) VALUES ($1) RETURNING "id"`; previews[0].SQL.Statement != want {
+		t.Errorf("Statement = %q, want %q", previews[0].SQL.Statement, want)
+	}
+}
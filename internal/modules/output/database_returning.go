@@ -0,0 +1,149 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cannectors/runtime/internal/database"
+)
+
+// ErrDatabaseOutputInvalidReturning is returned for invalid returning configuration.
+var ErrDatabaseOutputInvalidReturning = errors.New("invalid database output returning configuration")
+
+// preparedExecer runs a prepared statement as a sqlExecer. The query
+// arguments are ignored: the statement is already prepared.
+type preparedExecer struct {
+	stmt *sql.Stmt
+}
+
+func (p preparedExecer) ExecContext(ctx context.Context, _ string, args ...interface{}) (sql.Result, error) {
+	return p.stmt.ExecContext(ctx, args...)
+}
+
+func (p preparedExecer) QueryContext(ctx context.Context, _ string, args ...interface{}) (*sql.Rows, error) {
+	return p.stmt.QueryContext(ctx, args...)
+}
+
+// validateReturningConfig validates the returned columns for the driver:
+// plain column names, and a single column on MySQL (LastInsertId).
+func validateReturningConfig(config DatabaseOutputConfig, driver string) error {
+	for _, column := range config.Returning {
+		if !columnNamePattern.MatchString(column) {
+			return fmt.Errorf("%w: invalid column name %q", ErrDatabaseOutputInvalidReturning, column)
+		}
+	}
+	if driver == database.DriverMySQL && len(config.Returning) > 1 {
+		return fmt.Errorf("%w: mysql returns the generated id only: returning must list one column", ErrDatabaseOutputInvalidReturning)
+	}
+	if returningClause(driver, config.Returning) != "" && database.HasKeyword(config.Query, driver, "RETURNING") {
+		return fmt.Errorf("%w: the query already has a RETURNING clause: remove it or returning", ErrDatabaseOutputInvalidReturning)
+	}
+	return nil
+}
+
+// returningClause returns the RETURNING clause of the returned columns, or
+// "" without returned columns and on MySQL, which has no RETURNING.
+func returningClause(driver string, columns []string) string {
+	if len(columns) == 0 || driver == database.DriverMySQL {
+		return ""
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(driver, column)
+	}
+	return " RETURNING " + strings.Join(quoted, ", ")
+}
+
+// execWrite executes the statement writing record. With returning, the
+// values returned by the statement (the generated id on MySQL) are stored in
+// the record, which is added to the returned records.
+func (d *DatabaseOutput) execWrite(ctx context.Context, exec sqlExecer, query string, args []interface{}, record map[string]interface{}) error {
+	if len(d.config.Returning) == 0 {
+		_, err := exec.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	if d.driver == database.DriverMySQL {
+		result, err := exec.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("reading generated id: %w", err)
+		}
+		// No id is generated for updated rows
+		if id != 0 {
+			d.storeReturned(record, map[string]interface{}{d.config.Returning[0]: id})
+		}
+		return nil
+	}
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	// Statements matching several rows return the values of the first
+	if !rows.Next() {
+		return rows.Err()
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("reading returned columns: %w", err)
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return fmt.Errorf("reading returned values: %w", err)
+	}
+	// Closing reads the remaining rows and reports their errors
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	returned := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if b, ok := values[i].([]byte); ok {
+			values[i] = string(b)
+		}
+		returned[column] = values[i]
+	}
+	d.storeReturned(record, returned)
+	return nil
+}
+
+// storeReturned stores the returned values in record, at returningField if
+// set (e.g. _metadata.db), and adds the record to the returned records.
+func (d *DatabaseOutput) storeReturned(record map[string]interface{}, returned map[string]interface{}) {
+	target := record
+	if d.config.ReturningField != "" {
+		for _, part := range strings.Split(d.config.ReturningField, ".") {
+			next, ok := target[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{}, len(returned))
+				target[part] = next
+			}
+			target = next
+		}
+	}
+	for column, value := range returned {
+		target[column] = value
+	}
+	d.returned = append(d.returned, record)
+}
+
+// ReturnedRecords returns the records written by the last Send, with the
+// values returned by the database, or nil without returning. Records whose
+// writes were rolled back are not included.
+func (d *DatabaseOutput) ReturnedRecords() []map[string]interface{} {
+	return d.returned
+}
@@ -0,0 +1,115 @@
+package output
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateReturningConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		driver    string
+		returning []string
+		query     string
+		wantErr   bool
+	}{
+		{name: "none", driver: "postgres"},
+		{name: "columns", driver: "postgres", returning: []string{"id", "created_at"}},
+		{name: "mysql id", driver: "mysql", returning: []string{"id"}},
+		{name: "mysql several columns", driver: "mysql", returning: []string{"id", "created_at"}, wantErr: true},
+		{name: "invalid column", driver: "sqlite", returning: []string{"id; DROP TABLE t"}, wantErr: true},
+		{name: "query with returning", driver: "postgres", returning: []string{"id"}, query: "INSERT INTO t (a) VALUES ({{record.a}}) returning id", wantErr: true},
+		{name: "query returning without columns", driver: "postgres", query: "INSERT INTO t (a) VALUES ({{record.a}}) RETURNING id"},
+		{name: "returning in a comment", driver: "sqlite", returning: []string{"id"}, query: "INSERT INTO t (a) VALUES ({{record.a}}) -- no RETURNING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateReturningConfig(DatabaseOutputConfig{Returning: tt.returning, Query: tt.query}, tt.driver)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateReturningConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrDatabaseOutputInvalidReturning) {
+				t.Errorf("validateReturningConfig() error = %v, want ErrDatabaseOutputInvalidReturning", err)
+			}
+		})
+	}
+}
+
+func TestBuildWriteStatement_Returning(t *testing.T) {
+	config := DatabaseOutputConfig{
+		Table:     "orders",
+		Columns:   []writeColumn{{Column: "ref", Field: "ref"}},
+		Mode:      WriteModeInsert,
+		Returning: []string{"id", "created_at"},
+	}
+
+	stmt := buildWriteStatement("postgres", config)
+	want := `INSERT INTO "orders" ("ref") VALUES ($1) RETURNING "id", "created_at"`
+	if stmt.query != want {
+		t.Errorf("query = %s\nwant    %s", stmt.query, want)
+	}
+	if stmt.multiRow() {
+		t.Error("returning statement is multi-row, want one row at a time")
+	}
+
+	// MySQL reads the generated id, without RETURNING
+	config.Returning = []string{"id"}
+	stmt = buildWriteStatement("mysql", config)
+	if want := "INSERT INTO `orders` (`ref`) VALUES (?)"; stmt.query != want {
+		t.Errorf("query = %s\nwant    %s", stmt.query, want)
+	}
+	if stmt.multiRow() {
+		t.Error("mysql returning statement is multi-row, want one row at a time")
+	}
+}
+
+func TestDatabaseOutputBuildRecordQuery_Returning(t *testing.T) {
+	d := &DatabaseOutput{
+		driver: "postgres",
+		config: DatabaseOutputConfig{
+			Query:     "INSERT INTO orders (ref) VALUES ({{record.ref}}); -- one order\n",
+			Returning: []string{"id"},
+		},
+	}
+
+	query, args, err := d.buildRecordQuery(map[string]interface{}{"ref": "A1"})
+	if err != nil {
+		t.Fatalf("buildRecordQuery() error = %v", err)
+	}
+	if want := `INSERT INTO orders (ref) VALUES ($1) RETURNING "id"`; query != want {
+		t.Errorf("query = %s\nwant    %s", query, want)
+	}
+	if len(args) != 1 || args[0] != "A1" {
+		t.Errorf("args = %v, want [A1]", args)
+	}
+}
+
+func TestDatabaseOutputStoreReturned(t *testing.T) {
+	d := &DatabaseOutput{config: DatabaseOutputConfig{ReturningField: "_metadata.db"}}
+	record := map[string]interface{}{
+		"ref":       "A-1",
+		"_metadata": map[string]interface{}{"source": "api"},
+	}
+
+	d.storeReturned(record, map[string]interface{}{"id": int64(7)})
+	want := map[string]interface{}{
+		"ref":       "A-1",
+		"_metadata": map[string]interface{}{"source": "api", "db": map[string]interface{}{"id": int64(7)}},
+	}
+	if !reflect.DeepEqual(record, want) {
+		t.Errorf("record = %v, want %v", record, want)
+	}
+
+	// Without returningField, values are stored at the record top level
+	d.config.ReturningField = ""
+	other := map[string]interface{}{"ref": "A-2"}
+	d.storeReturned(other, map[string]interface{}{"id": int64(8)})
+	if other["id"] != int64(8) {
+		t.Errorf("record = %v, want id 8", other)
+	}
+	if got := d.ReturnedRecords(); len(got) != 2 {
+		t.Errorf("ReturnedRecords() = %d records, want 2", len(got))
+	}
+}
@@ -0,0 +1,429 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cannectors/runtime/internal/database"
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// Schema management modes
+const (
+	SchemaManual = "manual" // the table must exist with the mapped columns
+	SchemaAuto   = "auto"   // the table is created and its columns added from the records
+)
+
+// defaultSchemaSampleSize is the number of values per column from which its
+// type is inferred.
+const defaultSchemaSampleSize = 100
+
+// ErrDatabaseOutputSchemaConflict is returned when records have values that
+// an existing column's type cannot hold.
+var ErrDatabaseOutputSchemaConflict = errors.New("database output schema conflict")
+
+// sqlTypePattern matches declared column types, e.g. TEXT, VARCHAR(64),
+// NUMERIC(10, 2) or DOUBLE PRECISION.
+var sqlTypePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_ ]*(\(\s*[0-9]+\s*(,\s*[0-9]+\s*)?\))?$`)
+
+// columnKind is the type of the values of a column, from which its SQL type
+// is derived.
+type columnKind string
+
+const (
+	kindUnknown   columnKind = ""
+	kindBoolean   columnKind = "boolean"
+	kindInteger   columnKind = "integer"
+	kindFloat     columnKind = "float"
+	kindText      columnKind = "text"
+	kindTimestamp columnKind = "timestamp"
+	kindJSON      columnKind = "json"
+)
+
+// columnTypeKinds maps database type names to kinds, by substring, in order.
+var columnTypeKinds = []struct {
+	substr string
+	kind   columnKind
+}{
+	{"INTERVAL", kindUnknown},
+	{"POINT", kindUnknown},
+	{"BOOL", kindBoolean},
+	{"INT", kindInteger},
+	{"JSON", kindJSON},
+	{"CHAR", kindText},
+	{"TEXT", kindText},
+	{"CLOB", kindText},
+	{"FLOAT", kindFloat},
+	{"DOUBLE", kindFloat},
+	{"REAL", kindFloat},
+	{"NUMERIC", kindFloat},
+	{"DECIMAL", kindFloat},
+	{"TIME", kindTimestamp},
+	{"DATE", kindTimestamp},
+}
+
+// tableSchema is the columns of an existing table and their database type
+// names. A nil *tableSchema is a table that does not exist.
+type tableSchema struct {
+	columns map[string]string
+}
+
+// schemaPlan is the DDL creating the table or adding its missing columns,
+// and the statement writing the records.
+type schemaPlan struct {
+	ddl       []string
+	statement *writeStatement
+}
+
+// validateSchemaConfig validates the schema mode and the declared column types.
+func validateSchemaConfig(config DatabaseOutputConfig) error {
+	switch config.Schema {
+	case "", SchemaManual:
+		if len(config.ColumnTypes) > 0 {
+			return fmt.Errorf("%w: columnTypes requires schema auto", ErrDatabaseOutputInvalidTable)
+		}
+		return nil
+	case SchemaAuto:
+	default:
+		return fmt.Errorf("%w: unsupported schema %q (supported: manual, auto)", ErrDatabaseOutputInvalidTable, config.Schema)
+	}
+
+	if config.Table == "" {
+		return fmt.Errorf("%w: schema auto requires table", ErrDatabaseOutputInvalidTable)
+	}
+	for column, sqlType := range config.ColumnTypes {
+		if !columnNamePattern.MatchString(column) {
+			return fmt.Errorf("%w: invalid column name %q in columnTypes", ErrDatabaseOutputInvalidTable, column)
+		}
+		if !sqlTypePattern.MatchString(sqlType) {
+			return fmt.Errorf("%w: invalid type %q for column %s", ErrDatabaseOutputInvalidTable, sqlType, column)
+		}
+	}
+	return nil
+}
+
+// ensureSchema creates the table or adds its missing columns for records,
+// then generates the statement writing them. It does nothing unless schema
+// is auto. The table columns are read once, then after each change.
+func (d *DatabaseOutput) ensureSchema(ctx context.Context, records []map[string]interface{}) error {
+	if d.config.Schema != SchemaAuto {
+		return nil
+	}
+
+	existing, readErr := d.currentTableSchema(ctx)
+	plan, err := d.planSchema(existing, records)
+	if err != nil {
+		return err
+	}
+
+	for _, ddl := range plan.ddl {
+		queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		_, err := d.db.ExecContext(queryCtx, ddl)
+		cancel()
+		if err != nil {
+			return database.ClassifyDatabaseError(err, d.driver, "exec", ddl, 0)
+		}
+		logger.Info("database output schema updated",
+			slog.String("module_type", "database"),
+			slog.String("table", d.config.Table),
+			slog.String("statement", ddl),
+		)
+	}
+
+	if existing == nil || len(plan.ddl) > 0 {
+		d.tableSchema = nil
+		if _, err = d.currentTableSchema(ctx); err != nil {
+			if readErr != nil {
+				err = readErr
+			}
+			return fmt.Errorf("%w: reading columns of table %s: %w", ErrDatabaseOutputInvalidTable, d.config.Table, err)
+		}
+	}
+	d.statement = plan.statement
+	return nil
+}
+
+// currentTableSchema returns the cached table columns, or reads and caches
+// them. A table that cannot be read is returned as nil (to be created) with
+// the error.
+func (d *DatabaseOutput) currentTableSchema(ctx context.Context) (*tableSchema, error) {
+	if d.tableSchema != nil {
+		return d.tableSchema, nil
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	schema, err := readTableSchema(queryCtx, d.db, d.driver, d.config.Table)
+	if err != nil {
+		return nil, err
+	}
+	d.tableSchema = schema
+	return schema, nil
+}
+
+// readTableSchema reads the columns of a table and their database type names
+// from an empty result set.
+func readTableSchema(ctx context.Context, db *sql.DB, driver, table string) (*tableSchema, error) {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+quoteIdentifier(driver, table)+" WHERE 1 = 0")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &tableSchema{columns: make(map[string]string, len(types))}
+	for _, t := range types {
+		schema.columns[t.Name()] = t.DatabaseTypeName()
+	}
+	return schema, nil
+}
+
+// planSchema returns the DDL bringing the table (nil if it does not exist)
+// to the columns of records, and the statement writing them. Columns are the
+// mapped columns, or else the top-level record fields and declared columns.
+//
+// New columns are nullable and typed from columnTypes, or else from their
+// first schemaSampleSize non-null values; key columns form the primary key
+// of a created table. Existing columns whose type cannot hold the values
+// are reported as conflicts.
+func (d *DatabaseOutput) planSchema(existing *tableSchema, records []map[string]interface{}) (*schemaPlan, error) {
+	config := d.config
+	if len(config.Columns) == 0 {
+		columns, err := recordColumns(records, config.ColumnTypes)
+		if err != nil {
+			return nil, err
+		}
+		config.Columns = columns
+	}
+	if err := validateWriteConfig(config); err != nil {
+		return nil, err
+	}
+
+	isKey := make(map[string]bool, len(config.Keys))
+	for _, key := range config.Keys {
+		isKey[key] = true
+	}
+	kinds := inferColumnKinds(records, config.Columns, config.SchemaSampleSize)
+	table := quoteIdentifier(d.driver, config.Table)
+
+	plan := &schemaPlan{statement: buildWriteStatement(d.driver, config)}
+	var definitions, conflicts []string
+	for _, c := range config.Columns {
+		declared, isDeclared := config.ColumnTypes[c.Column]
+		if existing != nil {
+			if typeName, ok := existing.columns[c.Column]; ok {
+				if columnKind := databaseTypeKind(typeName); !isDeclared && !kindAccepts(columnKind, kinds[c.Column], d.driver) {
+					conflicts = append(conflicts, fmt.Sprintf("column %s is %s but records have %s values", c.Column, typeName, kinds[c.Column]))
+				}
+				continue
+			}
+		}
+
+		sqlType := declared
+		if !isDeclared {
+			sqlType = columnSQLType(d.driver, kinds[c.Column], isKey[c.Column])
+		}
+		definition := quoteIdentifier(d.driver, c.Column) + " " + sqlType
+		if existing == nil {
+			definitions = append(definitions, definition)
+		} else {
+			plan.ddl = append(plan.ddl, "ALTER TABLE "+table+" ADD COLUMN "+definition)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: table %s: %s (alter the column type, or map the field to another column)",
+			ErrDatabaseOutputSchemaConflict, config.Table, strings.Join(conflicts, "; "))
+	}
+
+	if existing == nil {
+		if len(config.Keys) > 0 {
+			keys := make([]string, len(config.Keys))
+			for i, key := range config.Keys {
+				keys[i] = quoteIdentifier(d.driver, key)
+			}
+			definitions = append(definitions, "PRIMARY KEY ("+strings.Join(keys, ", ")+")")
+		}
+		plan.ddl = []string{"CREATE TABLE IF NOT EXISTS " + table + " (" + strings.Join(definitions, ", ") + ")"}
+	}
+	return plan, nil
+}
+
+// recordColumns returns the columns of the top-level record fields and the
+// declared columns, each written from the field of the same name.
+func recordColumns(records []map[string]interface{}, columnTypes map[string]string) ([]writeColumn, error) {
+	names := make(map[string]bool, len(columnTypes))
+	for column := range columnTypes {
+		names[column] = true
+	}
+	for _, record := range records {
+		for field := range record {
+			if names[field] {
+				continue
+			}
+			if !columnNamePattern.MatchString(field) {
+				return nil, fmt.Errorf("%w: field %q is not a valid column name (map it in columns)", ErrDatabaseOutputInvalidTable, field)
+			}
+			names[field] = true
+		}
+	}
+
+	columns := make([]writeColumn, 0, len(names))
+	for name := range names {
+		columns = append(columns, writeColumn{Column: name, Field: name})
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Column < columns[j].Column })
+	return columns, nil
+}
+
+// inferColumnKinds returns the kind of each column, merged from the first
+// sampleSize non-null values of its field.
+func inferColumnKinds(records []map[string]interface{}, columns []writeColumn, sampleSize int) map[string]columnKind {
+	kinds := make(map[string]columnKind, len(columns))
+	for _, c := range columns {
+		sampled := 0
+		for _, record := range records {
+			if sampled == sampleSize {
+				break
+			}
+			kind := valueKind(getDBFieldValue(record, c.Field))
+			if kind == kindUnknown {
+				continue
+			}
+			kinds[c.Column] = mergeKinds(kinds[c.Column], kind)
+			sampled++
+		}
+	}
+	return kinds
+}
+
+// valueKind returns the kind of a record value. Integral numbers are
+// integers; nested objects and arrays are JSON.
+func valueKind(value interface{}) columnKind {
+	switch v := value.(type) {
+	case nil:
+		return kindUnknown
+	case bool:
+		return kindBoolean
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return kindInteger
+	case float32:
+		return valueKind(float64(v))
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return kindInteger
+		}
+		return kindFloat
+	case time.Time:
+		return kindTimestamp
+	case map[string]interface{}, []interface{}:
+		return kindJSON
+	default:
+		return kindText
+	}
+}
+
+// mergeKinds returns the kind holding the values of both kinds: integers
+// widen to floats, other mixes to text.
+func mergeKinds(a, b columnKind) columnKind {
+	switch {
+	case a == kindUnknown || a == b:
+		return b
+	case b == kindUnknown:
+		return a
+	case (a == kindInteger && b == kindFloat) || (a == kindFloat && b == kindInteger):
+		return kindFloat
+	default:
+		return kindText
+	}
+}
+
+// databaseTypeKind returns the kind of a database type name, or kindUnknown.
+func databaseTypeKind(typeName string) columnKind {
+	typeName = strings.ToUpper(typeName)
+	for _, k := range columnTypeKinds {
+		if strings.Contains(typeName, k.substr) {
+			return k.kind
+		}
+	}
+	return kindUnknown
+}
+
+// kindAccepts reports whether a column of kind column can hold values of
+// kind values. Unknown kinds are not checked.
+func kindAccepts(column, values columnKind, driver string) bool {
+	switch {
+	case column == kindUnknown || values == kindUnknown || column == values:
+		return true
+	case column == kindFloat && values == kindInteger:
+		return true
+	case column == kindText && values == kindJSON:
+		return true // written as JSON text
+	case column == kindTimestamp && values == kindText:
+		return true // parsed by the database
+	case column == kindInteger && values == kindBoolean:
+		return driver != database.DriverPostgres // MySQL BOOLEAN is TINYINT
+	}
+	return false
+}
+
+// columnSQLType returns the SQL type of a new column of kind for the driver.
+// Columns without values are text.
+func columnSQLType(driver string, kind columnKind, key bool) string {
+	switch driver {
+	case database.DriverPostgres:
+		switch kind {
+		case kindBoolean:
+			return "BOOLEAN"
+		case kindInteger:
+			return "BIGINT"
+		case kindFloat:
+			return "DOUBLE PRECISION"
+		case kindTimestamp:
+			return "TIMESTAMPTZ"
+		case kindJSON:
+			return "JSONB"
+		}
+		return "TEXT"
+	case database.DriverMySQL:
+		switch kind {
+		case kindBoolean:
+			return "BOOLEAN"
+		case kindInteger:
+			return "BIGINT"
+		case kindFloat:
+			return "DOUBLE"
+		case kindTimestamp:
+			return "DATETIME(6)"
+		case kindJSON:
+			return "JSON"
+		}
+		if key {
+			return "VARCHAR(255)" // TEXT columns cannot be keys without a prefix length
+		}
+		return "TEXT"
+	default:
+		switch kind {
+		case kindBoolean:
+			return "BOOLEAN"
+		case kindInteger:
+			return "INTEGER"
+		case kindFloat:
+			return "REAL"
+		case kindTimestamp:
+			return "TIMESTAMP"
+		}
+		return "TEXT"
+	}
+}
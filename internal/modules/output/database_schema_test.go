@@ -0,0 +1,186 @@
+package output
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanSchema_CreateTable(t *testing.T) {
+	d := &DatabaseOutput{
+		driver: "postgres",
+		config: DatabaseOutputConfig{
+			Table:            "products",
+			Keys:             []string{"sku"},
+			Mode:             WriteModeUpsert,
+			Schema:           SchemaAuto,
+			SchemaSampleSize: defaultSchemaSampleSize,
+			ColumnTypes:      map[string]string{"price": "NUMERIC(10, 2)"},
+		},
+	}
+	records := []map[string]interface{}{
+		{"sku": "A", "stock": float64(3), "weight": float64(1), "active": true, "tags": []interface{}{"x"}},
+		{"sku": "B", "stock": float64(5), "weight": 1.5, "note": nil},
+	}
+
+	plan, err := d.planSchema(nil, records)
+	if err != nil {
+		t.Fatalf("planSchema() error = %v", err)
+	}
+	want := `CREATE TABLE IF NOT EXISTS "products" (` +
+		`"active" BOOLEAN, "note" TEXT, "price" NUMERIC(10, 2), "sku" TEXT, "stock" BIGINT, "tags" JSONB, "weight" DOUBLE PRECISION, ` +
+		`PRIMARY KEY ("sku"))`
+	if !reflect.DeepEqual(plan.ddl, []string{want}) {
+		t.Errorf("planSchema() ddl = %v\nwant %s", plan.ddl, want)
+	}
+	if !strings.HasPrefix(plan.statement.query, `INSERT INTO "products" ("active", "note", "price", "sku", "stock", "tags", "weight")`) {
+		t.Errorf("planSchema() statement = %s", plan.statement.query)
+	}
+	if args := plan.statement.args(records[0]); args[5] != `["x"]` {
+		t.Errorf("tags arg = %v, want JSON text", args[5])
+	}
+}
+
+func TestPlanSchema_ExistingTable(t *testing.T) {
+	d := &DatabaseOutput{
+		driver: "sqlite",
+		config: DatabaseOutputConfig{
+			Table:            "events",
+			Mode:             WriteModeInsert,
+			Schema:           SchemaAuto,
+			SchemaSampleSize: 1,
+		},
+	}
+	existing := &tableSchema{columns: map[string]string{"id": "INTEGER", "name": "TEXT", "score": "REAL"}}
+
+	// score is sampled from its first value only; new fields become columns
+	records := []map[string]interface{}{
+		{"id": float64(1), "name": "a", "score": float64(2), "source": "api"},
+		{"id": float64(2), "score": 2.5, "payload": map[string]interface{}{"k": "v"}},
+	}
+	plan, err := d.planSchema(existing, records)
+	if err != nil {
+		t.Fatalf("planSchema() error = %v", err)
+	}
+	want := []string{
+		`ALTER TABLE "events" ADD COLUMN "payload" TEXT`,
+		`ALTER TABLE "events" ADD COLUMN "source" TEXT`,
+	}
+	if !reflect.DeepEqual(plan.ddl, want) {
+		t.Errorf("planSchema() ddl = %v, want %v", plan.ddl, want)
+	}
+
+	// A column is not narrowed: floats do not fit an integer column
+	records = []map[string]interface{}{{"id": 1.5, "name": float64(3)}}
+	_, err = d.planSchema(existing, records)
+	if !errors.Is(err, ErrDatabaseOutputSchemaConflict) {
+		t.Fatalf("planSchema() error = %v, want ErrDatabaseOutputSchemaConflict", err)
+	}
+	for _, conflict := range []string{"column id is INTEGER but records have float values", "column name is TEXT but records have integer values"} {
+		if !strings.Contains(err.Error(), conflict) {
+			t.Errorf("planSchema() error = %v, want %q", err, conflict)
+		}
+	}
+}
+
+func TestEnsureSchema_CachesExistingTable(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("CREATE TABLE events (id INTEGER, name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &DatabaseOutput{
+		db:      db,
+		driver:  "sqlite",
+		timeout: time.Second,
+		config: DatabaseOutputConfig{
+			Table:            "events",
+			Mode:             WriteModeInsert,
+			Schema:           SchemaAuto,
+			SchemaSampleSize: defaultSchemaSampleSize,
+		},
+	}
+	records := []map[string]interface{}{{"id": float64(1), "name": "a"}}
+	if err := d.ensureSchema(context.Background(), records); err != nil {
+		t.Fatalf("ensureSchema() error = %v", err)
+	}
+
+	// The table needed no change: its columns are still cached
+	want := map[string]string{"id": "INTEGER", "name": "TEXT"}
+	if d.tableSchema == nil || !reflect.DeepEqual(d.tableSchema.columns, want) {
+		t.Fatalf("cached schema = %+v, want columns %v", d.tableSchema, want)
+	}
+	if _, err := db.Exec("DROP TABLE events"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ensureSchema(context.Background(), records); err != nil {
+		t.Fatalf("ensureSchema() error = %v, want the cached columns to be used", err)
+	}
+}
+
+func TestPlanSchema_InvalidField(t *testing.T) {
+	d := &DatabaseOutput{
+		driver: "sqlite",
+		config: DatabaseOutputConfig{Table: "t", Mode: WriteModeUpsert, Keys: []string{"id"}, Schema: SchemaAuto},
+	}
+
+	if _, err := d.planSchema(nil, []map[string]interface{}{{"first-name": "a", "id": 1}}); !errors.Is(err, ErrDatabaseOutputInvalidTable) {
+		t.Errorf("planSchema() error = %v, want ErrDatabaseOutputInvalidTable", err)
+	}
+	if _, err := d.planSchema(nil, []map[string]interface{}{{"name": "a"}}); !errors.Is(err, ErrDatabaseOutputInvalidTable) {
+		t.Errorf("planSchema() without key field error = %v, want ErrDatabaseOutputInvalidTable", err)
+	}
+}
+
+func TestValidateSchemaConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  DatabaseOutputConfig
+		wantErr bool
+	}{
+		{name: "manual", config: DatabaseOutputConfig{Query: "INSERT INTO t VALUES (1)"}},
+		{name: "auto", config: DatabaseOutputConfig{Table: "t", Schema: SchemaAuto, ColumnTypes: map[string]string{"id": "BIGINT", "name": "VARCHAR(64)"}}},
+		{name: "unsupported schema", config: DatabaseOutputConfig{Table: "t", Schema: "migrate"}, wantErr: true},
+		{name: "auto without table", config: DatabaseOutputConfig{Query: "INSERT INTO t VALUES (1)", Schema: SchemaAuto}, wantErr: true},
+		{name: "column types without auto", config: DatabaseOutputConfig{Table: "t", ColumnTypes: map[string]string{"id": "BIGINT"}}, wantErr: true},
+		{name: "invalid type", config: DatabaseOutputConfig{Table: "t", Schema: SchemaAuto, ColumnTypes: map[string]string{"id": "BIGINT); DROP TABLE t; --"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchemaConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSchemaConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDatabaseTypeKind(t *testing.T) {
+	tests := map[string]columnKind{
+		"INT8":              kindInteger,
+		"TINYINT":           kindInteger,
+		"BOOL":              kindBoolean,
+		"DOUBLE PRECISION":  kindFloat,
+		"NUMERIC(10, 2)":    kindFloat,
+		"character varying": kindText,
+		"TIMESTAMPTZ":       kindTimestamp,
+		"JSONB":             kindJSON,
+		"INTERVAL":          kindUnknown,
+		"":                  kindUnknown,
+	}
+	for typeName, want := range tests {
+		if got := databaseTypeKind(typeName); got != want {
+			t.Errorf("databaseTypeKind(%q) = %q, want %q", typeName, got, want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cannectors/runtime/internal/database"
+)
+
+// Declarative write modes
+const (
+	WriteModeInsert = "insert"
+	WriteModeUpsert = "upsert"
+	WriteModeUpdate = "update"
+	WriteModeDelete = "delete"
+)
+
+// ErrDatabaseOutputInvalidTable is returned for invalid declarative
+// table, columns, keys or mode configuration.
+var ErrDatabaseOutputInvalidTable = errors.New("invalid database output table configuration")
+
+// columnNamePattern matches plain column names, tableNamePattern optionally
+// schema-qualified table names. Identifiers are quoted in generated SQL.
+var (
+	columnNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	tableNamePattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
+// writeColumn maps a record field to a table column.
+type writeColumn struct {
+	Column string
+	Field  string
+}
+
+// writeStatement is a parameterized statement generated from the declarative
+// configuration. Its parameters are the record fields, in order.
+type writeStatement struct {
+	query  string
+	fields []string
+
+	// Multi-row form of insert and upsert statements: prefix, then one
+	// VALUES tuple per row, then suffix (conflict clause)
+	driver string
+	prefix string
+	suffix string
+}
+
+// multiRow reports whether the statement can write several rows at once.
+func (s *writeStatement) multiRow() bool {
+	return s.prefix != ""
+}
+
+// multiRowQuery returns the statement writing rows records in a single
+// multi-row VALUES statement. Its parameters are the fields of each record.
+func (s *writeStatement) multiRowQuery(rows int) string {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	param := 1
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for i := range s.fields {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(database.FormatPlaceholder(s.driver, param))
+			param++
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(s.suffix)
+	return b.String()
+}
+
+// args returns the statement parameters for a record. Nested objects and
+// arrays are written as JSON.
+func (s *writeStatement) args(record map[string]interface{}) []interface{} {
+	args := make([]interface{}, len(s.fields))
+	for i, field := range s.fields {
+		args[i] = getDBFieldValue(record, field)
+		switch args[i].(type) {
+		case map[string]interface{}, []interface{}:
+			if data, err := json.Marshal(args[i]); err == nil {
+				args[i] = string(data)
+			}
+		}
+	}
+	return args
+}
+
+// parseWriteColumns parses the columns map (record field to column), sorted
+// by column so that generated statements are stable.
+func parseWriteColumns(raw interface{}) []writeColumn {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	columns := make([]writeColumn, 0, len(m))
+	for field, v := range m {
+		if column, ok := v.(string); ok {
+			columns = append(columns, writeColumn{Column: column, Field: field})
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Column < columns[j].Column })
+	return columns
+}
+
+// validateWriteConfig validates the declarative configuration: a table,
+// columns, a supported mode, and keys among the columns for modes matching
+// existing rows.
+func validateWriteConfig(config DatabaseOutputConfig) error {
+	if !tableNamePattern.MatchString(config.Table) {
+		return fmt.Errorf("%w: invalid table name %q", ErrDatabaseOutputInvalidTable, config.Table)
+	}
+	if len(config.Columns) == 0 && config.Schema != SchemaAuto {
+		return fmt.Errorf("%w: columns are required with table", ErrDatabaseOutputInvalidTable)
+	}
+	mapped := make(map[string]bool, len(config.Columns))
+	for _, c := range config.Columns {
+		if !columnNamePattern.MatchString(c.Column) {
+			return fmt.Errorf("%w: invalid column name %q", ErrDatabaseOutputInvalidTable, c.Column)
+		}
+		if mapped[c.Column] {
+			return fmt.Errorf("%w: column %q is mapped more than once", ErrDatabaseOutputInvalidTable, c.Column)
+		}
+		mapped[c.Column] = true
+	}
+
+	switch config.Mode {
+	case WriteModeInsert:
+		return nil
+	case WriteModeUpsert, WriteModeUpdate, WriteModeDelete:
+	default:
+		return fmt.Errorf("%w: unsupported mode %q (supported: insert, upsert, update, delete)", ErrDatabaseOutputInvalidTable, config.Mode)
+	}
+	if len(config.Keys) == 0 {
+		return fmt.Errorf("%w: keys are required with mode %s", ErrDatabaseOutputInvalidTable, config.Mode)
+	}
+	if len(config.Columns) == 0 {
+		return nil // schema auto: keys are checked against the columns of the records
+	}
+	for _, key := range config.Keys {
+		if !mapped[key] {
+			return fmt.Errorf("%w: key %q is not a mapped column", ErrDatabaseOutputInvalidTable, key)
+		}
+	}
+	if config.Mode == WriteModeUpdate && len(config.Keys) == len(config.Columns) {
+		return fmt.Errorf("%w: mode update requires columns besides the keys", ErrDatabaseOutputInvalidTable)
+	}
+	return nil
+}
+
+// quoteIdentifier quotes a (possibly schema-qualified) identifier for the driver.
+func quoteIdentifier(driver, name string) string {
+	quote := `"`
+	if driver == database.DriverMySQL {
+		quote = "`"
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quote + part + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// buildWriteStatement generates the statement of the configured mode for the driver:
+//   - insert: INSERT INTO t (c...) VALUES (...)
+//   - upsert: INSERT ... ON CONFLICT (keys) DO UPDATE SET (PostgreSQL, SQLite)
+//     or ON DUPLICATE KEY UPDATE (MySQL, keys must be a unique index)
+//   - update: UPDATE t SET c = ... WHERE keys = ...
+//   - delete: DELETE FROM t WHERE keys = ...
+func buildWriteStatement(driver string, config DatabaseOutputConfig) *writeStatement {
+	isKey := make(map[string]bool, len(config.Keys))
+	for _, key := range config.Keys {
+		isKey[key] = true
+	}
+	var keyColumns, valueColumns []writeColumn
+	for _, c := range config.Columns {
+		if isKey[c.Column] {
+			keyColumns = append(keyColumns, c)
+		} else {
+			valueColumns = append(valueColumns, c)
+		}
+	}
+
+	stmt := &writeStatement{driver: driver}
+	table := quoteIdentifier(driver, config.Table)
+	param := func(c writeColumn) string {
+		stmt.fields = append(stmt.fields, c.Field)
+		return database.FormatPlaceholder(driver, len(stmt.fields))
+	}
+
+	switch config.Mode {
+	case WriteModeUpdate:
+		set := assignColumns(driver, valueColumns, ", ", param)
+		stmt.query = "UPDATE " + table + " SET " + set + " WHERE " + assignColumns(driver, keyColumns, " AND ", param)
+	case WriteModeDelete:
+		stmt.query = "DELETE FROM " + table + " WHERE " + assignColumns(driver, keyColumns, " AND ", param)
+	default:
+		for _, c := range config.Columns {
+			stmt.fields = append(stmt.fields, c.Field)
+		}
+		stmt.prefix = "INSERT INTO " + table + " (" + strings.Join(quoteColumns(driver, config.Columns), ", ") + ") VALUES "
+		if config.Mode == WriteModeUpsert {
+			stmt.suffix = upsertClause(driver, keyColumns, valueColumns)
+		}
+		stmt.query = stmt.multiRowQuery(1)
+	}
+
+	if len(config.Returning) > 0 {
+		// Rows returned by a multi-row statement are not in VALUES order, and
+		// MySQL returns the first generated id only: returning statements
+		// write one row at a time
+		stmt.query += returningClause(driver, config.Returning)
+		stmt.prefix = ""
+	}
+	return stmt
+}
+
+// upsertClause returns the conflict clause of an upsert statement.
+func upsertClause(driver string, keyColumns, valueColumns []writeColumn) string {
+	if driver == database.DriverMySQL {
+		// Rows matching only on keys keep their values (no-op assignment)
+		updated := valueColumns
+		if len(updated) == 0 {
+			updated = keyColumns[:1]
+		}
+		return " ON DUPLICATE KEY UPDATE " + assignColumns(driver, updated, ", ", func(c writeColumn) string {
+			return "VALUES(" + quoteIdentifier(driver, c.Column) + ")"
+		})
+	}
+
+	clause := " ON CONFLICT (" + strings.Join(quoteColumns(driver, keyColumns), ", ") + ")"
+	if len(valueColumns) == 0 {
+		return clause + " DO NOTHING"
+	}
+	return clause + " DO UPDATE SET " + assignColumns(driver, valueColumns, ", ", func(c writeColumn) string {
+		return "EXCLUDED." + quoteIdentifier(driver, c.Column)
+	})
+}
+
+// quoteColumns returns the quoted names of columns.
+func quoteColumns(driver string, columns []writeColumn) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = quoteIdentifier(driver, c.Column)
+	}
+	return names
+}
+
+// assignColumns returns "column = value" for each column, joined by sep.
+func assignColumns(driver string, columns []writeColumn, sep string, value func(writeColumn) string) string {
+	parts := make([]string, len(columns))
+	for i, c := range columns {
+		parts[i] = quoteIdentifier(driver, c.Column) + " = " + value(c)
+	}
+	return strings.Join(parts, sep)
+}
+
+// validateTableColumns checks that the mapped columns exist in the table,
+// reading its columns from an empty result set.
+func validateTableColumns(ctx context.Context, db *sql.DB, driver string, config DatabaseOutputConfig) error {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+quoteIdentifier(driver, config.Table)+" WHERE 1 = 0")
+	if err != nil {
+		return fmt.Errorf("%w: reading columns of table %s: %w", ErrDatabaseOutputInvalidTable, config.Table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	names, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("%w: reading columns of table %s: %w", ErrDatabaseOutputInvalidTable, config.Table, err)
+	}
+
+	existing := make(map[string]bool, len(names))
+	for _, name := range names {
+		existing[name] = true
+	}
+	var missing []string
+	for _, c := range config.Columns {
+		if !existing[c.Column] {
+			missing = append(missing, c.Column)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: table %s has no column %s (columns: %s)",
+			ErrDatabaseOutputInvalidTable, config.Table, strings.Join(missing, ", "), strings.Join(names, ", "))
+	}
+	return nil
+}
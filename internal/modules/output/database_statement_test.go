@@ -0,0 +1,215 @@
+package output
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseWriteColumns(t *testing.T) {
+	columns := parseWriteColumns(map[string]interface{}{
+		"product_name": "name",
+		"sku":          "sku",
+		"pricing.net":  "price",
+		"ignored":      float64(1),
+	})
+	want := []writeColumn{
+		{Column: "name", Field: "product_name"},
+		{Column: "price", Field: "pricing.net"},
+		{Column: "sku", Field: "sku"},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("parseWriteColumns() = %+v, want %+v", columns, want)
+	}
+}
+
+func TestBuildWriteStatement(t *testing.T) {
+	columns := []writeColumn{
+		{Column: "name", Field: "product_name"},
+		{Column: "price", Field: "price"},
+		{Column: "sku", Field: "sku"},
+	}
+
+	tests := []struct {
+		name       string
+		driver     string
+		mode       string
+		table      string
+		keys       []string
+		columns    []writeColumn
+		wantQuery  string
+		wantFields []string
+	}{
+		{
+			name:       "insert postgres",
+			driver:     "postgres",
+			mode:       WriteModeInsert,
+			wantQuery:  `INSERT INTO "products" ("name", "price", "sku") VALUES ($1, $2, $3)`,
+			wantFields: []string{"product_name", "price", "sku"},
+		},
+		{
+			name:       "insert mysql schema-qualified",
+			driver:     "mysql",
+			mode:       WriteModeInsert,
+			table:      "shop.products",
+			wantQuery:  "INSERT INTO `shop`.`products` (`name`, `price`, `sku`) VALUES (?, ?, ?)",
+			wantFields: []string{"product_name", "price", "sku"},
+		},
+		{
+			name:       "upsert postgres",
+			driver:     "postgres",
+			mode:       WriteModeUpsert,
+			keys:       []string{"sku"},
+			wantQuery:  `INSERT INTO "products" ("name", "price", "sku") VALUES ($1, $2, $3) ON CONFLICT ("sku") DO UPDATE SET "name" = EXCLUDED."name", "price" = EXCLUDED."price"`,
+			wantFields: []string{"product_name", "price", "sku"},
+		},
+		{
+			name:       "upsert sqlite",
+			driver:     "sqlite",
+			mode:       WriteModeUpsert,
+			keys:       []string{"sku"},
+			wantQuery:  `INSERT INTO "products" ("name", "price", "sku") VALUES (?, ?, ?) ON CONFLICT ("sku") DO UPDATE SET "name" = EXCLUDED."name", "price" = EXCLUDED."price"`,
+			wantFields: []string{"product_name", "price", "sku"},
+		},
+		{
+			name:       "upsert mysql",
+			driver:     "mysql",
+			mode:       WriteModeUpsert,
+			keys:       []string{"sku"},
+			wantQuery:  "INSERT INTO `products` (`name`, `price`, `sku`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `price` = VALUES(`price`)",
+			wantFields: []string{"product_name", "price", "sku"},
+		},
+		{
+			name:       "upsert keys only postgres",
+			driver:     "postgres",
+			mode:       WriteModeUpsert,
+			keys:       []string{"sku"},
+			columns:    []writeColumn{{Column: "sku", Field: "sku"}},
+			wantQuery:  `INSERT INTO "products" ("sku") VALUES ($1) ON CONFLICT ("sku") DO NOTHING`,
+			wantFields: []string{"sku"},
+		},
+		{
+			name:       "upsert keys only mysql",
+			driver:     "mysql",
+			mode:       WriteModeUpsert,
+			keys:       []string{"sku"},
+			columns:    []writeColumn{{Column: "sku", Field: "sku"}},
+			wantQuery:  "INSERT INTO `products` (`sku`) VALUES (?) ON DUPLICATE KEY UPDATE `sku` = VALUES(`sku`)",
+			wantFields: []string{"sku"},
+		},
+		{
+			name:       "update postgres",
+			driver:     "postgres",
+			mode:       WriteModeUpdate,
+			keys:       []string{"sku"},
+			wantQuery:  `UPDATE "products" SET "name" = $1, "price" = $2 WHERE "sku" = $3`,
+			wantFields: []string{"product_name", "price", "sku"},
+		},
+		{
+			name:       "delete composite key",
+			driver:     "postgres",
+			mode:       WriteModeDelete,
+			keys:       []string{"price", "sku"},
+			wantQuery:  `DELETE FROM "products" WHERE "price" = $1 AND "sku" = $2`,
+			wantFields: []string{"price", "sku"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DatabaseOutputConfig{Table: "products", Columns: columns, Keys: tt.keys, Mode: tt.mode}
+			if tt.table != "" {
+				config.Table = tt.table
+			}
+			if tt.columns != nil {
+				config.Columns = tt.columns
+			}
+			if err := validateWriteConfig(config); err != nil {
+				t.Fatalf("validateWriteConfig() error = %v", err)
+			}
+
+			stmt := buildWriteStatement(tt.driver, config)
+			if stmt.query != tt.wantQuery {
+				t.Errorf("query = %s\nwant    %s", stmt.query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(stmt.fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", stmt.fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestWriteStatementArgs(t *testing.T) {
+	stmt := &writeStatement{fields: []string{"sku", "pricing.net", "missing"}}
+	args := stmt.args(map[string]interface{}{
+		"sku":     "SKU001",
+		"pricing": map[string]interface{}{"net": 9.99},
+	})
+	if !reflect.DeepEqual(args, []interface{}{"SKU001", 9.99, nil}) {
+		t.Errorf("args() = %v", args)
+	}
+}
+
+func TestValidateWriteConfig(t *testing.T) {
+	columns := []writeColumn{{Column: "name", Field: "name"}, {Column: "sku", Field: "sku"}}
+
+	tests := []struct {
+		name   string
+		config DatabaseOutputConfig
+	}{
+		{name: "invalid table", config: DatabaseOutputConfig{Table: "products; DROP TABLE x", Columns: columns, Mode: WriteModeInsert}},
+		{name: "no columns", config: DatabaseOutputConfig{Table: "products", Mode: WriteModeInsert}},
+		{name: "invalid column", config: DatabaseOutputConfig{Table: "products", Columns: []writeColumn{{Column: `na"me`, Field: "name"}}, Mode: WriteModeInsert}},
+		{name: "column mapped twice", config: DatabaseOutputConfig{Table: "products", Columns: []writeColumn{{Column: "sku", Field: "a"}, {Column: "sku", Field: "b"}}, Mode: WriteModeInsert}},
+		{name: "unsupported mode", config: DatabaseOutputConfig{Table: "products", Columns: columns, Mode: "merge"}},
+		{name: "upsert without keys", config: DatabaseOutputConfig{Table: "products", Columns: columns, Mode: WriteModeUpsert}},
+		{name: "key not mapped", config: DatabaseOutputConfig{Table: "products", Columns: columns, Keys: []string{"id"}, Mode: WriteModeDelete}},
+		{name: "update of keys only", config: DatabaseOutputConfig{Table: "products", Columns: columns, Keys: []string{"name", "sku"}, Mode: WriteModeUpdate}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateWriteConfig(tt.config); !errors.Is(err, ErrDatabaseOutputInvalidTable) {
+				t.Errorf("validateWriteConfig() error = %v, want ErrDatabaseOutputInvalidTable", err)
+			}
+		})
+	}
+}
+
+func TestWriteStatementMultiRowQuery(t *testing.T) {
+	config := DatabaseOutputConfig{
+		Table:   "products",
+		Columns: []writeColumn{{Column: "name", Field: "name"}, {Column: "sku", Field: "sku"}},
+		Keys:    []string{"sku"},
+		Mode:    WriteModeUpsert,
+	}
+
+	stmt := buildWriteStatement("postgres", config)
+	want := `INSERT INTO "products" ("name", "sku") VALUES ($1, $2), ($3, $4), ($5, $6) ON CONFLICT ("sku") DO UPDATE SET "name" = EXCLUDED."name"`
+	if got := stmt.multiRowQuery(3); got != want {
+		t.Errorf("multiRowQuery(3) = %s\nwant                %s", got, want)
+	}
+
+	config.Mode = WriteModeDelete
+	if stmt := buildWriteStatement("postgres", config); stmt.multiRow() {
+		t.Error("delete statement reported as multi-row")
+	}
+}
+
+func TestDatabaseOutputBatchRows(t *testing.T) {
+	columns := make([]writeColumn, 10)
+	for i := range columns {
+		columns[i] = writeColumn{Column: "c" + string(rune('a'+i)), Field: "f"}
+	}
+	config := DatabaseOutputConfig{Table: "t", Columns: columns, Mode: WriteModeInsert, BatchSize: 10000}
+
+	d := &DatabaseOutput{config: config}
+	if got := d.batchRows(buildWriteStatement("sqlite", config)); got != maxBatchParams/10 {
+		t.Errorf("batchRows() = %d, want %d (parameter limit)", got, maxBatchParams/10)
+	}
+
+	// Prepared batches are not bound by the parameter limit
+	if got := d.batchRows(nil); got != 10000 {
+		t.Errorf("batchRows() = %d, want 10000", got)
+	}
+}
@@ -1,6 +1,7 @@
 package output
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/cannectors/runtime/pkg/connector"
@@ -162,6 +163,31 @@ func TestNewDatabaseOutputFromConfig_Validation(t *testing.T) {
 			},
 			wantErr: ErrDatabaseOutputMissingQuery,
 		},
+		{
+			name: "unsupported isolation level",
+			cfg: &connector.ModuleConfig{
+				Type: "database",
+				Config: map[string]interface{}{
+					"connectionString": "postgres://localhost/db",
+					"query":            "INSERT INTO users (name) VALUES ({{record.name}})",
+					"transaction":      true,
+					"isolationLevel":   "snapshot",
+				},
+			},
+			wantErr: ErrDatabaseOutputInvalidTx,
+		},
+		{
+			name: "commitEvery without transaction",
+			cfg: &connector.ModuleConfig{
+				Type: "database",
+				Config: map[string]interface{}{
+					"connectionString": "postgres://localhost/db",
+					"query":            "INSERT INTO users (name) VALUES ({{record.name}})",
+					"commitEvery":      float64(100),
+				},
+			},
+			wantErr: ErrDatabaseOutputInvalidTx,
+		},
 	}
 
 	for _, tt := range tests {
@@ -171,7 +197,7 @@ func TestNewDatabaseOutputFromConfig_Validation(t *testing.T) {
 				t.Error("expected error, got nil")
 				return
 			}
-			if err != tt.wantErr {
+			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("error = %v, want %v", err, tt.wantErr)
 			}
 		})
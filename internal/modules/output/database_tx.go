@@ -0,0 +1,105 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// recordSavepoint is the savepoint isolating a record or batch written in a
+// transaction, so that its failure can be rolled back alone.
+const recordSavepoint = "cannectors_record"
+
+// sendTx is the transaction of a send. With commitEvery, it is committed
+// every commitEvery records and a new transaction begun, so that a failure
+// only rolls back the records written since the last commit.
+type sendTx struct {
+	*sql.Tx
+	d        *DatabaseOutput
+	pending  int // records written since the last commit
+	returned int // returned records committed
+}
+
+// beginSendTx begins the transaction of a send with the configured options.
+func (d *DatabaseOutput) beginSendTx(ctx context.Context) (*sendTx, error) {
+	tx, err := d.db.BeginTx(ctx, d.txOptions)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	return &sendTx{Tx: tx, d: d, returned: len(d.returned)}, nil
+}
+
+// rollback rolls back the transaction, and drops the returned records
+// written since the last commit.
+func (t *sendTx) rollback() {
+	_ = t.Rollback()
+	t.d.returned = t.d.returned[:t.returned]
+}
+
+// current returns the open transaction, or nil without a transaction.
+func (t *sendTx) current() *sql.Tx {
+	if t == nil {
+		return nil
+	}
+	return t.Tx
+}
+
+// written counts n more records written, committing and beginning a new
+// transaction once commitEvery records are pending.
+func (t *sendTx) written(ctx context.Context, n int) error {
+	t.pending += n
+	if t.d.config.CommitEvery <= 0 || t.pending < t.d.config.CommitEvery {
+		return nil
+	}
+
+	if err := t.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	logger.Debug("database output transaction committed",
+		slog.String("module_type", "database"),
+		slog.Int("record_count", t.pending),
+	)
+	t.pending = 0
+	t.returned = len(t.d.returned)
+
+	tx, err := t.d.db.BeginTx(ctx, t.d.txOptions)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	t.Tx = tx
+	return nil
+}
+
+// execIsolated runs exec in tx. When record failures are skipped or logged,
+// exec runs under a savepoint rolled back if it fails, so that the
+// transaction can go on: on PostgreSQL, a failed statement otherwise aborts
+// the whole transaction. Returns the error of exec, and the error of the
+// savepoint statements, which ends the send.
+func (d *DatabaseOutput) execIsolated(ctx context.Context, tx *sql.Tx, exec func() error) (execErr, err error) {
+	if d.config.OnError == "fail" {
+		return exec(), nil
+	}
+	return execInSavepoint(ctx, tx, recordSavepoint, exec)
+}
+
+// execInSavepoint runs exec in tx under the savepoint name, which is rolled
+// back to if exec fails, then released. Returns the error of exec, and the
+// error of the savepoint statements.
+func execInSavepoint(ctx context.Context, tx *sql.Tx, name string, exec func() error) (execErr, err error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, fmt.Errorf("creating savepoint: %w", err)
+	}
+	if execErr = exec(); execErr != nil {
+		if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); err != nil {
+			return execErr, fmt.Errorf("rolling back to savepoint: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return execErr, fmt.Errorf("releasing savepoint: %w", err)
+	}
+	return execErr, nil
+}
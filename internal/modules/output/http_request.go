@@ -15,6 +15,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/expr-lang/expr"
@@ -24,6 +25,7 @@ import (
 	"github.com/cannectors/runtime/internal/errhandling"
 	"github.com/cannectors/runtime/internal/httpconfig"
 	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/ratelimit"
 	"github.com/cannectors/runtime/pkg/connector"
 )
 
@@ -105,6 +107,9 @@ type RequestConfig struct {
 	HeadersFromRecord map[string]string // Headers extracted from record data
 	BodyTemplateFile  string            // Path to external template file for request body
 	bodyTemplateRaw   string            // Loaded template content (internal use)
+	BatchSize         int               // Max records per request in batch mode (0 = all)
+	MaxBodyBytes      int               // Max request body size (0 = unlimited)
+	Concurrency       int               // Max requests in flight (default 1)
 }
 
 // RetryConfig is an alias for errhandling.RetryConfig for backward compatibility.
@@ -124,9 +129,12 @@ type HTTPRequestModule struct {
 	client            *http.Client
 	onError           errhandling.OnErrorStrategy // "fail", "skip", "log"
 	successCodes      []int                       // HTTP status codes considered success
+	itemResults       *ItemResultsConfig          // Per-record results read from responses
 	lastRetryInfo     *connector.RetryInfo
-	retryHintProgram  *vm.Program        // Compiled expr program for retryHintFromBody
-	templateEvaluator *TemplateEvaluator // Template evaluator for dynamic content
+	failed            []connector.RecordFailure // Records of the last Send that failed
+	mu                sync.Mutex                // guards lastRetryInfo and failed during concurrent requests
+	retryHintProgram  *vm.Program               // Compiled expr program for retryHintFromBody
+	templateEvaluator *TemplateEvaluator        // Template evaluator for dynamic content
 }
 
 // Default success status codes
@@ -142,8 +150,10 @@ var defaultSuccessCodes = []int{200, 201, 202, 204}
 // Optional config fields:
 //   - headers: Custom HTTP headers (map[string]string)
 //   - timeoutMs: Request timeout in milliseconds (default 30000)
-//   - request: Request configuration (bodyFrom, pathParams, query)
+//   - request: Request configuration (bodyFrom, pathParams, query, batchSize, maxBodyBytes, concurrency)
 //   - onError: Error handling mode ("fail", "skip", "log")
+//   - success: Success status codes, and per-record results read from responses (items)
+//   - rateLimit: Client-side rate limit (requestsPerSecond, burst), shared per host
 func NewHTTPRequestFromConfig(config *connector.ModuleConfig) (*HTTPRequestModule, error) {
 	if config == nil {
 		return nil, ErrNilConfig
@@ -156,10 +166,21 @@ func NewHTTPRequestFromConfig(config *connector.ModuleConfig) (*HTTPRequestModul
 
 	headers := extractHeaders(config.Config)
 	reqConfig := extractRequestConfig(config.Config)
+	if err := validateRequestBatching(reqConfig); err != nil {
+		return nil, err
+	}
 	onError := extractErrorHandling(config.Config)
 	successCodes := extractSuccessCodes(config.Config)
+	itemResults, err := extractItemResultsConfig(config.Config)
+	if err != nil {
+		return nil, err
+	}
 	retryConfig := extractRetryConfig(config.Config)
-	client := createHTTPClient(timeout)
+	rateLimit, err := httpconfig.ExtractRateLimitConfig(config.Config)
+	if err != nil {
+		return nil, err
+	}
+	client := createHTTPClient(timeout, rateLimit)
 
 	// Create authentication handler if configured
 	authHandler, err := auth.NewHandler(config.Authentication, client)
@@ -215,6 +236,7 @@ func NewHTTPRequestFromConfig(config *connector.ModuleConfig) (*HTTPRequestModul
 		client:            client,
 		onError:           onError,
 		successCodes:      successCodes,
+		itemResults:       itemResults,
 		retryHintProgram:  retryHintProgram,
 		templateEvaluator: NewTemplateEvaluator(),
 	}
@@ -236,6 +258,9 @@ func NewHTTPRequestFromConfig(config *connector.ModuleConfig) (*HTTPRequestModul
 		slog.String("body_from", reqConfig.BodyFrom),
 		slog.Bool("has_templating", hasTemplating),
 		slog.String("body_template_file", reqConfig.BodyTemplateFile),
+		slog.Int("batch_size", reqConfig.BatchSize),
+		slog.Int("max_body_bytes", reqConfig.MaxBodyBytes),
+		slog.Int("concurrency", reqConfig.Concurrency),
 	)
 
 	return module, nil
@@ -292,7 +317,8 @@ func extractHeaders(config map[string]interface{}) map[string]string {
 // extractRequestConfig extracts request-specific configuration
 func extractRequestConfig(config map[string]interface{}) RequestConfig {
 	reqConfig := RequestConfig{
-		BodyFrom: defaultBodyFrom,
+		BodyFrom:    defaultBodyFrom,
+		Concurrency: defaultRequestConcurrency,
 	}
 
 	// Extract dynamic params using httpconfig
@@ -311,6 +337,7 @@ func extractRequestConfig(config map[string]interface{}) RequestConfig {
 		if bodyFrom, ok := requestVal["bodyFrom"].(string); ok {
 			reqConfig.BodyFrom = bodyFrom
 		}
+		extractRequestBatching(requestVal, &reqConfig)
 	}
 
 	return reqConfig
@@ -360,17 +387,22 @@ func extractRetryConfig(config map[string]interface{}) RetryConfig {
 	return errhandling.ParseRetryConfig(retryVal)
 }
 
-// createHTTPClient creates an HTTP client with configured timeout and transport settings
-func createHTTPClient(timeout time.Duration) *http.Client {
+// createHTTPClient creates an HTTP client with configured timeout and transport settings.
+// If rateLimit is set, requests are paced by the shared limiter of their host.
+func createHTTPClient(timeout time.Duration, rateLimit *ratelimit.Config) *http.Client {
 	transport := &http.Transport{
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 10,
 		IdleConnTimeout:     90 * time.Second,
 	}
-	return &http.Client{
+	client := &http.Client{
 		Timeout:   timeout,
 		Transport: transport,
 	}
+	if rateLimit != nil {
+		ratelimit.WrapClient(client, *rateLimit)
+	}
+	return client
 }
 
 // Send transmits records to the destination via HTTP.
@@ -385,6 +417,7 @@ func createHTTPClient(timeout time.Duration) *http.Client {
 // Empty or nil records return success with 0 sent.
 func (h *HTTPRequestModule) Send(ctx context.Context, records []map[string]interface{}) (int, error) {
 	startTime := time.Now()
+	h.failed = nil
 
 	// Handle empty/nil records gracefully
 	if len(records) == 0 {
@@ -441,101 +474,55 @@ func (h *HTTPRequestModule) Send(ctx context.Context, records []map[string]inter
 	return sent, nil
 }
 
-// sendBatchMode sends all records in a single HTTP request as JSON array
+// sendBatchMode sends records as JSON arrays, in one request or, with
+// batchSize or maxBodyBytes, in one request per chunk of records.
 func (h *HTTPRequestModule) sendBatchMode(ctx context.Context, records []map[string]interface{}) (int, error) {
-	requestStart := time.Now()
+	// Without body template, records are marshaled one by one (with metadata
+	// stripped) to split chunks by body size
+	var items [][]byte
+	if h.request.bodyTemplateRaw == "" {
+		items = make([][]byte, len(records))
+		for i, record := range stripMetadataFromRecords(records) {
+			item, err := json.Marshal(record)
+			if err != nil {
+				logger.Error("failed to marshal records to JSON",
+					slog.String("module_type", "httpRequest"),
+					slog.String("endpoint", h.endpoint),
+					slog.Int("record_count", len(records)),
+					slog.String("error", err.Error()),
+				)
+				return 0, fmt.Errorf("%w: %w", ErrJSONMarshal, err)
+			}
+			items[i] = item
+		}
+	}
+	chunks := h.batchChunks(len(records), items)
 
 	logger.Debug("sending records in batch mode",
 		slog.String("module_type", "httpRequest"),
 		slog.String("endpoint", h.endpoint),
 		slog.String("method", h.method),
 		slog.Int("record_count", len(records)),
+		slog.Int("request_count", len(chunks)),
 	)
 
-	// Build request body
-	var body []byte
-	var err error
-
-	if h.request.bodyTemplateRaw != "" {
-		// Use template file: evaluate template with first record (batch context)
-		// For batch mode with template, we can only use first record's data
-		// The template should be designed accordingly
-		if len(records) > 0 {
-			bodyStr := h.templateEvaluator.EvaluateTemplate(h.request.bodyTemplateRaw, records[0])
-			body = []byte(bodyStr)
-
-			// Validate resulting JSON is well-formed (AC #4)
-			// Only validate if Content-Type indicates JSON
-			if h.isJSONContentType() {
-				if validationErr := validateJSON(body); validationErr != nil {
-					logger.Warn("body template produced invalid JSON, continuing anyway",
-						slog.String("error", validationErr.Error()),
-						slog.String("body_preview", truncateString(string(body), 100)),
-					)
-					// Continue execution - let HTTP client handle the error if needed
-				}
-			}
-		} else {
-			body = []byte(h.request.bodyTemplateRaw)
-		}
-		logger.Debug("body generated from template file",
-			slog.Int("body_size", len(body)),
-		)
-	} else {
-		// Default: marshal records to JSON array (with metadata stripped)
-		recordsForBody := stripMetadataFromRecords(records)
-		body, err = json.Marshal(recordsForBody)
-		if err != nil {
-			logger.Error("failed to marshal records to JSON",
-				slog.String("module_type", "httpRequest"),
-				slog.String("endpoint", h.endpoint),
-				slog.Int("record_count", len(records)),
-				slog.String("error", err.Error()),
-			)
-			return 0, fmt.Errorf("%w: %w", ErrJSONMarshal, err)
-		}
-	}
+	sent, failed, err := h.sendRequests(ctx, len(chunks), func(i int) outgoingRequest {
+		return h.batchRequest(records, items, chunks[i])
+	})
 
-	logger.Debug("request body prepared",
+	logger.Debug("batch mode completed",
 		slog.String("module_type", "httpRequest"),
-		slog.Int("body_size", len(body)),
-	)
-
-	// Resolve endpoint with template variables and static query parameters
-	// In batch mode, templates are evaluated using the first record
-	endpoint := h.resolveEndpointForBatch(h.endpoint, records)
-
-	// Evaluate templated headers using first record in batch mode
-	var batchHeaders map[string]string
-	if len(records) > 0 {
-		batchHeaders = h.extractHeadersFromRecord(records[0])
-	}
-
-	// Execute request with batch-resolved headers
-	err = h.doRequestWithHeaders(ctx, endpoint, body, batchHeaders)
-	requestDuration := time.Since(requestStart)
-
-	if err != nil {
-		logger.Debug("batch request failed",
-			slog.String("module_type", "httpRequest"),
-			slog.String("endpoint", endpoint),
-			slog.Duration("duration", requestDuration),
-			slog.String("error", err.Error()),
-		)
-		return 0, err
-	}
-
-	logger.Debug("batch request completed",
-		slog.String("module_type", "httpRequest"),
-		slog.String("endpoint", endpoint),
-		slog.Int("records_sent", len(records)),
-		slog.Duration("duration", requestDuration),
+		slog.Int("total_records", len(records)),
+		slog.Int("requests", len(chunks)),
+		slog.Int("sent", sent),
+		slog.Int("failed", failed),
 	)
 
-	return len(records), nil
+	return sent, err
 }
 
-// sendSingleRecordMode sends one HTTP request per record
+// sendSingleRecordMode sends one HTTP request per record, with at most
+// concurrency requests in flight
 func (h *HTTPRequestModule) sendSingleRecordMode(ctx context.Context, records []map[string]interface{}) (int, error) {
 	logger.Debug("sending records in single record mode",
 		slog.String("module_type", "httpRequest"),
@@ -543,35 +530,24 @@ func (h *HTTPRequestModule) sendSingleRecordMode(ctx context.Context, records []
 		slog.String("method", h.method),
 		slog.Int("record_count", len(records)),
 		slog.String("on_error", string(h.onError)),
+		slog.Int("concurrency", h.request.Concurrency),
 	)
 
-	sent := 0
-	failed := 0
-	for i, record := range records {
-		requestStart := time.Now()
-
+	sent, failed, err := h.sendRequests(ctx, len(records), func(i int) outgoingRequest {
+		record := records[i]
 		body, err := h.buildBodyForRecord(record, i)
 		if err != nil {
-			failed++
-			if h.onError == errhandling.OnErrorFail {
-				return sent, fmt.Errorf("%w at record %d: %w", ErrJSONMarshal, i, err)
-			}
-			continue
+			return outgoingRequest{first: i, records: records[i : i+1], err: fmt.Errorf("%w at record %d: %w", ErrJSONMarshal, i, err)}
 		}
-
-		endpoint := h.resolveEndpointForRecord(record)
-		recordHeaders := h.extractHeadersFromRecord(record)
-
-		ok, err := h.executeRequestAndLog(ctx, endpoint, body, recordHeaders, i, requestStart)
-		if !ok {
-			failed++
-			if h.onError == errhandling.OnErrorFail {
-				return sent, err
-			}
-			continue
+		return outgoingRequest{
+			first:    i,
+			records:  records[i : i+1],
+			endpoint: h.resolveEndpointForRecord(record),
+			body:     body,
+			headers:  h.extractHeadersFromRecord(record),
+			err:      h.checkBodySize(body, i, 1),
 		}
-		sent++
-	}
+	})
 
 	logger.Debug("single record mode completed",
 		slog.String("module_type", "httpRequest"),
@@ -580,7 +556,7 @@ func (h *HTTPRequestModule) sendSingleRecordMode(ctx context.Context, records []
 		slog.Int("failed", failed),
 	)
 
-	return sent, nil
+	return sent, err
 }
 
 // buildBodyForRecord builds the request body for a single record (template or JSON marshal).
@@ -614,12 +590,13 @@ func (h *HTTPRequestModule) buildBodyForRecord(record map[string]interface{}, re
 	return body, nil
 }
 
-// executeRequestAndLog performs the HTTP request for a single record, logs outcome, and returns success.
+// executeRequestAndLog performs the HTTP request of a record or chunk of records, logs outcome,
+// and returns the response body.
 func (h *HTTPRequestModule) executeRequestAndLog(
 	ctx context.Context, endpoint string, body []byte, recordHeaders map[string]string,
 	recordIndex int, requestStart time.Time,
-) (ok bool, err error) {
-	err = h.doRequestWithHeaders(ctx, endpoint, body, recordHeaders)
+) ([]byte, error) {
+	respBody, err := h.doRequestWithHeaders(ctx, endpoint, body, recordHeaders)
 	duration := time.Since(requestStart)
 
 	if err != nil {
@@ -635,7 +612,7 @@ func (h *HTTPRequestModule) executeRequestAndLog(
 			slog.Bool("is_fatal", isFatal),
 			slog.String("on_error", string(h.onError)),
 		)
-		return false, err
+		return nil, err
 	}
 
 	logger.Debug("record sent successfully",
@@ -644,7 +621,7 @@ func (h *HTTPRequestModule) executeRequestAndLog(
 		slog.String("endpoint", endpoint),
 		slog.Duration("duration", duration),
 	)
-	return true, nil
+	return respBody, nil
 }
 
 // handleOAuth2Unauthorized handles 401 Unauthorized for OAuth2 authentication
@@ -685,24 +662,25 @@ func (h *HTTPRequestModule) handleOAuth2Unauthorized(err error, alreadyRetried b
 // doRequestWithHeaders executes a single HTTP request with optional record-specific headers
 // Implements retry logic for transient errors (5xx, network errors)
 // Special handling for 401 with OAuth2: invalidates token and retries once with new token
-func (h *HTTPRequestModule) doRequestWithHeaders(ctx context.Context, endpoint string, body []byte, recordHeaders map[string]string) error {
+// Returns the body of the successful response.
+func (h *HTTPRequestModule) doRequestWithHeaders(ctx context.Context, endpoint string, body []byte, recordHeaders map[string]string) ([]byte, error) {
 	startTime := time.Now()
 	var delaysMs []int64
 	oauth2Retried := false
 
-	lastErr := h.retryLoop(ctx, endpoint, body, recordHeaders, startTime, &delaysMs, &oauth2Retried)
+	respBody, lastErr := h.retryLoop(ctx, endpoint, body, recordHeaders, startTime, &delaysMs, &oauth2Retried)
 
 	if lastErr != nil {
-		return h.handleRetryFailure(lastErr, delaysMs, startTime, endpoint)
+		return nil, h.handleRetryFailure(lastErr, delaysMs, startTime, endpoint)
 	}
-	return nil
+	return respBody, nil
 }
 
 // retryLoop executes the retry loop for HTTP requests.
 // For HTTP errors, it uses h.retry.IsStatusCodeRetryable to decide retryability based on the module's
 // configured retryableStatusCodes (AC #1: module config takes precedence over defaults).
 // For network errors, it defers to errhandling.IsRetryable (network errors bypass retryableStatusCodes).
-func (h *HTTPRequestModule) retryLoop(ctx context.Context, endpoint string, body []byte, recordHeaders map[string]string, startTime time.Time, delaysMs *[]int64, oauth2Retried *bool) error {
+func (h *HTTPRequestModule) retryLoop(ctx context.Context, endpoint string, body []byte, recordHeaders map[string]string, startTime time.Time, delaysMs *[]int64, oauth2Retried *bool) ([]byte, error) {
 	var lastErr error
 
 	for attempt := 0; attempt <= h.retry.MaxAttempts; attempt++ {
@@ -711,16 +689,16 @@ func (h *HTTPRequestModule) retryLoop(ctx context.Context, endpoint string, body
 			backoff := h.waitForRetry(attempt, delaysMs, endpoint, lastErr)
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				return nil, ctx.Err()
 			case <-time.After(backoff):
 				// Continue to next attempt
 			}
 		}
 
-		err := h.executeHTTPRequest(ctx, endpoint, body, recordHeaders)
+		respBody, err := h.executeHTTPRequest(ctx, endpoint, body, recordHeaders)
 		if err == nil {
 			h.handleRetrySuccess(attempt, delaysMs, startTime, endpoint)
-			return nil
+			return respBody, nil
 		}
 
 		lastErr = err
@@ -732,13 +710,13 @@ func (h *HTTPRequestModule) retryLoop(ctx context.Context, endpoint string, body
 		// Use module's retryableStatusCodes for HTTP errors (AC #1)
 		if !h.isErrorRetryable(err) {
 			h.logNonRetryableError(err, endpoint)
-			return err
+			return nil, err
 		}
 
 		h.logTransientError(err, attempt, endpoint)
 	}
 
-	return lastErr // All attempts exhausted
+	return nil, lastErr // All attempts exhausted
 }
 
 // isErrorRetryable determines if an error should trigger a retry.
@@ -953,13 +931,13 @@ func (h *HTTPRequestModule) handleRetrySuccess(attempt int, delaysMs *[]int64, s
 			slog.Int("attempts", attempt+1),
 			slog.Duration("total_duration", time.Since(startTime)),
 		)
-		h.lastRetryInfo = &connector.RetryInfo{
+		h.setLastRetryInfo(&connector.RetryInfo{
 			TotalAttempts: attempt + 1,
 			RetryCount:    attempt,
 			RetryDelaysMs: *delaysMs,
-		}
+		})
 	} else {
-		h.lastRetryInfo = nil
+		h.setLastRetryInfo(nil)
 	}
 }
 
@@ -970,11 +948,11 @@ func (h *HTTPRequestModule) handleRetryFailure(lastErr error, delaysMs []int64,
 		safeErr = fmt.Errorf("all retry attempts exhausted but no error captured (max_attempts=%d)", h.retry.MaxAttempts)
 	}
 
-	h.lastRetryInfo = &connector.RetryInfo{
+	h.setLastRetryInfo(&connector.RetryInfo{
 		TotalAttempts: len(delaysMs) + 1,
 		RetryCount:    len(delaysMs),
 		RetryDelaysMs: delaysMs,
-	}
+	})
 
 	logger.Error("all retry attempts exhausted",
 		slog.String("module_type", "httpRequest"),
@@ -1011,11 +989,21 @@ func (h *HTTPRequestModule) logTransientError(err error, attempt int, endpoint s
 
 // GetRetryInfo returns retry information from the last Send request (RetryInfoProvider).
 func (h *HTTPRequestModule) GetRetryInfo() *connector.RetryInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	return h.lastRetryInfo
 }
 
-// executeHTTPRequest executes a single HTTP request without retry logic
-func (h *HTTPRequestModule) executeHTTPRequest(ctx context.Context, endpoint string, body []byte, recordHeaders map[string]string) error {
+// setLastRetryInfo records retry information; safe for concurrent requests.
+func (h *HTTPRequestModule) setLastRetryInfo(info *connector.RetryInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastRetryInfo = info
+}
+
+// executeHTTPRequest executes a single HTTP request without retry logic.
+// Returns the response body on success.
+func (h *HTTPRequestModule) executeHTTPRequest(ctx context.Context, endpoint string, body []byte, recordHeaders map[string]string) ([]byte, error) {
 	requestStart := time.Now()
 
 	req, err := http.NewRequestWithContext(ctx, h.method, endpoint, bytes.NewReader(body))
@@ -1026,7 +1014,7 @@ func (h *HTTPRequestModule) executeHTTPRequest(ctx context.Context, endpoint str
 			slog.String("method", h.method),
 			slog.String("error", err.Error()),
 		)
-		return fmt.Errorf("creating http request: %w", err)
+		return nil, fmt.Errorf("creating http request: %w", err)
 	}
 
 	// Apply validated headers (defaults + static config + record; all custom headers validated via tryAddValidHeader)
@@ -1042,7 +1030,7 @@ func (h *HTTPRequestModule) executeHTTPRequest(ctx context.Context, endpoint str
 			slog.String("endpoint", endpoint),
 			slog.String("error", err.Error()),
 		)
-		return fmt.Errorf("applying authentication: %w", err)
+		return nil, fmt.Errorf("applying authentication: %w", err)
 	}
 
 	logger.Debug("sending http request",
@@ -1065,7 +1053,7 @@ func (h *HTTPRequestModule) executeHTTPRequest(ctx context.Context, endpoint str
 			slog.String("error", err.Error()),
 		)
 		// Classify network error for retry logic
-		return errhandling.ClassifyNetworkError(err)
+		return nil, errhandling.ClassifyNetworkError(err)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -1112,7 +1100,7 @@ func (h *HTTPRequestModule) executeHTTPRequest(ctx context.Context, endpoint str
 			ResponseBody:    string(respBody),
 			ResponseHeaders: resp.Header.Clone(), // Capture headers for Retry-After support
 		}
-		return classifiedErr
+		return nil, classifiedErr
 	}
 
 	logger.Debug("http request completed successfully",
@@ -1124,7 +1112,7 @@ func (h *HTTPRequestModule) executeHTTPRequest(ctx context.Context, endpoint str
 		slog.Int("response_size", len(respBody)),
 	)
 
-	return nil
+	return respBody, nil
 }
 
 // Note: calculateBackoff and isTransientError methods have been replaced by
@@ -1490,7 +1478,7 @@ func (h *HTTPRequestModule) isSuccessStatusCode(statusCode int) bool {
 // Close releases any resources held by the HTTP request module.
 func (h *HTTPRequestModule) Close() error {
 	// Close idle connections in the transport to ensure timely cleanup
-	if transport, ok := h.client.Transport.(*http.Transport); ok {
+	if transport, ok := h.client.Transport.(interface{ CloseIdleConnections() }); ok {
 		transport.CloseIdleConnections()
 	}
 	logger.Debug("http request output module closed",
@@ -1522,29 +1510,46 @@ func (h *HTTPRequestModule) PreviewRequest(records []map[string]interface{}, opt
 	return h.previewBatchMode(records, opts)
 }
 
-// previewBatchMode creates a single preview for all records (batch mode)
+// previewBatchMode creates one preview per batch request: a single preview
+// for all records unless batchSize or maxBodyBytes split them
 func (h *HTTPRequestModule) previewBatchMode(records []map[string]interface{}, opts PreviewOptions) ([]RequestPreview, error) {
 	// Resolve endpoint with static query parameters
 	endpoint := h.resolveEndpointWithStaticQuery(h.endpoint)
 
-	// Marshal records to formatted JSON
-	bodyPreview, err := formatJSONPreview(records)
-	if err != nil {
-		return nil, fmt.Errorf("formatting body preview: %w", err)
-	}
-
 	// Build headers (masked or unmasked based on options)
 	headers := h.buildPreviewHeaders(nil, opts)
 
-	preview := RequestPreview{
-		Endpoint:    endpoint,
-		Method:      h.method,
-		Headers:     headers,
-		BodyPreview: bodyPreview,
-		RecordCount: len(records),
+	var items [][]byte
+	if h.request.MaxBodyBytes > 0 && h.request.bodyTemplateRaw == "" {
+		items = make([][]byte, len(records))
+		for i, record := range stripMetadataFromRecords(records) {
+			item, err := json.Marshal(record)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrJSONMarshal, err)
+			}
+			items[i] = item
+		}
+	}
+
+	chunks := h.batchChunks(len(records), items)
+	previews := make([]RequestPreview, 0, len(chunks))
+	for _, c := range chunks {
+		// Marshal records to formatted JSON
+		bodyPreview, err := formatJSONPreview(records[c.start:c.end])
+		if err != nil {
+			return nil, fmt.Errorf("formatting body preview: %w", err)
+		}
+
+		previews = append(previews, RequestPreview{
+			Endpoint:    endpoint,
+			Method:      h.method,
+			Headers:     headers,
+			BodyPreview: bodyPreview,
+			RecordCount: c.end - c.start,
+		})
 	}
 
-	return []RequestPreview{preview}, nil
+	return previews, nil
 }
 
 // previewSingleRecordMode creates one preview per record
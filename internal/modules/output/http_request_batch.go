@@ -0,0 +1,228 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/cannectors/runtime/internal/errhandling"
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// defaultRequestConcurrency sends requests one at a time unless configured otherwise.
+const defaultRequestConcurrency = 1
+
+// Error types for request batching
+var (
+	ErrInvalidRequestBatching = errors.New("invalid request batching configuration")
+	ErrRequestBodyTooLarge    = errors.New("request body exceeds maxBodyBytes")
+)
+
+// outgoingRequest is one request of a Send: a chunk of records in batch mode,
+// a record in single record mode.
+type outgoingRequest struct {
+	first    int // index of the first record
+	records  []map[string]interface{}
+	endpoint string
+	body     []byte
+	headers  map[string]string
+	err      error // error building the request, which is then not sent
+}
+
+// recordChunk is the range of records [start, end) sent in one batch request.
+type recordChunk struct {
+	start, end int
+}
+
+// extractRequestBatching extracts batchSize, maxBodyBytes and concurrency
+// from the request sub-object.
+func extractRequestBatching(requestVal map[string]interface{}, reqConfig *RequestConfig) {
+	if v, ok := requestVal["batchSize"].(float64); ok {
+		reqConfig.BatchSize = int(v)
+	}
+	if v, ok := requestVal["maxBodyBytes"].(float64); ok {
+		reqConfig.MaxBodyBytes = int(v)
+	}
+	if v, ok := requestVal["concurrency"].(float64); ok {
+		reqConfig.Concurrency = int(v)
+	}
+}
+
+// validateRequestBatching validates the batching options of a request
+// configuration. batchSize splits batch mode bodies only.
+func validateRequestBatching(reqConfig RequestConfig) error {
+	if reqConfig.BatchSize < 0 {
+		return fmt.Errorf("%w: batchSize must be at least 1", ErrInvalidRequestBatching)
+	}
+	if reqConfig.BatchSize > 0 && reqConfig.BodyFrom == "record" {
+		return fmt.Errorf("%w: batchSize requires bodyFrom records", ErrInvalidRequestBatching)
+	}
+	if reqConfig.MaxBodyBytes < 0 {
+		return fmt.Errorf("%w: maxBodyBytes must be at least 1", ErrInvalidRequestBatching)
+	}
+	if reqConfig.Concurrency < 1 {
+		return fmt.Errorf("%w: concurrency must be at least 1", ErrInvalidRequestBatching)
+	}
+	return nil
+}
+
+// batchChunks splits n records into the chunks of batch requests, of at most
+// batchSize records. When items holds the JSON of each record, chunks are
+// also split so that their JSON array body fits maxBodyBytes; a record too
+// large on its own is a chunk of its own, rejected when its body is built.
+func (h *HTTPRequestModule) batchChunks(n int, items [][]byte) []recordChunk {
+	if n == 0 {
+		return nil
+	}
+	maxBytes := h.request.MaxBodyBytes
+	if items == nil {
+		maxBytes = 0
+	}
+
+	var chunks []recordChunk
+	start, size := 0, 2 // JSON array brackets
+	for i := 0; i < n; i++ {
+		count := i - start
+		itemSize := 0
+		if maxBytes > 0 {
+			itemSize = len(items[i])
+		}
+		if count > 0 && ((h.request.BatchSize > 0 && count >= h.request.BatchSize) ||
+			(maxBytes > 0 && size+1+itemSize > maxBytes)) {
+			chunks = append(chunks, recordChunk{start: start, end: i})
+			start, size, count = i, 2, 0
+		}
+		if count > 0 {
+			size++ // comma
+		}
+		size += itemSize
+	}
+	return append(chunks, recordChunk{start: start, end: n})
+}
+
+// batchRequest builds the request of a chunk of records. Its body is the JSON
+// array of the chunk's records, or the body template evaluated with the first
+// record of the chunk; endpoint and header templates use the first record too.
+func (h *HTTPRequestModule) batchRequest(records []map[string]interface{}, items [][]byte, c recordChunk) outgoingRequest {
+	chunk := records[c.start:c.end]
+	req := outgoingRequest{
+		first:    c.start,
+		records:  chunk,
+		endpoint: h.resolveEndpointForBatch(h.endpoint, chunk),
+		headers:  h.extractHeadersFromRecord(chunk[0]),
+	}
+
+	if items == nil {
+		// Template body: buildBodyForRecord only fails when marshaling
+		req.body, _ = h.buildBodyForRecord(chunk[0], c.start)
+	} else {
+		req.body = append(append([]byte{'['}, bytes.Join(items[c.start:c.end], []byte{','})...), ']')
+	}
+	req.err = h.checkBodySize(req.body, c.start, len(chunk))
+	return req
+}
+
+// checkBodySize returns ErrRequestBodyTooLarge if body exceeds maxBodyBytes.
+func (h *HTTPRequestModule) checkBodySize(body []byte, first, count int) error {
+	if h.request.MaxBodyBytes <= 0 || len(body) <= h.request.MaxBodyBytes {
+		return nil
+	}
+	logger.Error("request body too large, not sending",
+		slog.String("module_type", "httpRequest"),
+		slog.Int("record_index", first),
+		slog.Int("record_count", count),
+		slog.Int("body_size", len(body)),
+		slog.Int("max_body_bytes", h.request.MaxBodyBytes),
+		slog.String("on_error", string(h.onError)),
+	)
+	return fmt.Errorf("%w: %d bytes for records %d to %d (max %d)",
+		ErrRequestBodyTooLarge, len(body), first, first+count-1, h.request.MaxBodyBytes)
+}
+
+// sendRequests sends n requests built in order by build, with at most
+// concurrency requests in flight. Requests are built on the calling goroutine,
+// as template evaluation is not safe for concurrent use. Retry and rate
+// limiting apply per request.
+//
+// The records of a request that fails (or cannot be built), and those
+// rejected in its response (success.items), are recorded as failed. With
+// onError fail, the first failure ends the send: no further request is
+// started and the requests still in flight are canceled. Returns the number
+// of records sent, the number of failed records, and the first error.
+func (h *HTTPRequestModule) sendRequests(ctx context.Context, n int, build func(i int) outgoingRequest) (sent, failed int, err error) {
+	sendCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, h.request.Concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex // guards sent, failed and err
+	fail := func(records []map[string]interface{}, recordErr error) {
+		h.recordFailures(records, recordErr)
+		mu.Lock()
+		defer mu.Unlock()
+		failed += len(records)
+		if h.onError == errhandling.OnErrorFail && err == nil {
+			err = recordErr
+			cancel()
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		// With concurrency 1, the previous request has completed here
+		sem <- struct{}{}
+		if sendCtx.Err() != nil {
+			<-sem
+			break
+		}
+
+		req := build(i)
+		if req.err != nil {
+			<-sem
+			fail(req.records, req.err)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			respBody, reqErr := h.executeRequestAndLog(sendCtx, req.endpoint, req.body, req.headers, req.first, time.Now())
+			if reqErr != nil {
+				fail(req.records, reqErr)
+				return
+			}
+
+			rejected := h.rejectedItems(respBody, len(req.records))
+			for j := range req.records {
+				rejectErr, ok := rejected[j]
+				if !ok {
+					continue
+				}
+				logger.Error("record rejected by destination",
+					slog.String("module_type", "httpRequest"),
+					slog.Int("record_index", req.first+j),
+					slog.String("endpoint", req.endpoint),
+					slog.String("error", rejectErr.Error()),
+					slog.String("on_error", string(h.onError)),
+				)
+				fail(req.records[j:j+1], fmt.Errorf("record %d: %w", req.first+j, rejectErr))
+			}
+			mu.Lock()
+			sent += len(req.records) - len(rejected)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+	return sent, failed, err
+}
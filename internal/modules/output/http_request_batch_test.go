@@ -0,0 +1,351 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPRequest_Send_BatchSize(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"request":  map[string]interface{}{"batchSize": float64(2)},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	records := []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}, {"id": 4}, {"id": 5}}
+	sent, err := module.Send(context.Background(), records)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != 5 {
+		t.Errorf("sent = %d, want 5", sent)
+	}
+
+	requests := ts.getRequests()
+	wantSizes := []int{2, 2, 1}
+	if len(requests) != len(wantSizes) {
+		t.Fatalf("got %d requests, want %d", len(requests), len(wantSizes))
+	}
+	for i, req := range requests {
+		var body []map[string]interface{}
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			t.Fatalf("request %d body is not a JSON array: %v", i, err)
+		}
+		if len(body) != wantSizes[i] {
+			t.Errorf("request %d has %d records, want %d", i, len(body), wantSizes[i])
+		}
+	}
+}
+
+func TestHTTPRequest_Send_MaxBodyBytes(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	// Each record marshals to 11 bytes: {"id":"a0"}
+	records := make([]map[string]interface{}, 10)
+	for i := range records {
+		records[i] = map[string]interface{}{"id": string(rune('a'+i)) + "0"}
+	}
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"request":  map[string]interface{}{"maxBodyBytes": float64(40)},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	sent, err := module.Send(context.Background(), records)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != 10 {
+		t.Errorf("sent = %d, want 10", sent)
+	}
+
+	// [a,b,c] is 2 + 3*11 + 2 = 37 bytes: 3 records per request
+	total := 0
+	for i, req := range ts.getRequests() {
+		if len(req.Body) > 40 {
+			t.Errorf("request %d body is %d bytes, want at most 40", i, len(req.Body))
+		}
+		var body []map[string]interface{}
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			t.Fatalf("request %d body is not a JSON array: %v", i, err)
+		}
+		total += len(body)
+	}
+	if n := len(ts.getRequests()); n != 4 {
+		t.Errorf("got %d requests, want 4", n)
+	}
+	if total != 10 {
+		t.Errorf("requests hold %d records, want 10", total)
+	}
+}
+
+func TestHTTPRequest_Send_MaxBodyBytes_RecordTooLarge(t *testing.T) {
+	records := []map[string]interface{}{
+		{"id": "a"},
+		{"id": "b", "payload": "this record does not fit the body limit"},
+		{"id": "c"},
+	}
+
+	tests := []struct {
+		onError  string
+		wantSent int
+		wantErr  bool
+	}{
+		{onError: "fail", wantSent: 1, wantErr: true},
+		{onError: "skip", wantSent: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.onError, func(t *testing.T) {
+			ts := newTestServer()
+			defer ts.Close()
+
+			module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+				"endpoint": ts.URL + "/api/data",
+				"method":   "POST",
+				"onError":  tt.onError,
+				"request":  map[string]interface{}{"maxBodyBytes": float64(30)},
+			}))
+			if err != nil {
+				t.Fatalf("failed to create module: %v", err)
+			}
+
+			sent, err := module.Send(context.Background(), records)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrRequestBodyTooLarge) {
+				t.Errorf("Send() error = %v, want ErrRequestBodyTooLarge", err)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("sent = %d, want %d", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestHTTPRequest_Send_BatchSize_ChunkFailure(t *testing.T) {
+	ts := newTestServer()
+	ts.failAfter = 1
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"request":  map[string]interface{}{"batchSize": float64(2)},
+		"retry":    map[string]interface{}{"maxAttempts": float64(0)},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	records := []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}, {"id": 4}, {"id": 5}}
+	sent, err := module.Send(context.Background(), records)
+	if err == nil {
+		t.Fatal("Send() succeeded, want error of the second chunk")
+	}
+	if sent != 2 {
+		t.Errorf("sent = %d, want the 2 records of the first chunk", sent)
+	}
+	if n := len(ts.getRequests()); n != 2 {
+		t.Errorf("got %d requests, want 2 (no request after the failed chunk)", n)
+	}
+}
+
+func TestHTTPRequest_Send_BatchSize_RetryAfterPerChunk(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt of the second chunk is rate limited
+		if requests.Add(1) == 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"request":  map[string]interface{}{"batchSize": float64(2)},
+		"retry": map[string]interface{}{
+			"maxAttempts":         float64(2),
+			"delayMs":             float64(5000),
+			"useRetryAfterHeader": true,
+		},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	start := time.Now()
+	sent, err := module.Send(context.Background(), []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != 3 {
+		t.Errorf("sent = %d, want 3", sent)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send() took %v, want Retry-After 0 to override the 5s delay", elapsed)
+	}
+	if info := module.GetRetryInfo(); info == nil || info.RetryCount != 1 {
+		t.Errorf("GetRetryInfo() = %+v, want 1 retry", info)
+	}
+}
+
+func TestHTTPRequest_Send_Concurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	var mu sync.Mutex
+	failed := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		var record map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&record)
+		if record["id"] == float64(4) {
+			mu.Lock()
+			failed++
+			mu.Unlock()
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"onError":  "skip",
+		"request":  map[string]interface{}{"bodyFrom": "record", "concurrency": float64(3)},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	records := make([]map[string]interface{}, 9)
+	for i := range records {
+		records[i] = map[string]interface{}{"id": i}
+	}
+	sent, err := module.Send(context.Background(), records)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != 8 || failed != 1 {
+		t.Errorf("sent = %d, failed = %d, want 8 sent and 1 failed", sent, failed)
+	}
+	if m := maxInFlight.Load(); m < 2 || m > 3 {
+		t.Errorf("max requests in flight = %d, want 2 to 3", m)
+	}
+}
+
+func TestHTTPRequest_Send_Concurrency_OnErrorFail(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"onError":  "fail",
+		"request":  map[string]interface{}{"bodyFrom": "record", "concurrency": float64(2)},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	records := make([]map[string]interface{}, 20)
+	for i := range records {
+		records[i] = map[string]interface{}{"id": i}
+	}
+	sent, err := module.Send(context.Background(), records)
+	if err == nil {
+		t.Fatal("Send() succeeded, want error")
+	}
+	if n := int(requests.Load()); n >= len(records) || sent >= n {
+		t.Errorf("sent = %d of %d requests, want the send stopped at the failure", sent, n)
+	}
+}
+
+func TestValidateRequestBatching(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RequestConfig
+		wantErr bool
+	}{
+		{name: "defaults", config: RequestConfig{BodyFrom: "records", Concurrency: 1}},
+		{name: "batch", config: RequestConfig{BodyFrom: "records", BatchSize: 1000, MaxBodyBytes: 5 << 20, Concurrency: 4}},
+		{name: "single with max body", config: RequestConfig{BodyFrom: "record", MaxBodyBytes: 1024, Concurrency: 8}},
+		{name: "batchSize in single mode", config: RequestConfig{BodyFrom: "record", BatchSize: 10, Concurrency: 1}, wantErr: true},
+		{name: "negative batchSize", config: RequestConfig{BodyFrom: "records", BatchSize: -1, Concurrency: 1}, wantErr: true},
+		{name: "negative maxBodyBytes", config: RequestConfig{BodyFrom: "records", MaxBodyBytes: -1, Concurrency: 1}, wantErr: true},
+		{name: "zero concurrency", config: RequestConfig{BodyFrom: "records"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRequestBatching(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateRequestBatching() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRequestBatching) {
+				t.Errorf("validateRequestBatching() error = %v, want ErrInvalidRequestBatching", err)
+			}
+		})
+	}
+}
+
+func TestHTTPRequest_PreviewRequest_BatchSize(t *testing.T) {
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": "https://api.example.com/data",
+		"method":   "POST",
+		"request":  map[string]interface{}{"batchSize": float64(2)},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	previews, err := module.PreviewRequest([]map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}}, PreviewOptions{})
+	if err != nil {
+		t.Fatalf("PreviewRequest() error = %v", err)
+	}
+	if len(previews) != 2 || previews[0].RecordCount != 2 || previews[1].RecordCount != 1 {
+		t.Errorf("previews = %+v, want 2 requests of 2 and 1 records", previews)
+	}
+}
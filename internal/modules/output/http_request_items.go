@@ -0,0 +1,150 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/template"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// Error types for per-record results
+var (
+	ErrInvalidItemResults = errors.New("invalid success.items configuration")
+	ErrRecordsRejected    = errors.New("records rejected by destination")
+)
+
+// ItemResultsConfig reads per-record results from successful responses, for
+// APIs answering 207 Multi-Status or 200 with per-item errors.
+type ItemResultsConfig struct {
+	// Path is the dot path of the results array in the response body (empty:
+	// the body is the array). The i-th result is that of the i-th record of
+	// the request; a single object is the result of a single record.
+	Path string
+	// Expression is an expr expression evaluated per result, true when the
+	// record succeeded. It has access to "item" (the result) and "body".
+	Expression string
+	// ErrorPath is the dot path of the error message in a result (optional).
+	ErrorPath string
+	program   *vm.Program
+}
+
+// extractItemResultsConfig extracts and compiles success.items, or returns nil if not configured.
+func extractItemResultsConfig(config map[string]interface{}) (*ItemResultsConfig, error) {
+	successConfig, ok := config["success"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	items, ok := successConfig["items"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	c := &ItemResultsConfig{}
+	c.Path, _ = items["path"].(string)
+	c.Expression, _ = items["expression"].(string)
+	c.ErrorPath, _ = items["errorPath"].(string)
+	if c.Expression == "" {
+		return nil, fmt.Errorf("%w: expression is required", ErrInvalidItemResults)
+	}
+	if len(c.Expression) > MaxRetryHintExpressionLength {
+		return nil, fmt.Errorf("%w: expression length %d exceeds maximum %d", ErrInvalidItemResults, len(c.Expression), MaxRetryHintExpressionLength)
+	}
+	program, err := expr.Compile(c.Expression, expr.AllowUndefinedVariables(), expr.AsBool())
+	if err != nil {
+		return nil, fmt.Errorf("%w: compiling expression: %w", ErrInvalidItemResults, err)
+	}
+	c.program = program
+	return c, nil
+}
+
+// rejectedItems returns the errors of the records of a request rejected in
+// its response body, by index in the request. Responses whose results cannot
+// be read are logged and trusted: their status code was a success.
+func (h *HTTPRequestModule) rejectedItems(respBody []byte, count int) map[int]error {
+	if h.itemResults == nil {
+		return nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(respBody, &body); err != nil {
+		logger.Warn("failed to parse response body for item results, assuming all records succeeded",
+			slog.String("module_type", "httpRequest"),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+
+	results := body
+	if h.itemResults.Path != "" {
+		obj, _ := body.(map[string]interface{})
+		results, _ = template.GetNestedValue(obj, h.itemResults.Path)
+	}
+	items, ok := results.([]interface{})
+	if result, isObject := results.(map[string]interface{}); isObject && count == 1 {
+		items, ok = []interface{}{result}, true
+	}
+	if !ok {
+		logger.Warn("item results not found in response body, assuming all records succeeded",
+			slog.String("module_type", "httpRequest"),
+			slog.String("path", h.itemResults.Path),
+		)
+		return nil
+	}
+	if len(items) != count {
+		logger.Warn("item results do not match the records of the request",
+			slog.String("module_type", "httpRequest"),
+			slog.Int("results", len(items)),
+			slog.Int("records", count),
+		)
+	}
+
+	rejected := make(map[int]error)
+	for i := 0; i < len(items) && i < count; i++ {
+		result, err := expr.Run(h.itemResults.program, map[string]interface{}{"item": items[i], "body": body})
+		if err != nil {
+			rejected[i] = fmt.Errorf("evaluating success.items expression: %w", err)
+			continue
+		}
+		if ok, _ := result.(bool); ok {
+			continue
+		}
+		rejected[i] = h.itemError(items[i])
+	}
+	return rejected
+}
+
+// itemError returns the error of a rejected record, with the message at errorPath if any.
+func (h *HTTPRequestModule) itemError(item interface{}) error {
+	if obj, ok := item.(map[string]interface{}); ok && h.itemResults.ErrorPath != "" {
+		if message, found := template.GetNestedValue(obj, h.itemResults.ErrorPath); found && message != nil {
+			return fmt.Errorf("%w: %v", ErrRecordsRejected, message)
+		}
+	}
+	return ErrRecordsRejected
+}
+
+// FailedRecords returns the records the last Send failed to send or that the
+// destination rejected (RecordFailureProvider). With onError fail, records
+// after the failure are not sent and not included.
+func (h *HTTPRequestModule) FailedRecords() []connector.RecordFailure {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failed
+}
+
+// recordFailures records the failure of records; safe for concurrent requests.
+func (h *HTTPRequestModule) recordFailures(records []map[string]interface{}, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, record := range records {
+		h.failed = append(h.failed, connector.RecordFailure{Record: record, Error: err.Error()})
+	}
+}
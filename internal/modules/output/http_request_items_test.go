@@ -0,0 +1,241 @@
+// Package output provides implementations for output modules.
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPRequest_FailedRecords_SingleRecordMode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&record)
+		if record["id"] == float64(2) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"onError":  "skip",
+		"request":  map[string]interface{}{"bodyFrom": "record"},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	records := []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}}
+	sent, err := module.Send(context.Background(), records)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+	failed := module.FailedRecords()
+	if len(failed) != 1 || failed[0].Record["id"] != 2 || !strings.Contains(failed[0].Error, "422") {
+		t.Errorf("FailedRecords() = %+v, want record 2 with its 422 error", failed)
+	}
+
+	// Failures are reset by each Send
+	if _, err := module.Send(context.Background(), records[:1]); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if failed := module.FailedRecords(); len(failed) != 0 {
+		t.Errorf("FailedRecords() = %+v after a successful Send, want none", failed)
+	}
+}
+
+func TestHTTPRequest_FailedRecords_BatchChunk(t *testing.T) {
+	ts := newTestServer()
+	ts.failAfter = 1
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"onError":  "log",
+		"request":  map[string]interface{}{"batchSize": float64(2)},
+		"retry":    map[string]interface{}{"maxAttempts": float64(0)},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	records := []map[string]interface{}{{"id": 1}, {"id": 2}, {"id": 3}}
+	sent, err := module.Send(context.Background(), records)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+	if failed := module.FailedRecords(); len(failed) != 1 || failed[0].Record["id"] != 3 {
+		t.Errorf("FailedRecords() = %+v, want record 3 of the failed chunk", failed)
+	}
+}
+
+func TestHTTPRequest_ItemResults(t *testing.T) {
+	// A 207 answer with one result per record, in request order
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var records []map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&records)
+		results := make([]map[string]interface{}, len(records))
+		for i, record := range records {
+			results[i] = map[string]interface{}{"status": 201}
+			if record["sku"] == "" {
+				results[i] = map[string]interface{}{"status": 400, "error": map[string]interface{}{"message": "sku is required"}}
+			}
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"results": results}})
+	}))
+	defer ts.Close()
+
+	records := []map[string]interface{}{{"sku": "A"}, {"sku": ""}, {"sku": "C"}, {"sku": ""}}
+
+	tests := []struct {
+		onError  string
+		wantSent int
+		wantErr  bool
+	}{
+		{onError: "skip", wantSent: 2},
+		{onError: "fail", wantSent: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.onError, func(t *testing.T) {
+			module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+				"endpoint": ts.URL + "/api/products",
+				"method":   "POST",
+				"onError":  tt.onError,
+				"success": map[string]interface{}{
+					"statusCodes": []interface{}{float64(200), float64(207)},
+					"items": map[string]interface{}{
+						"path":       "data.results",
+						"expression": "item.status < 300",
+						"errorPath":  "error.message",
+					},
+				},
+			}))
+			if err != nil {
+				t.Fatalf("failed to create module: %v", err)
+			}
+
+			sent, err := module.Send(context.Background(), records)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrRecordsRejected) {
+				t.Errorf("Send() error = %v, want ErrRecordsRejected", err)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("sent = %d, want %d", sent, tt.wantSent)
+			}
+
+			failed := module.FailedRecords()
+			if len(failed) != 2 {
+				t.Fatalf("FailedRecords() = %+v, want records 1 and 3", failed)
+			}
+			for _, f := range failed {
+				if f.Record["sku"] != "" || !strings.Contains(f.Error, "sku is required") {
+					t.Errorf("failure = %+v, want empty sku with its error message", f)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPRequest_ItemResults_SingleRecordObject(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok": false, "reason": "quota exceeded"}`))
+	}))
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"onError":  "skip",
+		"request":  map[string]interface{}{"bodyFrom": "record"},
+		"success": map[string]interface{}{
+			"items": map[string]interface{}{"expression": "item.ok == true", "errorPath": "reason"},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	sent, err := module.Send(context.Background(), []map[string]interface{}{{"id": 1}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != 0 {
+		t.Errorf("sent = %d, want 0", sent)
+	}
+	if failed := module.FailedRecords(); len(failed) != 1 || !strings.Contains(failed[0].Error, "quota exceeded") {
+		t.Errorf("FailedRecords() = %+v, want the rejected record", failed)
+	}
+}
+
+func TestHTTPRequest_ItemResults_UnreadableResponse(t *testing.T) {
+	ts := newTestServer()
+	ts.responseBody = `not json`
+	defer ts.Close()
+
+	module, err := NewHTTPRequestFromConfig(newModuleConfig(map[string]interface{}{
+		"endpoint": ts.URL + "/api/data",
+		"method":   "POST",
+		"success": map[string]interface{}{
+			"items": map[string]interface{}{"path": "results", "expression": "item.ok"},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("failed to create module: %v", err)
+	}
+
+	// The status code was a success: records are not reported failed
+	sent, err := module.Send(context.Background(), []map[string]interface{}{{"id": 1}, {"id": 2}})
+	if err != nil || sent != 2 {
+		t.Errorf("Send() = %d, %v, want 2 sent", sent, err)
+	}
+}
+
+func TestExtractItemResultsConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "not configured", config: map[string]interface{}{}, wantNil: true},
+		{name: "status codes only", config: map[string]interface{}{"success": map[string]interface{}{"statusCodes": []interface{}{float64(200)}}}, wantNil: true},
+		{name: "valid", config: map[string]interface{}{"success": map[string]interface{}{"items": map[string]interface{}{"path": "results", "expression": "item.status < 300"}}}},
+		{name: "missing expression", config: map[string]interface{}{"success": map[string]interface{}{"items": map[string]interface{}{"path": "results"}}}, wantErr: true},
+		{name: "invalid expression", config: map[string]interface{}{"success": map[string]interface{}{"items": map[string]interface{}{"expression": "item.status <"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := extractItemResultsConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractItemResultsConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidItemResults) {
+				t.Errorf("extractItemResultsConfig() error = %v, want ErrInvalidItemResults", err)
+			}
+			if !tt.wantErr && (c == nil) != tt.wantNil {
+				t.Errorf("extractItemResultsConfig() = %+v, wantNil %v", c, tt.wantNil)
+			}
+		})
+	}
+}
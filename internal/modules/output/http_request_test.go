@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/cannectors/runtime/internal/errhandling"
+	"github.com/cannectors/runtime/internal/ratelimit"
 	"github.com/cannectors/runtime/pkg/connector"
 )
 
@@ -141,6 +142,30 @@ func TestNewHTTPRequestFromConfig_InvalidMethod(t *testing.T) {
 	}
 }
 
+func TestNewHTTPRequestFromConfig_RateLimit(t *testing.T) {
+	config := newModuleConfig(map[string]interface{}{
+		"endpoint":  "https://api.example.com/data",
+		"method":    "POST",
+		"rateLimit": map[string]interface{}{"requestsPerSecond": float64(10)},
+	})
+
+	module, err := NewHTTPRequestFromConfig(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := module.client.Transport.(*ratelimit.Transport); !ok {
+		t.Errorf("expected rate limited transport, got %T", module.client.Transport)
+	}
+	if err := module.Close(); err != nil {
+		t.Errorf("Close() returned error: %v", err)
+	}
+
+	config.Config["rateLimit"] = map[string]interface{}{"requestsPerSecond": float64(-1)}
+	if _, err := NewHTTPRequestFromConfig(config); err == nil {
+		t.Fatal("expected error for invalid rateLimit")
+	}
+}
+
 func TestNewHTTPRequestFromConfig_RetryHintFromBody_TooLong(t *testing.T) {
 	// Create an expression that exceeds MaxRetryHintExpressionLength
 	longExpression := strings.Repeat("body.field == true && ", 1000) + "body.field == true"
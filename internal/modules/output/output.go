@@ -156,23 +156,25 @@ type Module interface {
 	Close() error
 }
 
-// RequestPreview contains the preview of an HTTP request that would be sent.
+// RequestPreview contains the preview of a request that would be sent.
 // Used in dry-run mode to show what would be sent without actually sending.
 //
-// NOTE: This type is HTTP-specific by design. It is used by HTTP-based output modules
-// (httpRequest, REST API clients, webhooks). Non-HTTP output modules (databases, message queues,
-// file systems) are not required to implement PreviewableModule.
+// HTTP-based output modules (httpRequest, REST API clients, webhooks) fill in
+// Endpoint, Method, Headers and BodyPreview. SQL-based output modules describe
+// the statement in SQL, with the operation as Method and the target table as
+// Endpoint.
 //
-// For non-HTTP output modules that want to support dry-run mode:
-//   - Either map your protocol to HTTP-like semantics (e.g., "INSERT" as Method, table name as Endpoint)
+// For other output modules that want to support dry-run mode:
+//   - Either map your protocol to these semantics (e.g., topic as Endpoint)
 //   - Or define a custom preview type in your module and don't implement PreviewableModule
 //
-// The core Module interface remains protocol-agnostic - only PreviewableModule is HTTP-specific.
+// The core Module interface remains protocol-agnostic.
 type RequestPreview struct {
-	// Endpoint is the resolved URL including path parameters and query params
+	// Endpoint is the resolved URL including path parameters and query params,
+	// or the target table of a SQL statement
 	Endpoint string `json:"endpoint"`
 
-	// Method is the HTTP method (POST, PUT, PATCH)
+	// Method is the HTTP method (POST, PUT, PATCH), or the SQL operation
 	Method string `json:"method"`
 
 	// Headers contains all request headers (auth headers may be masked)
@@ -183,6 +185,33 @@ type RequestPreview struct {
 
 	// RecordCount is the number of records included in this request
 	RecordCount int `json:"recordCount"`
+
+	// SQL describes the statement executed for SQL-based output modules
+	SQL *SQLPreview `json:"sql,omitempty"`
+}
+
+// SQLPreview describes a SQL statement that would be executed.
+type SQLPreview struct {
+	// Statement is the rendered statement, with the driver's placeholders
+	Statement string `json:"statement"`
+
+	// Parameters are the values bound to the placeholders, in order.
+	// Values of sensitive fields (passwords, tokens, ...) are masked unless
+	// ShowCredentials is set.
+	Parameters []interface{} `json:"parameters"`
+
+	// Table is the target table, if known
+	Table string `json:"table,omitempty"`
+
+	// Operation is the kind of statement (insert, upsert, update, delete, ...)
+	Operation string `json:"operation"`
+
+	// Executed is set when the statement was executed in a transaction that
+	// was rolled back, to validate it against the database
+	Executed bool `json:"executed,omitempty"`
+
+	// Error is the database error of the executed statement, if any
+	Error string `json:"error,omitempty"`
 }
 
 // PreviewOptions configures preview generation behavior.
@@ -205,7 +234,7 @@ type PreviewOptions struct {
 //
 // This is an optional extension interface that modules can implement to support
 // dry-run mode. Modules that implement PreviewableModule can show what requests
-// would be sent without actually sending them.
+// or statements would be sent without actually sending them.
 //
 // # Interface Composition
 //
@@ -218,6 +247,7 @@ type PreviewOptions struct {
 //
 // Implement PreviewableModule if your output module:
 //   - Sends HTTP requests (REST APIs, webhooks)
+//   - Executes SQL statements
 //   - Makes network calls that can be previewed
 //   - Would benefit from showing users what would be sent before actual execution
 //
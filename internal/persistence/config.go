@@ -1,6 +1,8 @@
 // Package persistence provides state persistence for pipeline execution.
 package persistence
 
+import "time"
+
 // StatePersistenceConfig holds the configuration for state persistence.
 // Parsed from module configuration's "statePersistence" field.
 type StatePersistenceConfig struct {
@@ -10,6 +12,12 @@ type StatePersistenceConfig struct {
 	// ID persistence configuration
 	ID *IDConfig `json:"id,omitempty"`
 
+	// Conditional requests configuration (ETag / Last-Modified)
+	Conditional *ConditionalConfig `json:"conditional,omitempty"`
+
+	// Composite cursor persistence configuration
+	Cursor *CursorConfig `json:"cursor,omitempty"`
+
 	// StoragePath is the custom storage directory path.
 	// Defaults to DefaultStatePath if empty.
 	StoragePath string `json:"storagePath,omitempty"`
@@ -23,6 +31,30 @@ type TimestampConfig struct {
 	// QueryParam is the query parameter name for API filtering.
 	// If set, adds ?{QueryParam}={timestamp} to requests.
 	QueryParam string `json:"queryParam,omitempty"`
+
+	// Variable is the GraphQL variable name for API filtering.
+	// If set, the graphql input sets variables[{Variable}]={timestamp}.
+	Variable string `json:"variable,omitempty"`
+
+	// BodyField is the request body field (dot notation) for API filtering.
+	// If set, POST polling requests set {BodyField}={timestamp} in the JSON body.
+	BodyField string `json:"bodyField,omitempty"`
+
+	// UntilQueryParam and UntilBodyField receive the end of the time window
+	// of backfill executions, the start being sent in
+	// QueryParam or BodyField.
+	UntilQueryParam string `json:"untilQueryParam,omitempty"`
+	UntilBodyField  string `json:"untilBodyField,omitempty"`
+
+	// Field is the record field (dot notation) holding the record timestamp.
+	// If set, the persisted timestamp is the maximum value of this field in
+	// the raw records (high-watermark) instead of the execution start time.
+	Field string `json:"field,omitempty"`
+
+	// LookbackSeconds is subtracted from the persisted timestamp when it is
+	// used for filtering, so that records committed late are read again.
+	// Combine with a dedupe filter to drop the overlap.
+	LookbackSeconds int `json:"lookbackSeconds,omitempty"`
 }
 
 // IDConfig holds ID persistence configuration.
@@ -37,6 +69,31 @@ type IDConfig struct {
 	// QueryParam is the query parameter name for API filtering.
 	// If set, adds ?{QueryParam}={lastId} to requests.
 	QueryParam string `json:"queryParam,omitempty"`
+
+	// Variable is the GraphQL variable name for API filtering.
+	// If set, the graphql input sets variables[{Variable}]={lastId}.
+	Variable string `json:"variable,omitempty"`
+
+	// BodyField is the request body field (dot notation) for API filtering.
+	// If set, POST polling requests set {BodyField}={lastId} in the JSON body.
+	BodyField string `json:"bodyField,omitempty"`
+}
+
+// ConditionalConfig holds conditional request configuration.
+// When enabled, the ETag and Last-Modified response headers are persisted and
+// sent back as If-None-Match / If-Modified-Since on the next execution.
+type ConditionalConfig struct {
+	// Enabled enables conditional requests.
+	Enabled bool `json:"enabled"`
+}
+
+// CursorConfig holds composite cursor persistence configuration.
+type CursorConfig struct {
+	// Enabled enables cursor persistence.
+	Enabled bool `json:"enabled"`
+
+	// Fields are the record fields making up the cursor, in order.
+	Fields []string `json:"fields,omitempty"`
 }
 
 // IsEnabled returns true if any persistence is enabled.
@@ -45,7 +102,9 @@ func (c *StatePersistenceConfig) IsEnabled() bool {
 		return false
 	}
 	return (c.Timestamp != nil && c.Timestamp.Enabled) ||
-		(c.ID != nil && c.ID.Enabled)
+		(c.ID != nil && c.ID.Enabled) ||
+		(c.Conditional != nil && c.Conditional.Enabled) ||
+		(c.Cursor != nil && c.Cursor.Enabled)
 }
 
 // TimestampEnabled returns true if timestamp persistence is enabled.
@@ -53,11 +112,51 @@ func (c *StatePersistenceConfig) TimestampEnabled() bool {
 	return c != nil && c.Timestamp != nil && c.Timestamp.Enabled
 }
 
+// WatermarkEnabled returns true if the persisted timestamp is the
+// high-watermark of a record field.
+func (c *StatePersistenceConfig) WatermarkEnabled() bool {
+	return c.TimestampEnabled() && c.Timestamp.Field != ""
+}
+
+// TimestampSince returns the timestamp to filter records from: the persisted
+// timestamp minus the configured lookback. Returns nil if the state has no
+// timestamp.
+func (c *StatePersistenceConfig) TimestampSince(state *State) *time.Time {
+	if state == nil || state.LastTimestamp == nil {
+		return nil
+	}
+	since := *state.LastTimestamp
+	if c != nil && c.Timestamp != nil && c.Timestamp.LookbackSeconds > 0 {
+		since = since.Add(-time.Duration(c.Timestamp.LookbackSeconds) * time.Second)
+	}
+	return &since
+}
+
+// FormatTimestampSince returns TimestampSince formatted as RFC3339 (ISO 8601).
+// Returns empty string if the state has no timestamp.
+func (c *StatePersistenceConfig) FormatTimestampSince(state *State) string {
+	since := c.TimestampSince(state)
+	if since == nil {
+		return ""
+	}
+	return since.Format(time.RFC3339)
+}
+
 // IDEnabled returns true if ID persistence is enabled.
 func (c *StatePersistenceConfig) IDEnabled() bool {
 	return c != nil && c.ID != nil && c.ID.Enabled
 }
 
+// ConditionalEnabled returns true if conditional requests are enabled.
+func (c *StatePersistenceConfig) ConditionalEnabled() bool {
+	return c != nil && c.Conditional != nil && c.Conditional.Enabled
+}
+
+// CursorEnabled returns true if composite cursor persistence is enabled.
+func (c *StatePersistenceConfig) CursorEnabled() bool {
+	return c != nil && c.Cursor != nil && c.Cursor.Enabled
+}
+
 // ParseStatePersistenceConfig parses state persistence configuration from a map.
 // Returns nil if the map is nil or empty.
 func ParseStatePersistenceConfig(config map[string]interface{}) *StatePersistenceConfig {
@@ -81,6 +180,24 @@ func ParseStatePersistenceConfig(config map[string]interface{}) *StatePersistenc
 		if queryParam, ok := tsConfig["queryParam"].(string); ok {
 			result.Timestamp.QueryParam = queryParam
 		}
+		if variable, ok := tsConfig["variable"].(string); ok {
+			result.Timestamp.Variable = variable
+		}
+		if bodyField, ok := tsConfig["bodyField"].(string); ok {
+			result.Timestamp.BodyField = bodyField
+		}
+		if untilQueryParam, ok := tsConfig["untilQueryParam"].(string); ok {
+			result.Timestamp.UntilQueryParam = untilQueryParam
+		}
+		if untilBodyField, ok := tsConfig["untilBodyField"].(string); ok {
+			result.Timestamp.UntilBodyField = untilBodyField
+		}
+		if field, ok := tsConfig["field"].(string); ok {
+			result.Timestamp.Field = field
+		}
+		if lookback, ok := tsConfig["lookbackSeconds"].(float64); ok && lookback > 0 {
+			result.Timestamp.LookbackSeconds = int(lookback)
+		}
 	}
 
 	// Parse ID config
@@ -95,6 +212,20 @@ func ParseStatePersistenceConfig(config map[string]interface{}) *StatePersistenc
 		if queryParam, ok := idConfig["queryParam"].(string); ok {
 			result.ID.QueryParam = queryParam
 		}
+		if variable, ok := idConfig["variable"].(string); ok {
+			result.ID.Variable = variable
+		}
+		if bodyField, ok := idConfig["bodyField"].(string); ok {
+			result.ID.BodyField = bodyField
+		}
+	}
+
+	// Parse conditional requests config
+	if condConfig, ok := spConfig["conditional"].(map[string]interface{}); ok {
+		result.Conditional = &ConditionalConfig{}
+		if enabled, ok := condConfig["enabled"].(bool); ok {
+			result.Conditional.Enabled = enabled
+		}
 	}
 
 	// Parse storage path
@@ -3,6 +3,7 @@ package persistence
 
 import (
 	"testing"
+	"time"
 )
 
 func TestParseStatePersistenceConfig_Nil(t *testing.T) {
@@ -55,6 +56,51 @@ func TestParseStatePersistenceConfig_TimestampOnly(t *testing.T) {
 	}
 }
 
+func TestParseStatePersistenceConfig_Watermark(t *testing.T) {
+	config := map[string]interface{}{
+		"statePersistence": map[string]interface{}{
+			"timestamp": map[string]interface{}{
+				"enabled":         true,
+				"queryParam":      "since",
+				"field":           "updated_at",
+				"lookbackSeconds": float64(300),
+			},
+		},
+	}
+
+	result := ParseStatePersistenceConfig(config)
+	if !result.WatermarkEnabled() {
+		t.Fatal("WatermarkEnabled() = false, want true")
+	}
+	if result.Timestamp.Field != "updated_at" {
+		t.Errorf("Timestamp.Field = %q, want %q", result.Timestamp.Field, "updated_at")
+	}
+	if result.Timestamp.LookbackSeconds != 300 {
+		t.Errorf("Timestamp.LookbackSeconds = %d, want 300", result.Timestamp.LookbackSeconds)
+	}
+}
+
+func TestStatePersistenceConfig_TimestampSince(t *testing.T) {
+	watermark := time.Date(2026, 1, 26, 10, 30, 0, 0, time.UTC)
+	state := &State{LastTimestamp: &watermark}
+
+	config := &StatePersistenceConfig{Timestamp: &TimestampConfig{Enabled: true, LookbackSeconds: 300}}
+	if got := config.FormatTimestampSince(state); got != "2026-01-26T10:25:00Z" {
+		t.Errorf("FormatTimestampSince() = %q, want 2026-01-26T10:25:00Z", got)
+	}
+	if !state.LastTimestamp.Equal(watermark) {
+		t.Error("TimestampSince() must not modify the state")
+	}
+
+	var noConfig *StatePersistenceConfig
+	if got := noConfig.TimestampSince(state); got == nil || !got.Equal(watermark) {
+		t.Errorf("TimestampSince() without config = %v, want %v", got, watermark)
+	}
+	if got := config.TimestampSince(&State{}); got != nil {
+		t.Errorf("TimestampSince() without timestamp = %v, want nil", got)
+	}
+}
+
 func TestParseStatePersistenceConfig_IDOnly(t *testing.T) {
 	config := map[string]interface{}{
 		"statePersistence": map[string]interface{}{
@@ -120,6 +166,66 @@ func TestParseStatePersistenceConfig_Both(t *testing.T) {
 	}
 }
 
+func TestParseStatePersistenceConfig_VariablesAndBodyFields(t *testing.T) {
+	config := map[string]interface{}{
+		"statePersistence": map[string]interface{}{
+			"timestamp": map[string]interface{}{
+				"enabled":   true,
+				"variable":  "updatedAfter",
+				"bodyField": "query.updatedSince",
+			},
+			"id": map[string]interface{}{
+				"enabled":   true,
+				"field":     "id",
+				"variable":  "afterId",
+				"bodyField": "afterId",
+			},
+		},
+	}
+
+	result := ParseStatePersistenceConfig(config)
+	if result == nil {
+		t.Fatal("ParseStatePersistenceConfig returned nil")
+	}
+
+	if result.Timestamp.Variable != "updatedAfter" {
+		t.Errorf("Timestamp.Variable = %q, want %q", result.Timestamp.Variable, "updatedAfter")
+	}
+	if result.ID.Variable != "afterId" {
+		t.Errorf("ID.Variable = %q, want %q", result.ID.Variable, "afterId")
+	}
+	if result.Timestamp.BodyField != "query.updatedSince" {
+		t.Errorf("Timestamp.BodyField = %q, want %q", result.Timestamp.BodyField, "query.updatedSince")
+	}
+	if result.ID.BodyField != "afterId" {
+		t.Errorf("ID.BodyField = %q, want %q", result.ID.BodyField, "afterId")
+	}
+	if result.Timestamp.QueryParam != "" {
+		t.Errorf("Timestamp.QueryParam = %q, want empty", result.Timestamp.QueryParam)
+	}
+}
+
+func TestParseStatePersistenceConfig_Conditional(t *testing.T) {
+	config := map[string]interface{}{
+		"statePersistence": map[string]interface{}{
+			"conditional": map[string]interface{}{
+				"enabled": true,
+			},
+		},
+	}
+
+	result := ParseStatePersistenceConfig(config)
+	if result == nil {
+		t.Fatal("ParseStatePersistenceConfig returned nil")
+	}
+	if !result.ConditionalEnabled() {
+		t.Error("ConditionalEnabled() = false, want true")
+	}
+	if result.TimestampEnabled() || result.IDEnabled() {
+		t.Error("Timestamp and ID should not be enabled")
+	}
+}
+
 func TestStatePersistenceConfig_IsEnabled(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -132,6 +238,7 @@ func TestStatePersistenceConfig_IsEnabled(t *testing.T) {
 		{"timestamp disabled", &StatePersistenceConfig{Timestamp: &TimestampConfig{Enabled: false}}, false},
 		{"id enabled", &StatePersistenceConfig{ID: &IDConfig{Enabled: true}}, true},
 		{"id disabled", &StatePersistenceConfig{ID: &IDConfig{Enabled: false}}, false},
+		{"conditional enabled", &StatePersistenceConfig{Conditional: &ConditionalConfig{Enabled: true}}, true},
 		{"both enabled", &StatePersistenceConfig{
 			Timestamp: &TimestampConfig{Enabled: true},
 			ID:        &IDConfig{Enabled: true},
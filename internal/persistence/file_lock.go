@@ -0,0 +1,191 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The file backend locks a pipeline with a lock file holding its LockHolder,
+// in the locks subdirectory. Lock files are written to a temp file first and
+// then linked (creation) or renamed (renewal) into place, so that they are
+// never read partially written. Taking over an expired lock is guarded by a
+// takeover file, so that a single run takes it over.
+
+// lockPath returns the full path for a pipeline's lock file.
+func (s *FileStateStore) lockPath(pipelineID string) string {
+	return filepath.Join(s.basePath, "locks", filepath.Base(pipelineID)+".lock")
+}
+
+// TryLock acquires, renews or takes over the lock file of a pipeline.
+func (s *FileStateStore) TryLock(pipelineID, owner string, lease time.Duration) (*LockHolder, error) {
+	if pipelineID == "" {
+		return nil, ErrInvalidPipelineID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.lockPath(pipelineID)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+	mine := &LockHolder{Owner: owner, ExpiresAt: time.Now().Add(lease)}
+
+	created, err := createLockFile(path, mine)
+	if err != nil || created {
+		return nil, err
+	}
+
+	holder, err := readLockFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Released in the meantime: retry once, another run may win the race
+		if created, err := createLockFile(path, mine); err != nil || created {
+			return nil, err
+		}
+		holder, err = readLockFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case holder.Owner == owner:
+		return nil, replaceLockFile(path, mine)
+	case time.Now().Before(holder.ExpiresAt):
+		return holder, nil
+	default:
+		return s.takeOverLock(path, holder, mine)
+	}
+}
+
+// takeOverLock replaces an expired lock file, unless another run takes it
+// over first.
+func (s *FileStateStore) takeOverLock(path string, expired, mine *LockHolder) (*LockHolder, error) {
+	guard := path + ".takeover"
+	f, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("creating lock takeover file: %w", err)
+		}
+		// Another run is taking over; a guard left by a crash is removed
+		// for the next attempt
+		if info, statErr := os.Stat(guard); statErr == nil && time.Since(info.ModTime()) > minLockLease {
+			_ = os.Remove(guard)
+		}
+		return expired, nil
+	}
+	_ = f.Close()
+	defer func() {
+		_ = os.Remove(guard)
+	}()
+
+	// The lock may have been renewed or taken over since it was read
+	current, err := readLockFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if current != nil && (current.Owner != expired.Owner || !current.ExpiresAt.Equal(expired.ExpiresAt)) {
+		return current, nil
+	}
+	return nil, replaceLockFile(path, mine)
+}
+
+// Unlock removes the lock file of a pipeline if owner holds it.
+func (s *FileStateStore) Unlock(pipelineID, owner string) error {
+	if pipelineID == "" {
+		return ErrInvalidPipelineID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.lockPath(pipelineID)
+	holder, err := readLockFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if holder.Owner != owner {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing lock file: %w", err)
+	}
+	return nil
+}
+
+// writeLockTemp writes a LockHolder to a new temp file next to path.
+func writeLockTemp(path string, holder *LockHolder) (string, error) {
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return "", fmt.Errorf("marshaling lock: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("writing lock file: %w", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("writing lock file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// createLockFile creates the lock file if it does not exist.
+// Returns false if it exists.
+func createLockFile(path string, holder *LockHolder) (bool, error) {
+	tempPath, err := writeLockTemp(path, holder)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = os.Remove(tempPath)
+	}()
+
+	if err := os.Link(tempPath, path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("creating lock file: %w", err)
+	}
+	return true, nil
+}
+
+// replaceLockFile atomically replaces the lock file.
+func replaceLockFile(path string, holder *LockHolder) error {
+	tempPath, err := writeLockTemp(path, holder)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("replacing lock file: %w", err)
+	}
+	return nil
+}
+
+// readLockFile reads the holder of a lock file.
+func readLockFile(path string) (*LockHolder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("reading lock file: %w", err)
+	}
+	var holder LockHolder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil, fmt.Errorf("unmarshaling lock file %s: %w", path, err)
+	}
+	return &holder, nil
+}
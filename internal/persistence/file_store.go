@@ -0,0 +1,323 @@
+// Package persistence provides state persistence for pipeline execution.
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// FileStateStore is the file backend of StateStore.
+// The state of each pipeline is stored as a JSON file in the configured base path,
+// and its history as a JSON array in the history subdirectory.
+type FileStateStore struct {
+	basePath string
+	mu       sync.RWMutex
+}
+
+// NewFileStateStore creates a new FileStateStore with the specified base path.
+// If basePath is empty, DefaultStatePath is used.
+func NewFileStateStore(basePath string) *FileStateStore {
+	if basePath == "" {
+		basePath = DefaultStatePath
+	}
+	return &FileStateStore{
+		basePath: basePath,
+	}
+}
+
+// filePath returns the full path for a pipeline's state file.
+func (s *FileStateStore) filePath(pipelineID string) string {
+	// Sanitize pipeline ID to prevent directory traversal
+	safeName := filepath.Base(pipelineID)
+	return filepath.Join(s.basePath, safeName+".json")
+}
+
+// historyPath returns the full path for a pipeline's history file.
+func (s *FileStateStore) historyPath(pipelineID string) string {
+	return filepath.Join(s.basePath, "history", filepath.Base(pipelineID)+".json")
+}
+
+// pushHistory prepends the current state of a pipeline, if any, to its
+// history. Checkpointed states are not kept.
+// The caller must hold the write lock.
+func (s *FileStateStore) pushHistory(pipelineID string) error {
+	current, err := s.load(pipelineID)
+	if err != nil || current == nil || current.Checkpoint != nil {
+		return err
+	}
+	history, err := s.history(pipelineID)
+	if err != nil {
+		return err
+	}
+	history = append([]*State{current}, history...)
+	if len(history) > HistorySize {
+		history = history[:HistorySize]
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling state history: %w", err)
+	}
+	historyPath := s.historyPath(pipelineID)
+	if err := os.MkdirAll(filepath.Dir(historyPath), 0700); err != nil {
+		return fmt.Errorf("creating state history directory: %w", err)
+	}
+	if err := writeFileAtomic(historyPath, data); err != nil {
+		return fmt.Errorf("writing state history: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes to a temp file first and renames it to path
+// (atomic on POSIX).
+func writeFileAtomic(path string, data []byte) error {
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		// Clean up temp file on error
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+// Save persists the state for a pipeline.
+// Uses atomic write (temp file + rename) to prevent corruption.
+// Creates the base directory if it doesn't exist.
+func (s *FileStateStore) Save(pipelineID string, state *State) error {
+	if pipelineID == "" {
+		return ErrInvalidPipelineID
+	}
+	if state == nil {
+		return ErrNilState
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Ensure directory exists
+	if err := os.MkdirAll(s.basePath, 0700); err != nil {
+		logger.Warn("failed to create state directory",
+			"path", s.basePath,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+
+	// Ensure state has the correct pipeline ID
+	state.PipelineID = pipelineID
+
+	// Keep the replaced state; written first so that a crash never loses it
+	if err := s.pushHistory(pipelineID); err != nil {
+		logger.Warn("failed to record state history",
+			"pipeline_id", pipelineID,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	// Marshal state to JSON
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		logger.Warn("failed to marshal state",
+			"pipeline_id", pipelineID,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("marshaling state: %w", err)
+	}
+
+	// Write to temp file first (atomic write)
+	filePath := s.filePath(pipelineID)
+	tempPath := filePath + ".tmp"
+
+	if err := os.WriteFile(tempPath, data, 0600); err != nil {
+		logger.Warn("failed to write temp state file",
+			"pipeline_id", pipelineID,
+			"path", tempPath,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("writing temp state file: %w", err)
+	}
+
+	// Rename temp file to final path (atomic on POSIX)
+	if err := os.Rename(tempPath, filePath); err != nil {
+		// Clean up temp file on error
+		_ = os.Remove(tempPath)
+		logger.Warn("failed to rename state file",
+			"pipeline_id", pipelineID,
+			"temp_path", tempPath,
+			"final_path", filePath,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("renaming state file: %w", err)
+	}
+
+	logger.Debug("state saved",
+		"pipeline_id", pipelineID,
+		"path", filePath,
+		"has_timestamp", state.LastTimestamp != nil,
+		"has_id", state.LastID != nil,
+	)
+
+	return nil
+}
+
+// Load retrieves the state for a pipeline.
+// Returns nil, nil if the state file doesn't exist (first execution).
+func (s *FileStateStore) Load(pipelineID string) (*State, error) {
+	if pipelineID == "" {
+		return nil, ErrInvalidPipelineID
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.load(pipelineID)
+}
+
+// load reads the state of a pipeline. The caller must hold the lock.
+func (s *FileStateStore) load(pipelineID string) (*State, error) {
+	filePath := s.filePath(pipelineID)
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// No state file = first execution, not an error
+			logger.Debug("no state file found (first execution)",
+				"pipeline_id", pipelineID,
+				"path", filePath,
+			)
+			return nil, nil
+		}
+		logger.Warn("failed to read state file",
+			"pipeline_id", pipelineID,
+			"path", filePath,
+			"error", err.Error(),
+		)
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		logger.Warn("failed to unmarshal state",
+			"pipeline_id", pipelineID,
+			"path", filePath,
+			"error", err.Error(),
+		)
+		return nil, fmt.Errorf("unmarshaling state: %w", err)
+	}
+
+	logger.Debug("state loaded",
+		"pipeline_id", pipelineID,
+		"path", filePath,
+		"has_timestamp", state.LastTimestamp != nil,
+		"has_id", state.LastID != nil,
+	)
+
+	return &state, nil
+}
+
+// Delete removes the state file for a pipeline, keeping it in the history.
+// Returns nil if the file doesn't exist.
+func (s *FileStateStore) Delete(pipelineID string) error {
+	if pipelineID == "" {
+		return ErrInvalidPipelineID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.pushHistory(pipelineID); err != nil {
+		logger.Warn("failed to record state history",
+			"pipeline_id", pipelineID,
+			"error", err.Error(),
+		)
+		return err
+	}
+
+	filePath := s.filePath(pipelineID)
+
+	if err := os.Remove(filePath); err != nil {
+		if os.IsNotExist(err) {
+			// File doesn't exist, nothing to delete
+			return nil
+		}
+		logger.Warn("failed to delete state file",
+			"pipeline_id", pipelineID,
+			"path", filePath,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("deleting state file: %w", err)
+	}
+
+	logger.Debug("state deleted",
+		"pipeline_id", pipelineID,
+		"path", filePath,
+	)
+
+	return nil
+}
+
+// Exists checks if a state file exists for a pipeline.
+func (s *FileStateStore) Exists(pipelineID string) (bool, error) {
+	if pipelineID == "" {
+		return false, ErrInvalidPipelineID
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filePath := s.filePath(pipelineID)
+	_, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("checking state file: %w", err)
+	}
+	return true, nil
+}
+
+// History returns the previous states of a pipeline, most recent first.
+func (s *FileStateStore) History(pipelineID string) ([]*State, error) {
+	if pipelineID == "" {
+		return nil, ErrInvalidPipelineID
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.history(pipelineID)
+}
+
+// history reads the history of a pipeline. The caller must hold the lock.
+func (s *FileStateStore) history(pipelineID string) ([]*State, error) {
+	data, err := os.ReadFile(s.historyPath(pipelineID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading state history: %w", err)
+	}
+
+	var history []*State
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("unmarshaling state history: %w", err)
+	}
+	return history, nil
+}
+
+// Close is a no-op: the file backend holds no resources between calls.
+func (s *FileStateStore) Close() error {
+	return nil
+}
+
+// Verify FileStateStore implements StateStore
+var _ StateStore = (*FileStateStore)(nil)
@@ -30,11 +30,23 @@ var (
 // The field path supports dot notation for nested fields (e.g., "data.id").
 // Returns the ID as a string, converting numeric types if necessary.
 func ExtractID(record map[string]interface{}, fieldPath string) (string, error) {
+	value, err := lookupField(record, fieldPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Convert the final value to string
+	return valueToString(value)
+}
+
+// lookupField returns the value of a field path in a record.
+// The field path supports dot notation for nested fields (e.g., "data.id").
+func lookupField(record map[string]interface{}, fieldPath string) (interface{}, error) {
 	if record == nil {
-		return "", ErrNilRecord
+		return nil, ErrNilRecord
 	}
 	if fieldPath == "" {
-		return "", ErrEmptyFieldPath
+		return nil, ErrEmptyFieldPath
 	}
 
 	// Split field path by dots for nested access
@@ -45,20 +57,19 @@ func ExtractID(record map[string]interface{}, fieldPath string) (string, error)
 		// Check if current is a map
 		m, ok := current.(map[string]interface{})
 		if !ok {
-			return "", fmt.Errorf("%w: at path segment %q", ErrInvalidFieldType, strings.Join(parts[:i], "."))
+			return nil, fmt.Errorf("%w: at path segment %q", ErrInvalidFieldType, strings.Join(parts[:i], "."))
 		}
 
 		// Get the field value
 		value, exists := m[part]
 		if !exists {
-			return "", fmt.Errorf("%w: %q", ErrFieldNotFound, fieldPath)
+			return nil, fmt.Errorf("%w: %q", ErrFieldNotFound, fieldPath)
 		}
 
 		current = value
 	}
 
-	// Convert the final value to string
-	return valueToString(current)
+	return current, nil
 }
 
 // valueToString converts an interface value to a string.
@@ -0,0 +1,217 @@
+package persistence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// Lock defaults
+const (
+	// DefaultLockLease is how long a lock outlives a holder that stopped
+	// renewing it, e.g. a crashed process.
+	DefaultLockLease = 2 * time.Minute
+
+	// minLockLease bounds the lease so that renewals are not too frequent.
+	minLockLease = 3 * time.Second
+)
+
+// lockPollInterval is how often AcquireLock retries while waiting for a lock.
+var lockPollInterval = time.Second
+
+var (
+	// ErrLockHeld is returned when the lock of a pipeline is held by another run.
+	ErrLockHeld = errors.New("pipeline is locked by another run")
+)
+
+// LockHolder describes the current holder of a pipeline lock.
+type LockHolder struct {
+	// Owner identifies the holder (host, process and run).
+	Owner string `json:"owner"`
+
+	// ExpiresAt is when the lock expires unless renewed.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Locker provides advisory per-pipeline locks, so that a single run of a
+// pipeline reads and saves its state at a time, across processes.
+// A lock is leased: it expires unless its owner renews it.
+type Locker interface {
+	// TryLock acquires the lock of a pipeline for owner, or renews it if owner
+	// already holds it. An expired lock is taken over.
+	// Returns the current holder if another owner holds the lock, nil if the
+	// lock was acquired.
+	TryLock(pipelineID, owner string, lease time.Duration) (*LockHolder, error)
+
+	// Unlock releases the lock of a pipeline if owner holds it.
+	Unlock(pipelineID, owner string) error
+}
+
+// LockOptions configures how a run acquires the lock of its pipeline.
+type LockOptions struct {
+	// Wait makes AcquireLock wait for the lock to be released instead of
+	// failing with ErrLockHeld.
+	Wait bool
+
+	// WaitTimeout bounds the wait (0 waits until the context is done).
+	WaitTimeout time.Duration
+
+	// Lease is how long the lock outlives a holder that stopped renewing it
+	// (default DefaultLockLease). The holder renews the lock every third of it.
+	Lease time.Duration
+}
+
+// PipelineLock is a pipeline lock held by the current run. It is renewed in
+// the background until released.
+type PipelineLock struct {
+	locker     Locker
+	pipelineID string
+	owner      string
+
+	stop     chan struct{}
+	done     chan struct{}
+	mu       sync.Mutex
+	lost     bool
+	released bool
+}
+
+// AcquireLock acquires the lock of a pipeline, waiting for it if opts.Wait.
+// Returns ErrLockHeld, with the holder in the message, if the lock is held
+// by another run (after the wait, if any).
+func AcquireLock(ctx context.Context, locker Locker, pipelineID string, opts LockOptions) (*PipelineLock, error) {
+	if pipelineID == "" {
+		return nil, ErrInvalidPipelineID
+	}
+	lease := opts.Lease
+	if lease <= 0 {
+		lease = DefaultLockLease
+	}
+	if lease < minLockLease {
+		lease = minLockLease
+	}
+	owner := newLockOwner()
+
+	if opts.Wait && opts.WaitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.WaitTimeout)
+		defer cancel()
+	}
+
+	for {
+		holder, err := locker.TryLock(pipelineID, owner, lease)
+		if err != nil {
+			return nil, fmt.Errorf("acquiring pipeline lock: %w", err)
+		}
+		if holder == nil {
+			lock := &PipelineLock{
+				locker:     locker,
+				pipelineID: pipelineID,
+				owner:      owner,
+				stop:       make(chan struct{}),
+				done:       make(chan struct{}),
+			}
+			go lock.renew(lease)
+			logger.Debug("pipeline lock acquired", "pipeline_id", pipelineID, "owner", owner)
+			return lock, nil
+		}
+
+		heldErr := fmt.Errorf("%w: held by %s until %s", ErrLockHeld, holder.Owner, holder.ExpiresAt.Format(time.RFC3339))
+		if !opts.Wait {
+			return nil, heldErr
+		}
+		logger.Debug("waiting for pipeline lock",
+			"pipeline_id", pipelineID,
+			"holder", holder.Owner,
+			"expires_at", holder.ExpiresAt.Format(time.RFC3339),
+		)
+		select {
+		case <-ctx.Done():
+			return nil, heldErr
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// renew renews the lease until the lock is released or lost.
+func (l *PipelineLock) renew(lease time.Duration) {
+	defer close(l.done)
+	ticker := time.NewTicker(lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			holder, err := l.locker.TryLock(l.pipelineID, l.owner, lease)
+			if err != nil {
+				// Transient store failure: the lease covers the next attempts
+				logger.Warn("failed to renew pipeline lock",
+					"pipeline_id", l.pipelineID,
+					"error", err.Error(),
+				)
+				continue
+			}
+			if holder != nil {
+				l.mu.Lock()
+				l.lost = true
+				l.mu.Unlock()
+				logger.Error("pipeline lock lost, its lease expired and another run took it over",
+					"pipeline_id", l.pipelineID,
+					"holder", holder.Owner,
+				)
+				return
+			}
+		}
+	}
+}
+
+// Lost reports whether the lease expired and another run took the lock over.
+// State must then not be saved, not to overwrite the other run's state.
+// A nil lock (locking disabled) is never lost.
+func (l *PipelineLock) Lost() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lost
+}
+
+// Release stops renewing the lock and releases it. Safe to call more than once.
+func (l *PipelineLock) Release() error {
+	l.mu.Lock()
+	if l.released {
+		l.mu.Unlock()
+		return nil
+	}
+	l.released = true
+	l.mu.Unlock()
+
+	close(l.stop)
+	<-l.done
+	if err := l.locker.Unlock(l.pipelineID, l.owner); err != nil {
+		return fmt.Errorf("releasing pipeline lock: %w", err)
+	}
+	logger.Debug("pipeline lock released", "pipeline_id", l.pipelineID, "owner", l.owner)
+	return nil
+}
+
+// newLockOwner returns a lock owner unique to this run: host, process and a
+// random suffix.
+func newLockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
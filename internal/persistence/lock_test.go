@@ -0,0 +1,191 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lockBackends returns a factory per backend. Stores created by one factory
+// share their location, like separate processes would.
+func lockBackends(t *testing.T) map[string]func() StateStore {
+	dir := t.TempDir()
+	return map[string]func() StateStore{
+		"file": func() StateStore { return NewFileStateStore(filepath.Join(dir, "state")) },
+		"sqlite": func() StateStore {
+			store, err := NewSQLiteStateStore(filepath.Join(dir, "state.db"))
+			if err != nil {
+				t.Fatalf("NewSQLiteStateStore failed: %v", err)
+			}
+			t.Cleanup(func() {
+				_ = store.Close()
+			})
+			return store
+		},
+	}
+}
+
+func TestLocker_TryLock(t *testing.T) {
+	for name, open := range lockBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			a, b := open(), open()
+
+			if holder, err := a.TryLock("p", "owner-a", time.Minute); err != nil || holder != nil {
+				t.Fatalf("TryLock(a) = %v, %v, want acquired", holder, err)
+			}
+			holder, err := b.TryLock("p", "owner-b", time.Minute)
+			if err != nil || holder == nil || holder.Owner != "owner-a" {
+				t.Fatalf("TryLock(b) = %v, %v, want held by owner-a", holder, err)
+			}
+			// Locks are per pipeline
+			if holder, err := b.TryLock("other", "owner-b", time.Minute); err != nil || holder != nil {
+				t.Errorf("TryLock(b, other) = %v, %v, want acquired", holder, err)
+			}
+			// The owner renews its lock
+			if holder, err := a.TryLock("p", "owner-a", time.Minute); err != nil || holder != nil {
+				t.Errorf("renewal = %v, %v, want acquired", holder, err)
+			}
+
+			// Only the owner releases the lock
+			if err := b.Unlock("p", "owner-b"); err != nil {
+				t.Fatalf("Unlock(b) failed: %v", err)
+			}
+			if holder, _ := b.TryLock("p", "owner-b", time.Minute); holder == nil {
+				t.Fatal("Unlock by another owner released the lock")
+			}
+			if err := a.Unlock("p", "owner-a"); err != nil {
+				t.Fatalf("Unlock(a) failed: %v", err)
+			}
+			if holder, err := b.TryLock("p", "owner-b", time.Minute); err != nil || holder != nil {
+				t.Errorf("TryLock(b) after release = %v, %v, want acquired", holder, err)
+			}
+		})
+	}
+}
+
+func TestLocker_ExpiredLeaseTakeover(t *testing.T) {
+	for name, open := range lockBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			a, b := open(), open()
+
+			if holder, err := a.TryLock("p", "crashed", 10*time.Millisecond); err != nil || holder != nil {
+				t.Fatalf("TryLock = %v, %v, want acquired", holder, err)
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			if holder, err := b.TryLock("p", "owner-b", time.Minute); err != nil || holder != nil {
+				t.Fatalf("TryLock of expired lock = %v, %v, want taken over", holder, err)
+			}
+			// The crashed holder does not get it back by renewing
+			if holder, _ := a.TryLock("p", "crashed", time.Minute); holder == nil || holder.Owner != "owner-b" {
+				t.Errorf("renewal by previous holder = %v, want held by owner-b", holder)
+			}
+		})
+	}
+}
+
+func TestLocker_ConcurrentAcquisition(t *testing.T) {
+	for name, open := range lockBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			var acquired atomic.Int32
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				store := open()
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					holder, err := store.TryLock("p", fmt.Sprintf("owner-%d", i), time.Minute)
+					if err != nil {
+						t.Errorf("TryLock failed: %v", err)
+						return
+					}
+					if holder == nil {
+						acquired.Add(1)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			if n := acquired.Load(); n != 1 {
+				t.Errorf("%d runs acquired the lock, want 1", n)
+			}
+		})
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	lockPollInterval = 10 * time.Millisecond
+	store := NewFileStateStore(t.TempDir())
+	ctx := context.Background()
+
+	lock, err := AcquireLock(ctx, store, "p", LockOptions{})
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+
+	// Skip semantics
+	if _, err := AcquireLock(ctx, store, "p", LockOptions{}); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("AcquireLock of held lock error = %v, want ErrLockHeld", err)
+	}
+
+	// Wait semantics, bounded by the timeout
+	if _, err := AcquireLock(ctx, store, "p", LockOptions{Wait: true, WaitTimeout: 50 * time.Millisecond}); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("AcquireLock wait timeout error = %v, want ErrLockHeld", err)
+	}
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = lock.Release()
+	}()
+	next, err := AcquireLock(ctx, store, "p", LockOptions{Wait: true, WaitTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("AcquireLock waiting for release failed: %v", err)
+	}
+	if next.Lost() {
+		t.Error("Lost() = true for a held lock")
+	}
+	if err := next.Release(); err != nil {
+		t.Errorf("Release failed: %v", err)
+	}
+	if err := next.Release(); err != nil {
+		t.Errorf("second Release failed: %v", err)
+	}
+}
+
+func TestAcquireLock_Lost(t *testing.T) {
+	store := NewFileStateStore(t.TempDir())
+
+	lock, err := AcquireLock(context.Background(), store, "p", LockOptions{Lease: minLockLease})
+	if err != nil {
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	// Another run takes the lock over, as after an expired lease
+	if err := store.Unlock("p", lock.owner); err != nil {
+		t.Fatal(err)
+	}
+	if holder, err := store.TryLock("p", "other", time.Minute); err != nil || holder != nil {
+		t.Fatalf("TryLock = %v, %v", holder, err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for !lock.Lost() && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !lock.Lost() {
+		t.Error("Lost() = false after the lock was taken over")
+	}
+	// Releasing a lost lock leaves the new holder's lock
+	_ = lock.Release()
+	if holder, _ := store.TryLock("p", "third", time.Minute); holder == nil || holder.Owner != "other" {
+		t.Errorf("holder after release of lost lock = %v, want other", holder)
+	}
+}
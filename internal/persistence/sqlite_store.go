@@ -0,0 +1,397 @@
+// Package persistence provides state persistence for pipeline execution.
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+
+	// SQLite driver for the embedded state database
+	_ "modernc.org/sqlite"
+)
+
+// sqliteBusyTimeoutMs is how long a writer waits for a lock held by another
+// connection or process before failing.
+const sqliteBusyTimeoutMs = 5000
+
+// sqliteSchema creates the state tables. The state of a pipeline is one row
+// of pipeline_state; its named keys are rows of pipeline_state_keys, so that
+// a single key can be inspected or updated with SQL. History rows hold
+// whole states, keys included. A pipeline lock is a row of pipeline_locks,
+// expiring at expires_at (Unix milliseconds).
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS pipeline_state (
+	pipeline_id TEXT PRIMARY KEY,
+	state       TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS pipeline_state_keys (
+	pipeline_id TEXT NOT NULL,
+	key         TEXT NOT NULL,
+	value       TEXT NOT NULL,
+	PRIMARY KEY (pipeline_id, key)
+);
+CREATE TABLE IF NOT EXISTS pipeline_state_history (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	pipeline_id TEXT NOT NULL,
+	state       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS pipeline_state_history_pipeline ON pipeline_state_history (pipeline_id, id);
+CREATE TABLE IF NOT EXISTS pipeline_locks (
+	pipeline_id TEXT PRIMARY KEY,
+	owner       TEXT NOT NULL,
+	expires_at  INTEGER NOT NULL
+);`
+
+// sqliteQueryer is implemented by *sql.DB and *sql.Tx.
+type sqliteQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// SQLiteStateStore is the embedded SQLite backend of StateStore.
+// All pipelines share one database file. Save writes the state and all of
+// its named keys in a single transaction.
+type SQLiteStateStore struct {
+	path string
+	db   *sql.DB
+}
+
+// NewSQLiteStateStore opens (and creates if needed) the state database at path.
+// If path is empty, DefaultSQLiteStatePath is used.
+func NewSQLiteStateStore(path string) (*SQLiteStateStore, error) {
+	if path == "" {
+		path = DefaultSQLiteStatePath
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating state directory: %w", err)
+	}
+
+	// Transactions take the write lock upfront: Save reads the replaced state
+	// before writing, and a read lock cannot wait to be upgraded.
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate", path, sqliteBusyTimeoutMs)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening state database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("creating state tables: %w", err)
+	}
+
+	logger.Debug("sqlite state store opened", "path", path)
+	return &SQLiteStateStore{path: path, db: db}, nil
+}
+
+// Save atomically replaces the state and named keys of a pipeline.
+func (s *SQLiteStateStore) Save(pipelineID string, state *State) error {
+	if pipelineID == "" {
+		return ErrInvalidPipelineID
+	}
+	if state == nil {
+		return ErrNilState
+	}
+
+	// Ensure state has the correct pipeline ID
+	state.PipelineID = pipelineID
+
+	// Named keys are stored as rows of their own
+	row := *state
+	row.Keys = nil
+	data, err := json.Marshal(&row)
+	if err != nil {
+		return fmt.Errorf("marshaling state: %w", err)
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning state transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := s.pushHistory(ctx, tx, pipelineID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO pipeline_state (pipeline_id, state, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (pipeline_id) DO UPDATE SET state = excluded.state, updated_at = excluded.updated_at`,
+		pipelineID, string(data), state.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	); err != nil {
+		return fmt.Errorf("saving state: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pipeline_state_keys WHERE pipeline_id = ?`, pipelineID); err != nil {
+		return fmt.Errorf("saving state keys: %w", err)
+	}
+	for key, value := range state.Keys {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO pipeline_state_keys (pipeline_id, key, value) VALUES (?, ?, ?)`,
+			pipelineID, key, string(value),
+		); err != nil {
+			return fmt.Errorf("saving state key %q: %w", key, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Warn("failed to commit state",
+			"pipeline_id", pipelineID,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("committing state: %w", err)
+	}
+
+	logger.Debug("state saved",
+		"pipeline_id", pipelineID,
+		"path", s.path,
+		"has_timestamp", state.LastTimestamp != nil,
+		"has_id", state.LastID != nil,
+		"keys", len(state.Keys),
+	)
+	return nil
+}
+
+// Load retrieves the state and named keys of a pipeline.
+// Returns nil, nil if no state exists (first execution).
+func (s *SQLiteStateStore) Load(pipelineID string) (*State, error) {
+	if pipelineID == "" {
+		return nil, ErrInvalidPipelineID
+	}
+
+	state, err := loadSQLiteState(context.Background(), s.db, pipelineID)
+	if err != nil || state == nil {
+		return nil, err
+	}
+
+	logger.Debug("state loaded",
+		"pipeline_id", pipelineID,
+		"path", s.path,
+		"has_timestamp", state.LastTimestamp != nil,
+		"has_id", state.LastID != nil,
+		"keys", len(state.Keys),
+	)
+	return state, nil
+}
+
+// loadSQLiteState reads the state and named keys of a pipeline.
+// Returns nil, nil if no state exists.
+func loadSQLiteState(ctx context.Context, q sqliteQueryer, pipelineID string) (*State, error) {
+	var data string
+	err := q.QueryRowContext(ctx, `SELECT state FROM pipeline_state WHERE pipeline_id = ?`, pipelineID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Debug("no state found (first execution)", "pipeline_id", pipelineID)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("unmarshaling state: %w", err)
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT key, value FROM pipeline_state_keys WHERE pipeline_id = ?`, pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("reading state keys: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("reading state keys: %w", err)
+		}
+		if state.Keys == nil {
+			state.Keys = make(map[string]json.RawMessage)
+		}
+		state.Keys[key] = json.RawMessage(value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading state keys: %w", err)
+	}
+	return &state, nil
+}
+
+// pushHistory records the current state of a pipeline, if any, in its
+// history and drops the entries beyond HistorySize. Checkpointed states are
+// not kept.
+func (s *SQLiteStateStore) pushHistory(ctx context.Context, tx *sql.Tx, pipelineID string) error {
+	current, err := loadSQLiteState(ctx, tx, pipelineID)
+	if err != nil || current == nil || current.Checkpoint != nil {
+		return err
+	}
+	data, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("marshaling state history: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO pipeline_state_history (pipeline_id, state) VALUES (?, ?)`,
+		pipelineID, string(data),
+	); err != nil {
+		return fmt.Errorf("saving state history: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM pipeline_state_history WHERE pipeline_id = ? AND id NOT IN (
+			SELECT id FROM pipeline_state_history WHERE pipeline_id = ? ORDER BY id DESC LIMIT ?
+		)`, pipelineID, pipelineID, HistorySize,
+	); err != nil {
+		return fmt.Errorf("trimming state history: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the state and named keys of a pipeline, keeping them in
+// the history. Returns nil if no state exists.
+func (s *SQLiteStateStore) Delete(pipelineID string) error {
+	if pipelineID == "" {
+		return ErrInvalidPipelineID
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning state transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := s.pushHistory(ctx, tx, pipelineID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pipeline_state WHERE pipeline_id = ?`, pipelineID); err != nil {
+		return fmt.Errorf("deleting state: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pipeline_state_keys WHERE pipeline_id = ?`, pipelineID); err != nil {
+		return fmt.Errorf("deleting state keys: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing state deletion: %w", err)
+	}
+
+	logger.Debug("state deleted", "pipeline_id", pipelineID, "path", s.path)
+	return nil
+}
+
+// Exists checks if state exists for a pipeline.
+func (s *SQLiteStateStore) Exists(pipelineID string) (bool, error) {
+	if pipelineID == "" {
+		return false, ErrInvalidPipelineID
+	}
+
+	var one int
+	err := s.db.QueryRow(`SELECT 1 FROM pipeline_state WHERE pipeline_id = ?`, pipelineID).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("checking state: %w", err)
+	}
+	return true, nil
+}
+
+// History returns the previous states of a pipeline, most recent first.
+func (s *SQLiteStateStore) History(pipelineID string) ([]*State, error) {
+	if pipelineID == "" {
+		return nil, ErrInvalidPipelineID
+	}
+
+	rows, err := s.db.Query(`SELECT state FROM pipeline_state_history WHERE pipeline_id = ? ORDER BY id DESC`, pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("reading state history: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var history []*State
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("reading state history: %w", err)
+		}
+		var state State
+		if err := json.Unmarshal([]byte(data), &state); err != nil {
+			return nil, fmt.Errorf("unmarshaling state history: %w", err)
+		}
+		history = append(history, &state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading state history: %w", err)
+	}
+	return history, nil
+}
+
+// TryLock acquires, renews or takes over the lock row of a pipeline, in a
+// single statement.
+func (s *SQLiteStateStore) TryLock(pipelineID, owner string, lease time.Duration) (*LockHolder, error) {
+	if pipelineID == "" {
+		return nil, ErrInvalidPipelineID
+	}
+
+	// A lock released between both statements is acquired on the second attempt
+	for attempt := 0; ; attempt++ {
+		holder, err := s.tryLock(pipelineID, owner, lease)
+		if err != nil || holder == nil || holder.Owner != "" || attempt > 0 {
+			return holder, err
+		}
+	}
+}
+
+// tryLock makes one attempt of TryLock. Returns a holder without owner if
+// the lock was released after the attempt.
+func (s *SQLiteStateStore) tryLock(pipelineID, owner string, lease time.Duration) (*LockHolder, error) {
+	now := time.Now()
+	res, err := s.db.Exec(`
+		INSERT INTO pipeline_locks (pipeline_id, owner, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (pipeline_id) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at
+		WHERE pipeline_locks.owner = excluded.owner OR pipeline_locks.expires_at <= ?`,
+		pipelineID, owner, now.Add(lease).UnixMilli(), now.UnixMilli(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("acquiring lock: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("acquiring lock: %w", err)
+	} else if n > 0 {
+		return nil, nil
+	}
+
+	var holder LockHolder
+	var expiresAt int64
+	err = s.db.QueryRow(`SELECT owner, expires_at FROM pipeline_locks WHERE pipeline_id = ?`, pipelineID).Scan(&holder.Owner, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &LockHolder{ExpiresAt: now}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading lock: %w", err)
+	}
+	holder.ExpiresAt = time.UnixMilli(expiresAt)
+	return &holder, nil
+}
+
+// Unlock deletes the lock row of a pipeline if owner holds it.
+func (s *SQLiteStateStore) Unlock(pipelineID, owner string) error {
+	if pipelineID == "" {
+		return ErrInvalidPipelineID
+	}
+	if _, err := s.db.Exec(`DELETE FROM pipeline_locks WHERE pipeline_id = ? AND owner = ?`, pipelineID, owner); err != nil {
+		return fmt.Errorf("releasing lock: %w", err)
+	}
+	return nil
+}
+
+// Close closes the state database.
+func (s *SQLiteStateStore) Close() error {
+	return s.db.Close()
+}
+
+// Verify SQLiteStateStore implements StateStore
+var _ StateStore = (*SQLiteStateStore)(nil)
@@ -0,0 +1,201 @@
+package persistence
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStateStore {
+	t.Helper()
+	store, err := NewSQLiteStateStore(filepath.Join(t.TempDir(), "nested", "state.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStateStore failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	return store
+}
+
+func TestSQLiteStateStore_SaveAndLoad(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	timestamp := time.Date(2026, 1, 26, 10, 30, 0, 0, time.UTC)
+	lastID := "12345"
+	state := &State{
+		LastTimestamp: &timestamp,
+		LastID:        &lastID,
+		Cursor:        map[string]interface{}{"id": float64(42)},
+		Keys: map[string]json.RawMessage{
+			"filters.0.seen":  json.RawMessage(`["a","b"]`),
+			"output.checksum": json.RawMessage(`"abc"`),
+		},
+		UpdatedAt: time.Now(),
+	}
+	if err := store.Save("pipeline-1", state); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("pipeline-1")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.PipelineID != "pipeline-1" {
+		t.Errorf("PipelineID = %q, want pipeline-1", loaded.PipelineID)
+	}
+	if loaded.LastTimestamp == nil || !loaded.LastTimestamp.Equal(timestamp) {
+		t.Errorf("LastTimestamp = %v, want %v", loaded.LastTimestamp, timestamp)
+	}
+	if loaded.LastID == nil || *loaded.LastID != lastID {
+		t.Errorf("LastID = %v, want %q", loaded.LastID, lastID)
+	}
+	if loaded.Cursor["id"] != float64(42) {
+		t.Errorf("Cursor = %v, want id 42", loaded.Cursor)
+	}
+	if len(loaded.Keys) != 2 || string(loaded.Keys["filters.0.seen"]) != `["a","b"]` || string(loaded.Keys["output.checksum"]) != `"abc"` {
+		t.Errorf("Keys = %v", loaded.Keys)
+	}
+}
+
+func TestSQLiteStateStore_SaveReplacesKeys(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	first := &State{Keys: map[string]json.RawMessage{"a": json.RawMessage(`1`), "b": json.RawMessage(`2`)}, UpdatedAt: time.Now()}
+	if err := store.Save("p", first); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	second := &State{Keys: map[string]json.RawMessage{"b": json.RawMessage(`3`)}, UpdatedAt: time.Now()}
+	if err := store.Save("p", second); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("p")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Keys) != 1 || string(loaded.Keys["b"]) != "3" {
+		t.Errorf("Keys = %v, want only b=3", loaded.Keys)
+	}
+}
+
+func TestSQLiteStateStore_LoadNotFound(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	loaded, err := store.Load("missing")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded != nil {
+		t.Errorf("Load = %+v, want nil", loaded)
+	}
+}
+
+func TestSQLiteStateStore_DeleteAndExists(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	state := &State{Keys: map[string]json.RawMessage{"k": json.RawMessage(`true`)}, UpdatedAt: time.Now()}
+	if err := store.Save("p", state); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if exists, err := store.Exists("p"); err != nil || !exists {
+		t.Fatalf("Exists = %v, %v, want true", exists, err)
+	}
+
+	if err := store.Delete("p"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if exists, err := store.Exists("p"); err != nil || exists {
+		t.Errorf("Exists after Delete = %v, %v, want false", exists, err)
+	}
+	if err := store.Delete("p"); err != nil {
+		t.Errorf("Delete of missing state failed: %v", err)
+	}
+
+	// Keys of a deleted pipeline do not resurface
+	if err := store.Save("p", &State{UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, _ := store.Load("p")
+	if len(loaded.Keys) != 0 {
+		t.Errorf("Keys after Delete = %v, want none", loaded.Keys)
+	}
+}
+
+func TestSQLiteStateStore_InvalidInput(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	if err := store.Save("", &State{}); err != ErrInvalidPipelineID {
+		t.Errorf("Save(\"\") error = %v, want ErrInvalidPipelineID", err)
+	}
+	if err := store.Save("p", nil); err != ErrNilState {
+		t.Errorf("Save(nil) error = %v, want ErrNilState", err)
+	}
+	if _, err := store.Load(""); err != ErrInvalidPipelineID {
+		t.Errorf("Load(\"\") error = %v, want ErrInvalidPipelineID", err)
+	}
+}
+
+func TestSQLiteStateStore_ConcurrentAccess(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, _ := json.Marshal(i)
+			state := &State{Keys: map[string]json.RawMessage{"n": value}, UpdatedAt: time.Now()}
+			if err := store.Save("p", state); err != nil {
+				t.Errorf("Save failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	loaded, err := store.Load("p")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Keys) != 1 {
+		t.Errorf("Keys = %v, want a single key from one of the writers", loaded.Keys)
+	}
+}
+
+func TestSQLiteStateStore_History(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	for i := 1; i <= HistorySize+2; i++ {
+		id := strconv.Itoa(i)
+		state := &State{LastID: &id, Keys: map[string]json.RawMessage{"n": json.RawMessage(id)}, UpdatedAt: time.Now()}
+		if err := store.Save("p", state); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+	if err := store.Delete("p"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	history, err := store.History("p")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != HistorySize {
+		t.Fatalf("len(History) = %d, want %d", len(history), HistorySize)
+	}
+	// History entries keep their named keys
+	last := strconv.Itoa(HistorySize + 2)
+	if *history[0].LastID != last || string(history[0].Keys["n"]) != last {
+		t.Errorf("History[0] = %+v, want the deleted state", history[0])
+	}
+	if *history[HistorySize-1].LastID != "3" {
+		t.Errorf("oldest History entry = %s, want 3", *history[HistorySize-1].LastID)
+	}
+
+	if history, _ := store.History("other"); len(history) != 0 {
+		t.Errorf("History of another pipeline = %v, want empty", history)
+	}
+}
@@ -1,22 +1,33 @@
 // Package persistence provides state persistence for pipeline execution.
 // It supports persisting last execution timestamp and last processed ID
-// for polling input modules to enable reliable resumption after restarts.
+// for polling input modules to enable reliable resumption after restarts,
+// and named keys for filter and output modules, in a file or SQLite store.
 package persistence
 
 import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"sync"
 	"time"
+)
+
+// Default state store locations
+const (
+	// DefaultStatePath is the default directory for state files.
+	DefaultStatePath = "./cannectors-data/state"
 
-	"github.com/cannectors/runtime/internal/logger"
+	// DefaultSQLiteStatePath is the default database file of the SQLite backend.
+	DefaultSQLiteStatePath = "./cannectors-data/state.db"
+
+	// HistorySize is the number of previous states kept per pipeline.
+	HistorySize = 20
 )
 
-// DefaultStatePath is the default directory for state files.
-const DefaultStatePath = "./cannectors-data/state"
+// State store backends
+const (
+	BackendFile   = "file"
+	BackendSQLite = "sqlite"
+)
 
 // Common errors
 var (
@@ -25,6 +36,9 @@ var (
 
 	// ErrNilState is returned when state is nil.
 	ErrNilState = errors.New("state is nil")
+
+	// ErrUnknownBackend is returned for an unsupported state store backend.
+	ErrUnknownBackend = errors.New("unknown state store backend")
 )
 
 // State represents the persisted state for a pipeline.
@@ -33,7 +47,8 @@ type State struct {
 	// PipelineID is the unique identifier for the pipeline.
 	PipelineID string `json:"pipelineId"`
 
-	// LastTimestamp is the execution start timestamp from the last successful execution.
+	// LastTimestamp is the execution start timestamp from the last successful execution,
+	// or the record high-watermark when a timestamp field is configured.
 	// Used for timestamp-based filtering (e.g., ?since=2026-01-26T10:30:00Z).
 	LastTimestamp *time.Time `json:"lastTimestamp,omitempty"`
 
@@ -42,215 +57,135 @@ type State struct {
 	// assuming records are processed in chronological or ID order.
 	LastID *string `json:"lastId,omitempty"`
 
-	// UpdatedAt is when this state was last updated.
-	UpdatedAt time.Time `json:"updatedAt"`
-}
+	// ETag is the ETag response header from the last successful execution.
+	// Sent as If-None-Match when conditional requests are enabled.
+	ETag string `json:"etag,omitempty"`
 
-// FormatTimestamp returns the LastTimestamp formatted as RFC3339 (ISO 8601).
-// Returns empty string if LastTimestamp is nil.
-func (s *State) FormatTimestamp() string {
-	if s.LastTimestamp == nil {
-		return ""
-	}
-	return s.LastTimestamp.Format(time.RFC3339)
-}
+	// LastModified is the Last-Modified response header from the last successful execution.
+	// Sent as If-Modified-Since when conditional requests are enabled.
+	LastModified string `json:"lastModified,omitempty"`
 
-// StateStore provides thread-safe persistence of pipeline state.
-// State files are stored as JSON in the configured base path.
-type StateStore struct {
-	basePath string
-	mu       sync.RWMutex
-}
-
-// NewStateStore creates a new StateStore with the specified base path.
-// If basePath is empty, DefaultStatePath is used.
-func NewStateStore(basePath string) *StateStore {
-	if basePath == "" {
-		basePath = DefaultStatePath
-	}
-	return &StateStore{
-		basePath: basePath,
-	}
-}
+	// Cursor is the composite keyset cursor from the last successful execution,
+	// keyed by record field (e.g. {"updated_at": "...", "id": 42}).
+	// Used by database inputs to resume after the last row read.
+	Cursor map[string]interface{} `json:"cursor,omitempty"`
 
-// filePath returns the full path for a pipeline's state file.
-func (s *StateStore) filePath(pipelineID string) string {
-	// Sanitize pipeline ID to prevent directory traversal
-	safeName := filepath.Base(pipelineID)
-	return filepath.Join(s.basePath, safeName+".json")
-}
+	// Keys holds arbitrary named state, such as the cursors, dedupe sets or
+	// change hashes of filter and output modules. Values are JSON documents.
+	// Keys are saved with the rest of the state, so that all of them are
+	// committed atomically.
+	Keys map[string]json.RawMessage `json:"keys,omitempty"`
 
-// Save persists the state for a pipeline.
-// Uses atomic write (temp file + rename) to prevent corruption.
-// Creates the base directory if it doesn't exist.
-func (s *StateStore) Save(pipelineID string, state *State) error {
-	if pipelineID == "" {
-		return ErrInvalidPipelineID
-	}
-	if state == nil {
-		return ErrNilState
-	}
+	// Checkpoint is the position of a run interrupted after delivering part
+	// of its records. The other fields still hold the state of the last
+	// successful run. Cleared when a run completes.
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	// UpdatedAt is when this state was last updated.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(s.basePath, 0700); err != nil {
-		logger.Warn("failed to create state directory",
-			"path", s.basePath,
-			"error", err.Error(),
-		)
-		return fmt.Errorf("creating state directory: %w", err)
-	}
+// Checkpoint is saved after each chunk of records delivered by a streaming
+// run, so that a restarted run resumes after the last delivered chunk instead
+// of starting over. The position fields set depend on the input module.
+type Checkpoint struct {
+	// StartedAt is when the interrupted run started. A resumed run completes
+	// it, and persists this timestamp as its execution start.
+	StartedAt time.Time `json:"startedAt"`
 
-	// Ensure state has the correct pipeline ID
-	state.PipelineID = pipelineID
-
-	// Marshal state to JSON
-	data, err := json.MarshalIndent(state, "", "  ")
-	if err != nil {
-		logger.Warn("failed to marshal state",
-			"pipeline_id", pipelineID,
-			"error", err.Error(),
-		)
-		return fmt.Errorf("marshaling state: %w", err)
-	}
+	// Chunks is the number of chunks delivered so far.
+	Chunks int `json:"chunks"`
 
-	// Write to temp file first (atomic write)
-	filePath := s.filePath(pipelineID)
-	tempPath := filePath + ".tmp"
-
-	if err := os.WriteFile(tempPath, data, 0600); err != nil {
-		logger.Warn("failed to write temp state file",
-			"pipeline_id", pipelineID,
-			"path", tempPath,
-			"error", err.Error(),
-		)
-		return fmt.Errorf("writing temp state file: %w", err)
-	}
+	// RecordsProcessed is the number of records sent by the output so far.
+	RecordsProcessed int `json:"recordsProcessed"`
 
-	// Rename temp file to final path (atomic on POSIX)
-	if err := os.Rename(tempPath, filePath); err != nil {
-		// Clean up temp file on error
-		_ = os.Remove(tempPath)
-		logger.Warn("failed to rename state file",
-			"pipeline_id", pipelineID,
-			"temp_path", tempPath,
-			"final_path", filePath,
-			"error", err.Error(),
-		)
-		return fmt.Errorf("renaming state file: %w", err)
-	}
+	// Page is the next page to fetch (page pagination).
+	Page int `json:"page,omitempty"`
 
-	logger.Debug("state saved",
-		"pipeline_id", pipelineID,
-		"path", filePath,
-		"has_timestamp", state.LastTimestamp != nil,
-		"has_id", state.LastID != nil,
-	)
+	// Offset is the next offset to fetch (offset pagination), or the number
+	// of rows already delivered (database streaming without keyset cursor).
+	Offset int `json:"offset,omitempty"`
 
-	return nil
-}
+	// PageCursor is the cursor of the next page (cursor pagination).
+	PageCursor string `json:"pageCursor,omitempty"`
 
-// Load retrieves the state for a pipeline.
-// Returns nil, nil if the state file doesn't exist (first execution).
-func (s *StateStore) Load(pipelineID string) (*State, error) {
-	if pipelineID == "" {
-		return nil, ErrInvalidPipelineID
-	}
+	// SearchAfter holds the sort values of the last record delivered
+	// (searchAfter pagination).
+	SearchAfter interface{} `json:"searchAfter,omitempty"`
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	filePath := s.filePath(pipelineID)
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// No state file = first execution, not an error
-			logger.Debug("no state file found (first execution)",
-				"pipeline_id", pipelineID,
-				"path", filePath,
-			)
-			return nil, nil
-		}
-		logger.Warn("failed to read state file",
-			"pipeline_id", pipelineID,
-			"path", filePath,
-			"error", err.Error(),
-		)
-		return nil, fmt.Errorf("reading state file: %w", err)
-	}
+	// Cursor is the keyset cursor of the last row delivered (database).
+	Cursor map[string]interface{} `json:"cursor,omitempty"`
 
-	var state State
-	if err := json.Unmarshal(data, &state); err != nil {
-		logger.Warn("failed to unmarshal state",
-			"pipeline_id", pipelineID,
-			"path", filePath,
-			"error", err.Error(),
-		)
-		return nil, fmt.Errorf("unmarshaling state: %w", err)
-	}
+	// LastID is the ID of the last record delivered, if ID persistence is enabled.
+	LastID *string `json:"lastId,omitempty"`
 
-	logger.Debug("state loaded",
-		"pipeline_id", pipelineID,
-		"path", filePath,
-		"has_timestamp", state.LastTimestamp != nil,
-		"has_id", state.LastID != nil,
-	)
+	// Watermark is the record high-watermark of the delivered records, if
+	// watermark persistence is enabled.
+	Watermark *time.Time `json:"watermark,omitempty"`
 
-	return &state, nil
+	// UpdatedAt is when the checkpoint was saved.
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
-// Delete removes the state file for a pipeline.
-// Returns nil if the file doesn't exist.
-func (s *StateStore) Delete(pipelineID string) error {
-	if pipelineID == "" {
-		return ErrInvalidPipelineID
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	filePath := s.filePath(pipelineID)
-
-	if err := os.Remove(filePath); err != nil {
-		if os.IsNotExist(err) {
-			// File doesn't exist, nothing to delete
-			return nil
-		}
-		logger.Warn("failed to delete state file",
-			"pipeline_id", pipelineID,
-			"path", filePath,
-			"error", err.Error(),
-		)
-		return fmt.Errorf("deleting state file: %w", err)
+// FormatTimestamp returns the LastTimestamp formatted as RFC3339 (ISO 8601).
+// Returns empty string if LastTimestamp is nil.
+func (s *State) FormatTimestamp() string {
+	if s.LastTimestamp == nil {
+		return ""
 	}
+	return s.LastTimestamp.Format(time.RFC3339)
+}
 
-	logger.Debug("state deleted",
-		"pipeline_id", pipelineID,
-		"path", filePath,
-	)
-
-	return nil
+// StateStore persists pipeline state between executions.
+// Implementations must be safe for concurrent use. Save replaces the whole
+// state of a pipeline atomically, including all of its named keys.
+//
+// Save and Delete keep the replaced state in a history of the last
+// HistorySize states, so that a pipeline can be rolled back. States holding
+// a Checkpoint are transient and not kept. Runs of a pipeline hold its lock
+// (see Locker) while they read and save its state.
+type StateStore interface {
+	// Load retrieves the state for a pipeline.
+	// Returns nil, nil if no state exists (first execution).
+	Load(pipelineID string) (*State, error)
+
+	// Save atomically replaces the state for a pipeline.
+	Save(pipelineID string, state *State) error
+
+	// Delete removes the state for a pipeline.
+	// Returns nil if no state exists.
+	Delete(pipelineID string) error
+
+	// Exists checks if state exists for a pipeline.
+	Exists(pipelineID string) (bool, error)
+
+	// History returns the previous states of a pipeline, most recent first.
+	History(pipelineID string) ([]*State, error)
+
+	// Locker provides the per-pipeline run locks.
+	Locker
+
+	// Close releases the resources held by the store.
+	Close() error
 }
 
-// Exists checks if a state file exists for a pipeline.
-func (s *StateStore) Exists(pipelineID string) (bool, error) {
-	if pipelineID == "" {
-		return false, ErrInvalidPipelineID
+// OpenStateStore opens a state store backend. An empty backend selects the
+// file backend. An empty path selects the backend's default location.
+func OpenStateStore(backend, path string) (StateStore, error) {
+	switch backend {
+	case "", BackendFile:
+		return NewFileStateStore(path), nil
+	case BackendSQLite:
+		return NewSQLiteStateStore(path)
+	default:
+		return nil, fmt.Errorf("%w: %q (supported: %s, %s)", ErrUnknownBackend, backend, BackendFile, BackendSQLite)
 	}
+}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	filePath := s.filePath(pipelineID)
-	_, err := os.Stat(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, fmt.Errorf("checking state file: %w", err)
-	}
-	return true, nil
+// Window is the time range read by a backfill execution. Its bounds replace
+// the persisted timestamp in the timestamp parameters of the input module, and
+// the query decides whether each bound is included.
+type Window struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
 }
@@ -2,29 +2,33 @@
 package persistence
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 )
 
-func TestNewStateStore(t *testing.T) {
+func TestNewFileStateStore(t *testing.T) {
 	tmpDir := t.TempDir()
 
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 	if store == nil {
-		t.Fatal("NewStateStore returned nil")
+		t.Fatal("NewFileStateStore returned nil")
 	}
 	if store.basePath != tmpDir {
 		t.Errorf("basePath = %q, want %q", store.basePath, tmpDir)
 	}
 }
 
-func TestNewStateStore_DefaultPath(t *testing.T) {
-	store := NewStateStore("")
+func TestNewFileStateStore_DefaultPath(t *testing.T) {
+	store := NewFileStateStore("")
 	if store == nil {
-		t.Fatal("NewStateStore returned nil")
+		t.Fatal("NewFileStateStore returned nil")
 	}
 	if store.basePath != DefaultStatePath {
 		t.Errorf("basePath = %q, want default %q", store.basePath, DefaultStatePath)
@@ -33,7 +37,7 @@ func TestNewStateStore_DefaultPath(t *testing.T) {
 
 func TestStateStore_SaveAndLoad(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	pipelineID := "test-pipeline-1"
 	timestamp := time.Date(2026, 1, 26, 10, 30, 0, 0, time.UTC)
@@ -77,7 +81,7 @@ func TestStateStore_SaveAndLoad(t *testing.T) {
 
 func TestStateStore_Load_NotFound(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	// Load non-existent state
 	loaded, err := store.Load("non-existent-pipeline")
@@ -91,7 +95,7 @@ func TestStateStore_Load_NotFound(t *testing.T) {
 
 func TestStateStore_Save_TimestampOnly(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	pipelineID := "timestamp-only-pipeline"
 	timestamp := time.Date(2026, 1, 26, 12, 0, 0, 0, time.UTC)
@@ -123,7 +127,7 @@ func TestStateStore_Save_TimestampOnly(t *testing.T) {
 
 func TestStateStore_Save_IDOnly(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	pipelineID := "id-only-pipeline"
 	lastID := "abc-123"
@@ -155,7 +159,7 @@ func TestStateStore_Save_IDOnly(t *testing.T) {
 
 func TestStateStore_Delete(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	pipelineID := "delete-test-pipeline"
 	timestamp := time.Now()
@@ -196,7 +200,7 @@ func TestStateStore_Delete(t *testing.T) {
 
 func TestStateStore_Delete_NotFound(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	// Delete non-existent state should not error
 	err := store.Delete("non-existent-pipeline")
@@ -207,7 +211,7 @@ func TestStateStore_Delete_NotFound(t *testing.T) {
 
 func TestStateStore_PerPipelineIsolation(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	// Create states for two different pipelines
 	pipelineID1 := "pipeline-1"
@@ -268,7 +272,7 @@ func TestStateStore_PerPipelineIsolation(t *testing.T) {
 
 func TestStateStore_ConcurrentAccess(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	pipelineID := "concurrent-test-pipeline"
 	iterations := 100
@@ -322,7 +326,7 @@ func TestStateStore_CreatesDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 	subPath := filepath.Join(tmpDir, "nested", "state", "dir")
 
-	store := NewStateStore(subPath)
+	store := NewFileStateStore(subPath)
 
 	pipelineID := "test-pipeline"
 	timestamp := time.Now()
@@ -346,7 +350,7 @@ func TestStateStore_CreatesDirectory(t *testing.T) {
 
 func TestStateStore_AtomicWrite(t *testing.T) {
 	tmpDir := t.TempDir()
-	store := NewStateStore(tmpDir)
+	store := NewFileStateStore(tmpDir)
 
 	pipelineID := "atomic-test-pipeline"
 
@@ -418,3 +422,119 @@ func TestState_FormatTimestamp_Nil(t *testing.T) {
 		t.Errorf("FormatTimestamp() = %q, want empty string", formatted)
 	}
 }
+
+func TestStateStore_SaveAndLoad_Keys(t *testing.T) {
+	store := NewFileStateStore(t.TempDir())
+
+	state := &State{
+		Keys:      map[string]json.RawMessage{"output.hashes": json.RawMessage(`{"1":"abc"}`)},
+		UpdatedAt: time.Now(),
+	}
+	if err := store.Save("pipeline-keys", state); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load("pipeline-keys")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	// The state file is indented: compare compacted values
+	var got bytes.Buffer
+	if err := json.Compact(&got, loaded.Keys["output.hashes"]); err != nil || got.String() != `{"1":"abc"}` {
+		t.Errorf("Keys = %v", loaded.Keys)
+	}
+}
+
+func TestOpenStateStore(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, backend := range []string{"", BackendFile} {
+		store, err := OpenStateStore(backend, dir)
+		if err != nil {
+			t.Fatalf("OpenStateStore(%q) error = %v", backend, err)
+		}
+		if _, ok := store.(*FileStateStore); !ok {
+			t.Errorf("OpenStateStore(%q) = %T, want *FileStateStore", backend, store)
+		}
+	}
+
+	store, err := OpenStateStore(BackendSQLite, filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("OpenStateStore(sqlite) error = %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+	if _, ok := store.(*SQLiteStateStore); !ok {
+		t.Errorf("OpenStateStore(sqlite) = %T, want *SQLiteStateStore", store)
+	}
+
+	if _, err := OpenStateStore("redis", ""); !errors.Is(err, ErrUnknownBackend) {
+		t.Errorf("OpenStateStore(redis) error = %v, want ErrUnknownBackend", err)
+	}
+}
+
+func TestStateStore_History(t *testing.T) {
+	store := NewFileStateStore(t.TempDir())
+
+	history, err := store.History("p")
+	if err != nil || len(history) != 0 {
+		t.Fatalf("History = %v, %v, want empty", history, err)
+	}
+
+	for i := 1; i <= HistorySize+2; i++ {
+		id := strconv.Itoa(i)
+		if err := store.Save("p", &State{LastID: &id, UpdatedAt: time.Now()}); err != nil {
+			t.Fatalf("Save %d failed: %v", i, err)
+		}
+	}
+	if err := store.Delete("p"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	history, err = store.History("p")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != HistorySize {
+		t.Fatalf("len(History) = %d, want %d", len(history), HistorySize)
+	}
+	// Most recent first: the deleted state, then the states it replaced
+	if *history[0].LastID != strconv.Itoa(HistorySize+2) || *history[1].LastID != strconv.Itoa(HistorySize+1) {
+		t.Errorf("History starts with %s, %s", *history[0].LastID, *history[1].LastID)
+	}
+}
+
+func TestStateStore_HistorySkipsCheckpoints(t *testing.T) {
+	stores := map[string]StateStore{
+		BackendFile:   NewFileStateStore(t.TempDir()),
+		BackendSQLite: newTestSQLiteStore(t),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			committed := "1"
+			if err := store.Save("p", &State{LastID: &committed, UpdatedAt: time.Now()}); err != nil {
+				t.Fatal(err)
+			}
+			// A run saves two checkpoints, then completes
+			for i := 1; i <= 2; i++ {
+				state := &State{LastID: &committed, Checkpoint: &Checkpoint{Page: i + 1, Chunks: i}, UpdatedAt: time.Now()}
+				if err := store.Save("p", state); err != nil {
+					t.Fatal(err)
+				}
+			}
+			done := "2"
+			if err := store.Save("p", &State{LastID: &done, UpdatedAt: time.Now()}); err != nil {
+				t.Fatal(err)
+			}
+
+			history, err := store.History("p")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != 1 || history[0].Checkpoint != nil || *history[0].LastID != committed {
+				t.Errorf("History = %+v, want only the committed state", history)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package persistence provides state persistence for pipeline execution.
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidTimestamp is returned when a field value cannot be parsed as a timestamp.
+var ErrInvalidTimestamp = errors.New("invalid timestamp value")
+
+// timestampLayouts are the string layouts accepted for record timestamps,
+// tried in order. Layouts without a zone are interpreted as UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// epochMillisThreshold separates Unix timestamps in seconds from timestamps
+// in milliseconds: larger values are milliseconds (after 2001-09-09).
+const epochMillisThreshold = 1e12
+
+// ParseTimestamp parses a record field value as a timestamp.
+// Supported values are time.Time, RFC 3339 strings, "YYYY-MM-DD HH:MM:SS"
+// strings (UTC unless they carry an offset), dates, and Unix epoch numbers
+// in seconds or milliseconds (as numbers or numeric strings).
+func ParseTimestamp(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range timestampLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return epochTimestamp(f), nil
+		}
+	case float64:
+		return epochTimestamp(v), nil
+	case int64:
+		return epochTimestamp(float64(v)), nil
+	case int:
+		return epochTimestamp(float64(v)), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidTimestamp, value)
+}
+
+// epochTimestamp converts a Unix epoch in seconds or milliseconds.
+func epochTimestamp(v float64) time.Time {
+	if v > epochMillisThreshold {
+		return time.UnixMilli(int64(v)).UTC()
+	}
+	sec := int64(v)
+	return time.Unix(sec, int64((v-float64(sec))*1e9)).UTC()
+}
+
+// ExtractWatermark returns the maximum timestamp of a field across records
+// (the high-watermark). The field path supports dot notation for nested
+// fields. Records where the field is missing or null are skipped.
+// Returns ErrNoRecords if no record holds a timestamp.
+func ExtractWatermark(records []map[string]interface{}, fieldPath string) (time.Time, error) {
+	var watermark time.Time
+	found := false
+	for _, record := range records {
+		value, err := lookupField(record, fieldPath)
+		if errors.Is(err, ErrFieldNotFound) || (err == nil && value == nil) {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		t, err := ParseTimestamp(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("field %q: %w", fieldPath, err)
+		}
+		if !found || t.After(watermark) {
+			watermark = t
+			found = true
+		}
+	}
+	if !found {
+		return time.Time{}, ErrNoRecords
+	}
+	return watermark, nil
+}
+
+// LaterTimestamp returns the later of previous and next, so that a
+// watermark never moves backwards (e.g. when a lookback re-reads older
+// records only). previous may be nil.
+func LaterTimestamp(previous *time.Time, next time.Time) *time.Time {
+	if previous != nil && previous.After(next) {
+		later := *previous
+		return &later
+	}
+	return &next
+}
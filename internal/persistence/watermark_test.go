@@ -0,0 +1,89 @@
+// Package persistence provides state persistence for pipeline execution.
+package persistence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2026, 1, 26, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  time.Time
+	}{
+		{"RFC3339", "2026-01-26T10:30:00Z", want},
+		{"RFC3339 with offset", "2026-01-26T12:30:00+02:00", want},
+		{"RFC3339 fractional", "2026-01-26T10:30:00.250Z", want.Add(250 * time.Millisecond)},
+		{"SQL datetime", "2026-01-26 10:30:00", want},
+		{"date", "2026-01-26", time.Date(2026, 1, 26, 0, 0, 0, 0, time.UTC)},
+		{"epoch seconds", float64(want.Unix()), want},
+		{"epoch milliseconds", float64(want.UnixMilli()), want},
+		{"epoch string", "1769423400", want},
+		{"int64", want.Unix(), want},
+		{"time.Time", want, want},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.value)
+			if err != nil {
+				t.Fatalf("ParseTimestamp(%v) error = %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTimestamp(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	for _, value := range []interface{}{"yesterday", true, map[string]interface{}{}} {
+		if _, err := ParseTimestamp(value); !errors.Is(err, ErrInvalidTimestamp) {
+			t.Errorf("ParseTimestamp(%v) error = %v, want ErrInvalidTimestamp", value, err)
+		}
+	}
+}
+
+func TestExtractWatermark(t *testing.T) {
+	records := []map[string]interface{}{
+		{"id": "1", "meta": map[string]interface{}{"updated_at": "2026-01-26T10:30:00Z"}},
+		{"id": "2", "meta": map[string]interface{}{"updated_at": "2026-01-26T11:45:00Z"}},
+		{"id": "3", "meta": map[string]interface{}{"updated_at": nil}},
+		{"id": "4"},
+		{"id": "5", "meta": map[string]interface{}{"updated_at": "2026-01-26T09:00:00Z"}},
+	}
+
+	got, err := ExtractWatermark(records, "meta.updated_at")
+	if err != nil {
+		t.Fatalf("ExtractWatermark() error = %v", err)
+	}
+	if want := time.Date(2026, 1, 26, 11, 45, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ExtractWatermark() = %v, want %v", got, want)
+	}
+
+	if _, err := ExtractWatermark(records[2:4], "meta.updated_at"); !errors.Is(err, ErrNoRecords) {
+		t.Errorf("ExtractWatermark() without timestamps error = %v, want ErrNoRecords", err)
+	}
+
+	invalid := []map[string]interface{}{{"updated_at": "not a date"}}
+	if _, err := ExtractWatermark(invalid, "updated_at"); !errors.Is(err, ErrInvalidTimestamp) {
+		t.Errorf("ExtractWatermark() with invalid value error = %v, want ErrInvalidTimestamp", err)
+	}
+}
+
+func TestLaterTimestamp(t *testing.T) {
+	earlier := time.Date(2026, 1, 26, 10, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	if got := LaterTimestamp(nil, earlier); !got.Equal(earlier) {
+		t.Errorf("LaterTimestamp(nil, earlier) = %v, want %v", got, earlier)
+	}
+	if got := LaterTimestamp(&later, earlier); !got.Equal(later) {
+		t.Errorf("LaterTimestamp(later, earlier) = %v, want %v", got, later)
+	}
+	if got := LaterTimestamp(&earlier, later); !got.Equal(later) {
+		t.Errorf("LaterTimestamp(earlier, later) = %v, want %v", got, later)
+	}
+}
@@ -0,0 +1,219 @@
+// Package ratelimit provides client-side rate limiting for HTTP-based modules.
+// It implements a token bucket that adapts to server rate limit headers
+// (X-RateLimit-Remaining / X-RateLimit-Reset and the IETF RateLimit headers).
+// Limiters are shared per host across all modules of the process.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Common errors
+var (
+	// ErrInvalidConfig is returned when the rate limit configuration is invalid.
+	ErrInvalidConfig = errors.New("invalid rate limit configuration")
+)
+
+// Config holds the rate limit configuration of a module.
+// Parsed from the module configuration's "rateLimit" field.
+type Config struct {
+	// RequestsPerSecond is the sustained request rate (required, > 0).
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+
+	// Burst is the maximum number of requests sent without waiting.
+	// Defaults to 1.
+	Burst int `json:"burst,omitempty"`
+}
+
+// ParseConfig parses a rate limit configuration from a map.
+// Returns an error if requestsPerSecond is missing or not positive.
+func ParseConfig(raw map[string]interface{}) (Config, error) {
+	cfg := Config{Burst: 1}
+
+	rps, ok := raw["requestsPerSecond"].(float64)
+	if !ok || rps <= 0 {
+		return Config{}, fmt.Errorf("%w: requestsPerSecond must be a positive number", ErrInvalidConfig)
+	}
+	cfg.RequestsPerSecond = rps
+
+	if burst, ok := raw["burst"].(float64); ok {
+		if burst < 1 {
+			return Config{}, fmt.Errorf("%w: burst must be at least 1", ErrInvalidConfig)
+		}
+		cfg.Burst = int(burst)
+	}
+
+	return cfg, nil
+}
+
+// Limiter is a thread-safe token bucket limiter.
+//
+// Besides the configured rate, the limiter adapts to the quota reported by the
+// server: when the remaining quota would be exhausted before the reset at the
+// configured rate, requests are paced to spread the remaining quota until the
+// reset; when the quota is exhausted, requests wait for the reset.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  int
+	tokens float64
+	last   time.Time
+
+	// Server-reported quota
+	serverRate  float64   // pace derived from the quota, 0 if none
+	serverUntil time.Time // end of the quota window (serverRate and pausedUntil)
+	pausedUntil time.Time // quota exhausted: no request before this time
+
+	now func() time.Time
+}
+
+// NewLimiter creates a limiter allowing requestsPerSecond requests per second
+// with the given burst. The bucket starts full.
+func NewLimiter(requestsPerSecond float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &Limiter{
+		rate:   requestsPerSecond,
+		burst:  burst,
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+	l.last = l.now()
+	return l
+}
+
+// Wait blocks until a request is allowed or the context is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available and returns 0.
+// Otherwise it returns the time to wait before trying again.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	rate := l.effectiveRate(now)
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+	if elapsed > 0 {
+		l.tokens = math.Min(float64(l.burst), l.tokens+elapsed*rate)
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / rate * float64(time.Second))
+}
+
+// effectiveRate returns the configured rate, lowered to the server-derived pace
+// while the server quota window is active.
+func (l *Limiter) effectiveRate(now time.Time) float64 {
+	if l.serverRate > 0 && now.Before(l.serverUntil) && l.serverRate < l.rate {
+		return l.serverRate
+	}
+	return l.rate
+}
+
+// Tighten lowers the limiter rate and burst to the given values if they are more restrictive.
+// Used when several modules with different configurations share the limiter of a host.
+func (l *Limiter) Tighten(requestsPerSecond float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if requestsPerSecond > 0 && requestsPerSecond < l.rate {
+		l.rate = requestsPerSecond
+	}
+	if burst >= 1 && burst < l.burst {
+		l.burst = burst
+		l.tokens = math.Min(l.tokens, float64(burst))
+	}
+}
+
+// ObserveQuota adapts the limiter to a server-reported quota: remaining
+// requests allowed until reset.
+func (l *Limiter) ObserveQuota(remaining int, reset time.Duration) {
+	if reset <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.serverUntil = now.Add(reset)
+
+	if remaining <= 0 {
+		// Quota exhausted: wait for the reset
+		l.pausedUntil = l.serverUntil
+		l.tokens = 0
+		return
+	}
+
+	l.serverRate = float64(remaining) / reset.Seconds()
+	l.tokens = math.Min(l.tokens, float64(remaining))
+}
+
+// Pause blocks all requests for the given duration (e.g. from a Retry-After header).
+func (l *Limiter) Pause(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := l.now().Add(d)
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+		l.tokens = 0
+	}
+}
+
+// Process-wide limiters, one per host
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Limiter)
+)
+
+// ForHost returns the shared limiter of a host (host[:port]), creating it with
+// cfg if it does not exist. If it exists, it is tightened to cfg so that the
+// most restrictive configuration of the modules targeting the host applies.
+func ForHost(host string, cfg Config) *Limiter {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if l, ok := registry[host]; ok {
+		l.Tighten(cfg.RequestsPerSecond, cfg.Burst)
+		return l
+	}
+
+	l := NewLimiter(cfg.RequestsPerSecond, cfg.Burst)
+	registry[host] = l
+	return l
+}
@@ -0,0 +1,246 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for deterministic limiter tests.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(rps float64, burst int) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 1, 26, 10, 0, 0, 0, time.UTC)}
+	l := NewLimiter(rps, burst)
+	l.now = clock.now
+	l.last = clock.t
+	return l, clock
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(map[string]interface{}{"requestsPerSecond": float64(5), "burst": float64(10)})
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if cfg.RequestsPerSecond != 5 || cfg.Burst != 10 {
+		t.Errorf("ParseConfig() = %+v, want rps 5 burst 10", cfg)
+	}
+
+	cfg, err = ParseConfig(map[string]interface{}{"requestsPerSecond": 0.5})
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if cfg.Burst != 1 {
+		t.Errorf("default burst = %d, want 1", cfg.Burst)
+	}
+
+	invalid := []map[string]interface{}{
+		{},
+		{"requestsPerSecond": float64(0)},
+		{"requestsPerSecond": float64(2), "burst": float64(0)},
+	}
+	for _, raw := range invalid {
+		if _, err := ParseConfig(raw); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("ParseConfig(%v) error = %v, want ErrInvalidConfig", raw, err)
+		}
+	}
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	l, clock := newTestLimiter(2, 2)
+
+	// Burst is available immediately
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("request %d: expected no wait within burst, got %v", i, d)
+		}
+	}
+
+	// Bucket empty: next token in 1/rate
+	if d := l.reserve(); d != 500*time.Millisecond {
+		t.Errorf("expected 500ms wait, got %v", d)
+	}
+
+	clock.t = clock.t.Add(500 * time.Millisecond)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("expected token after refill, got wait %v", d)
+	}
+}
+
+func TestLimiter_ObserveQuota(t *testing.T) {
+	l, clock := newTestLimiter(10, 1)
+
+	// 5 requests left for 10 seconds: pace at 0.5 req/s instead of 10
+	l.ObserveQuota(5, 10*time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Fatalf("expected first request allowed, got wait %v", d)
+	}
+	if d := l.reserve(); d != 2*time.Second {
+		t.Errorf("expected 2s wait at server pace, got %v", d)
+	}
+
+	// After the quota window, the configured rate applies again
+	clock.t = clock.t.Add(11 * time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("expected request allowed after reset, got wait %v", d)
+	}
+	if d := l.reserve(); d != 100*time.Millisecond {
+		t.Errorf("expected configured pace after reset, got wait %v", d)
+	}
+}
+
+func TestLimiter_QuotaExhausted(t *testing.T) {
+	l, clock := newTestLimiter(10, 5)
+
+	l.ObserveQuota(0, 30*time.Second)
+	if d := l.reserve(); d != 30*time.Second {
+		t.Errorf("expected wait until reset, got %v", d)
+	}
+
+	clock.t = clock.t.Add(30 * time.Second)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("expected request allowed at reset, got wait %v", d)
+	}
+}
+
+func TestLimiter_WaitContextCanceled(t *testing.T) {
+	l := NewLimiter(0.001, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestForHost_SharedAndTightened(t *testing.T) {
+	host := "shared.example.com:" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	first := ForHost(host, Config{RequestsPerSecond: 10, Burst: 5})
+	second := ForHost(host, Config{RequestsPerSecond: 2, Burst: 1})
+	if first != second {
+		t.Fatal("expected the same limiter for the same host")
+	}
+	if first.rate != 2 || first.burst != 1 {
+		t.Errorf("expected limiter tightened to 2 rps / burst 1, got %v / %d", first.rate, first.burst)
+	}
+
+	third := ForHost(host, Config{RequestsPerSecond: 50, Burst: 50})
+	if third.rate != 2 {
+		t.Errorf("expected looser config not to relax the limiter, got %v", third.rate)
+	}
+}
+
+func TestParseQuotaHeaders(t *testing.T) {
+	now := time.Date(2026, 1, 26, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		wantRemaining int
+		wantReset     time.Duration
+		wantOK        bool
+	}{
+		{
+			name:          "x-ratelimit seconds",
+			headers:       map[string]string{"X-RateLimit-Remaining": "42", "X-RateLimit-Reset": "60"},
+			wantRemaining: 42, wantReset: 60 * time.Second, wantOK: true,
+		},
+		{
+			name:          "x-ratelimit epoch",
+			headers:       map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Add(90*time.Second).Unix(), 10)},
+			wantRemaining: 0, wantReset: 90 * time.Second, wantOK: true,
+		},
+		{
+			name:          "ietf separate headers",
+			headers:       map[string]string{"RateLimit-Remaining": "7", "RateLimit-Reset": "15"},
+			wantRemaining: 7, wantReset: 15 * time.Second, wantOK: true,
+		},
+		{
+			name:          "ietf list field",
+			headers:       map[string]string{"RateLimit": "limit=100, remaining=3, reset=20"},
+			wantRemaining: 3, wantReset: 20 * time.Second, wantOK: true,
+		},
+		{
+			name:          "ietf structured field",
+			headers:       map[string]string{"RateLimit": `"default";r=9;t=5`},
+			wantRemaining: 9, wantReset: 5 * time.Second, wantOK: true,
+		},
+		{
+			name:    "missing reset",
+			headers: map[string]string{"X-RateLimit-Remaining": "5"},
+		},
+		{
+			name: "no headers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.headers {
+				header.Set(k, v)
+			}
+			remaining, reset, ok := ParseQuotaHeaders(header, now)
+			if ok != tt.wantOK || remaining != tt.wantRemaining || reset != tt.wantReset {
+				t.Errorf("ParseQuotaHeaders() = (%d, %v, %v), want (%d, %v, %v)",
+					remaining, reset, ok, tt.wantRemaining, tt.wantReset, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 26, 10, 0, 0, 0, time.UTC)
+	if d, ok := ParseRetryAfter("3", now); !ok || d != 3*time.Second {
+		t.Errorf("ParseRetryAfter(seconds) = %v, %v", d, ok)
+	}
+	date := now.Add(10 * time.Second).Format(http.TimeFormat)
+	if d, ok := ParseRetryAfter(date, now); !ok || d != 10*time.Second {
+		t.Errorf("ParseRetryAfter(date) = %v, %v", d, ok)
+	}
+	if _, ok := ParseRetryAfter("soon", now); ok {
+		t.Error("ParseRetryAfter(invalid) should not be ok")
+	}
+}
+
+func TestTransport_PacesRequestsAndObservesHeaders(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{}
+	WrapClient(client, Config{RequestsPerSecond: 1000, Burst: 10})
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// The first response reports an exhausted quota for 1 second
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("expected second request to wait for the quota reset, took %v", elapsed)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
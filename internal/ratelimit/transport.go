@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// epochThreshold distinguishes X-RateLimit-Reset values given as a Unix
+// timestamp from values given as a number of seconds.
+const epochThreshold = 1_000_000_000
+
+// Transport is an http.RoundTripper that waits for the shared limiter of the
+// request host before each request and adapts the limiter to the rate limit
+// headers of each response.
+type Transport struct {
+	// Base is the underlying transport. http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+
+	// Config is the rate limit configuration of the module.
+	Config Config
+}
+
+// NewTransport wraps base with rate limiting.
+func NewTransport(base http.RoundTripper, cfg Config) *Transport {
+	return &Transport{Base: base, Config: cfg}
+}
+
+// WrapClient wraps the transport of client with rate limiting.
+func WrapClient(client *http.Client, cfg Config) {
+	client.Transport = NewTransport(client.Transport, cfg)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := ForHost(req.URL.Host, t.Config)
+	if err := limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if remaining, reset, ok := ParseQuotaHeaders(resp.Header, time.Now()); ok {
+		limiter.ObserveQuota(remaining, reset)
+		if remaining <= 0 {
+			logger.Debug("rate limit quota exhausted, pausing requests",
+				"host", req.URL.Host,
+				"reset", reset.String(),
+			)
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if delay, ok := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			limiter.Pause(delay)
+			logger.Debug("rate limited by server, pausing requests",
+				"host", req.URL.Host,
+				"retry_after", delay.String(),
+			)
+		}
+	}
+
+	return resp, nil
+}
+
+// CloseIdleConnections closes idle connections of the underlying transport.
+func (t *Transport) CloseIdleConnections() {
+	if closer, ok := t.base().(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// ParseQuotaHeaders extracts the remaining quota and the time until reset from
+// rate limit response headers. Supported headers:
+//   - X-RateLimit-Remaining / X-RateLimit-Reset (reset in seconds or as a Unix timestamp)
+//   - RateLimit-Remaining / RateLimit-Reset (IETF draft, reset in seconds)
+//   - RateLimit: remaining=10, reset=30 or "policy";r=10;t=30 (IETF draft)
+func ParseQuotaHeaders(header http.Header, now time.Time) (int, time.Duration, bool) {
+	if remaining, reset, ok := parseQuotaPair(header.Get("X-RateLimit-Remaining"), header.Get("X-RateLimit-Reset"), now); ok {
+		return remaining, reset, true
+	}
+	if remaining, reset, ok := parseQuotaPair(header.Get("RateLimit-Remaining"), header.Get("RateLimit-Reset"), now); ok {
+		return remaining, reset, true
+	}
+	return parseRateLimitField(header.Get("RateLimit"))
+}
+
+// parseQuotaPair parses a remaining / reset header pair.
+func parseQuotaPair(remainingValue, resetValue string, now time.Time) (int, time.Duration, bool) {
+	if remainingValue == "" || resetValue == "" {
+		return 0, 0, false
+	}
+	remaining, err := strconv.Atoi(strings.TrimSpace(remainingValue))
+	if err != nil {
+		return 0, 0, false
+	}
+	reset, err := strconv.ParseFloat(strings.TrimSpace(resetValue), 64)
+	if err != nil || reset < 0 {
+		return 0, 0, false
+	}
+	if reset >= epochThreshold {
+		return remaining, time.Unix(int64(reset), 0).Sub(now), true
+	}
+	return remaining, time.Duration(reset * float64(time.Second)), true
+}
+
+// parseRateLimitField parses the IETF RateLimit field, either as
+// "limit=100, remaining=10, reset=30" or as a structured field "policy";r=10;t=30.
+func parseRateLimitField(value string) (int, time.Duration, bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+
+	remaining, reset := -1, -1
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, val, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(val))
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "remaining", "r":
+			remaining = n
+		case "reset", "t":
+			reset = n
+		}
+	}
+
+	if remaining < 0 || reset < 0 {
+		return 0, 0, false
+	}
+	return remaining, time.Duration(reset) * time.Second, true
+}
+
+// ParseRetryAfter parses a Retry-After header value (seconds or HTTP date).
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
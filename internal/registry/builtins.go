@@ -42,6 +42,14 @@ func registerBuiltinInputModules() {
 		}
 		return input.NewDatabaseInputFromConfig(cfg)
 	})
+
+	// graphql - GraphQL input module with Relay cursor pagination
+	RegisterInput("graphql", func(cfg *connector.ModuleConfig) (input.Module, error) {
+		if cfg == nil {
+			return nil, nil
+		}
+		return input.NewGraphQLInputFromConfig(cfg)
+	})
 }
 
 // registerBuiltinFilterModules registers all built-in filter module types.
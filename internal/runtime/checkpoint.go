@@ -0,0 +1,210 @@
+package runtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/modules/input"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// CheckpointInput is an optional interface for streaming input modules that
+// can resume an interrupted run. The runtime saves a checkpoint after each
+// chunk delivered by the output, and hands the checkpoint of an interrupted
+// run to the next run.
+type CheckpointInput interface {
+	// ResumeFrom is called before Stream with the checkpoint of an
+	// interrupted run: Stream then starts after its position.
+	ResumeFrom(checkpoint *persistence.Checkpoint) error
+
+	// Checkpoint returns the position after the last chunk handed to the
+	// chunk handler, with only the input-specific position fields set
+	// (page, offset, cursor...). Returns nil if the input cannot resume
+	// from the current position.
+	Checkpoint() *persistence.Checkpoint
+}
+
+// checkpointer saves the checkpoints of a streaming execution, on top of the
+// state of the last successful run.
+type checkpointer struct {
+	store      persistence.StateStore
+	input      CheckpointInput
+	pipelineID string
+	lock       *persistence.PipelineLock
+	keys       func() map[string]json.RawMessage
+
+	base         *persistence.State      // state of the last successful run
+	resumed      *persistence.Checkpoint // checkpoint this execution resumed from
+	runStartedAt time.Time
+	chunks       int
+	records      int
+	stored       bool // a checkpoint is stored and must be cleared on completion
+}
+
+// newCheckpointer enables checkpointing if the input streams and can resume,
+// and resumes an interrupted run if a checkpoint is stored. Returns nil if
+// checkpointing does not apply (no state store, dry-run, input not streaming).
+func (e *Executor) newCheckpointer(pipeline *connector.Pipeline, startedAt time.Time, result *connector.ExecutionResult, lock *persistence.PipelineLock, keys func() map[string]json.RawMessage) *checkpointer {
+	if e.stateStore == nil || e.dryRun {
+		return nil
+	}
+	streamer, ok := e.inputModule.(input.StreamingModule)
+	if !ok || !streamer.Streaming() {
+		return nil
+	}
+	cpInput, ok := e.inputModule.(CheckpointInput)
+	if !ok {
+		return nil
+	}
+
+	state, err := e.stateStore.Load(pipeline.ID)
+	if err != nil {
+		logger.Warn("failed to load checkpoint, checkpointing disabled for this execution",
+			slog.String("pipeline_id", pipeline.ID),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+
+	c := &checkpointer{
+		store:        e.stateStore,
+		input:        cpInput,
+		pipelineID:   pipeline.ID,
+		lock:         lock,
+		keys:         keys,
+		base:         state,
+		runStartedAt: startedAt,
+	}
+	if state == nil || state.Checkpoint == nil {
+		return c
+	}
+
+	checkpoint := state.Checkpoint
+	c.stored = true
+	if err := cpInput.ResumeFrom(checkpoint); err != nil {
+		logger.Warn("cannot resume from checkpoint, starting over",
+			slog.String("pipeline_id", pipeline.ID),
+			slog.String("error", err.Error()),
+		)
+		return c
+	}
+
+	c.resumed = checkpoint
+	c.runStartedAt = checkpoint.StartedAt
+	c.chunks = checkpoint.Chunks
+	c.records = checkpoint.RecordsProcessed
+	result.ResumedFrom = &connector.ResumeInfo{
+		RunStartedAt:     checkpoint.StartedAt,
+		CheckpointedAt:   checkpoint.UpdatedAt,
+		Chunks:           checkpoint.Chunks,
+		RecordsProcessed: checkpoint.RecordsProcessed,
+	}
+	logger.Info("resuming interrupted run from checkpoint",
+		slog.String("pipeline_id", pipeline.ID),
+		slog.Time("run_started_at", checkpoint.StartedAt),
+		slog.Time("checkpointed_at", checkpoint.UpdatedAt),
+		slog.Int("chunks_delivered", checkpoint.Chunks),
+		slog.Int("records_delivered", checkpoint.RecordsProcessed),
+	)
+	return c
+}
+
+// executionStart returns the start of the run the execution completes: the
+// interrupted run when resuming, so that the persisted timestamp covers the
+// records delivered before the interruption.
+func (c *checkpointer) executionStart(startedAt time.Time) time.Time {
+	if c == nil || c.resumed == nil {
+		return startedAt
+	}
+	return c.runStartedAt
+}
+
+// initialMarks returns the record marks of the chunks delivered before the
+// interruption.
+func (c *checkpointer) initialMarks() recordMarks {
+	if c == nil || c.resumed == nil {
+		return recordMarks{}
+	}
+	return recordMarks{lastID: c.resumed.LastID, watermark: c.resumed.Watermark}
+}
+
+// save saves a checkpoint after a chunk delivered by the output. Failures are
+// logged: the execution goes on, and an interrupted run would resume from the
+// previous checkpoint.
+func (c *checkpointer) save(marks recordMarks, recordsSent int) {
+	if c == nil {
+		return
+	}
+	c.chunks++
+	c.records += recordsSent
+
+	checkpoint := c.input.Checkpoint()
+	if checkpoint == nil {
+		return
+	}
+	if c.lock.Lost() {
+		// Another run took the pipeline over: its state must not be overwritten
+		return
+	}
+
+	now := time.Now()
+	checkpoint.StartedAt = c.runStartedAt
+	checkpoint.Chunks = c.chunks
+	checkpoint.RecordsProcessed = c.records
+	checkpoint.LastID = marks.lastID
+	checkpoint.Watermark = marks.watermark
+	checkpoint.UpdatedAt = now
+
+	state := &persistence.State{}
+	if c.base != nil {
+		base := *c.base
+		state = &base
+	}
+	if c.keys != nil {
+		state.Keys = c.keys()
+	}
+	state.Checkpoint = checkpoint
+	state.UpdatedAt = now
+
+	if err := c.store.Save(c.pipelineID, state); err != nil {
+		logger.Warn("failed to save checkpoint",
+			slog.String("pipeline_id", c.pipelineID),
+			slog.Int("chunk", c.chunks),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	c.stored = true
+	logger.Debug("checkpoint saved",
+		slog.String("pipeline_id", c.pipelineID),
+		slog.Int("chunks", c.chunks),
+		slog.Int("records_processed", c.records),
+	)
+}
+
+// finish clears the stored checkpoint once the run completed, unless the
+// final state save already replaced it.
+func (c *checkpointer) finish() {
+	if c == nil || !c.stored || c.lock.Lost() {
+		return
+	}
+
+	state, err := c.store.Load(c.pipelineID)
+	if err == nil && (state == nil || state.Checkpoint == nil) {
+		return
+	}
+	if err == nil {
+		state.Checkpoint = nil
+		state.UpdatedAt = time.Now()
+		err = c.store.Save(c.pipelineID, state)
+	}
+	if err != nil {
+		logger.Warn("failed to clear checkpoint after completed run, the next run resumes from it",
+			slog.String("pipeline_id", c.pipelineID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
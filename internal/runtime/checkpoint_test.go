@@ -0,0 +1,148 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cannectors/runtime/internal/modules/input"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// mockCheckpointInput is a streaming input resuming at a chunk index,
+// checkpointed in Checkpoint.Page.
+type mockCheckpointInput struct {
+	MockStreamingInputModule
+	position  int
+	resumed   *persistence.Checkpoint
+	resumeErr error
+}
+
+func (m *mockCheckpointInput) Stream(ctx context.Context, handle input.ChunkHandler) error {
+	start := 0
+	if m.resumed != nil {
+		start = m.resumed.Page
+	}
+	for i := start; i < len(m.chunks); i++ {
+		m.position = i + 1
+		if err := handle(ctx, m.chunks[i]); err != nil {
+			return err
+		}
+	}
+	return m.err
+}
+
+func (m *mockCheckpointInput) ResumeFrom(checkpoint *persistence.Checkpoint) error {
+	if m.resumeErr != nil {
+		return m.resumeErr
+	}
+	m.resumed = checkpoint
+	return nil
+}
+
+func (m *mockCheckpointInput) Checkpoint() *persistence.Checkpoint {
+	return &persistence.Checkpoint{Page: m.position}
+}
+
+var _ CheckpointInput = (*mockCheckpointInput)(nil)
+
+func TestExecutor_Checkpoint_ResumesInterruptedRun(t *testing.T) {
+	stateStore := persistence.NewFileStateStore(t.TempDir())
+	pipeline := &connector.Pipeline{ID: "checkpoint-resume", Name: "Checkpoint", Version: "1.0.0", Enabled: true}
+
+	// First run fails on the second chunk: the first one is checkpointed
+	first := &mockCheckpointInput{MockStreamingInputModule: MockStreamingInputModule{chunks: streamingChunks()}}
+	executor := NewExecutorWithModules(first, nil, &chunkRecordingOutput{failAt: 2, sendErr: errors.New("destination unavailable")}, false)
+	executor.SetStateStore(stateStore)
+	failed, err := executor.Execute(pipeline)
+	if err == nil {
+		t.Fatal("expected first run to fail")
+	}
+
+	state, err := stateStore.Load(pipeline.ID)
+	if err != nil || state == nil || state.Checkpoint == nil {
+		t.Fatalf("Load = %+v, %v, want a checkpoint", state, err)
+	}
+	cp := state.Checkpoint
+	if cp.Page != 1 || cp.Chunks != 1 || cp.RecordsProcessed != 2 || !cp.StartedAt.Equal(failed.StartedAt) {
+		t.Errorf("checkpoint = %+v, want page 1 after 1 chunk of 2 records, started at %v", cp, failed.StartedAt)
+	}
+
+	// Second run resumes after the checkpointed chunk
+	second := &mockCheckpointInput{MockStreamingInputModule: MockStreamingInputModule{chunks: streamingChunks()}}
+	output := &chunkRecordingOutput{}
+	executor = NewExecutorWithModules(second, nil, output, false)
+	executor.SetStateStore(stateStore)
+	result, err := executor.Execute(pipeline)
+	if err != nil || result.Status != StatusSuccess {
+		t.Fatalf("Execute = %+v, %v, want success", result, err)
+	}
+	if len(output.sends) != 2 || result.RecordsProcessed != 3 {
+		t.Errorf("resumed run sent %v (%d records), want chunks [2 1]", output.sends, result.RecordsProcessed)
+	}
+	r := result.ResumedFrom
+	if r == nil || r.Chunks != 1 || r.RecordsProcessed != 2 || !r.RunStartedAt.Equal(failed.StartedAt) {
+		t.Errorf("ResumedFrom = %+v, want 1 chunk of 2 records of the first run", r)
+	}
+
+	// The completed run clears the checkpoint
+	state, err = stateStore.Load(pipeline.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != nil && state.Checkpoint != nil {
+		t.Errorf("checkpoint not cleared after completed run: %+v", state.Checkpoint)
+	}
+
+	// A new run starts from the beginning
+	third := &mockCheckpointInput{MockStreamingInputModule: MockStreamingInputModule{chunks: streamingChunks()}}
+	executor = NewExecutorWithModules(third, nil, &chunkRecordingOutput{}, false)
+	executor.SetStateStore(stateStore)
+	result, err = executor.Execute(pipeline)
+	if err != nil || result.ResumedFrom != nil || result.RecordsProcessed != 5 {
+		t.Errorf("Execute = %+v, %v, want full run of 5 records", result, err)
+	}
+}
+
+func TestExecutor_Checkpoint_InvalidCheckpointStartsOver(t *testing.T) {
+	stateStore := persistence.NewFileStateStore(t.TempDir())
+	pipeline := &connector.Pipeline{ID: "checkpoint-invalid", Name: "Checkpoint", Version: "1.0.0", Enabled: true}
+	if err := stateStore.Save(pipeline.ID, &persistence.State{Checkpoint: &persistence.Checkpoint{Page: 2, Chunks: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	mockInput := &mockCheckpointInput{
+		MockStreamingInputModule: MockStreamingInputModule{chunks: streamingChunks()},
+		resumeErr:                errors.New("pagination changed"),
+	}
+	executor := NewExecutorWithModules(mockInput, nil, &chunkRecordingOutput{}, false)
+	executor.SetStateStore(stateStore)
+	result, err := executor.Execute(pipeline)
+	if err != nil || result.ResumedFrom != nil || result.RecordsProcessed != 5 {
+		t.Fatalf("Execute = %+v, %v, want full run of 5 records", result, err)
+	}
+
+	state, err := stateStore.Load(pipeline.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != nil && state.Checkpoint != nil {
+		t.Errorf("checkpoint not cleared after completed run: %+v", state.Checkpoint)
+	}
+}
+
+func TestExecutor_Checkpoint_DryRun(t *testing.T) {
+	stateStore := persistence.NewFileStateStore(t.TempDir())
+	pipeline := &connector.Pipeline{ID: "checkpoint-dry-run", Name: "Checkpoint", Version: "1.0.0", Enabled: true}
+
+	mockInput := &mockCheckpointInput{MockStreamingInputModule: MockStreamingInputModule{chunks: streamingChunks()}}
+	executor := NewExecutorWithModules(mockInput, nil, &chunkRecordingOutput{}, true)
+	executor.SetStateStore(stateStore)
+	if _, err := executor.Execute(pipeline); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := stateStore.Exists(pipeline.ID); err != nil || exists {
+		t.Errorf("dry run saved state: exists=%v, err=%v", exists, err)
+	}
+}
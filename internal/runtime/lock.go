@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// SetLockOptions enables the per-pipeline lock: each execution holds the lock
+// of its pipeline in the state store, so that concurrent runs of a pipeline
+// (replicas, a cron job and a manual run) do not read and save the same state.
+// nil disables locking.
+func (e *Executor) SetLockOptions(opts *persistence.LockOptions) {
+	e.lockOptions = opts
+}
+
+// acquirePipelineLock acquires the lock of the pipeline if locking is enabled.
+// Returns nil, nil if locking is disabled. If another run holds the lock,
+// marks the result skipped and returns an error wrapping persistence.ErrLockHeld.
+func (e *Executor) acquirePipelineLock(ctx context.Context, pipeline *connector.Pipeline, result *connector.ExecutionResult, execCtx logger.ExecutionContext, startedAt time.Time) (*persistence.PipelineLock, error) {
+	if e.lockOptions == nil || e.stateStore == nil {
+		return nil, nil
+	}
+
+	lock, err := persistence.AcquireLock(ctx, e.stateStore, pipeline.ID, *e.lockOptions)
+	if err == nil {
+		return lock, nil
+	}
+
+	result.CompletedAt = time.Now()
+	if errors.Is(err, persistence.ErrLockHeld) {
+		result.Status = StatusSkipped
+		result.Error = &connector.ExecutionError{
+			Code:    ErrCodePipelineLocked,
+			Message: err.Error(),
+		}
+		logger.Warn("pipeline execution skipped, another run holds the pipeline lock",
+			slog.String("pipeline_id", pipeline.ID),
+			slog.Bool("waited", e.lockOptions.Wait),
+			slog.String("error", err.Error()),
+		)
+		e.handleExecutionFailure(execCtx, startedAt, StatusSkipped, 0)
+		return nil, err
+	}
+
+	result.Error = &connector.ExecutionError{
+		Code:    ErrCodePipelineLocked,
+		Message: err.Error(),
+	}
+	logger.Error("failed to acquire pipeline lock",
+		slog.String("pipeline_id", pipeline.ID),
+		slog.String("error", err.Error()),
+	)
+	e.handleExecutionFailure(execCtx, startedAt, StatusError, 0)
+	return nil, err
+}
+
+// releasePipelineLock releases the lock of the pipeline, logging failures:
+// an unreleased lock expires at the end of its lease.
+func (e *Executor) releasePipelineLock(pipelineID string, lock *persistence.PipelineLock) {
+	if err := lock.Release(); err != nil {
+		logger.Warn("failed to release pipeline lock, it expires at the end of its lease",
+			slog.String("pipeline_id", pipelineID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+func TestExecutor_PipelineLock(t *testing.T) {
+	stateStore := persistence.NewFileStateStore(t.TempDir())
+	pipeline := &connector.Pipeline{ID: "test-pipeline-lock", Name: "Test Pipeline", Version: "1.0.0", Enabled: true}
+	records := []map[string]interface{}{{"id": 1}}
+
+	// Another process holds the lock
+	if holder, err := stateStore.TryLock(pipeline.ID, "other-process", time.Minute); err != nil || holder != nil {
+		t.Fatalf("TryLock = %v, %v", holder, err)
+	}
+
+	inputModule := NewMockInputModule(records, nil)
+	executor := NewExecutorWithModules(inputModule, nil, NewMockOutputModule(nil), false)
+	executor.SetStateStore(stateStore)
+	executor.SetLockOptions(&persistence.LockOptions{})
+
+	result, err := executor.Execute(pipeline)
+	if err != nil {
+		t.Fatalf("skipped execution returned error: %v", err)
+	}
+	if result.Status != StatusSkipped || result.Error == nil || result.Error.Code != ErrCodePipelineLocked {
+		t.Errorf("result = %+v, want skipped with %s", result, ErrCodePipelineLocked)
+	}
+	if inputModule.fetchCalled {
+		t.Error("input was fetched while another run holds the lock")
+	}
+
+	// Once released, the run executes and releases the lock
+	if err := stateStore.Unlock(pipeline.ID, "other-process"); err != nil {
+		t.Fatal(err)
+	}
+	inputModule = NewMockInputModule(records, nil)
+	executor = NewExecutorWithModules(inputModule, nil, NewMockOutputModule(nil), false)
+	executor.SetStateStore(stateStore)
+	executor.SetLockOptions(&persistence.LockOptions{})
+
+	result, err = executor.Execute(pipeline)
+	if err != nil || result.Status != StatusSuccess {
+		t.Fatalf("Execute = %+v, %v, want success", result, err)
+	}
+	if holder, err := stateStore.TryLock(pipeline.ID, "other-process", time.Minute); err != nil || holder != nil {
+		t.Errorf("lock not released after execution: %v, %v", holder, err)
+	}
+}
+
+func TestExecutor_PipelineLock_StoreFailure(t *testing.T) {
+	executor := NewExecutorWithModules(NewMockInputModule(nil, nil), nil, NewMockOutputModule(nil), false)
+	executor.SetStateStore(failingLockStore{persistence.NewFileStateStore(t.TempDir())})
+	executor.SetLockOptions(&persistence.LockOptions{})
+
+	result, err := executor.Execute(&connector.Pipeline{ID: "p", Name: "P", Version: "1.0.0", Enabled: true})
+	if err == nil || result.Status != StatusError || result.Error == nil || result.Error.Code != ErrCodePipelineLocked {
+		t.Errorf("Execute = %+v, %v, want lock error", result, err)
+	}
+}
+
+// failingLockStore is a state store whose locks cannot be acquired.
+type failingLockStore struct {
+	*persistence.FileStateStore
+}
+
+func (failingLockStore) TryLock(string, string, time.Duration) (*persistence.LockHolder, error) {
+	return nil, errors.New("store unavailable")
+}
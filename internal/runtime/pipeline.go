@@ -4,6 +4,7 @@ package runtime
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -24,6 +25,10 @@ const (
 	ErrCodeFilterFailed = "FILTER_FAILED"
 	ErrCodeOutputFailed = "OUTPUT_FAILED"
 	ErrCodeInvalidInput = "INVALID_INPUT"
+
+	// ErrCodePipelineLocked is set on executions skipped (or failed) because
+	// the pipeline lock could not be acquired.
+	ErrCodePipelineLocked = "PIPELINE_LOCKED"
 )
 
 // Execution status values
@@ -31,6 +36,7 @@ const (
 	StatusSuccess = "success"
 	StatusError   = "error"
 	StatusPartial = "partial"
+	StatusSkipped = "skipped"
 )
 
 // filterResult holds the result of filter module execution
@@ -67,7 +73,7 @@ type StatePersistentInput interface {
 
 	// SetStateStore sets the state store to use for persistence.
 	// If not called, the input module will use its own state store.
-	SetStateStore(store *persistence.StateStore)
+	SetStateStore(store persistence.StateStore)
 
 	// LoadState loads the last persisted state for this pipeline.
 	LoadState() (*persistence.State, error)
@@ -79,6 +85,20 @@ type StatePersistentInput interface {
 	GetLastState() *persistence.State
 }
 
+// ConditionalInput is an optional interface for input modules that send
+// conditional requests. ResponseValidators returns the ETag and Last-Modified
+// response headers of the last fetch, persisted for the next execution.
+type ConditionalInput interface {
+	ResponseValidators() (etag, lastModified string)
+}
+
+// CursorInput is an optional interface for input modules that track a
+// composite cursor (e.g. the keyset columns of the last database row read).
+// LastCursor returns nil when no record was read.
+type CursorInput interface {
+	LastCursor() map[string]interface{}
+}
+
 // Executor is responsible for executing pipeline configurations.
 // It orchestrates the execution flow: Input → Filters → Output.
 //
@@ -94,7 +114,9 @@ type Executor struct {
 	dryRun        bool
 
 	// State persistence
-	stateStore *persistence.StateStore
+	stateStore  persistence.StateStore
+	lockOptions *persistence.LockOptions
+	window      *persistence.Window // time range of backfill executions
 }
 
 // NewExecutor creates a new pipeline executor with only dry-run flag.
@@ -130,22 +152,67 @@ func NewExecutorWithModules(
 // SetStateStore sets the state store for state persistence.
 // If set and the input module supports state persistence, state will be
 // loaded before execution and persisted after successful execution.
-func (e *Executor) SetStateStore(store *persistence.StateStore) {
+func (e *Executor) SetStateStore(store persistence.StateStore) {
 	e.stateStore = store
 }
 
+// inputStateRefs keeps the input module's state interfaces for use after the
+// input module has been closed and released.
+type inputStateRefs struct {
+	previous    *persistence.State
+	conditional ConditionalInput
+	cursor      CursorInput
+	keys        map[string]json.RawMessage // named keys persisted by the last execution
+}
+
+// newInputStateRefs captures the state interfaces of the current input module.
+func (e *Executor) newInputStateRefs() inputStateRefs {
+	var refs inputStateRefs
+	if spInput, ok := e.inputModule.(StatePersistentInput); ok {
+		refs.previous = spInput.GetLastState()
+		if refs.previous != nil {
+			refs.keys = refs.previous.Keys
+		}
+	}
+	refs.conditional, _ = e.inputModule.(ConditionalInput)
+	refs.cursor, _ = e.inputModule.(CursorInput)
+	return refs
+}
+
 // persistState saves the execution state after successful pipeline execution.
-// It persists the execution start timestamp and/or last ID.
-// lastID is the ID extracted from raw records (before filters) to ensure the field path
-// matches the API response structure, not transformed records.
-func (e *Executor) persistState(pipelineID string, executionStart time.Time, lastID *string, config *persistence.StatePersistenceConfig) {
+// It persists the execution start timestamp (or record watermark), last ID,
+// response validators, composite cursor and/or named keys, in a single atomic save.
+// marks are extracted from raw records (before filters) to ensure the field paths
+// match the API response structure, not transformed records.
+func (e *Executor) persistState(pipelineID string, executionStart time.Time, marks recordMarks, keys map[string]json.RawMessage, config *persistence.StatePersistenceConfig, refs inputStateRefs) {
+	lastID := marks.lastID
 	state := &persistence.State{
 		PipelineID: pipelineID,
+		Keys:       keys,
 		UpdatedAt:  time.Now(),
 	}
 
 	// Set timestamp if enabled
-	if config.TimestampEnabled() {
+	if config.WatermarkEnabled() {
+		var previous *time.Time
+		if refs.previous != nil {
+			previous = refs.previous.LastTimestamp
+		}
+		if marks.watermark != nil {
+			// Never move the watermark backwards
+			state.LastTimestamp = persistence.LaterTimestamp(previous, *marks.watermark)
+		} else {
+			// No timestamped records: keep the previous watermark
+			state.LastTimestamp = previous
+		}
+		if state.LastTimestamp != nil {
+			logger.Debug("persisting record watermark",
+				slog.String("pipeline_id", pipelineID),
+				slog.String("timestamp_field", config.Timestamp.Field),
+				slog.String("timestamp", state.LastTimestamp.Format(time.RFC3339Nano)),
+			)
+		}
+	} else if config.TimestampEnabled() {
 		state.LastTimestamp = &executionStart
 		logger.Debug("persisting execution timestamp",
 			slog.String("pipeline_id", pipelineID),
@@ -161,10 +228,29 @@ func (e *Executor) persistState(pipelineID string, executionStart time.Time, las
 			slog.String("id_field", config.ID.Field),
 			slog.String("last_id", *lastID),
 		)
+	} else if config.IDEnabled() && refs.previous != nil && refs.previous.LastID != nil {
+		// No records (e.g. 304 Not Modified): keep the previous ID cursor
+		state.LastID = refs.previous.LastID
+	}
+
+	// Set response validators if conditional requests are enabled
+	if config.ConditionalEnabled() && refs.conditional != nil {
+		state.ETag, state.LastModified = refs.conditional.ResponseValidators()
+	}
+
+	// Set composite cursor if enabled
+	if config.CursorEnabled() {
+		if refs.cursor != nil {
+			state.Cursor = refs.cursor.LastCursor()
+		}
+		if state.Cursor == nil && refs.previous != nil {
+			// No records: keep the previous cursor
+			state.Cursor = refs.previous.Cursor
+		}
 	}
 
 	// Only save if we have something to persist
-	if state.LastTimestamp != nil || state.LastID != nil {
+	if state.LastTimestamp != nil || state.LastID != nil || state.ETag != "" || state.LastModified != "" || state.Cursor != nil || len(state.Keys) > 0 {
 		if err := e.stateStore.Save(pipelineID, state); err != nil {
 			logger.Warn("failed to persist state after execution",
 				slog.String("pipeline_id", pipelineID),
@@ -175,6 +261,8 @@ func (e *Executor) persistState(pipelineID string, executionStart time.Time, las
 				slog.String("pipeline_id", pipelineID),
 				slog.Bool("has_timestamp", state.LastTimestamp != nil),
 				slog.Bool("has_id", state.LastID != nil),
+				slog.Bool("has_cursor", state.Cursor != nil),
+				slog.Int("keys", len(state.Keys)),
 			)
 		}
 	}
@@ -332,6 +420,10 @@ func (e *Executor) executeDryRunPreview(pipelineID string, records []map[string]
 			BodyPreview: p.BodyPreview,
 			RecordCount: p.RecordCount,
 		}
+		if p.SQL != nil {
+			sqlPreview := connector.SQLPreview(*p.SQL)
+			previews[i].SQL = &sqlPreview
+		}
 	}
 
 	logger.Debug("dry-run preview generated",
@@ -400,6 +492,31 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, pipeline *connector.P
 	execCtx := e.createExecutionContext(pipeline)
 	logger.LogExecutionStart(execCtx)
 
+	// Windowed executions (backfill) leave the pipeline state untouched
+	if e.window != nil {
+		if err := e.applyWindow(pipeline, result, execCtx, startedAt); err != nil {
+			return result, err
+		}
+		stateStore := e.stateStore
+		e.stateStore = nil
+		defer func() {
+			e.stateStore = stateStore
+		}()
+	}
+
+	// Hold the pipeline lock while the state is read and saved.
+	// A run skipped because another run holds it is not a failure.
+	lock, err := e.acquirePipelineLock(ctx, pipeline, result, execCtx, startedAt)
+	if err != nil {
+		if errors.Is(err, persistence.ErrLockHeld) {
+			return result, nil
+		}
+		return result, err
+	}
+	if lock != nil {
+		defer e.releasePipelineLock(pipeline.ID, lock)
+	}
+
 	// Setup output module cleanup (deferred to end of execution)
 	if e.outputModule != nil {
 		defer e.closeModule(pipeline.ID, "output", e.outputModule)
@@ -407,18 +524,39 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, pipeline *connector.P
 
 	// Setup state persistence if input module supports it
 	persistenceConfig := e.setupStatePersistence(pipeline)
+	// Keep references: the input module is closed and released after input execution
+	stateRefs := e.newInputStateRefs()
+	// Hand modules their named state keys
+	keyedModules := e.keyedStateModules()
+	if persisted := e.restoreStateKeys(pipeline.ID, keyedModules); persisted != nil {
+		stateRefs.keys = persisted
+	}
+
+	// Checkpoint streamed chunks, resuming an interrupted run if any
+	cp := e.newCheckpointer(pipeline, startedAt, result, lock, func() map[string]json.RawMessage {
+		return collectStateKeys(pipeline.ID, stateRefs.keys, keyedModules)
+	})
+	// A resumed run completes the interrupted run, and persists its start
+	executionStart := cp.executionStart(startedAt)
 
 	// Execute pipeline stages (Input → Filter → Output)
-	// Extract ID from raw records immediately after input to free memory early
-	timings, lastID, err := e.executePipelineStages(ctx, pipeline, result, execCtx, startedAt, persistenceConfig)
+	// Extract ID and watermark from raw records immediately after input to free memory early
+	timings, marks, err := e.executePipelineStages(ctx, pipeline, result, execCtx, startedAt, persistenceConfig, cp)
 	if err != nil {
 		return result, err
 	}
 
-	// Persist state after successful execution (Input → Filter → Output all succeeded)
-	if persistenceConfig != nil && persistenceConfig.IsEnabled() && e.stateStore != nil {
-		e.persistState(pipeline.ID, startedAt, lastID, persistenceConfig)
+	// Persist state after successful execution (Input → Filter → Output all succeeded),
+	// unless another run took the lock over: its state must not be overwritten
+	if lock.Lost() {
+		logger.Error("state not saved, the pipeline lock was lost during execution",
+			slog.String("pipeline_id", pipeline.ID),
+		)
+	} else if e.stateStore != nil && (persistenceConfig.IsEnabled() || len(keyedModules) > 0) {
+		keys := collectStateKeys(pipeline.ID, stateRefs.keys, keyedModules)
+		e.persistState(pipeline.ID, executionStart, marks, keys, persistenceConfig, stateRefs)
 	}
+	cp.finish()
 
 	e.finalizeSuccessWithMetrics(result, startedAt, pipeline, timings)
 	return result, nil
@@ -463,6 +601,11 @@ func (e *Executor) setupStatePersistence(pipeline *connector.Pipeline) *persiste
 				spInput.SetStateStore(e.stateStore)
 			}
 		}
+		persistenceConfig = spInput.GetPersistenceConfig()
+		if e.window != nil {
+			// Windowed executions do not resume from the persisted state
+			return persistenceConfig
+		}
 		state, err := spInput.LoadState()
 		if err != nil {
 			// Log warning but continue - state loading failure is not fatal
@@ -478,14 +621,13 @@ func (e *Executor) setupStatePersistence(pipeline *connector.Pipeline) *persiste
 				slog.Bool("has_id", state.LastID != nil),
 			)
 		}
-		persistenceConfig = spInput.GetPersistenceConfig()
 	}
 	return persistenceConfig
 }
 
 // executePipelineStages executes Input, Filter, and Output modules in sequence.
-// Extracts ID from raw records immediately after input to free memory early.
-// Returns timings, last ID (extracted from raw records before filters), and any error encountered.
+// Extracts ID and watermark from raw records immediately after input to free memory early.
+// Returns timings, record marks (extracted from raw records before filters), and any error encountered.
 func (e *Executor) executePipelineStages(
 	ctx context.Context,
 	pipeline *connector.Pipeline,
@@ -493,9 +635,13 @@ func (e *Executor) executePipelineStages(
 	execCtx logger.ExecutionContext,
 	startedAt time.Time,
 	persistenceConfig *persistence.StatePersistenceConfig,
-) (stageTimings, *string, error) {
+	cp *checkpointer,
+) (stageTimings, recordMarks, error) {
+	if streamer, ok := e.inputModule.(input.StreamingModule); ok && streamer.Streaming() {
+		return e.executeStreamingStages(ctx, pipeline, result, execCtx, startedAt, persistenceConfig, streamer, cp)
+	}
+
 	var timings stageTimings
-	var lastID *string
 
 	// Execute Input module (returns duration measured inside)
 	rawRecords, inputDuration, err := e.executeInput(ctx, pipeline, result)
@@ -512,29 +658,12 @@ func (e *Executor) executePipelineStages(
 
 	if err != nil {
 		e.handleExecutionFailure(execCtx, startedAt, StatusError, 0)
-		return timings, nil, err
+		return timings, recordMarks{}, err
 	}
 
-	// Extract ID from raw records immediately (before filters) to free memory early
-	// This ensures the ID field path matches the API response structure, not transformed records
-	if persistenceConfig != nil && persistenceConfig.IDEnabled() && persistenceConfig.ID.Field != "" && len(rawRecords) > 0 {
-		extractedID, extractErr := persistence.ExtractLastID(rawRecords, persistenceConfig.ID.Field)
-		if extractErr != nil {
-			logger.Warn("failed to extract last ID for state persistence",
-				slog.String("pipeline_id", pipeline.ID),
-				slog.String("id_field", persistenceConfig.ID.Field),
-				slog.String("error", extractErr.Error()),
-			)
-			// Continue without ID - state will be persisted with timestamp only if enabled
-		} else {
-			lastID = &extractedID
-			logger.Debug("extracted last ID from raw records",
-				slog.String("pipeline_id", pipeline.ID),
-				slog.String("id_field", persistenceConfig.ID.Field),
-				slog.String("last_id", extractedID),
-			)
-		}
-	}
+	// Extract ID and watermark from raw records immediately (before filters) to free memory early
+	// This ensures the field paths match the API response structure, not transformed records
+	marks := extractRecordMarks(pipeline.ID, rawRecords, persistenceConfig)
 
 	// Execute Filter modules (returns duration measured inside)
 	filteredRecords, filterDuration, err := e.executeFiltersWithResult(ctx, pipeline, rawRecords, result)
@@ -542,7 +671,7 @@ func (e *Executor) executePipelineStages(
 	// rawRecords can now be garbage collected after filters start processing
 	if err != nil {
 		e.handleExecutionFailure(execCtx, startedAt, StatusError, len(rawRecords))
-		return timings, nil, err
+		return timings, recordMarks{}, err
 	}
 
 	// Generate dry-run preview if applicable
@@ -555,10 +684,84 @@ func (e *Executor) executePipelineStages(
 	timings.outputDuration = outputDuration
 	if err != nil {
 		e.handleExecutionFailure(execCtx, startedAt, StatusError, result.RecordsProcessed)
-		return timings, nil, err
+		return timings, recordMarks{}, err
+	}
+
+	return timings, marks, nil
+}
+
+// recordMarks holds the state extracted from raw records for persistence.
+type recordMarks struct {
+	// lastID is the ID of the last record, if ID persistence is enabled
+	lastID *string
+	// watermark is the maximum record timestamp, if watermark persistence is enabled
+	watermark *time.Time
+}
+
+// merge folds the marks of a later chunk of records into m.
+func (m *recordMarks) merge(next recordMarks) {
+	if next.lastID != nil {
+		m.lastID = next.lastID
+	}
+	if next.watermark != nil {
+		m.watermark = persistence.LaterTimestamp(m.watermark, *next.watermark)
+	}
+}
+
+// extractRecordMarks extracts the last ID and watermark of raw records for state persistence.
+func extractRecordMarks(pipelineID string, rawRecords []map[string]interface{}, persistenceConfig *persistence.StatePersistenceConfig) recordMarks {
+	return recordMarks{
+		lastID:    extractLastID(pipelineID, rawRecords, persistenceConfig),
+		watermark: extractWatermark(pipelineID, rawRecords, persistenceConfig),
+	}
+}
+
+// extractWatermark extracts the maximum timestamp of the watermark field of raw records.
+// Returns nil if watermark persistence is disabled, no record holds a timestamp or
+// extraction fails; the previous watermark is then kept.
+func extractWatermark(pipelineID string, rawRecords []map[string]interface{}, persistenceConfig *persistence.StatePersistenceConfig) *time.Time {
+	if !persistenceConfig.WatermarkEnabled() || len(rawRecords) == 0 {
+		return nil
+	}
+
+	watermark, err := persistence.ExtractWatermark(rawRecords, persistenceConfig.Timestamp.Field)
+	if err != nil {
+		if !errors.Is(err, persistence.ErrNoRecords) {
+			logger.Warn("failed to extract watermark for state persistence",
+				slog.String("pipeline_id", pipelineID),
+				slog.String("timestamp_field", persistenceConfig.Timestamp.Field),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil
+	}
+	return &watermark
+}
+
+// extractLastID extracts the ID of the last raw record for state persistence.
+// Returns nil if ID persistence is disabled, there are no records or extraction fails.
+func extractLastID(pipelineID string, rawRecords []map[string]interface{}, persistenceConfig *persistence.StatePersistenceConfig) *string {
+	if persistenceConfig == nil || !persistenceConfig.IDEnabled() || persistenceConfig.ID.Field == "" || len(rawRecords) == 0 {
+		return nil
+	}
+
+	extractedID, err := persistence.ExtractLastID(rawRecords, persistenceConfig.ID.Field)
+	if err != nil {
+		logger.Warn("failed to extract last ID for state persistence",
+			slog.String("pipeline_id", pipelineID),
+			slog.String("id_field", persistenceConfig.ID.Field),
+			slog.String("error", err.Error()),
+		)
+		// Continue without ID - state will be persisted with timestamp only if enabled
+		return nil
 	}
 
-	return timings, lastID, nil
+	logger.Debug("extracted last ID from raw records",
+		slog.String("pipeline_id", pipelineID),
+		slog.String("id_field", persistenceConfig.ID.Field),
+		slog.String("last_id", extractedID),
+	)
+	return &extractedID
 }
 
 // handleExecutionFailure logs execution end on failure.
@@ -728,9 +931,7 @@ func (e *Executor) executeOutputWithResult(ctx context.Context, pipeline *connec
 		result.RecordsProcessed = outputRes.recordsSent
 		result.RecordsFailed = outputRes.recordsFailed
 		result.Error = buildExecutionError(ErrCodeOutputFailed, "output", outputRes.err)
-		if p, ok := e.outputModule.(connector.RetryInfoProvider); ok {
-			result.RetryInfo = p.GetRetryInfo()
-		}
+		e.collectOutputInfo(result)
 		logger.LogStageEnd(stageCtx, len(records), outputDuration, &logger.ExecutionError{
 			Code:    ErrCodeOutputFailed,
 			Message: outputRes.err.Error(),
@@ -738,18 +939,41 @@ func (e *Executor) executeOutputWithResult(ctx context.Context, pipeline *connec
 		return outputDuration, fmt.Errorf("executing output module: %w", outputRes.err)
 	}
 
-	if p, ok := e.outputModule.(connector.RetryInfoProvider); ok {
-		result.RetryInfo = p.GetRetryInfo()
-	}
+	e.collectOutputInfo(result)
 	logger.LogStageEnd(stageCtx, outputRes.recordsSent, outputDuration, nil)
 	result.RecordsProcessed = outputRes.recordsSent
 	return outputDuration, nil
 }
 
-// finalizeSuccessWithMetrics marks the execution as successful and logs completion with detailed metrics.
+// collectOutputInfo adds the retry information, the returned records and the
+// failed records of the last output Send to result. Returned and failed
+// records accumulate across the chunks of a streaming execution.
+func (e *Executor) collectOutputInfo(result *connector.ExecutionResult) {
+	if p, ok := e.outputModule.(connector.RetryInfoProvider); ok {
+		result.RetryInfo = p.GetRetryInfo()
+	}
+	if p, ok := e.outputModule.(connector.ReturnedRecordsProvider); ok {
+		result.ReturnedRecords = append(result.ReturnedRecords, p.ReturnedRecords()...)
+	}
+	if p, ok := e.outputModule.(connector.RecordFailureProvider); ok {
+		result.FailedRecords = append(result.FailedRecords, p.FailedRecords()...)
+	}
+}
+
+// completionStatus returns the status of an execution that completed: partial
+// when the output module skipped failed records, success otherwise.
+func completionStatus(result *connector.ExecutionResult) string {
+	if len(result.FailedRecords) > 0 {
+		return StatusPartial
+	}
+	return StatusSuccess
+}
+
+// finalizeSuccessWithMetrics marks the execution as successful (or partial, with failed
+// records) and logs completion with detailed metrics.
 func (e *Executor) finalizeSuccessWithMetrics(result *connector.ExecutionResult, startedAt time.Time, pipeline *connector.Pipeline, timings stageTimings) {
-	result.Status = StatusSuccess
-	result.RecordsFailed = 0
+	result.Status = completionStatus(result)
+	result.RecordsFailed = len(result.FailedRecords)
 	result.CompletedAt = time.Now()
 	result.Error = nil
 
@@ -777,13 +1001,13 @@ func (e *Executor) finalizeSuccessWithMetrics(result *connector.ExecutionResult,
 		FilterDuration:   timings.filterDuration,
 		OutputDuration:   timings.outputDuration,
 		RecordsProcessed: recordsProcessed,
-		RecordsFailed:    0,
+		RecordsFailed:    result.RecordsFailed,
 		RecordsPerSecond: recordsPerSecond,
 		AvgRecordTime:    avgRecordTime,
 	}
 
 	// Log execution end (includes metrics via LogMetrics call)
-	logger.LogExecutionEnd(ctx, StatusSuccess, recordsProcessed, totalDuration)
+	logger.LogExecutionEnd(ctx, result.Status, recordsProcessed, totalDuration)
 	logger.LogMetrics(ctx, metrics)
 }
 
@@ -871,18 +1095,14 @@ func (e *Executor) ExecuteWithRecordsContext(ctx context.Context, pipeline *conn
 		result.RecordsProcessed = outputRes.recordsSent
 		result.RecordsFailed = outputRes.recordsFailed
 		result.Error = buildExecutionError(ErrCodeOutputFailed, "output", outputRes.err)
-		if p, ok := e.outputModule.(connector.RetryInfoProvider); ok {
-			result.RetryInfo = p.GetRetryInfo()
-		}
+		e.collectOutputInfo(result)
 		return result, fmt.Errorf("executing output module: %w", outputRes.err)
 	}
-	if p, ok := e.outputModule.(connector.RetryInfoProvider); ok {
-		result.RetryInfo = p.GetRetryInfo()
-	}
+	e.collectOutputInfo(result)
 
-	result.Status = StatusSuccess
+	result.Status = completionStatus(result)
 	result.RecordsProcessed = outputRes.recordsSent
-	result.RecordsFailed = 0
+	result.RecordsFailed = len(result.FailedRecords)
 	result.CompletedAt = time.Now()
 	result.Error = nil
 
@@ -890,9 +1110,9 @@ func (e *Executor) ExecuteWithRecordsContext(ctx context.Context, pipeline *conn
 
 	logger.Info("pipeline execution completed",
 		slog.String("pipeline_id", pipeline.ID),
-		slog.String("status", StatusSuccess),
+		slog.String("status", result.Status),
 		slog.Int("records_processed", outputRes.recordsSent),
-		slog.Int("records_failed", 0),
+		slog.Int("records_failed", result.RecordsFailed),
 		slog.Duration("total_duration", totalDuration),
 		slog.Bool("dry_run", e.dryRun),
 	)
@@ -365,6 +365,67 @@ func TestExecutor_ExecuteWithRecords_Success(t *testing.T) {
 	}
 }
 
+// partialOutputModule skips the records with a "reject" field, reporting
+// them as failed (connector.RecordFailureProvider)
+type partialOutputModule struct {
+	failed []connector.RecordFailure
+}
+
+func (m *partialOutputModule) Send(_ context.Context, records []map[string]interface{}) (int, error) {
+	m.failed = nil
+	for _, record := range records {
+		if reason, ok := record["reject"].(string); ok {
+			m.failed = append(m.failed, connector.RecordFailure{Record: record, Error: reason})
+		}
+	}
+	return len(records) - len(m.failed), nil
+}
+
+func (m *partialOutputModule) FailedRecords() []connector.RecordFailure {
+	return m.failed
+}
+
+func (m *partialOutputModule) Close() error {
+	return nil
+}
+
+func TestExecutor_Execute_PartialFailure(t *testing.T) {
+	records := []map[string]interface{}{
+		{"id": "1"},
+		{"id": "2", "reject": "duplicate id"},
+		{"id": "3"},
+	}
+	pipeline := &connector.Pipeline{ID: "test-pipeline", Name: "Test Pipeline", Version: "1.0.0", Enabled: true}
+
+	check := func(t *testing.T, result *connector.ExecutionResult, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("execution returned unexpected error: %v", err)
+		}
+		if result.Status != StatusPartial {
+			t.Errorf("Status = %q, want %q", result.Status, StatusPartial)
+		}
+		if result.RecordsProcessed != 2 || result.RecordsFailed != 1 {
+			t.Errorf("RecordsProcessed = %d, RecordsFailed = %d, want 2 and 1", result.RecordsProcessed, result.RecordsFailed)
+		}
+		if len(result.FailedRecords) != 1 || result.FailedRecords[0].Record["id"] != "2" || result.FailedRecords[0].Error != "duplicate id" {
+			t.Errorf("FailedRecords = %+v, want record 2", result.FailedRecords)
+		}
+	}
+
+	t.Run("Execute", func(t *testing.T) {
+		executor := NewExecutorWithModules(NewMockInputModule(records, nil), nil, &partialOutputModule{}, false)
+		result, err := executor.Execute(pipeline)
+		check(t, result, err)
+	})
+
+	t.Run("ExecuteWithRecords", func(t *testing.T) {
+		executor := NewExecutorWithModules(nil, nil, &partialOutputModule{}, false)
+		result, err := executor.ExecuteWithRecords(pipeline, records)
+		check(t, result, err)
+	})
+}
+
 func TestExecutor_Execute_WithFilters(t *testing.T) {
 	// Arrange
 	inputData := []map[string]interface{}{
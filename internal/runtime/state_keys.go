@@ -0,0 +1,128 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cannectors/runtime/internal/logger"
+)
+
+// KeyedStateModule is an optional interface for input, filter and output
+// modules that persist their own named state between executions, such as
+// per-value cursors, dedupe sets or change hashes.
+//
+// RestoreStateKeys is called before execution with the keys persisted by
+// the last successful execution (empty on the first execution), or by the
+// last checkpoint of an interrupted streamed run. StateKeys is called after a
+// successful execution, and at each checkpoint of a streamed run; the
+// returned keys replace the previous ones, and a nil value deletes a key.
+// Keys of all modules are committed atomically with the input state, and
+// never after a failed execution (except with its checkpoint).
+//
+// Key names are local to the module: the runtime namespaces them per module
+// ("input.<name>", "filters.<index>.<name>", "output.<name>"). Windowed
+// (backfill) executions neither restore nor commit keys.
+type KeyedStateModule interface {
+	RestoreStateKeys(keys map[string]json.RawMessage) error
+	StateKeys() (map[string]json.RawMessage, error)
+}
+
+// keyedModule is a KeyedStateModule with the namespace of its keys.
+type keyedModule struct {
+	prefix string
+	module KeyedStateModule
+}
+
+// keyedStateModules returns the modules persisting named state.
+func (e *Executor) keyedStateModules() []keyedModule {
+	var modules []keyedModule
+	if m, ok := e.inputModule.(KeyedStateModule); ok {
+		modules = append(modules, keyedModule{prefix: "input.", module: m})
+	}
+	for i, f := range e.filterModules {
+		if m, ok := f.(KeyedStateModule); ok {
+			modules = append(modules, keyedModule{prefix: fmt.Sprintf("filters.%d.", i), module: m})
+		}
+	}
+	if m, ok := e.outputModule.(KeyedStateModule); ok {
+		modules = append(modules, keyedModule{prefix: "output.", module: m})
+	}
+	return modules
+}
+
+// restoreStateKeys loads the named keys of the pipeline and hands each module
+// its own keys. Returns all persisted keys, carried forward on commit.
+// Failures are logged: the modules then start without state.
+func (e *Executor) restoreStateKeys(pipelineID string, modules []keyedModule) map[string]json.RawMessage {
+	if e.stateStore == nil || len(modules) == 0 {
+		return nil
+	}
+
+	state, err := e.stateStore.Load(pipelineID)
+	if err != nil {
+		logger.Warn("failed to load state keys, modules start without state",
+			slog.String("pipeline_id", pipelineID),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	var persisted map[string]json.RawMessage
+	if state != nil {
+		persisted = state.Keys
+	}
+
+	for _, m := range modules {
+		keys := make(map[string]json.RawMessage)
+		for name, value := range persisted {
+			if strings.HasPrefix(name, m.prefix) {
+				keys[strings.TrimPrefix(name, m.prefix)] = value
+			}
+		}
+		if err := m.module.RestoreStateKeys(keys); err != nil {
+			logger.Warn("failed to restore module state keys",
+				slog.String("pipeline_id", pipelineID),
+				slog.String("module", strings.TrimSuffix(m.prefix, ".")),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return persisted
+}
+
+// collectStateKeys merges the keys returned by each module over the
+// previously persisted keys. A module failing to return its keys keeps its
+// previous keys.
+func collectStateKeys(pipelineID string, previous map[string]json.RawMessage, modules []keyedModule) map[string]json.RawMessage {
+	if len(previous) == 0 && len(modules) == 0 {
+		return nil
+	}
+
+	keys := make(map[string]json.RawMessage, len(previous))
+	for name, value := range previous {
+		keys[name] = value
+	}
+	for _, m := range modules {
+		updates, err := m.module.StateKeys()
+		if err != nil {
+			logger.Warn("failed to collect module state keys, keeping previous keys",
+				slog.String("pipeline_id", pipelineID),
+				slog.String("module", strings.TrimSuffix(m.prefix, ".")),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		for name, value := range updates {
+			if value == nil {
+				delete(keys, m.prefix+name)
+				continue
+			}
+			keys[m.prefix+name] = value
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return keys
+}
@@ -0,0 +1,165 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cannectors/runtime/internal/modules/filter"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// mockDedupeFilter drops records already seen in previous executions,
+// persisting the seen IDs as a named state key.
+type mockDedupeFilter struct {
+	seen     map[string]bool
+	restored bool
+}
+
+func (m *mockDedupeFilter) Process(_ context.Context, records []map[string]interface{}) ([]map[string]interface{}, error) {
+	var out []map[string]interface{}
+	for _, record := range records {
+		id := fmt.Sprint(record["id"])
+		if m.seen[id] {
+			continue
+		}
+		m.seen[id] = true
+		out = append(out, record)
+	}
+	return out, nil
+}
+
+func (m *mockDedupeFilter) RestoreStateKeys(keys map[string]json.RawMessage) error {
+	m.restored = true
+	m.seen = make(map[string]bool)
+	if raw, ok := keys["seen"]; ok {
+		return json.Unmarshal(raw, &m.seen)
+	}
+	return nil
+}
+
+func (m *mockDedupeFilter) StateKeys() (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(m.seen)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]json.RawMessage{"seen": raw}, nil
+}
+
+var (
+	_ filter.Module    = (*mockDedupeFilter)(nil)
+	_ KeyedStateModule = (*mockDedupeFilter)(nil)
+)
+
+// mockKeyedInput counts the successful executions, persisted as a named
+// state key. Its keys are collected after it has been closed.
+type mockKeyedInput struct {
+	MockInputModule
+	runs int
+}
+
+func (m *mockKeyedInput) RestoreStateKeys(keys map[string]json.RawMessage) error {
+	if raw, ok := keys["runs"]; ok {
+		return json.Unmarshal(raw, &m.runs)
+	}
+	return nil
+}
+
+func (m *mockKeyedInput) StateKeys() (map[string]json.RawMessage, error) {
+	if !m.closed {
+		return nil, errors.New("keys collected before the input was closed")
+	}
+	return map[string]json.RawMessage{"runs": json.RawMessage(fmt.Sprint(m.runs + 1))}, nil
+}
+
+var _ KeyedStateModule = (*mockKeyedInput)(nil)
+
+// mockKeyedOutput persists the number of records it sent, and deletes its
+// "pending" key once set.
+type mockKeyedOutput struct {
+	MockOutputModule
+	restored map[string]json.RawMessage
+}
+
+func (m *mockKeyedOutput) RestoreStateKeys(keys map[string]json.RawMessage) error {
+	m.restored = keys
+	return nil
+}
+
+func (m *mockKeyedOutput) StateKeys() (map[string]json.RawMessage, error) {
+	keys := map[string]json.RawMessage{"sent": json.RawMessage(fmt.Sprint(len(m.sentRecords)))}
+	if _, ok := m.restored["pending"]; ok {
+		keys["pending"] = nil
+	} else {
+		keys["pending"] = json.RawMessage(`true`)
+	}
+	return keys, nil
+}
+
+func TestExecutor_StateKeys(t *testing.T) {
+	stateStore, err := persistence.NewSQLiteStateStore(t.TempDir() + "/state.db")
+	if err != nil {
+		t.Fatalf("NewSQLiteStateStore failed: %v", err)
+	}
+	defer func() {
+		_ = stateStore.Close()
+	}()
+	pipeline := &connector.Pipeline{ID: "test-pipeline-keys", Name: "Test Pipeline", Version: "1.0.0", Enabled: true}
+
+	run := func(records []map[string]interface{}, outputErr error) (*mockDedupeFilter, *mockKeyedOutput, error) {
+		dedupe := &mockDedupeFilter{}
+		output := &mockKeyedOutput{MockOutputModule: MockOutputModule{err: outputErr}}
+		input := &mockKeyedInput{MockInputModule: *NewMockInputModule(records, nil)}
+		executor := NewExecutorWithModules(input, []filter.Module{NewMockFilterModule(nil), dedupe}, output, false)
+		executor.SetStateStore(stateStore)
+		_, err := executor.Execute(pipeline)
+		return dedupe, output, err
+	}
+
+	// First execution: modules start without state
+	dedupe, output, err := run([]map[string]interface{}{{"id": 1}, {"id": 2}}, nil)
+	if err != nil {
+		t.Fatalf("Execution 1 failed: %v", err)
+	}
+	if !dedupe.restored || len(output.restored) != 0 {
+		t.Errorf("Execution 1 restored = %v, %v, want empty state", dedupe.restored, output.restored)
+	}
+
+	state, err := stateStore.Load(pipeline.ID)
+	if err != nil || state == nil {
+		t.Fatalf("Load = %v, %v", state, err)
+	}
+	if string(state.Keys["input.runs"]) != "1" || string(state.Keys["filters.1.seen"]) != `{"1":true,"2":true}` ||
+		string(state.Keys["output.sent"]) != "2" || string(state.Keys["output.pending"]) != "true" {
+		t.Errorf("Execution 1 keys = %v", state.Keys)
+	}
+
+	// Failed execution: keys are not committed
+	if _, _, err := run([]map[string]interface{}{{"id": 3}}, errors.New("output down")); err == nil {
+		t.Fatal("Execution 2 succeeded, want output error")
+	}
+
+	// Next execution resumes from the last committed keys
+	_, output, err = run([]map[string]interface{}{{"id": 2}, {"id": 3}}, nil)
+	if err != nil {
+		t.Fatalf("Execution 3 failed: %v", err)
+	}
+	if len(output.sentRecords) != 1 || output.sentRecords[0]["id"] != 3 {
+		t.Errorf("Execution 3 sent %v, want only id 3", output.sentRecords)
+	}
+	if string(output.restored["sent"]) != "2" {
+		t.Errorf("Execution 3 output restored %v, want sent=2", output.restored)
+	}
+
+	state, _ = stateStore.Load(pipeline.ID)
+	if string(state.Keys["input.runs"]) != "2" || string(state.Keys["filters.1.seen"]) != `{"1":true,"2":true,"3":true}` ||
+		string(state.Keys["output.sent"]) != "1" {
+		t.Errorf("Execution 3 keys = %v", state.Keys)
+	}
+	if _, ok := state.Keys["output.pending"]; ok {
+		t.Errorf("Execution 3 keys = %v, want output.pending deleted", state.Keys)
+	}
+}
@@ -18,7 +18,7 @@ import (
 // The ID should be extracted from raw records (before filters), not filtered records.
 func TestExecutor_StatePersistence_ID_WithFilterRenaming(t *testing.T) {
 	tmpDir := t.TempDir()
-	stateStore := persistence.NewStateStore(tmpDir)
+	stateStore := persistence.NewFileStateStore(tmpDir)
 
 	// Create test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -127,7 +127,7 @@ func TestExecutor_StatePersistence_ID_WithFilterRenaming(t *testing.T) {
 // works correctly when filters remove the ID field entirely.
 func TestExecutor_StatePersistence_ID_WithFilterRemoving(t *testing.T) {
 	tmpDir := t.TempDir()
-	stateStore := persistence.NewStateStore(tmpDir)
+	stateStore := persistence.NewFileStateStore(tmpDir)
 
 	// Create test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
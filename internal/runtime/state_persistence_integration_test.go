@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,7 +21,7 @@ import (
 // Verifies that state is persisted after first execution and used in subsequent executions.
 func TestExecutor_StatePersistence_Timestamp(t *testing.T) {
 	tmpDir := t.TempDir()
-	stateStore := persistence.NewStateStore(tmpDir)
+	stateStore := persistence.NewFileStateStore(tmpDir)
 
 	// Create test server that tracks query parameters
 	var lastQueryParam string
@@ -124,7 +125,7 @@ func TestExecutor_StatePersistence_Timestamp(t *testing.T) {
 // Verifies that state is persisted after first execution and used in subsequent executions.
 func TestExecutor_StatePersistence_ID(t *testing.T) {
 	tmpDir := t.TempDir()
-	stateStore := persistence.NewStateStore(tmpDir)
+	stateStore := persistence.NewFileStateStore(tmpDir)
 
 	// Create test server that tracks query parameters
 	var lastQueryParam string
@@ -228,7 +229,7 @@ func TestExecutor_StatePersistence_ID(t *testing.T) {
 // Verifies that state persists across executor instances (simulating runtime restart).
 func TestExecutor_StatePersistence_AfterRestart(t *testing.T) {
 	tmpDir := t.TempDir()
-	stateStore := persistence.NewStateStore(tmpDir)
+	stateStore := persistence.NewFileStateStore(tmpDir)
 
 	// Create test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -368,7 +369,7 @@ func TestExecutor_StatePersistence_NoStateStore(t *testing.T) {
 // TestExecutor_StatePersistence_FailedExecution verifies that state is NOT persisted on failure.
 func TestExecutor_StatePersistence_FailedExecution(t *testing.T) {
 	tmpDir := t.TempDir()
-	stateStore := persistence.NewStateStore(tmpDir)
+	stateStore := persistence.NewFileStateStore(tmpDir)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -427,3 +428,337 @@ func TestExecutor_StatePersistence_FailedExecution(t *testing.T) {
 		t.Error("State should NOT be persisted when execution fails")
 	}
 }
+
+// TestExecutor_StatePersistence_ForEachCommit tests that per-value forEach state
+// is committed only after a successful execution.
+func TestExecutor_StatePersistence_ForEachCommit(t *testing.T) {
+	tmpDir := t.TempDir()
+	stateStore := persistence.NewFileStateStore(tmpDir)
+
+	var mu sync.Mutex
+	afterIDs := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		afterIDs[r.URL.Query().Get("account")] = r.URL.Query().Get("after_id")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		records := []map[string]interface{}{
+			{"id": r.URL.Query().Get("account") + "-1"},
+		}
+		_ = json.NewEncoder(w).Encode(records)
+	}))
+	defer server.Close()
+
+	config := &connector.ModuleConfig{
+		Type: "http-polling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL + "?account={{forEach.value}}",
+			"forEach": map[string]interface{}{
+				"values": []interface{}{"a", "b"},
+			},
+			"statePersistence": map[string]interface{}{
+				"id": map[string]interface{}{
+					"enabled":    true,
+					"field":      "id",
+					"queryParam": "after_id",
+				},
+			},
+		},
+	}
+
+	pipeline := &connector.Pipeline{
+		ID:      "test-pipeline-foreach",
+		Name:    "Test Pipeline",
+		Version: "1.0.0",
+		Enabled: true,
+	}
+
+	// Failed execution: per-value state must not be committed
+	failingInput, err := input.NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+	}
+	failingExecutor := NewExecutorWithModules(failingInput, nil, NewMockOutputModule(errors.New("simulated output failure")), false)
+	failingExecutor.SetStateStore(stateStore)
+	if _, err := failingExecutor.Execute(pipeline); err == nil {
+		t.Fatal("Execution should fail")
+	}
+	exists, err := stateStore.Exists(pipeline.ID)
+	if err != nil {
+		t.Fatalf("Failed to check state: %v", err)
+	}
+	if exists {
+		t.Error("forEach state should NOT be committed when execution fails")
+	}
+
+	// Successful execution: per-value state is committed
+	inputModule, err := input.NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+	}
+	executor := NewExecutorWithModules(inputModule, nil, NewMockOutputModule(nil), false)
+	executor.SetStateStore(stateStore)
+	result, err := executor.Execute(pipeline)
+	if err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if result.Status != "success" {
+		t.Errorf("Execution status = %s, want success", result.Status)
+	}
+
+	// Per-value states are named keys of the pipeline state, saved with it
+	state, err := stateStore.Load(pipeline.ID)
+	if err != nil || state == nil {
+		t.Fatalf("Failed to load state: %v, %v", state, err)
+	}
+	for _, value := range []string{"a", "b"} {
+		var valueState persistence.State
+		raw := state.Keys[`input.forEach."`+value+`"`]
+		if err := json.Unmarshal(raw, &valueState); err != nil || valueState.LastID == nil || *valueState.LastID != value+"-1" {
+			t.Errorf("forEach state for %s = %s (err %v), want LastID %s-1", value, raw, err, value)
+		}
+	}
+
+	// Next execution resumes each value from its own state
+	nextInput, err := input.NewHTTPPollingFromConfig(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+	}
+	nextExecutor := NewExecutorWithModules(nextInput, nil, NewMockOutputModule(nil), false)
+	nextExecutor.SetStateStore(stateStore)
+	if _, err := nextExecutor.Execute(pipeline); err != nil {
+		t.Fatalf("Execution failed: %v", err)
+	}
+	if afterIDs["a"] != "a-1" || afterIDs["b"] != "b-1" {
+		t.Errorf("after_id = %v, want the last ID of each value", afterIDs)
+	}
+}
+
+// TestExecutor_StatePersistence_Conditional tests that response validators are
+// persisted and that a 304 Not Modified run succeeds with zero records while
+// keeping the previous ID cursor.
+func TestExecutor_StatePersistence_Conditional(t *testing.T) {
+	tmpDir := t.TempDir()
+	stateStore := persistence.NewFileStateStore(tmpDir)
+
+	var lastIfNoneMatch string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastIfNoneMatch = r.Header.Get("If-None-Match")
+		if lastIfNoneMatch == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		records := []map[string]interface{}{
+			{"id": "1"},
+			{"id": "2"},
+		}
+		_ = json.NewEncoder(w).Encode(records)
+	}))
+	defer server.Close()
+
+	config := &connector.ModuleConfig{
+		Type: "http-polling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"statePersistence": map[string]interface{}{
+				"id": map[string]interface{}{
+					"enabled":    true,
+					"field":      "id",
+					"queryParam": "after_id",
+				},
+				"conditional": map[string]interface{}{
+					"enabled": true,
+				},
+			},
+		},
+	}
+
+	pipeline := &connector.Pipeline{
+		ID:      "test-pipeline-conditional",
+		Name:    "Test Pipeline",
+		Version: "1.0.0",
+		Enabled: true,
+	}
+
+	for run := 1; run <= 2; run++ {
+		inputModule, err := input.NewHTTPPollingFromConfig(config)
+		if err != nil {
+			t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+		}
+		executor := NewExecutorWithModules(inputModule, nil, NewMockOutputModule(nil), false)
+		executor.SetStateStore(stateStore)
+
+		result, err := executor.Execute(pipeline)
+		if err != nil {
+			t.Fatalf("Execution %d failed: %v", run, err)
+		}
+		if result.Status != "success" {
+			t.Errorf("Execution %d status = %s, want success", run, result.Status)
+		}
+
+		wantRecords := 2
+		if run == 2 {
+			wantRecords = 0
+			if lastIfNoneMatch != `"v1"` {
+				t.Errorf("Execution 2 If-None-Match = %q, want %q", lastIfNoneMatch, `"v1"`)
+			}
+		}
+		if result.RecordsProcessed != wantRecords {
+			t.Errorf("Execution %d records = %d, want %d", run, result.RecordsProcessed, wantRecords)
+		}
+
+		state, err := stateStore.Load(pipeline.ID)
+		if err != nil {
+			t.Fatalf("Failed to load state: %v", err)
+		}
+		if state == nil || state.ETag != `"v1"` {
+			t.Fatalf("Execution %d state = %+v, want ETag \"v1\"", run, state)
+		}
+		if state.LastID == nil || *state.LastID != "2" {
+			t.Errorf("Execution %d state LastID = %v, want 2", run, state.LastID)
+		}
+	}
+}
+
+// mockCursorInput is a state-persistent input tracking a composite cursor.
+type mockCursorInput struct {
+	MockInputModule
+	pipelineID string
+	store      persistence.StateStore
+	lastState  *persistence.State
+	cursor     map[string]interface{}
+}
+
+func (m *mockCursorInput) SetPipelineID(pipelineID string)            { m.pipelineID = pipelineID }
+func (m *mockCursorInput) SetStateStore(store persistence.StateStore) { m.store = store }
+func (m *mockCursorInput) GetLastState() *persistence.State           { return m.lastState }
+func (m *mockCursorInput) LastCursor() map[string]interface{}         { return m.cursor }
+
+func (m *mockCursorInput) LoadState() (*persistence.State, error) {
+	state, err := m.store.Load(m.pipelineID)
+	m.lastState = state
+	return state, err
+}
+
+func (m *mockCursorInput) GetPersistenceConfig() *persistence.StatePersistenceConfig {
+	return &persistence.StatePersistenceConfig{
+		Cursor: &persistence.CursorConfig{Enabled: true, Fields: []string{"updated_at", "id"}},
+	}
+}
+
+// TestExecutor_StatePersistence_Cursor tests that the composite cursor of the
+// last row is persisted, and kept when an execution reads no rows.
+func TestExecutor_StatePersistence_Cursor(t *testing.T) {
+	stateStore := persistence.NewFileStateStore(t.TempDir())
+	pipeline := &connector.Pipeline{
+		ID:      "test-pipeline-cursor",
+		Name:    "Test Pipeline",
+		Version: "1.0.0",
+		Enabled: true,
+	}
+
+	runs := []struct {
+		records []map[string]interface{}
+		cursor  map[string]interface{}
+	}{
+		{
+			records: []map[string]interface{}{{"id": int64(1)}, {"id": int64(2)}},
+			cursor:  map[string]interface{}{"updated_at": "2026-01-26 10:00:00", "id": int64(2)},
+		},
+		{records: nil, cursor: nil},
+	}
+
+	for i, run := range runs {
+		inputModule := &mockCursorInput{MockInputModule: MockInputModule{data: run.records}, cursor: run.cursor}
+		executor := NewExecutorWithModules(inputModule, nil, NewMockOutputModule(nil), false)
+		executor.SetStateStore(stateStore)
+		if _, err := executor.Execute(pipeline); err != nil {
+			t.Fatalf("Execution %d failed: %v", i+1, err)
+		}
+
+		state, err := stateStore.Load(pipeline.ID)
+		if err != nil {
+			t.Fatalf("Failed to load state: %v", err)
+		}
+		if state == nil || state.Cursor["updated_at"] != "2026-01-26 10:00:00" || state.Cursor["id"] != float64(2) {
+			t.Errorf("Execution %d state cursor = %+v, want updated_at 2026-01-26 10:00:00 and id 2", i+1, state)
+		}
+	}
+}
+
+// TestExecutor_StatePersistence_Watermark tests that the timestamp persisted
+// is the maximum record timestamp, sent back minus the lookback, and that the
+// watermark does not move backwards when only older records are returned.
+func TestExecutor_StatePersistence_Watermark(t *testing.T) {
+	stateStore := persistence.NewFileStateStore(t.TempDir())
+
+	var sinceParams []string
+	responses := [][]map[string]interface{}{
+		{
+			{"id": "1", "updated_at": "2026-01-26T10:00:00Z"},
+			{"id": "2", "updated_at": "2026-01-26T10:30:00Z"},
+			{"id": "3", "updated_at": "2026-01-26T10:15:00Z"},
+		},
+		{
+			// Late record inside the lookback window, older than the watermark
+			{"id": "4", "updated_at": "2026-01-26T10:28:00Z"},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sinceParams = append(sinceParams, r.URL.Query().Get("since"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(responses[len(sinceParams)-1])
+	}))
+	defer server.Close()
+
+	config := &connector.ModuleConfig{
+		Type: "http-polling",
+		Config: map[string]interface{}{
+			"endpoint": server.URL,
+			"statePersistence": map[string]interface{}{
+				"timestamp": map[string]interface{}{
+					"enabled":         true,
+					"queryParam":      "since",
+					"field":           "updated_at",
+					"lookbackSeconds": float64(300),
+				},
+			},
+		},
+	}
+	pipeline := &connector.Pipeline{
+		ID:      "test-pipeline-watermark",
+		Name:    "Test Pipeline",
+		Version: "1.0.0",
+		Enabled: true,
+	}
+
+	want := time.Date(2026, 1, 26, 10, 30, 0, 0, time.UTC)
+	for run := 1; run <= 2; run++ {
+		inputModule, err := input.NewHTTPPollingFromConfig(config)
+		if err != nil {
+			t.Fatalf("NewHTTPPollingFromConfig() error = %v", err)
+		}
+		executor := NewExecutorWithModules(inputModule, nil, NewMockOutputModule(nil), false)
+		executor.SetStateStore(stateStore)
+		if _, err := executor.Execute(pipeline); err != nil {
+			t.Fatalf("Execution %d failed: %v", run, err)
+		}
+
+		state, err := stateStore.Load(pipeline.ID)
+		if err != nil {
+			t.Fatalf("Failed to load state: %v", err)
+		}
+		if state == nil || state.LastTimestamp == nil || !state.LastTimestamp.Equal(want) {
+			t.Errorf("Execution %d state = %+v, want LastTimestamp %v", run, state, want)
+		}
+	}
+
+	if sinceParams[0] != "" {
+		t.Errorf("first execution since = %q, want none", sinceParams[0])
+	}
+	if sinceParams[1] != "2026-01-26T10:25:00Z" {
+		t.Errorf("second execution since = %q, want watermark minus lookback 2026-01-26T10:25:00Z", sinceParams[1])
+	}
+}
@@ -0,0 +1,118 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/modules/input"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// executeStreamingStages executes the pipeline for a streaming input module:
+// filters and output run once per chunk handed by the input, so the full
+// result set is never held in memory. The first filter or output error stops
+// the stream. Records already sent by previous chunks are counted in
+// result.RecordsProcessed. If cp is set, a checkpoint is saved after each
+// chunk delivered by the output.
+//
+// Returns timings (input duration excludes the time spent in filters and
+// output), the record marks extracted from the raw records (last ID of the
+// last chunk, watermark across all chunks), and any error encountered.
+func (e *Executor) executeStreamingStages(
+	ctx context.Context,
+	pipeline *connector.Pipeline,
+	result *connector.ExecutionResult,
+	execCtx logger.ExecutionContext,
+	startedAt time.Time,
+	persistenceConfig *persistence.StatePersistenceConfig,
+	streamer input.StreamingModule,
+	cp *checkpointer,
+) (stageTimings, recordMarks, error) {
+	var timings stageTimings
+	// A resumed run starts from the marks of the chunks delivered before the interruption
+	marks := cp.initialMarks()
+	var stageErr error
+	inputRecords, chunks := 0, 0
+
+	stageCtx := logger.ExecutionContext{
+		PipelineID:   pipeline.ID,
+		PipelineName: pipeline.Name,
+		Stage:        "input",
+		DryRun:       e.dryRun,
+	}
+	logger.LogStageStart(stageCtx)
+
+	streamStart := time.Now()
+	err := streamer.Stream(ctx, func(ctx context.Context, records []map[string]interface{}) error {
+		chunks++
+		inputRecords += len(records)
+		logger.Debug("processing streamed chunk",
+			slog.String("pipeline_id", pipeline.ID),
+			slog.Int("chunk", chunks),
+			slog.Int("chunk_records", len(records)),
+		)
+
+		marks.merge(extractRecordMarks(pipeline.ID, records, persistenceConfig))
+
+		filtered, filterDuration, err := e.executeFiltersWithResult(ctx, pipeline, records, result)
+		timings.filterDuration += filterDuration
+		if err != nil {
+			stageErr = err
+			return err
+		}
+
+		if e.dryRun {
+			result.DryRunPreview = append(result.DryRunPreview, e.executeDryRunPreview(pipeline.ID, filtered, pipeline.DryRunOptions)...)
+		}
+
+		// executeOutputWithResult reports the records sent by this chunk only
+		sentBefore := result.RecordsProcessed
+		outputDuration, err := e.executeOutputWithResult(ctx, pipeline, filtered, result)
+		timings.outputDuration += outputDuration
+		result.RecordsProcessed += sentBefore
+		if err != nil {
+			stageErr = err
+			return err
+		}
+		cp.save(marks, result.RecordsProcessed-sentBefore)
+		return nil
+	})
+	timings.inputDuration = time.Since(streamStart) - timings.filterDuration - timings.outputDuration
+
+	// Close input module as soon as the stream ends
+	e.closeModule(pipeline.ID, "input", e.inputModule)
+	e.inputModule = nil // Prevent double-close
+
+	if stageErr != nil {
+		// Filter or output failure: result already updated by the stage
+		e.handleExecutionFailure(execCtx, startedAt, StatusError, result.RecordsProcessed)
+		return timings, recordMarks{}, stageErr
+	}
+
+	if err != nil {
+		result.CompletedAt = time.Now()
+		result.Error = buildExecutionError(ErrCodeInputFailed, "input", err)
+		if p, ok := streamer.(connector.RetryInfoProvider); ok {
+			result.RetryInfo = p.GetRetryInfo()
+		}
+		logger.LogStageEnd(stageCtx, inputRecords, timings.inputDuration, &logger.ExecutionError{
+			Code:    ErrCodeInputFailed,
+			Message: err.Error(),
+		})
+		e.handleExecutionFailure(execCtx, startedAt, StatusError, result.RecordsProcessed)
+		return timings, recordMarks{}, fmt.Errorf("executing input module: %w", err)
+	}
+
+	logger.LogStageEnd(stageCtx, inputRecords, timings.inputDuration, nil)
+	logger.Info("streamed input completed",
+		slog.String("pipeline_id", pipeline.ID),
+		slog.Int("chunks", chunks),
+		slog.Int("input_records", inputRecords),
+		slog.Int("records_processed", result.RecordsProcessed),
+	)
+	return timings, marks, nil
+}
@@ -0,0 +1,139 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cannectors/runtime/internal/modules/filter"
+	"github.com/cannectors/runtime/internal/modules/input"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// MockStreamingInputModule is a test mock for input.StreamingModule
+type MockStreamingInputModule struct {
+	chunks      [][]map[string]interface{}
+	err         error // returned after all chunks are handed
+	fetchCalled bool
+	closed      bool
+}
+
+func (m *MockStreamingInputModule) Fetch(_ context.Context) ([]map[string]interface{}, error) {
+	m.fetchCalled = true
+	return nil, errors.New("Fetch should not be called on a streaming input")
+}
+
+func (m *MockStreamingInputModule) Streaming() bool { return true }
+
+func (m *MockStreamingInputModule) Stream(ctx context.Context, handle input.ChunkHandler) error {
+	for _, chunk := range m.chunks {
+		if err := handle(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return m.err
+}
+
+func (m *MockStreamingInputModule) Close() error {
+	m.closed = true
+	return nil
+}
+
+// Verify MockStreamingInputModule implements input.StreamingModule
+var _ input.StreamingModule = (*MockStreamingInputModule)(nil)
+
+// chunkRecordingOutput records the size of each Send call.
+type chunkRecordingOutput struct {
+	MockOutputModule
+	sends   []int
+	failAt  int // 1-based Send call failing, 0 for none
+	sendErr error
+}
+
+func (m *chunkRecordingOutput) Send(_ context.Context, records []map[string]interface{}) (int, error) {
+	m.sends = append(m.sends, len(records))
+	if m.failAt == len(m.sends) {
+		return 0, m.sendErr
+	}
+	return len(records), nil
+}
+
+func streamingChunks() [][]map[string]interface{} {
+	return [][]map[string]interface{}{
+		{{"id": "1"}, {"id": "2"}},
+		{{"id": "3"}, {"id": "4"}},
+		{{"id": "5"}},
+	}
+}
+
+func TestExecutor_Streaming_ProcessesChunks(t *testing.T) {
+	mockInput := &MockStreamingInputModule{chunks: streamingChunks()}
+	mockOutput := &chunkRecordingOutput{}
+	filterCalls := 0
+	mockFilter := NewMockFilterModule(func(records []map[string]interface{}) ([]map[string]interface{}, error) {
+		filterCalls++
+		return records, nil
+	})
+
+	executor := NewExecutorWithModules(mockInput, []filter.Module{mockFilter}, mockOutput, false)
+
+	result, err := executor.Execute(&connector.Pipeline{ID: "streaming-test", Name: "Streaming", Version: "1.0.0", Enabled: true})
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if result.Status != StatusSuccess {
+		t.Errorf("expected status success, got %s", result.Status)
+	}
+	if result.RecordsProcessed != 5 {
+		t.Errorf("expected 5 records processed, got %d", result.RecordsProcessed)
+	}
+	if filterCalls != 3 {
+		t.Errorf("expected filters to run once per chunk, got %d calls", filterCalls)
+	}
+	if len(mockOutput.sends) != 3 || mockOutput.sends[0] != 2 || mockOutput.sends[2] != 1 {
+		t.Errorf("expected output to receive chunks [2 2 1], got %v", mockOutput.sends)
+	}
+	if mockInput.fetchCalled {
+		t.Error("expected Stream to be used instead of Fetch")
+	}
+	if !mockInput.closed {
+		t.Error("expected input module to be closed after the stream")
+	}
+}
+
+func TestExecutor_Streaming_OutputErrorStopsStream(t *testing.T) {
+	mockInput := &MockStreamingInputModule{chunks: streamingChunks()}
+	mockOutput := &chunkRecordingOutput{failAt: 2, sendErr: errors.New("destination unavailable")}
+
+	executor := NewExecutorWithModules(mockInput, nil, mockOutput, false)
+	result, err := executor.Execute(&connector.Pipeline{ID: "streaming-output-error", Name: "Streaming", Version: "1.0.0", Enabled: true})
+	if err == nil {
+		t.Fatal("expected Execute() to fail when output fails")
+	}
+	if result.Error == nil || result.Error.Module != "output" {
+		t.Errorf("expected output error in result, got %+v", result.Error)
+	}
+	if len(mockOutput.sends) != 2 {
+		t.Errorf("expected the stream to stop after the failing chunk, got %d sends", len(mockOutput.sends))
+	}
+	if result.RecordsProcessed != 2 {
+		t.Errorf("expected records of the first chunk counted as processed, got %d", result.RecordsProcessed)
+	}
+}
+
+func TestExecutor_Streaming_InputError(t *testing.T) {
+	mockInput := &MockStreamingInputModule{chunks: streamingChunks()[:1], err: errors.New("connection reset")}
+	mockOutput := &chunkRecordingOutput{}
+
+	executor := NewExecutorWithModules(mockInput, nil, mockOutput, false)
+	result, err := executor.Execute(&connector.Pipeline{ID: "streaming-input-error", Name: "Streaming", Version: "1.0.0", Enabled: true})
+	if err == nil {
+		t.Fatal("expected Execute() to fail when the stream fails")
+	}
+	if result.Error == nil || result.Error.Code != ErrCodeInputFailed {
+		t.Errorf("expected input error in result, got %+v", result.Error)
+	}
+	if !mockInput.closed {
+		t.Error("expected input module to be closed on error")
+	}
+}
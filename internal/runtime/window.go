@@ -0,0 +1,59 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cannectors/runtime/internal/logger"
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// ErrWindowNotSupported is returned when a windowed execution is requested for
+// an input module that cannot read a time range.
+var ErrWindowNotSupported = errors.New("input module does not support time windows")
+
+// WindowInput is an optional interface for input modules that can read a
+// fixed time range instead of resuming from the persisted state.
+type WindowInput interface {
+	// SetWindow makes the next fetch read the records of window: its bounds
+	// are sent in the timestamp parameters instead of the persisted
+	// timestamp. Returns an error if the module is not configured with
+	// parameters to send both bounds in.
+	SetWindow(window persistence.Window) error
+}
+
+// SetWindow makes executions read the records of a fixed time range, to
+// backfill a pipeline. Windowed executions leave the pipeline state untouched:
+// the state store is neither read nor saved, and no pipeline lock is held.
+// nil restores incremental executions.
+func (e *Executor) SetWindow(window *persistence.Window) {
+	e.window = window
+}
+
+// applyWindow hands the window to the input module. On failure, the result is
+// updated with the error.
+func (e *Executor) applyWindow(pipeline *connector.Pipeline, result *connector.ExecutionResult, execCtx logger.ExecutionContext, startedAt time.Time) error {
+	wInput, ok := e.inputModule.(WindowInput)
+	var err error
+	if !ok {
+		err = ErrWindowNotSupported
+	} else {
+		err = wInput.SetWindow(*e.window)
+	}
+	if err == nil {
+		logger.Info("executing pipeline for time window",
+			slog.String("pipeline_id", pipeline.ID),
+			slog.Time("from", e.window.From),
+			slog.Time("to", e.window.To),
+		)
+		return nil
+	}
+
+	result.CompletedAt = time.Now()
+	result.Error = buildExecutionError(ErrCodeInputFailed, "input", err)
+	e.handleExecutionFailure(execCtx, startedAt, StatusError, 0)
+	return fmt.Errorf("applying time window: %w", err)
+}
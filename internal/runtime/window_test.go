@@ -0,0 +1,97 @@
+package runtime
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cannectors/runtime/internal/persistence"
+	"github.com/cannectors/runtime/pkg/connector"
+)
+
+// mockWindowInput is a state-persistent input recording the window it reads.
+type mockWindowInput struct {
+	mockCursorInput
+	window    *persistence.Window
+	windowErr error
+}
+
+func (m *mockWindowInput) SetWindow(window persistence.Window) error {
+	if m.windowErr != nil {
+		return m.windowErr
+	}
+	m.window = &window
+	return nil
+}
+
+var _ WindowInput = (*mockWindowInput)(nil)
+
+func TestExecutor_Window_LeavesStateUntouched(t *testing.T) {
+	stateStore := persistence.NewFileStateStore(t.TempDir())
+	pipeline := &connector.Pipeline{ID: "window", Name: "Window", Version: "1.0.0", Enabled: true}
+	lastID := "7"
+	if err := stateStore.Save(pipeline.ID, &persistence.State{LastID: &lastID}); err != nil {
+		t.Fatal(err)
+	}
+
+	window := persistence.Window{
+		From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	mockInput := &mockWindowInput{mockCursorInput: mockCursorInput{
+		MockInputModule: MockInputModule{data: []map[string]interface{}{{"id": int64(1)}}},
+		cursor:          map[string]interface{}{"updated_at": "2026-01-01 10:00:00", "id": int64(1)},
+	}}
+	executor := NewExecutorWithModules(mockInput, nil, NewMockOutputModule(nil), false)
+	executor.SetStateStore(stateStore)
+	executor.SetWindow(&window)
+
+	result, err := executor.Execute(pipeline)
+	if err != nil || result.Status != StatusSuccess || result.RecordsProcessed != 1 {
+		t.Fatalf("Execute = %+v, %v, want success", result, err)
+	}
+	if mockInput.window == nil || *mockInput.window != window {
+		t.Errorf("input window = %+v, want %+v", mockInput.window, window)
+	}
+	if mockInput.lastState != nil {
+		t.Errorf("windowed execution loaded the pipeline state: %+v", mockInput.lastState)
+	}
+
+	state, err := stateStore.Load(pipeline.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state == nil || state.Cursor != nil || state.LastID == nil || *state.LastID != "7" {
+		t.Errorf("state after windowed execution = %+v, want unchanged", state)
+	}
+}
+
+func TestExecutor_Window_NotSupported(t *testing.T) {
+	pipeline := &connector.Pipeline{ID: "window-unsupported", Name: "Window", Version: "1.0.0", Enabled: true}
+	mockInput := NewMockInputModule([]map[string]interface{}{{"id": 1}}, nil)
+	executor := NewExecutorWithModules(mockInput, nil, NewMockOutputModule(nil), false)
+	executor.SetWindow(&persistence.Window{From: time.Now().Add(-time.Hour), To: time.Now()})
+
+	result, err := executor.Execute(pipeline)
+	if !errors.Is(err, ErrWindowNotSupported) {
+		t.Fatalf("Execute() error = %v, want ErrWindowNotSupported", err)
+	}
+	if result.Status != StatusError || result.Error == nil || result.Error.Module != "input" {
+		t.Errorf("result = %+v, want input error", result)
+	}
+	if mockInput.fetchCalled {
+		t.Error("input fetched despite unsupported window")
+	}
+}
+
+func TestExecutor_Window_InvalidParameters(t *testing.T) {
+	pipeline := &connector.Pipeline{ID: "window-invalid", Name: "Window", Version: "1.0.0", Enabled: true}
+	paramsErr := errors.New("no parameter for the window end")
+	mockInput := &mockWindowInput{windowErr: paramsErr}
+	executor := NewExecutorWithModules(mockInput, nil, NewMockOutputModule(nil), false)
+	executor.SetWindow(&persistence.Window{From: time.Now().Add(-time.Hour), To: time.Now()})
+
+	if _, err := executor.Execute(pipeline); !errors.Is(err, paramsErr) {
+		t.Errorf("Execute() error = %v, want %v", err, paramsErr)
+	}
+}
@@ -120,12 +120,37 @@ type RetryInfoProvider interface {
 	GetRetryInfo() *RetryInfo
 }
 
+// RecordFailure is a record an output module failed to send.
+type RecordFailure struct {
+	// Record is the record as passed to the output module
+	Record map[string]interface{} `json:"record"`
+
+	// Error describes why the record was not sent or was rejected
+	Error string `json:"error"`
+}
+
+// RecordFailureProvider is implemented by output modules that track which
+// records failed (e.g. HTTP Request). The executor uses it to populate
+// ExecutionResult.FailedRecords, and reports executions with failed records
+// as partial.
+type RecordFailureProvider interface {
+	FailedRecords() []RecordFailure
+}
+
+// ReturnedRecordsProvider is implemented by output modules that read values
+// back from the destination (e.g. Database with returning). The executor uses
+// it to populate ExecutionResult.ReturnedRecords.
+type ReturnedRecordsProvider interface {
+	ReturnedRecords() []map[string]interface{}
+}
+
 // ExecutionResult represents the result of a pipeline execution.
 type ExecutionResult struct {
 	// PipelineID is the ID of the executed pipeline
 	PipelineID string `json:"pipelineId"`
 
-	// Status is the execution status ("success", "error", "partial")
+	// Status is the execution status ("success", "error", "partial").
+	// Executions whose output module skipped failed records are partial.
 	Status string `json:"status"`
 
 	// StartedAt is when execution started
@@ -146,18 +171,47 @@ type ExecutionResult struct {
 	// RetryInfo holds retry information from the last stage that performed retries (Input or Output)
 	RetryInfo *RetryInfo `json:"retryInfo,omitempty"`
 
+	// FailedRecords holds the records the output module failed to send,
+	// when it tracks them (see RecordFailureProvider)
+	FailedRecords []RecordFailure `json:"failedRecords,omitempty"`
+
+	// ReturnedRecords holds the records written by the output module with
+	// the values returned by the destination (e.g. generated ids)
+	ReturnedRecords []map[string]interface{} `json:"returnedRecords,omitempty"`
+
 	// DryRunPreview contains preview of requests that would be sent (only set in dry-run mode)
 	// For output modules implementing PreviewableModule, this shows what would be sent
 	DryRunPreview []RequestPreview `json:"dryRunPreview,omitempty"`
+
+	// ResumedFrom is set when the execution resumed an interrupted run from
+	// its last checkpoint. RecordsProcessed counts this execution only.
+	ResumedFrom *ResumeInfo `json:"resumedFrom,omitempty"`
+}
+
+// ResumeInfo describes the checkpoint an execution resumed from.
+type ResumeInfo struct {
+	// RunStartedAt is when the interrupted run started
+	RunStartedAt time.Time `json:"runStartedAt"`
+
+	// CheckpointedAt is when the checkpoint was saved
+	CheckpointedAt time.Time `json:"checkpointedAt"`
+
+	// Chunks is the number of chunks delivered before the interruption
+	Chunks int `json:"chunks"`
+
+	// RecordsProcessed is the number of records sent before the interruption
+	RecordsProcessed int `json:"recordsProcessed"`
 }
 
-// RequestPreview contains the preview of an HTTP request that would be sent.
+// RequestPreview contains the preview of a request that would be sent.
 // Used in dry-run mode to show what would be sent without actually sending.
+// SQL-based output modules describe the statement in SQL.
 type RequestPreview struct {
-	// Endpoint is the resolved URL including path parameters and query params
+	// Endpoint is the resolved URL including path parameters and query params,
+	// or the target table of a SQL statement
 	Endpoint string `json:"endpoint"`
 
-	// Method is the HTTP method (POST, PUT, PATCH)
+	// Method is the HTTP method (POST, PUT, PATCH), or the SQL operation
 	Method string `json:"method"`
 
 	// Headers contains all request headers. Authentication-related headers may be
@@ -170,6 +224,31 @@ type RequestPreview struct {
 
 	// RecordCount is the number of records included in this request
 	RecordCount int `json:"recordCount"`
+
+	// SQL describes the statement executed for SQL-based output modules
+	SQL *SQLPreview `json:"sql,omitempty"`
+}
+
+// SQLPreview describes a SQL statement that would be executed.
+type SQLPreview struct {
+	// Statement is the rendered statement, with the driver's placeholders
+	Statement string `json:"statement"`
+
+	// Parameters are the values bound to the placeholders, in order
+	// (sensitive values masked unless ShowCredentials is enabled)
+	Parameters []interface{} `json:"parameters"`
+
+	// Table is the target table, if known
+	Table string `json:"table,omitempty"`
+
+	// Operation is the kind of statement (insert, upsert, update, delete, ...)
+	Operation string `json:"operation"`
+
+	// Executed is set when the statement was executed in a rolled back transaction
+	Executed bool `json:"executed,omitempty"`
+
+	// Error is the database error of the executed statement, if any
+	Error string `json:"error,omitempty"`
 }
 
 // ExecutionError contains details about an execution failure.